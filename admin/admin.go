@@ -0,0 +1,457 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// Action is what a request to the Handler wants to do
+type Action string
+
+const (
+	// ActionRead lists circuits or reads a single circuit
+	ActionRead Action = "read"
+	// ActionForceOpen forces a circuit open
+	ActionForceOpen Action = "open"
+	// ActionForceClosed forces a circuit closed
+	ActionForceClosed Action = "close"
+	// ActionReset removes a forced open or closed state
+	ActionReset Action = "reset"
+	// ActionPassThrough turns pass through on or off.  Circuits that pass through run every request with no checks.
+	ActionPassThrough Action = "passthrough"
+	// ActionConfig changes timeouts and thresholds
+	ActionConfig Action = "config"
+)
+
+// Handler serves the admin API.  Mount it with http.StripPrefix if it is not at the root of your mux.
+//
+//	GET  /circuits                    lists every circuit
+//	GET  /circuits/{name}             shows one circuit
+//	GET  /circuits/{name}/errors      shows the circuit's recent errors and bad requests
+//	POST /circuits/{name}/open        forces the circuit open
+//	POST /circuits/{name}/close       forces the circuit closed
+//	POST /circuits/{name}/reset       stops forcing the circuit open or closed
+//	POST /circuits/{name}/passthrough sets pass through with a body of {"enabled": true}
+//	POST /circuits/{name}/config      changes settings with a ConfigUpdate body
+//	GET  /watch                       streams server sent events of circuits that change state
+//	GET  /audit                       lists the config changes recorded by Audit
+//	GET  /memory                      estimates the memory held by each circuit, largest first, with ?limit=N
+//
+// Names are path escaped.  Responses are JSON CircuitStatus values.
+type Handler struct {
+	Manager *circuit.Manager
+	// Authorize decides if a request may take an action on a circuit.  circuitName is empty when listing circuits.
+	// Return an error to refuse the request.  If Authorize is nil, only ActionRead is allowed.
+	Authorize func(req *http.Request, action Action, circuitName string) error
+	// WatchInterval is how often /watch checks for state changes.  The default is one second.
+	WatchInterval time.Duration
+	// Audit is served at /audit.  Use the circuit.ConfigAuditLog given to the circuits as GeneralConfig.ConfigAudit.
+	Audit *circuit.ConfigAuditLog
+	// Identify names who sent a request, for the Source of the config changes it makes.  The default is the
+	// request's remote address.
+	Identify func(req *http.Request) string
+
+	// mu keeps concurrent changes to the same circuit from overwriting each other
+	mu sync.Mutex
+}
+
+var _ http.Handler = &Handler{}
+
+// CircuitStatus describes a circuit
+type CircuitStatus struct {
+	Name               string            `json:"name"`
+	State              circuit.StateInfo `json:"state"`
+	ForceOpen          bool              `json:"force_open"`
+	ForcedClosed       bool              `json:"forced_closed"`
+	PassThrough        bool              `json:"pass_through"`
+	Timeout            string            `json:"timeout"`
+	MaxConcurrent      int64             `json:"max_concurrent_requests"`
+	ConcurrentCommands int64             `json:"concurrent_commands"`
+	Opener             interface{}       `json:"opener,omitempty"`
+	Closer             interface{}       `json:"closer,omitempty"`
+}
+
+// RecentErrors are the most recent errors and bad requests of a circuit, in order backwards in time.  Bad requests
+// do not change the health of a circuit, so a spike of them, usually from a client regression, only shows up here.
+type RecentErrors struct {
+	Name        string                `json:"name"`
+	Errors      []circuit.ErrorSample `json:"errors"`
+	BadRequests []circuit.ErrorSample `json:"bad_requests"`
+}
+
+// CircuitMemory is the estimated memory held by a circuit
+type CircuitMemory struct {
+	Name  string              `json:"name"`
+	Bytes int64               `json:"bytes"`
+	Usage circuit.MemoryUsage `json:"usage"`
+}
+
+// MemoryReport estimates the memory held by circuits.  Services that create many keyed circuits can use it to find
+// which hold the most, and to bound their footprint.
+type MemoryReport struct {
+	// Circuits is how many circuits there are, and Bytes is what all of them hold
+	Circuits int   `json:"circuits"`
+	Bytes    int64 `json:"bytes"`
+	// Largest are the circuits holding the most memory, largest first
+	Largest []CircuitMemory `json:"largest"`
+}
+
+// ConfigUpdate changes settings of a circuit.  Fields that are not set are not changed.  Durations are strings that
+// time.ParseDuration understands.  The opener and closer settings only work for hystrix openers and closers.
+type ConfigUpdate struct {
+	Timeout                       string `json:"timeout,omitempty"`
+	MaxConcurrentRequests         *int64 `json:"max_concurrent_requests,omitempty"`
+	FallbackMaxConcurrentRequests *int64 `json:"fallback_max_concurrent_requests,omitempty"`
+	ErrorThresholdPercentage      *int64 `json:"error_threshold_percentage,omitempty"`
+	RequestVolumeThreshold        *int64 `json:"request_volume_threshold,omitempty"`
+	SleepWindow                   string `json:"sleep_window,omitempty"`
+}
+
+type passThroughRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Request is a single admin action on a circuit
+type Request struct {
+	// Name is the circuit to act on
+	Name   string
+	Action Action
+	// PassThrough is used by ActionPassThrough
+	PassThrough bool
+	// Update is used by ActionConfig
+	Update ConfigUpdate
+	// Source is recorded as who made the change in the circuit's config audit log.  The default is "admin".
+	Source string
+}
+
+// Error is returned by Handler methods.  Code is the HTTP status the admin API responds with, so other transports can
+// map it to their own codes.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &Error{Code: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP routes admin API requests
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch strings.Trim(req.URL.EscapedPath(), "/") {
+	case "watch":
+		if req.Method == http.MethodGet {
+			if err := h.authorize(req, ActionRead, ""); err != nil {
+				writeJSON(rw, http.StatusForbidden, map[string]string{"error": err.Error()})
+				return
+			}
+			h.watch(rw, req)
+			return
+		}
+	case "audit":
+		if req.Method == http.MethodGet && h.Audit != nil {
+			if err := h.authorize(req, ActionRead, ""); err != nil {
+				writeJSON(rw, http.StatusForbidden, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(rw, http.StatusOK, h.Audit.Changes())
+			return
+		}
+	case "memory":
+		if req.Method == http.MethodGet {
+			if err := h.authorize(req, ActionRead, ""); err != nil {
+				writeJSON(rw, http.StatusForbidden, map[string]string{"error": err.Error()})
+				return
+			}
+			limit := 0
+			if l := req.URL.Query().Get("limit"); l != "" {
+				var err error
+				if limit, err = strconv.Atoi(l); err != nil || limit < 0 {
+					writeJSON(rw, http.StatusBadRequest, map[string]string{"error": "invalid limit: " + l})
+					return
+				}
+			}
+			writeJSON(rw, http.StatusOK, h.Memory(limit))
+			return
+		}
+	}
+	name, action, recentErrors, err := route(req)
+	if err == nil {
+		err = h.authorize(req, action, name)
+	}
+	var resp interface{}
+	if err == nil {
+		if recentErrors {
+			resp, err = h.RecentErrors(name)
+		} else {
+			resp, err = h.serve(req, name, action)
+		}
+	}
+	if err != nil {
+		code := http.StatusInternalServerError
+		var ae *Error
+		if errors.As(err, &ae) {
+			code = ae.Code
+		}
+		writeJSON(rw, code, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(rw, http.StatusOK, resp)
+}
+
+// route parses the circuit name and action from a request.  recentErrors is true for reads of a circuit's errors.
+func route(req *http.Request) (name string, action Action, recentErrors bool, err error) {
+	parts := strings.Split(strings.Trim(req.URL.EscapedPath(), "/"), "/")
+	if len(parts) == 0 || parts[0] != "circuits" || len(parts) > 3 {
+		return "", "", false, &Error{Code: http.StatusNotFound, Message: "not found"}
+	}
+	if len(parts) > 1 {
+		if name, err = url.PathUnescape(parts[1]); err != nil {
+			return "", "", false, badRequest("invalid circuit name: %s", err)
+		}
+	}
+	if len(parts) < 3 || parts[2] == "errors" {
+		if req.Method != http.MethodGet {
+			return "", "", false, &Error{Code: http.StatusMethodNotAllowed, Message: "use GET"}
+		}
+		return name, ActionRead, len(parts) == 3, nil
+	}
+	if req.Method != http.MethodPost {
+		return "", "", false, &Error{Code: http.StatusMethodNotAllowed, Message: "use POST"}
+	}
+	action = Action(parts[2])
+	switch action {
+	case ActionForceOpen, ActionForceClosed, ActionReset, ActionPassThrough, ActionConfig:
+		return name, action, false, nil
+	}
+	return "", "", false, &Error{Code: http.StatusNotFound, Message: "unknown action " + parts[2]}
+}
+
+func (h *Handler) identify(req *http.Request) string {
+	if h.Identify != nil {
+		return h.Identify(req)
+	}
+	return req.RemoteAddr
+}
+
+func (h *Handler) authorize(req *http.Request, action Action, name string) error {
+	if h.Authorize == nil {
+		if action == ActionRead {
+			return nil
+		}
+		return &Error{Code: http.StatusForbidden, Message: "changes are not allowed without an Authorize hook"}
+	}
+	if err := h.Authorize(req, action, name); err != nil {
+		return &Error{Code: http.StatusForbidden, Message: err.Error()}
+	}
+	return nil
+}
+
+func (h *Handler) serve(req *http.Request, name string, action Action) (interface{}, error) {
+	if name == "" {
+		return h.List(), nil
+	}
+	r := Request{Name: name, Action: action, Source: "admin:" + h.identify(req)}
+	switch action {
+	case ActionPassThrough:
+		var body passThroughRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, badRequest("invalid body: %s", err)
+		}
+		r.PassThrough = body.Enabled
+	case ActionConfig:
+		if err := json.NewDecoder(req.Body).Decode(&r.Update); err != nil {
+			return nil, badRequest("invalid body: %s", err)
+		}
+	}
+	return h.Do(r)
+}
+
+// Do runs a request and returns the circuit's new status.  It does not call Authorize: transports other than HTTP
+// should authorize requests themselves before calling Do.
+func (h *Handler) Do(r Request) (CircuitStatus, error) {
+	c := h.Manager.GetCircuit(r.Name)
+	if c == nil {
+		return CircuitStatus{}, &Error{Code: http.StatusNotFound, Message: "no circuit named " + r.Name}
+	}
+	if r.Action == ActionRead {
+		return Status(c), nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cfg := c.Config()
+	switch r.Action {
+	case ActionForceOpen:
+		cfg.General.ForceOpen = true
+		cfg.General.ForcedClosed = false
+	case ActionForceClosed:
+		cfg.General.ForceOpen = false
+		cfg.General.ForcedClosed = true
+	case ActionReset:
+		cfg.General.ForceOpen = false
+		cfg.General.ForcedClosed = false
+	case ActionPassThrough:
+		cfg.General.Disabled = r.PassThrough
+	case ActionConfig:
+		if err := applyUpdate(c, &cfg, r.Update); err != nil {
+			return CircuitStatus{}, err
+		}
+	default:
+		return CircuitStatus{}, badRequest("unknown action %s", r.Action)
+	}
+	source := r.Source
+	if source == "" {
+		source = "admin"
+	}
+	c.SetConfigFrom(source, cfg)
+	return Status(c), nil
+}
+
+// applyUpdate changes cfg to match update.  Settings of the hystrix opener and closer of c are changed with
+// GeneralConfig.Thresholds, so they are audited and rolled back with the rest of the config.  Nothing is changed if
+// update is invalid.
+func applyUpdate(c *circuit.Circuit, cfg *circuit.Config, update ConfigUpdate) error {
+	var timeout, sleepWindow time.Duration
+	var err error
+	if update.Timeout != "" {
+		if timeout, err = time.ParseDuration(update.Timeout); err != nil {
+			return badRequest("invalid timeout: %s", err)
+		}
+	}
+	if update.SleepWindow != "" {
+		if sleepWindow, err = time.ParseDuration(update.SleepWindow); err != nil {
+			return badRequest("invalid sleep window: %s", err)
+		}
+	}
+	_, isHystrixOpener := c.ClosedToOpen.(*hystrix.Opener)
+	if (update.ErrorThresholdPercentage != nil || update.RequestVolumeThreshold != nil) && !isHystrixOpener {
+		return badRequest("circuit %s does not use a hystrix opener", c.Name())
+	}
+	_, isHystrixCloser := c.OpenToClose.(*hystrix.Closer)
+	if update.SleepWindow != "" && !isHystrixCloser {
+		return badRequest("circuit %s does not use a hystrix closer", c.Name())
+	}
+
+	if update.Timeout != "" {
+		cfg.Execution.Timeout = timeout
+	}
+	if update.MaxConcurrentRequests != nil {
+		cfg.Execution.MaxConcurrentRequests = *update.MaxConcurrentRequests
+	}
+	if update.FallbackMaxConcurrentRequests != nil {
+		cfg.Fallback.MaxConcurrentRequests = *update.FallbackMaxConcurrentRequests
+	}
+	// The map is shared with the circuit's current config, so change a copy
+	thresholds := make(map[string]int64, len(cfg.General.Thresholds)+3)
+	for k, v := range cfg.General.Thresholds {
+		thresholds[k] = v
+	}
+	if update.ErrorThresholdPercentage != nil {
+		thresholds[hystrix.ThresholdErrorPercentage] = *update.ErrorThresholdPercentage
+	}
+	if update.RequestVolumeThreshold != nil {
+		thresholds[hystrix.ThresholdRequestVolume] = *update.RequestVolumeThreshold
+	}
+	if update.SleepWindow != "" {
+		thresholds[hystrix.ThresholdSleepWindow] = sleepWindow.Nanoseconds()
+	}
+	if len(thresholds) != 0 {
+		cfg.General.Thresholds = thresholds
+	}
+	return nil
+}
+
+// RecentErrors returns the recent errors and bad requests of a circuit.  The number of each remembered is controlled by
+// circuit.GeneralConfig.RecentErrorsSize and RecentBadRequestsSize.
+func (h *Handler) RecentErrors(name string) (RecentErrors, error) {
+	c := h.Manager.GetCircuit(name)
+	if c == nil {
+		return RecentErrors{}, &Error{Code: http.StatusNotFound, Message: "no circuit named " + name}
+	}
+	return RecentErrors{
+		Name:        c.Name(),
+		Errors:      c.RecentErrors(),
+		BadRequests: c.RecentBadRequests(),
+	}, nil
+}
+
+// Memory estimates the memory held by every circuit.  Only the limit largest circuits are listed, or all of them if
+// limit is 0.
+func (h *Handler) Memory(limit int) MemoryReport {
+	circuits := h.Manager.AllCircuits()
+	ret := MemoryReport{
+		Circuits: len(circuits),
+		Largest:  make([]CircuitMemory, 0, len(circuits)),
+	}
+	for _, c := range circuits {
+		usage := c.MemoryUsage()
+		ret.Bytes += usage.Total()
+		ret.Largest = append(ret.Largest, CircuitMemory{Name: c.Name(), Bytes: usage.Total(), Usage: usage})
+	}
+	sort.Slice(ret.Largest, func(i, j int) bool {
+		if ret.Largest[i].Bytes != ret.Largest[j].Bytes {
+			return ret.Largest[i].Bytes > ret.Largest[j].Bytes
+		}
+		return ret.Largest[i].Name < ret.Largest[j].Name
+	})
+	if limit > 0 && len(ret.Largest) > limit {
+		ret.Largest = ret.Largest[:limit]
+	}
+	return ret
+}
+
+// List returns every circuit, sorted by name
+func (h *Handler) List() []CircuitStatus {
+	circuits := h.Manager.AllCircuits()
+	sort.Slice(circuits, func(i, j int) bool {
+		return circuits[i].Name() < circuits[j].Name()
+	})
+	ret := make([]CircuitStatus, 0, len(circuits))
+	for _, c := range circuits {
+		ret = append(ret, Status(c))
+	}
+	return ret
+}
+
+// Status describes a circuit the way the admin API does
+func Status(c *circuit.Circuit) CircuitStatus {
+	cfg := c.Config()
+	ret := CircuitStatus{
+		Name:               c.Name(),
+		State:              c.StateInfo(),
+		ForceOpen:          cfg.General.ForceOpen,
+		ForcedClosed:       cfg.General.ForcedClosed,
+		PassThrough:        cfg.General.Disabled,
+		Timeout:            cfg.Execution.Timeout.String(),
+		MaxConcurrent:      cfg.Execution.MaxConcurrentRequests,
+		ConcurrentCommands: c.ConcurrentCommands(),
+	}
+	if _, ok := c.ClosedToOpen.(json.Marshaler); ok {
+		ret.Opener = c.ClosedToOpen
+	}
+	if _, ok := c.OpenToClose.(json.Marshaler); ok {
+		ret.Closer = c.OpenToClose
+	}
+	return ret
+}
+
+func writeJSON(rw http.ResponseWriter, code int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	// The status is already written, so there is nothing useful to do with an error here
+	_ = json.NewEncoder(rw).Encode(v)
+}
@@ -0,0 +1,317 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func newTestHandler() *Handler {
+	f := hystrix.Factory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+	}
+	m.MustCreateCircuit("b")
+	m.MustCreateCircuit("a/with/slashes")
+	return &Handler{
+		Manager: m,
+		Authorize: func(req *http.Request, _ Action, _ string) error {
+			if req.Header.Get("Authorization") != "secret" {
+				return errors.New("bad credentials")
+			}
+			return nil
+		},
+	}
+}
+
+func do(t *testing.T, h http.Handler, method string, path string, body string, into interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "secret")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if into != nil && rw.Code == http.StatusOK {
+		if err := json.NewDecoder(rw.Body).Decode(into); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rw.Code
+}
+
+func TestHandler_List(t *testing.T) {
+	h := newTestHandler()
+	var statuses []CircuitStatus
+	if code := do(t, h, http.MethodGet, "/circuits", "", &statuses); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "a/with/slashes" || statuses[1].Name != "b" {
+		t.Errorf("unexpected circuits %+v", statuses)
+	}
+	var status CircuitStatus
+	if code := do(t, h, http.MethodGet, "/circuits/a%2Fwith%2Fslashes", "", &status); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if status.Name != "a/with/slashes" || status.Timeout != "1s" {
+		t.Errorf("unexpected circuit %+v", status)
+	}
+	if code := do(t, h, http.MethodGet, "/circuits/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", code)
+	}
+	if code := do(t, h, http.MethodGet, "/other", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", code)
+	}
+}
+
+func TestHandler_Force(t *testing.T) {
+	h := newTestHandler()
+	c := h.Manager.GetCircuit("b")
+	var status CircuitStatus
+	if code := do(t, h, http.MethodPost, "/circuits/b/open", "", &status); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if !c.IsOpen() || !status.ForceOpen || status.State.Reason != circuit.StateReasonForced {
+		t.Errorf("expected the circuit to be forced open, got %+v", status)
+	}
+	do(t, h, http.MethodPost, "/circuits/b/close", "", &status)
+	if c.IsOpen() || !status.ForcedClosed || status.ForceOpen {
+		t.Errorf("expected the circuit to be forced closed, got %+v", status)
+	}
+	do(t, h, http.MethodPost, "/circuits/b/reset", "", &status)
+	if status.ForcedClosed || status.ForceOpen {
+		t.Errorf("expected the circuit to not be forced, got %+v", status)
+	}
+	do(t, h, http.MethodPost, "/circuits/b/passthrough", `{"enabled": true}`, &status)
+	if !status.PassThrough || !c.Config().General.Disabled {
+		t.Errorf("expected the circuit to pass through, got %+v", status)
+	}
+	if code := do(t, h, http.MethodGet, "/circuits/b/open", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected changes to need POST, got %d", code)
+	}
+	if code := do(t, h, http.MethodPost, "/circuits/b/explode", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected unknown actions to not be found, got %d", code)
+	}
+}
+
+func TestHandler_Config(t *testing.T) {
+	h := newTestHandler()
+	c := h.Manager.GetCircuit("b")
+	body := `{"timeout": "2s", "max_concurrent_requests": 5, "error_threshold_percentage": 20, "sleep_window": "1m"}`
+	if code := do(t, h, http.MethodPost, "/circuits/b/config", body, nil); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if c.Config().Execution.Timeout != 2*time.Second || c.Config().Execution.MaxConcurrentRequests != 5 {
+		t.Errorf("unexpected config %+v", c.Config().Execution)
+	}
+	if c.ClosedToOpen.(*hystrix.Opener).Config().ErrorThresholdPercentage != 20 {
+		t.Error("expected the opener threshold to change")
+	}
+	if c.OpenToClose.(*hystrix.Closer).Config().SleepWindow != time.Minute {
+		t.Error("expected the closer sleep window to change")
+	}
+	if code := do(t, h, http.MethodPost, "/circuits/b/config", `{"timeout": "forever"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected a bad timeout to fail, got %d", code)
+	}
+	if c.Config().Execution.Timeout != 2*time.Second {
+		t.Error("a bad update should not change anything")
+	}
+
+	h.Manager = &circuit.Manager{}
+	h.Manager.MustCreateCircuit("not hystrix")
+	if code := do(t, h, http.MethodPost, "/circuits/not%20hystrix/config", `{"request_volume_threshold": 1}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected opener settings to need a hystrix opener, got %d", code)
+	}
+}
+
+func TestHandler_Authorize(t *testing.T) {
+	h := newTestHandler()
+	req := httptest.NewRequest(http.MethodPost, "/circuits/b/open", nil)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusForbidden || h.Manager.GetCircuit("b").IsOpen() {
+		t.Errorf("expected unauthorized changes to be refused, got %d", rw.Code)
+	}
+
+	h.Authorize = nil
+	if code := do(t, h, http.MethodGet, "/circuits", "", nil); code != http.StatusOK {
+		t.Errorf("expected reads without an Authorize hook, got %d", code)
+	}
+	if code := do(t, h, http.MethodPost, "/circuits/b/open", "", nil); code != http.StatusForbidden {
+		t.Errorf("expected changes to need an Authorize hook, got %d", code)
+	}
+}
+
+func TestChangedStatuses(t *testing.T) {
+	lastStates := make(map[string]circuit.StateInfo)
+	a := CircuitStatus{Name: "a"}
+	b := CircuitStatus{Name: "b"}
+	if changed := changedStatuses(lastStates, []CircuitStatus{a, b}); len(changed) != 2 {
+		t.Errorf("expected every circuit to be new, got %+v", changed)
+	}
+	if changed := changedStatuses(lastStates, []CircuitStatus{a, b}); len(changed) != 0 {
+		t.Errorf("expected no changes, got %+v", changed)
+	}
+	b.State.Open = true
+	if changed := changedStatuses(lastStates, []CircuitStatus{a, b}); len(changed) != 1 || changed[0].Name != "b" {
+		t.Errorf("expected the opened circuit, got %+v", changed)
+	}
+	changedStatuses(lastStates, []CircuitStatus{a})
+	if _, exists := lastStates["b"]; exists || len(lastStates) != 1 {
+		t.Errorf("expected removed circuits to be forgotten, got %+v", lastStates)
+	}
+}
+
+func TestHandler_Watch(t *testing.T) {
+	h := newTestHandler()
+	h.WatchInterval = time.Millisecond
+	server := httptest.NewServer(h)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/watch", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewScanner(resp.Body)
+	nextStatus := func() CircuitStatus {
+		t.Helper()
+		for events.Scan() {
+			if line := events.Text(); strings.HasPrefix(line, "data: ") {
+				var status CircuitStatus
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status); err != nil {
+					t.Fatal(err)
+				}
+				return status
+			}
+		}
+		t.Fatal("stream ended")
+		return CircuitStatus{}
+	}
+	if first, second := nextStatus(), nextStatus(); first.Name != "a/with/slashes" || second.Name != "b" {
+		t.Errorf("expected every circuit first, got %s and %s", first.Name, second.Name)
+	}
+	do(t, h, http.MethodPost, "/circuits/b/open", "", nil)
+	if changed := nextStatus(); changed.Name != "b" || !changed.State.Open {
+		t.Errorf("expected the opened circuit, got %+v", changed)
+	}
+}
+
+func TestHandler_Audit(t *testing.T) {
+	h := newTestHandler()
+	if code := do(t, h, http.MethodGet, "/audit", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected no audit log without Audit, got %d", code)
+	}
+	h.Audit = circuit.NewConfigAuditLog(10)
+	h.Identify = func(_ *http.Request) string {
+		return "alice"
+	}
+	c := h.Manager.MustCreateCircuit("audited", circuit.Config{General: circuit.GeneralConfig{ConfigAudit: h.Audit}})
+	if code := do(t, h, http.MethodPost, "/circuits/audited/config", `{"timeout": "2s"}`, nil); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if _, err := h.Do(Request{Name: "audited", Action: ActionForceOpen}); err != nil {
+		t.Fatal(err)
+	}
+	var changes []circuit.ConfigChange
+	if code := do(t, h, http.MethodGet, "/audit", "", &changes); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected two changes, got %+v", changes)
+	}
+	if changes[0].Circuit != c.Name() || changes[0].Source != "admin:alice" || changes[0].Fields[0].Field != "Execution.Timeout" {
+		t.Errorf("unexpected change %+v", changes[0])
+	}
+	if changes[1].Source != "admin" || changes[1].Fields[0].Field != "General.ForceOpen" {
+		t.Errorf("unexpected change %+v", changes[1])
+	}
+}
+
+func TestHandler_ConfigAuditedAndRolledBack(t *testing.T) {
+	h := newTestHandler()
+	audit := circuit.NewConfigAuditLog(10)
+	c := h.Manager.MustCreateCircuit("tuned", circuit.Config{General: circuit.GeneralConfig{ConfigAudit: audit}})
+	opener := c.ClosedToOpen.(*hystrix.Opener)
+	closer := c.OpenToClose.(*hystrix.Closer)
+	defaultThreshold, defaultSleepWindow := opener.Config().ErrorThresholdPercentage, closer.Config().SleepWindow
+	version := h.Manager.ConfigVersion()
+	body := `{"error_threshold_percentage": 20, "sleep_window": "1m"}`
+	if code := do(t, h, http.MethodPost, "/circuits/tuned/config", body, nil); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	changes := audit.Changes()
+	if len(changes) != 1 || len(changes[0].Fields) != 1 || changes[0].Fields[0].Field != "General.Thresholds" {
+		t.Fatalf("expected the opener and closer settings to be audited, got %+v", changes)
+	}
+	if h.Manager.ConfigVersion() == version {
+		t.Error("expected the opener and closer settings to change the config version")
+	}
+	if err := h.Manager.RollbackTo(version); err != nil {
+		t.Fatal(err)
+	}
+	if opener.Config().ErrorThresholdPercentage != defaultThreshold || closer.Config().SleepWindow != defaultSleepWindow {
+		t.Errorf("expected the rollback to restore the opener and closer, got %d and %s", opener.Config().ErrorThresholdPercentage, closer.Config().SleepWindow)
+	}
+}
+
+func TestHandler_RecentErrors(t *testing.T) {
+	h := newTestHandler()
+	c := h.Manager.GetCircuit("b")
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return circuit.SimpleBadRequest{Err: errors.New("missing user id")}
+	}, nil)
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return errors.New("connection refused")
+	}, nil)
+	var recent struct {
+		Errors      []map[string]interface{} `json:"errors"`
+		BadRequests []map[string]interface{} `json:"bad_requests"`
+	}
+	if code := do(t, h, http.MethodGet, "/circuits/b/errors", "", &recent); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if len(recent.BadRequests) != 1 || recent.BadRequests[0]["err"] != "missing user id" {
+		t.Errorf("unexpected bad requests %v", recent.BadRequests)
+	}
+	if len(recent.Errors) != 1 || recent.Errors[0]["err"] != "connection refused" {
+		t.Errorf("unexpected errors %v", recent.Errors)
+	}
+	if code := do(t, h, http.MethodPost, "/circuits/b/errors", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected errors to be read only, got %d", code)
+	}
+	if code := do(t, h, http.MethodGet, "/circuits/missing/errors", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected a missing circuit to not be found, got %d", code)
+	}
+}
+
+func TestHandler_Memory(t *testing.T) {
+	h := newTestHandler()
+	h.Manager.MustCreateCircuit("large", circuit.Config{General: circuit.GeneralConfig{RecentErrorsSize: 1000}})
+	var report MemoryReport
+	if code := do(t, h, http.MethodGet, "/memory?limit=2", "", &report); code != http.StatusOK {
+		t.Fatalf("unexpected code %d", code)
+	}
+	if report.Circuits != 3 || len(report.Largest) != 2 || report.Largest[0].Name != "large" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Largest[0].Bytes != report.Largest[0].Usage.Total() || report.Bytes < report.Largest[0].Bytes+report.Largest[1].Bytes {
+		t.Errorf("unexpected totals %+v", report)
+	}
+	if code := do(t, h, http.MethodGet, "/memory?limit=x", "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid limit to be refused, got %d", code)
+	}
+}
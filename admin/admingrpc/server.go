@@ -0,0 +1,194 @@
+/*
+Package admingrpc serves the admin API of package admin over gRPC, for environments where internal tooling only speaks
+gRPC.  The service is defined in adminpb/admin.proto.  It is a separate module so the circuit module does not depend
+on gRPC.
+*/
+package admingrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative adminpb/admin.proto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/cep21/circuit/admin/admingrpc/adminpb"
+	"github.com/cep21/circuit/v4/admin"
+)
+
+// Server implements adminpb.AdminServer.  Register it with adminpb.RegisterAdminServer.
+type Server struct {
+	adminpb.UnimplementedAdminServer
+	// Admin runs every request.  Share one Handler between HTTP and gRPC so concurrent changes to a circuit do not
+	// overwrite each other.  Admin.Authorize is not used: use Authorize instead.
+	Admin *admin.Handler
+	// Authorize decides if a call may take an action on a circuit.  circuitName is empty when listing or watching
+	// circuits.  Read credentials from ctx with the metadata package.  Return an error to refuse the call.  If
+	// Authorize is nil, only admin.ActionRead is allowed.
+	Authorize func(ctx context.Context, action admin.Action, circuitName string) error
+}
+
+var _ adminpb.AdminServer = &Server{}
+
+// ListCircuits returns every circuit
+func (s *Server) ListCircuits(ctx context.Context, _ *adminpb.ListCircuitsRequest) (*adminpb.ListCircuitsResponse, error) {
+	if err := s.authorize(ctx, admin.ActionRead, ""); err != nil {
+		return nil, err
+	}
+	statuses := s.Admin.List()
+	ret := &adminpb.ListCircuitsResponse{
+		Circuits: make([]*adminpb.CircuitStatus, 0, len(statuses)),
+	}
+	for _, st := range statuses {
+		ret.Circuits = append(ret.Circuits, toProto(st))
+	}
+	return ret, nil
+}
+
+// GetCircuit returns one circuit
+func (s *Server) GetCircuit(ctx context.Context, req *adminpb.CircuitRequest) (*adminpb.CircuitStatus, error) {
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionRead})
+}
+
+// OpenCircuit forces a circuit open
+func (s *Server) OpenCircuit(ctx context.Context, req *adminpb.CircuitRequest) (*adminpb.CircuitStatus, error) {
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionForceOpen})
+}
+
+// CloseCircuit forces a circuit closed
+func (s *Server) CloseCircuit(ctx context.Context, req *adminpb.CircuitRequest) (*adminpb.CircuitStatus, error) {
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionForceClosed})
+}
+
+// ResetCircuit stops forcing a circuit open or closed
+func (s *Server) ResetCircuit(ctx context.Context, req *adminpb.CircuitRequest) (*adminpb.CircuitStatus, error) {
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionReset})
+}
+
+// SetPassThrough turns pass through on or off
+func (s *Server) SetPassThrough(ctx context.Context, req *adminpb.SetPassThroughRequest) (*adminpb.CircuitStatus, error) {
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionPassThrough, PassThrough: req.GetEnabled()})
+}
+
+// UpdateConfig changes timeouts and thresholds
+func (s *Server) UpdateConfig(ctx context.Context, req *adminpb.UpdateConfigRequest) (*adminpb.CircuitStatus, error) {
+	update := admin.ConfigUpdate{
+		MaxConcurrentRequests:         req.MaxConcurrentRequests,
+		FallbackMaxConcurrentRequests: req.FallbackMaxConcurrentRequests,
+		ErrorThresholdPercentage:      req.ErrorThresholdPercentage,
+		RequestVolumeThreshold:        req.RequestVolumeThreshold,
+	}
+	if req.Timeout != nil {
+		update.Timeout = req.Timeout.AsDuration().String()
+	}
+	if req.SleepWindow != nil {
+		update.SleepWindow = req.SleepWindow.AsDuration().String()
+	}
+	return s.do(ctx, admin.Request{Name: req.GetName(), Action: admin.ActionConfig, Update: update})
+}
+
+// Watch streams every circuit, then the circuits that open or close, until the client leaves
+func (s *Server) Watch(_ *adminpb.WatchRequest, stream adminpb.Admin_WatchServer) error {
+	if err := s.authorize(stream.Context(), admin.ActionRead, ""); err != nil {
+		return err
+	}
+	err := s.Admin.Watch(stream.Context(), func(statuses []admin.CircuitStatus) error {
+		for _, st := range statuses {
+			if err := stream.Send(toProto(st)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+func (s *Server) do(ctx context.Context, req admin.Request) (*adminpb.CircuitStatus, error) {
+	if err := s.authorize(ctx, req.Action, req.Name); err != nil {
+		return nil, err
+	}
+	req.Source = "admingrpc"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.Source += ":" + p.Addr.String()
+	}
+	st, err := s.Admin.Do(req)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProto(st), nil
+}
+
+func (s *Server) authorize(ctx context.Context, action admin.Action, name string) error {
+	if s.Authorize == nil {
+		if action == admin.ActionRead {
+			return nil
+		}
+		return status.Error(codes.PermissionDenied, "changes are not allowed without an Authorize hook")
+	}
+	if err := s.Authorize(ctx, action, name); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// toStatusError maps the HTTP status of admin errors to gRPC codes
+func toStatusError(err error) error {
+	var ae *admin.Error
+	if !errors.As(err, &ae) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	switch ae.Code {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, ae.Message)
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, ae.Message)
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, ae.Message)
+	}
+	return status.Error(codes.Internal, ae.Message)
+}
+
+func toProto(st admin.CircuitStatus) *adminpb.CircuitStatus {
+	ret := &adminpb.CircuitStatus{
+		Name:                  st.Name,
+		Open:                  st.State.Open,
+		Reason:                string(st.State.Reason),
+		Since:                 timestamppb.New(st.State.Since),
+		ForceOpen:             st.ForceOpen,
+		ForcedClosed:          st.ForcedClosed,
+		PassThrough:           st.PassThrough,
+		MaxConcurrentRequests: st.MaxConcurrent,
+		ConcurrentCommands:    st.ConcurrentCommands,
+		OpenerJson:            marshalOrEmpty(st.Opener),
+		CloserJson:            marshalOrEmpty(st.Closer),
+	}
+	if !st.State.NextProbe.IsZero() {
+		ret.NextProbe = timestamppb.New(st.State.NextProbe)
+	}
+	if timeout, err := time.ParseDuration(st.Timeout); err == nil {
+		ret.Timeout = durationpb.New(timeout)
+	}
+	return ret
+}
+
+func marshalOrEmpty(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	asJSON, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(asJSON)
+}
@@ -0,0 +1,143 @@
+package admingrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/cep21/circuit/admin/admingrpc/adminpb"
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/admin"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func newTestClient(t *testing.T) (*circuit.Manager, adminpb.AdminClient) {
+	f := hystrix.Factory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+	}
+	m.MustCreateCircuit("b")
+	m.MustCreateCircuit("a/with/slashes")
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	adminpb.RegisterAdminServer(server, &Server{
+		Admin: &admin.Handler{
+			Manager:       m,
+			WatchInterval: time.Millisecond,
+		},
+		Authorize: func(ctx context.Context, _ admin.Action, _ string) error {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if auth := md.Get("authorization"); len(auth) != 1 || auth[0] != "secret" {
+				return errors.New("bad credentials")
+			}
+			return nil
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return m, adminpb.NewAdminClient(conn)
+}
+
+func authorized() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "secret")
+}
+
+func TestServer(t *testing.T) {
+	m, client := newTestClient(t)
+	ctx := authorized()
+	list, err := client.ListCircuits(ctx, &adminpb.ListCircuitsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Circuits) != 2 || list.Circuits[0].Name != "a/with/slashes" || list.Circuits[0].Timeout.AsDuration() != time.Second {
+		t.Errorf("unexpected circuits %v", list.Circuits)
+	}
+	opened, err := client.OpenCircuit(ctx, &adminpb.CircuitRequest{Name: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opened.Open || !opened.ForceOpen || opened.Reason != string(circuit.StateReasonForced) {
+		t.Errorf("expected a forced open circuit, got %v", opened)
+	}
+	if _, err := client.ResetCircuit(ctx, &adminpb.CircuitRequest{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	maxConcurrent := int64(20)
+	updated, err := client.UpdateConfig(ctx, &adminpb.UpdateConfigRequest{
+		Name:                  "b",
+		Timeout:               durationpb.New(2 * time.Second),
+		MaxConcurrentRequests: &maxConcurrent,
+		SleepWindow:           durationpb.New(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Timeout.AsDuration() != 2*time.Second || updated.MaxConcurrentRequests != 20 {
+		t.Errorf("expected new settings, got %v", updated)
+	}
+	if sleep := m.GetCircuit("b").OpenToClose.(*hystrix.Closer).Config().SleepWindow; sleep != time.Minute {
+		t.Errorf("expected the closer to change, got %s", sleep)
+	}
+}
+
+func TestServer_errors(t *testing.T) {
+	_, client := newTestClient(t)
+	if _, err := client.OpenCircuit(context.Background(), &adminpb.CircuitRequest{Name: "b"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+	if _, err := client.GetCircuit(authorized(), &adminpb.CircuitRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithCancel(authorized())
+	defer cancel()
+	stream, err := client.Watch(ctx, &adminpb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"a/with/slashes", "b"} {
+		got, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != expected {
+			t.Errorf("expected %s, got %s", expected, got.Name)
+		}
+	}
+	if _, err := client.OpenCircuit(authorized(), &adminpb.CircuitRequest{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "b" || !got.Open {
+		t.Errorf("expected the opened circuit, got %v", got)
+	}
+}
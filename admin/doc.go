@@ -0,0 +1,6 @@
+/*
+Package admin is an opt in http.Handler that lets operators list circuits and change them at runtime: force them open
+or closed, let requests pass through, or change timeouts and thresholds.  Changes are refused unless Handler.Authorize
+allows them.
+*/
+package admin
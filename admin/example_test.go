@@ -0,0 +1,26 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/admin"
+)
+
+// This example serves the admin API under /admin/, only allowing changes from requests with the right token
+func ExampleHandler() {
+	h := circuit.Manager{}
+	h.MustCreateCircuit("database")
+	adminHandler := &admin.Handler{
+		Manager: &h,
+		Authorize: func(req *http.Request, action admin.Action, circuitName string) error {
+			if action != admin.ActionRead && req.Header.Get("X-Admin-Token") != "secret" {
+				return errors.New("unknown admin token")
+			}
+			return nil
+		},
+	}
+	http.Handle("/admin/", http.StripPrefix("/admin", adminHandler))
+	// Output:
+}
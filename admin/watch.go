@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func (h *Handler) watchInterval() time.Duration {
+	if h.WatchInterval == 0 {
+		return time.Second
+	}
+	return h.WatchInterval
+}
+
+// watch sends Watch as server sent events until the client leaves
+func (h *Handler) watch(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeJSON(rw, http.StatusInternalServerError, map[string]string{"error": "streaming unsupported"})
+		return
+	}
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_ = h.Watch(req.Context(), func(statuses []CircuitStatus) error {
+		for _, status := range statuses {
+			asJSON, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			if _, err := rw.Write(append(append([]byte("data: "), asJSON...), '\n', '\n')); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// Watch calls send with every circuit, then with the circuits that opened or closed each WatchInterval.  It returns
+// when ctx ends or send returns an error.
+func (h *Handler) Watch(ctx context.Context, send func([]CircuitStatus) error) error {
+	lastStates := make(map[string]circuit.StateInfo)
+	ticker := time.NewTicker(h.watchInterval())
+	defer ticker.Stop()
+	for {
+		changed := changedStatuses(lastStates, h.List())
+		if len(changed) > 0 {
+			if err := send(changed); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// changedStatuses returns the statuses whose state is not the one in lastStates, and records them there.  Circuits that
+// are no longer in statuses are forgotten, so a long watch does not keep every circuit that ever existed.
+func changedStatuses(lastStates map[string]circuit.StateInfo, statuses []CircuitStatus) []CircuitStatus {
+	var changed []CircuitStatus
+	for _, status := range statuses {
+		if last, exists := lastStates[status.Name]; exists && sameState(last, status.State) {
+			continue
+		}
+		lastStates[status.Name] = status.State
+		changed = append(changed, status)
+	}
+	if len(lastStates) > len(statuses) {
+		present := make(map[string]struct{}, len(statuses))
+		for _, status := range statuses {
+			present[status.Name] = struct{}{}
+		}
+		for name := range lastStates {
+			if _, exists := present[name]; !exists {
+				delete(lastStates, name)
+			}
+		}
+	}
+	return changed
+}
+
+// sameState ignores NextProbe, which moves forward while an open circuit is probed
+func sameState(a circuit.StateInfo, b circuit.StateInfo) bool {
+	return a.Open == b.Open && a.Reason == b.Reason && a.Since.Equal(b.Since)
+}
@@ -0,0 +1,210 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitschema"
+	"github.com/cep21/circuit/v4/dashboard"
+)
+
+// Aggregator combines the reports of many processes into one view per circuit name.  It serves:
+//
+//	POST /push     takes a Report from a Pusher
+//	GET  /metrics  the combined circuits in the Prometheus text format
+//	GET  /         the dashboard page, showing the combined circuits
+//	GET  /events   the server sent events the dashboard page reads
+//
+// Mount it with http.StripPrefix if it is not at the root of your mux.
+type Aggregator struct {
+	// StaleAfter is how long the last report of an instance is used.  Instances that stop pushing, because they
+	// were shut down, drop out of the aggregate after it.  The default is 30 seconds.
+	StaleAfter time.Duration
+	// TickDuration is how often the dashboard is sent new samples.  The default is one second.
+	TickDuration time.Duration
+	// MaxReportSize limits the size of a pushed report.  The default is 10MB.
+	MaxReportSize int64
+	// Now returns the current time.  You only want to modify this for testing.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	reports map[string]received
+}
+
+var _ http.Handler = &Aggregator{}
+
+// received is a report and when it arrived.  Reports are aged by when they arrive, so clock skew between instances
+// does not matter.
+type received struct {
+	report Report
+	at     time.Time
+}
+
+// Aggregate is a circuit combined over every instance that reported it
+type Aggregate struct {
+	CircuitReport
+	// Instances is how many instances reported the circuit
+	Instances int `json:"instances"`
+	// OpenInstances is how many of them have the circuit open
+	OpenInstances int `json:"open_instances"`
+}
+
+func (a *Aggregator) staleAfter() time.Duration {
+	if a.StaleAfter == 0 {
+		return 30 * time.Second
+	}
+	return a.StaleAfter
+}
+
+func (a *Aggregator) maxReportSize() int64 {
+	if a.MaxReportSize == 0 {
+		return 10 << 20
+	}
+	return a.MaxReportSize
+}
+
+func (a *Aggregator) now() time.Time {
+	if a.Now == nil {
+		return time.Now()
+	}
+	return a.Now()
+}
+
+// Add stores a report, replacing the last report of the same instance
+func (a *Aggregator) Add(r Report) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.reports == nil {
+		a.reports = make(map[string]received)
+	}
+	a.reports[r.Instance] = received{report: r, at: a.now()}
+}
+
+// Aggregates returns every circuit combined over the instances that reported it recently, sorted by name.  Counts
+// and concurrency are summed.  The circuit is open if it is open on any instance.  LatencyMean is weighted by each
+// instance's requests.  A p99 cannot be combined from p99s, so LatencyP99 is the highest of them, which is an upper
+// bound of the real p99.
+func (a *Aggregator) Aggregates() []Aggregate {
+	now := a.now()
+	byName := make(map[string]*Aggregate)
+	// latencySum is the sum of each instance's mean latency times its requests, for the weighted mean
+	latencySum := make(map[string]float64)
+	a.mu.Lock()
+	for instance, r := range a.reports {
+		if now.Sub(r.at) > a.staleAfter() {
+			delete(a.reports, instance)
+			continue
+		}
+		for _, c := range r.report.Circuits {
+			agg, exists := byName[c.Name]
+			if !exists {
+				agg = &Aggregate{CircuitReport: CircuitReport{Name: c.Name}}
+				byName[c.Name] = agg
+			}
+			agg.add(c)
+			latencySum[c.Name] += float64(c.LatencyMean) * float64(c.LegitimateAttempts())
+		}
+	}
+	a.mu.Unlock()
+	ret := make([]Aggregate, 0, len(byName))
+	for name, agg := range byName {
+		if attempts := agg.LegitimateAttempts(); attempts != 0 {
+			agg.LatencyMean = circuitschema.Duration(latencySum[name] / float64(attempts))
+		}
+		ret = append(ret, *agg)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func (a *Aggregate) add(c CircuitReport) {
+	a.Instances++
+	if c.Open {
+		a.OpenInstances++
+		a.Open = true
+	}
+	a.ConcurrentCommands += c.ConcurrentCommands
+	a.Successes += c.Successes
+	a.ErrFailures += c.ErrFailures
+	a.ErrTimeouts += c.ErrTimeouts
+	a.ErrShortCircuits += c.ErrShortCircuits
+	a.ErrConcurrencyLimitRejects += c.ErrConcurrencyLimitRejects
+	a.ErrBadRequests += c.ErrBadRequests
+	a.ErrInterrupts += c.ErrInterrupts
+	if c.LatencyP99 > a.LatencyP99 {
+		a.LatencyP99 = c.LatencyP99
+	}
+}
+
+// Samples returns the aggregates as dashboard samples
+func (a *Aggregator) Samples() []dashboard.Sample {
+	aggregates := a.Aggregates()
+	ret := make([]dashboard.Sample, 0, len(aggregates))
+	for _, agg := range aggregates {
+		state := circuit.StateClosed
+		if agg.Open {
+			state = circuit.StateOpen
+		}
+		ret = append(ret, dashboard.Sample{
+			Name:               agg.Name,
+			State:              state,
+			Requests:           agg.LegitimateAttempts(),
+			ErrorPercentage:    100 * agg.ErrorPercentage(),
+			LatencyMean:        milliseconds(time.Duration(agg.LatencyMean)),
+			LatencyP99:         milliseconds(time.Duration(agg.LatencyP99)),
+			ConcurrentCommands: agg.ConcurrentCommands,
+		})
+	}
+	return ret
+}
+
+// ServeHTTP takes pushed reports, and serves the aggregate as Prometheus metrics and on the dashboard
+func (a *Aggregator) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch strings.Trim(req.URL.Path, "/") {
+	case "push":
+		a.push(rw, req)
+	case "metrics":
+		if req.Method != http.MethodGet {
+			http.Error(rw, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		rw.Header().Set("Content-Type", prometheusContentType)
+		// The status is already written, so there is nothing useful to do with an error here
+		_ = writePrometheus(rw, a.Aggregates())
+	default:
+		d := dashboard.Handler{
+			Source:       a.Samples,
+			TickDuration: a.TickDuration,
+		}
+		d.ServeHTTP(rw, req)
+	}
+}
+
+func (a *Aggregator) push(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	var r Report
+	if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, a.maxReportSize())).Decode(&r); err != nil {
+		http.Error(rw, "invalid report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Instance == "" {
+		http.Error(rw, "invalid report: missing instance", http.StatusBadRequest)
+		return
+	}
+	a.Add(r)
+	rw.WriteHeader(http.StatusOK)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
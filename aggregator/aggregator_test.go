@@ -0,0 +1,116 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+func newTestManager(t *testing.T, successes int, failures int) *circuit.Manager {
+	sf := rolling.StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("db")
+	for i := 0; i < successes; i++ {
+		if err := c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < failures; i++ {
+		_ = c.Execute(context.Background(), func(_ context.Context) error { return errors.New("bad") }, nil)
+	}
+	return m
+}
+
+func TestAggregator(t *testing.T) {
+	a := &Aggregator{}
+	server := httptest.NewServer(a)
+	defer server.Close()
+
+	first := newTestManager(t, 3, 1)
+	second := newTestManager(t, 4, 0)
+	second.GetCircuit("db").OpenCircuit(context.Background())
+	for instance, m := range map[string]*circuit.Manager{"first": first, "second": second} {
+		p := &Pusher{Manager: m, URL: server.URL + "/push", Instance: instance}
+		if err := p.Push(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	aggregates := a.Aggregates()
+	if len(aggregates) != 1 {
+		t.Fatalf("expected one circuit, got %+v", aggregates)
+	}
+	db := aggregates[0]
+	if db.Instances != 2 || db.OpenInstances != 1 || !db.Open || db.Successes != 7 || db.ErrFailures != 1 {
+		t.Errorf("unexpected aggregate %+v", db)
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`circuit_requests{circuit="db",result="success"} 7`,
+		`circuit_open_instances{circuit="db"} 1`,
+		`circuit_error_ratio{circuit="db"} 0.125`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("expected %s in\n%s", line, body)
+		}
+	}
+
+	if samples := a.Samples(); len(samples) != 1 || samples[0].State != circuit.StateOpen || samples[0].Requests != 8 {
+		t.Errorf("unexpected samples %+v", samples)
+	}
+}
+
+func TestAggregator_Stale(t *testing.T) {
+	now := time.Now()
+	a := &Aggregator{
+		StaleAfter: time.Minute,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	a.Add(Report{Instance: "old", Circuits: []CircuitReport{{Name: "db", Successes: 1}}})
+	now = now.Add(30 * time.Second)
+	a.Add(Report{Instance: "new", Circuits: []CircuitReport{{Name: "db", Successes: 2}}})
+	if aggregates := a.Aggregates(); aggregates[0].Successes != 3 {
+		t.Errorf("expected both reports, got %+v", aggregates)
+	}
+	now = now.Add(45 * time.Second)
+	if aggregates := a.Aggregates(); aggregates[0].Instances != 1 || aggregates[0].Successes != 2 {
+		t.Errorf("expected the old report to be dropped, got %+v", aggregates)
+	}
+}
+
+func TestAggregator_BadPush(t *testing.T) {
+	a := &Aggregator{}
+	for _, body := range []string{"not json", `{"circuits": []}`} {
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body)))
+		if rw.Code != http.StatusBadRequest {
+			t.Errorf("expected %q to be a bad request, got %d", body, rw.Code)
+		}
+	}
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/push", nil))
+	if rw.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET to not be allowed, got %d", rw.Code)
+	}
+}
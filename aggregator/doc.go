@@ -0,0 +1,7 @@
+/*
+Package aggregator combines the circuits of many processes, so a fleet can be watched as one.  Each process runs a
+Pusher that sends the rolling windows of its circuits to an Aggregator.  The Aggregator merges them by circuit name and
+serves the result as Prometheus metrics and on the dashboard of package dashboard.  It is what Turbine is to Hystrix.
+Command circuitaggregator runs an Aggregator as a server.
+*/
+package aggregator
@@ -0,0 +1,37 @@
+package aggregator_test
+
+import (
+	"net/http"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/aggregator"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+// This example pushes the circuits of a process to an aggregator, which is usually command circuitaggregator running
+// somewhere else
+func ExamplePusher() {
+	sf := rolling.StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	m.MustCreateCircuit("db")
+	p := &aggregator.Pusher{
+		Manager:  m,
+		URL:      "http://circuitaggregator:8080/push",
+		Instance: "host-1",
+	}
+	go func() {
+		_ = p.Start()
+	}()
+	// Stop pushing when the process shuts down
+	_ = p.Close()
+	// Output:
+}
+
+// This example serves an aggregator from your own server
+func ExampleAggregator() {
+	mux := http.NewServeMux()
+	mux.Handle("/aggregate/", http.StripPrefix("/aggregate", &aggregator.Aggregator{}))
+	// Output:
+}
@@ -0,0 +1,67 @@
+package aggregator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// prometheusContentType is the version of the Prometheus text format writePrometheus writes
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusLabelEscaper escapes label values as the Prometheus text format requires
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writePrometheus writes aggregates in the Prometheus text format.  Every metric is a gauge, since the counts are
+// sums over a rolling window and go down as well as up.
+func writePrometheus(w io.Writer, aggregates []Aggregate) error {
+	bw := bufio.NewWriter(w)
+	gauge := func(name string, help string, value func(a Aggregate) float64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, a := range aggregates {
+			fmt.Fprintf(bw, "%s{circuit=\"%s\"} %g\n", name, prometheusLabelEscaper.Replace(a.Name), value(a))
+		}
+	}
+	results := func(name string, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, a := range aggregates {
+			label := prometheusLabelEscaper.Replace(a.Name)
+			for _, r := range []struct {
+				result string
+				count  int64
+			}{
+				{"success", a.Successes},
+				{"failure", a.ErrFailures},
+				{"timeout", a.ErrTimeouts},
+				{"short_circuit", a.ErrShortCircuits},
+				{"concurrency_limit_reject", a.ErrConcurrencyLimitRejects},
+				{"bad_request", a.ErrBadRequests},
+				{"interrupt", a.ErrInterrupts},
+			} {
+				fmt.Fprintf(bw, "%s{circuit=\"%s\",result=\"%s\"} %d\n", name, label, r.result, r.count)
+			}
+		}
+	}
+	results("circuit_requests", "Requests in the rolling window, summed over instances, by result.")
+	gauge("circuit_error_ratio", "Failures and timeouts over legitimate attempts in the rolling window.", func(a Aggregate) float64 {
+		return a.ErrorPercentage()
+	})
+	gauge("circuit_instances", "Instances that reported the circuit.", func(a Aggregate) float64 {
+		return float64(a.Instances)
+	})
+	gauge("circuit_open_instances", "Instances that have the circuit open.", func(a Aggregate) float64 {
+		return float64(a.OpenInstances)
+	})
+	gauge("circuit_concurrent_commands", "Runs in progress, summed over instances.", func(a Aggregate) float64 {
+		return float64(a.ConcurrentCommands)
+	})
+	gauge("circuit_latency_mean_seconds", "Mean run latency in the rolling window.", func(a Aggregate) float64 {
+		return time.Duration(a.LatencyMean).Seconds()
+	})
+	gauge("circuit_latency_p99_seconds", "Highest p99 run latency of any instance in the rolling window.", func(a Aggregate) float64 {
+		return time.Duration(a.LatencyP99).Seconds()
+	})
+	return bw.Flush()
+}
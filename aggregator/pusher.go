@@ -0,0 +1,95 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Pusher sends a Report of a Manager's circuits to an Aggregator every Interval
+type Pusher struct {
+	Manager *circuit.Manager
+	// URL is where the Aggregator's push endpoint is served, like "http://aggregator:8080/push"
+	URL string
+	// Instance names this process in each Report, like a host name or pod name
+	Instance string
+	// Interval is how often reports are pushed.  The default is one second.
+	Interval time.Duration
+	// Client sends the reports.  The default is http.DefaultClient.
+	Client *http.Client
+	// OnError, if set, is called with errors pushing a report
+	OnError func(err error)
+
+	closeChan chan struct{}
+	once      sync.Once
+}
+
+func (p *Pusher) doOnce() {
+	p.closeChan = make(chan struct{})
+}
+
+func (p *Pusher) interval() time.Duration {
+	if p.Interval == 0 {
+		return time.Second
+	}
+	return p.Interval
+}
+
+func (p *Pusher) client() *http.Client {
+	if p.Client == nil {
+		return http.DefaultClient
+	}
+	return p.Client
+}
+
+// Start should be called once per Pusher.  It pushes a report every Interval until Close is called.
+func (p *Pusher) Start() error {
+	p.once.Do(p.doOnce)
+	for {
+		select {
+		case <-time.After(p.interval()):
+			ctx, cancel := context.WithTimeout(context.Background(), p.interval())
+			if err := p.Push(ctx); err != nil && p.OnError != nil {
+				p.OnError(err)
+			}
+			cancel()
+		case <-p.closeChan:
+			return nil
+		}
+	}
+}
+
+// Close ends the Start function
+func (p *Pusher) Close() error {
+	p.once.Do(p.doOnce)
+	close(p.closeChan)
+	return nil
+}
+
+// Push sends one report now.  Start calls it every Interval.
+func (p *Pusher) Push(ctx context.Context) error {
+	body, err := json.Marshal(TakeReport(p.Manager, p.Instance, time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("push to %s: %s", p.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package aggregator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitschema"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+// Report is what one process pushes to an Aggregator: the rolling window of each of its circuits
+type Report struct {
+	// Instance names the process, like a host name or pod name.  A newer report from the same instance replaces the
+	// older one.
+	Instance string          `json:"instance"`
+	Time     time.Time       `json:"time"`
+	Circuits []CircuitReport `json:"circuits"`
+}
+
+// CircuitReport is one circuit of a Report.  The counts are sums over the circuit's rolling window.
+type CircuitReport struct {
+	Name               string `json:"name"`
+	Open               bool   `json:"open"`
+	ConcurrentCommands int64  `json:"concurrent_commands"`
+
+	Successes                  int64 `json:"successes"`
+	ErrFailures                int64 `json:"err_failures"`
+	ErrTimeouts                int64 `json:"err_timeouts"`
+	ErrShortCircuits           int64 `json:"err_short_circuits"`
+	ErrConcurrencyLimitRejects int64 `json:"err_concurrency_limit_rejects"`
+	ErrBadRequests             int64 `json:"err_bad_requests"`
+	ErrInterrupts              int64 `json:"err_interrupts"`
+
+	LatencyMean circuitschema.Duration `json:"latency_mean"`
+	LatencyP99  circuitschema.Duration `json:"latency_p99"`
+}
+
+// TakeReport returns the current rolling window of every circuit of m, sorted by name.  Circuits without
+// rolling.RunStats are reported with only their state.
+func TakeReport(m *circuit.Manager, instance string, now time.Time) Report {
+	ret := Report{
+		Instance: instance,
+		Time:     now,
+	}
+	for _, c := range m.AllCircuits() {
+		ret.Circuits = append(ret.Circuits, reportCircuit(c, now))
+	}
+	sort.Slice(ret.Circuits, func(i, j int) bool {
+		return ret.Circuits[i].Name < ret.Circuits[j].Name
+	})
+	return ret
+}
+
+func reportCircuit(c *circuit.Circuit, now time.Time) CircuitReport {
+	ret := CircuitReport{
+		Name:               c.Name(),
+		Open:               c.IsOpen(),
+		ConcurrentCommands: c.ConcurrentCommands(),
+	}
+	stats := rolling.FindCommandMetrics(c)
+	if stats == nil {
+		return ret
+	}
+	ret.Successes = stats.Successes.RollingSumAt(now)
+	ret.ErrFailures = stats.ErrFailures.RollingSumAt(now)
+	ret.ErrTimeouts = stats.ErrTimeouts.RollingSumAt(now)
+	ret.ErrShortCircuits = stats.ErrShortCircuits.RollingSumAt(now)
+	ret.ErrConcurrencyLimitRejects = stats.ErrConcurrencyLimitRejects.RollingSumAt(now)
+	ret.ErrBadRequests = stats.ErrBadRequests.RollingSumAt(now)
+	ret.ErrInterrupts = stats.ErrInterrupts.RollingSumAt(now)
+	snap := stats.Latencies.SnapshotAt(now)
+	ret.LatencyMean = circuitschema.Duration(snap.Mean())
+	ret.LatencyP99 = circuitschema.Duration(snap.Percentile(99))
+	return ret
+}
+
+// Errors returns the # of errors (errors are timeouts and failures)
+func (c CircuitReport) Errors() int64 {
+	return c.ErrFailures + c.ErrTimeouts
+}
+
+// LegitimateAttempts returns the sum of errors and successes
+func (c CircuitReport) LegitimateAttempts() int64 {
+	return c.Successes + c.Errors()
+}
+
+// ErrorPercentage is [0.0 - 1.0] errors/legitimate
+func (c CircuitReport) ErrorPercentage() float64 {
+	attemptCount := c.LegitimateAttempts()
+	if attemptCount == 0 {
+		return 0
+	}
+	return float64(c.Errors()) / float64(attemptCount)
+}
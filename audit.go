@@ -0,0 +1,125 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ConfigChange is a change to a circuit's config made while it was running
+type ConfigChange struct {
+	Time    time.Time
+	Circuit string
+	// Source is who or what made the change, as given to Circuit.SetConfigFrom.  It is empty for changes made with
+	// SetConfigThreadSafe.
+	Source string
+	// Fields are the fields that changed.  Only fields that can be encoded as JSON are compared.
+	Fields []ConfigFieldChange
+}
+
+// ConfigFieldChange is one field of a ConfigChange
+type ConfigFieldChange struct {
+	// Field is the path of the field in Config, like "Execution.Timeout"
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// ConfigChangeMetrics can optionally be implemented by Metrics that want to know about runtime config changes.
+// ConfigChanged is called after each change made with SetConfigThreadSafe or SetConfigFrom that changes a field.
+type ConfigChangeMetrics interface {
+	ConfigChanged(ctx context.Context, now time.Time, change ConfigChange)
+}
+
+var _ ConfigChangeMetrics = MetricsCollection(nil)
+
+// ConfigChanged sends ConfigChanged to all collectors that implement ConfigChangeMetrics
+func (r MetricsCollection) ConfigChanged(ctx context.Context, now time.Time, change ConfigChange) {
+	for _, c := range r {
+		if cc, ok := c.(ConfigChangeMetrics); ok {
+			cc.ConfigChanged(ctx, now, change)
+		}
+	}
+}
+
+// watchesConfigChanges is true if any collector implements ConfigChangeMetrics
+func (r MetricsCollection) watchesConfigChanges() bool {
+	for _, c := range r {
+		if _, ok := c.(ConfigChangeMetrics); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigAuditLog remembers the most recent runtime config changes of the circuits that use it, so behavior changes can
+// be matched to config pushes.  Set it as GeneralConfig.ConfigAudit.  Changes that do not change any field are not
+// recorded.
+type ConfigAuditLog struct {
+	changes []ConfigChange
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+// NewConfigAuditLog returns a log that remembers the last size changes
+func NewConfigAuditLog(size int) *ConfigAuditLog {
+	return &ConfigAuditLog{
+		changes: make([]ConfigChange, size),
+	}
+}
+
+func (l *ConfigAuditLog) record(change ConfigChange) {
+	if l == nil || len(l.changes) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes[l.next] = change
+	l.next++
+	if l.next == len(l.changes) {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// Changes returns a copy of the remembered changes, oldest first
+func (l *ConfigAuditLog) Changes() []ConfigChange {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]ConfigChange(nil), l.changes[:l.next]...)
+	}
+	ret := make([]ConfigChange, 0, len(l.changes))
+	ret = append(ret, l.changes[l.next:]...)
+	return append(ret, l.changes[:l.next]...)
+}
+
+// diffConfig returns the fields that differ between old and new, skipping fields that are not encoded as JSON
+func diffConfig(prefix string, old reflect.Value, new reflect.Value) []ConfigFieldChange {
+	var ret []ConfigFieldChange
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		oldField, newField := old.Field(i), new.Field(i)
+		if f.Type.Kind() == reflect.Struct {
+			ret = append(ret, diffConfig(prefix+f.Name+".", oldField, newField)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			ret = append(ret, ConfigFieldChange{
+				Field: prefix + f.Name,
+				Old:   oldField.Interface(),
+				New:   newField.Interface(),
+			})
+		}
+	}
+	return ret
+}
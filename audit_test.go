@@ -0,0 +1,73 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCircuit_SetConfigFrom(t *testing.T) {
+	audit := NewConfigAuditLog(2)
+	c := NewCircuitFromConfig("TestCircuit_SetConfigFrom", Config{
+		General: GeneralConfig{
+			ConfigAudit: audit,
+		},
+	})
+	if changes := audit.Changes(); len(changes) != 0 {
+		t.Errorf("expected creating the circuit to not be audited, got %+v", changes)
+	}
+
+	cfg := c.Config()
+	c.SetConfigFrom("test", cfg)
+	if changes := audit.Changes(); len(changes) != 0 {
+		t.Errorf("expected a change to nothing to not be audited, got %+v", changes)
+	}
+
+	cfg.Execution.Timeout = 2 * time.Second
+	cfg.General.TimeKeeper.Now = time.Now
+	c.SetConfigFrom("test", cfg)
+	changes := audit.Changes()
+	if len(changes) != 1 || changes[0].Source != "test" || changes[0].Circuit != c.Name() {
+		t.Fatalf("expected one change from test, got %+v", changes)
+	}
+	expected := []ConfigFieldChange{{Field: "Execution.Timeout", Old: time.Second, New: 2 * time.Second}}
+	if !reflect.DeepEqual(changes[0].Fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, changes[0].Fields)
+	}
+
+	// The log only keeps the last two changes
+	cfg.Execution.Timeout = 3 * time.Second
+	c.SetConfigThreadSafe(cfg)
+	cfg.Execution.MaxConcurrentRequests = 3
+	c.SetConfigThreadSafe(cfg)
+	changes = audit.Changes()
+	if len(changes) != 2 || changes[0].Fields[0].Field != "Execution.Timeout" || changes[1].Fields[0].Field != "Execution.MaxConcurrentRequests" {
+		t.Errorf("expected the last two changes, oldest first, got %+v", changes)
+	}
+}
+
+type configChangeRecorder struct {
+	countingMetrics
+	changes []ConfigChange
+}
+
+func (c *configChangeRecorder) ConfigChanged(_ context.Context, _ time.Time, change ConfigChange) {
+	c.changes = append(c.changes, change)
+}
+
+func TestCircuit_ConfigChangeMetrics(t *testing.T) {
+	recorder := &configChangeRecorder{}
+	c := NewCircuitFromConfig("TestCircuit_ConfigChangeMetrics", Config{
+		Metrics: MetricsCollectors{
+			Circuit: []Metrics{recorder},
+		},
+	})
+	cfg := c.Config()
+	c.SetConfigFrom("test", cfg)
+	cfg.Execution.MaxConcurrentRequests = 20
+	c.SetConfigFrom("test", cfg)
+	if len(recorder.changes) != 1 || recorder.changes[0].Source != "test" || recorder.changes[0].Fields[0].Field != "Execution.MaxConcurrentRequests" {
+		t.Errorf("expected only the change that changed a field, got %+v", recorder.changes)
+	}
+}
@@ -0,0 +1,78 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchResult is the outcome of one item of ExecuteBatch
+type BatchResult[T any] struct {
+	// Value is what runFunc, or the fallback, returned for the item
+	Value T
+	// Err is the error of the item, like ExecuteValue would return it
+	Err error
+}
+
+// ExecuteBatch runs every item through c with ExecuteValue, at most parallelism at a time, and returns the results in
+// the order of items.  Each item is its own run: it counts towards the circuit's health, can be rejected, and can fall
+// back, on its own.  One failed item does not stop the others.  fallbackFunc may be nil.
+//
+// When parallelism is zero or less, it is the circuit's MaxConcurrentRequests, so the batch does not reject itself.
+// Items not started once ctx ends fail with a *ContextDoneError.
+func ExecuteBatch[I any, T any](ctx context.Context, c *Circuit, items []I, parallelism int, runFunc func(context.Context, I) (T, error), fallbackFunc func(context.Context, I, error) (T, error)) []BatchResult[T] {
+	ret := make([]BatchResult[T], len(items))
+	if len(items) == 0 {
+		return ret
+	}
+	if parallelism <= 0 {
+		parallelism = batchParallelism(c, len(items))
+	}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range items {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			item := items[i]
+			var fallback func(context.Context, error) (T, error)
+			if fallbackFunc != nil {
+				fallback = func(ctx context.Context, err error) (T, error) {
+					return fallbackFunc(ctx, item, err)
+				}
+			}
+			v, err := ExecuteValue(ctx, c, func(ctx context.Context) (T, error) {
+				return runFunc(ctx, item)
+			}, fallback)
+			ret[i] = BatchResult[T]{Value: v, Err: err}
+		}(i)
+	}
+	wg.Wait()
+	return ret
+}
+
+// batchParallelism is how many items of a batch of size n can run at once without going over c's concurrency limit
+func batchParallelism(c *Circuit, n int) int {
+	if c == nil {
+		return n
+	}
+	maxConcurrent := c.config().Execution.MaxConcurrentRequests
+	if maxConcurrent > 0 && maxConcurrent < int64(n) {
+		return int(maxConcurrent)
+	}
+	return n
+}
+
+// BatchErrors returns the errors of results that failed, in order
+func BatchErrors[T any](results []BatchResult[T]) []error {
+	var ret []error
+	for _, r := range results {
+		if r.Err != nil {
+			ret = append(ret, r.Err)
+		}
+	}
+	return ret
+}
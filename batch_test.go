@@ -0,0 +1,87 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	runs := &recordedRuns{}
+	c := NewCircuitFromConfig("TestExecuteBatch", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{runs},
+		},
+	})
+	failure := errors.New("odd")
+	items := []int{0, 1, 2, 3, 4}
+	results := ExecuteBatch(context.Background(), c, items, 1, func(_ context.Context, item int) (string, error) {
+		if item%2 == 1 {
+			return "", failure
+		}
+		return strconv.Itoa(item), nil
+	}, nil)
+	if len(results) != len(items) {
+		t.Fatalf("expected a result per item, got %d", len(results))
+	}
+	for i, r := range results {
+		if i%2 == 1 {
+			if !errors.Is(r.Err, failure) {
+				t.Errorf("expected item %d to fail, got %v", i, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.Value != strconv.Itoa(i) {
+			t.Errorf("expected item %d to return %d, got %q %v", i, i, r.Value, r.Err)
+		}
+	}
+	if runs.successes != 3 || runs.failures != 2 {
+		t.Errorf("expected each item to count as a run, got %+v", runs)
+	}
+	if errs := BatchErrors(results); len(errs) != 2 {
+		t.Errorf("expected two errors, got %v", errs)
+	}
+}
+
+func TestExecuteBatch_fallback(t *testing.T) {
+	c := NewCircuitFromConfig("TestExecuteBatch_fallback", Config{})
+	results := ExecuteBatch(context.Background(), c, []string{"a", "b"}, 0, func(_ context.Context, _ string) (string, error) {
+		return "", errors.New("failure")
+	}, func(_ context.Context, item string, _ error) (string, error) {
+		return item + "-fallback", nil
+	})
+	for i, expected := range []string{"a-fallback", "b-fallback"} {
+		if results[i].Err != nil || results[i].Value != expected {
+			t.Errorf("expected %q, got %+v", expected, results[i])
+		}
+	}
+}
+
+func TestExecuteBatch_parallelism(t *testing.T) {
+	c := NewCircuitFromConfig("TestExecuteBatch_parallelism", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 3,
+		},
+	})
+	var inFlight, maxInFlight atomic.Int64
+	items := make([]int, 50)
+	results := ExecuteBatch(context.Background(), c, items, 0, func(_ context.Context, _ int) (int, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if n <= seen || maxInFlight.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		return 1, nil
+	}, nil)
+	if errs := BatchErrors(results); len(errs) != 0 {
+		t.Fatalf("expected the batch to stay under the concurrency limit, got %v", errs)
+	}
+	if maxInFlight.Load() > 3 {
+		t.Errorf("expected at most 3 items at once, got %d", maxInFlight.Load())
+	}
+}
@@ -0,0 +1,53 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+// Budget divides the time left before a context's deadline across a sequence of circuit calls, so the last call of a
+// chain is not always the one that runs out of time.  Each call's share is taken from the time left when it starts,
+// so time an earlier call did not use goes to the calls after it.  A Budget is for one sequence of calls and is not
+// safe to use concurrently.
+type Budget struct {
+	ctx    context.Context
+	shares []float64
+	next   int
+	now    func() time.Time
+}
+
+// NewBudget creates a Budget for calls made with ctx.  shares are the relative portion of the deadline each call gets,
+// in order.  For example NewBudget(ctx, 6, 3, 1) gives the first call 60% of the time left, the second 75% of what
+// is left after the first, and the third everything after that.
+func NewBudget(ctx context.Context, shares ...float64) *Budget {
+	return &Budget{
+		ctx:    ctx,
+		shares: shares,
+		now:    time.Now,
+	}
+}
+
+// Next returns the context for the next call.  It carries a timeout override of the call's share of the time left, so
+// the circuit's timeout is bounded as well as the context.  Overrides are still capped by
+// ExecutionConfig.MaxTimeoutOverride, which by default only lets them shorten a circuit's timeout.  The context is
+// returned unchanged if it has no deadline, or once every share has been used.
+func (b *Budget) Next() context.Context {
+	deadline, hasDeadline := b.ctx.Deadline()
+	if !hasDeadline || b.next >= len(b.shares) {
+		return b.ctx
+	}
+	share := b.shares[b.next]
+	remainingShares := 0.0
+	for _, s := range b.shares[b.next:] {
+		remainingShares += s
+	}
+	b.next++
+	if share <= 0 || remainingShares <= 0 {
+		return b.ctx
+	}
+	left := deadline.Sub(b.now())
+	if left <= 0 {
+		return b.ctx
+	}
+	return WithTimeoutOverride(b.ctx, time.Duration(float64(left)*share/remainingShares))
+}
@@ -0,0 +1,63 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(10*time.Second))
+	defer cancel()
+	b := NewBudget(ctx, 6, 3, 1)
+	b.now = func() time.Time { return now }
+	overrideOf := func(ctx context.Context) time.Duration {
+		override, _ := ctx.Value(timeoutOverrideKey{}).(time.Duration)
+		return override
+	}
+
+	if override := overrideOf(b.Next()); override != 6*time.Second {
+		t.Errorf("expected 60%% of the time, got %s", override)
+	}
+	// The first call only used 2 of its 6 seconds, so the rest gets 8 seconds split 3 to 1
+	now = now.Add(2 * time.Second)
+	if override := overrideOf(b.Next()); override != 6*time.Second {
+		t.Errorf("expected 75%% of the time left, got %s", override)
+	}
+	now = now.Add(6 * time.Second)
+	if override := overrideOf(b.Next()); override != 2*time.Second {
+		t.Errorf("expected everything left, got %s", override)
+	}
+	if next := b.Next(); next != ctx {
+		t.Error("expected the context unchanged after every share is used")
+	}
+}
+
+func TestBudget_noDeadline(t *testing.T) {
+	ctx := context.Background()
+	if next := NewBudget(ctx, 1, 1).Next(); next != ctx {
+		t.Error("expected the context unchanged without a deadline")
+	}
+}
+
+func TestBudget_boundsRuns(t *testing.T) {
+	c := NewCircuitFromConfig("TestBudget_boundsRuns", Config{
+		Execution: ExecutionConfig{
+			Timeout: time.Hour,
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	b := NewBudget(ctx, 1, 1)
+	err := c.Run(b.Next(), func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		if left := time.Until(deadline); left > 31*time.Second {
+			t.Errorf("expected half the minute, got %s", left)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,7 +3,9 @@ package circuit
 import (
 	"context"
 	"expvar"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cep21/circuit/v4/faststats"
@@ -18,22 +20,53 @@ type Circuit struct {
 	// This is used to help run `Go` calls in the background
 	goroutineWrapper goroutineWrapper
 	name             string
-	// The passed in config is not atomic and thread safe.  We reference thread safe values during circuit operations
-	// with atomicCircuitConfig.  Those are, also, the only values that can actually be changed while a circuit is
-	// running.
-	notThreadSafeConfig Config
-	// The mutex supports setting and reading the command properties, but is not locked when we reference the config
-	// while live: we use the threadSafeConfig below
-	notThreadSafeConfigMu sync.Mutex
-	threadSafeConfig      atomicCircuitConfig
+	// currentConfig is the current config.  Runs load it without locks, and changes to the config store a new one.
+	currentConfig atomic.Pointer[configSnapshot]
+	// configMu makes changes to the config one at a time.  Runs never lock it.
+	configMu sync.Mutex
 
 	// Tracks if the circuit has been shut open or closed
 	isOpen faststats.AtomicBoolean
+	// Tracks why and when isOpen last changed
+	state stateChange
+	// Set when the next request to an open circuit should be allowed without asking OpenToClose
+	probeNext faststats.AtomicBoolean
+	// Set while the circuit is inside one of GeneralConfig.MaintenanceWindows, as last seen by checkMaintenance
+	maintenanceOpen faststats.AtomicBoolean
+	// Counts opens and closes inside GeneralConfig.FlapWindow
+	flaps faststats.RollingCounter
+	// When the circuit last closed, and until when it is held open, in unix nanoseconds
+	closedAt      faststats.AtomicInt64
+	holdOpenUntil faststats.AtomicInt64
 
 	// Tracks how many commands are currently running
 	concurrentCommands faststats.AtomicInt64
+	// Set while runs in flight are at ExecutionConfig.ConcurrencyWarningPercent
+	concurrencyWarned atomic.Bool
+	// Runs waiting for room under MaxConcurrentRequests
+	queue runQueue
 	// Tracks how many fallbacks are currently running
 	concurrentFallbacks faststats.AtomicInt64
+	// Tracks how often ExecuteShadow saw runFunc and the fallback disagree
+	shadowMatches    faststats.AtomicInt64
+	shadowMismatches faststats.AtomicInt64
+
+	// The most recent failures and timeouts.  Nil if the circuit remembers none.
+	recentErrors *errorSamples
+	// The most recent bad requests.  Nil if the circuit remembers none.
+	recentBadRequests *errorSamples
+	// Concurrency counts per partition.  Nil if ExecutionConfig.PartitionKey is not set.
+	partitions *partitions
+	// Calls to other circuits from inside this circuit's runs
+	nestedCalls nestedCalls
+	// First attempts and retries inside ExecutionConfig.RetryBudgetWindow
+	retryBudget retryBudget
+	// Totals of the counters named by GeneralConfig.Counters.  It does not change after SetConfigNotThreadSafe.
+	counters map[string]*faststats.AtomicInt64
+	// Set when a run collector implements OverheadMetrics
+	measureOverhead bool
+	// Set when runs without a timeout must still mark their context with the circuit.  See withRunningCircuit.
+	markRunning bool
 
 	// ClosedToOpen controls when to open a closed circuit
 	ClosedToOpen ClosedToOpen
@@ -41,6 +74,9 @@ type Circuit struct {
 	OpenToClose OpenToClosed
 
 	timeNow func() time.Time
+	// createdAt is when NewCircuitFromConfig created the circuit.  It is the zero time for circuits created any other
+	// way.
+	createdAt time.Time
 }
 
 // NewCircuitFromConfig creates an inline circuit.  If you want to group all your circuits together, you should probably
@@ -48,14 +84,16 @@ type Circuit struct {
 func NewCircuitFromConfig(name string, config Config) *Circuit {
 	config.Merge(defaultCommandProperties)
 	ret := &Circuit{
-		name:                name,
-		notThreadSafeConfig: config,
+		name: name,
 	}
 	ret.SetConfigNotThreadSafe(config)
+	ret.createdAt = ret.now()
+	ret.setState(StateReasonInitial, ret.createdAt)
+	ret.setInitialState(config.General.InitialState, ret.createdAt)
 	return ret
 }
 
-// ConcurrentCommands returns how many commands are currently running
+// ConcurrentCommands returns how many commands are currently running.  Runs with a Cost count that many times.
 func (c *Circuit) ConcurrentCommands() int64 {
 	return c.concurrentCommands.Get()
 }
@@ -68,70 +106,176 @@ func (c *Circuit) ConcurrentFallbacks() int64 {
 // SetConfigThreadSafe changes the current configuration of this circuit. Note that many config parameters, specifically those
 // around creating stat tracking buckets, are not modifiable during runtime for efficiency reasons.  Those buckets
 // will stay the same.
+//
+// The whole config is swapped at once.  A run that started before the change sees only the old config, and a run that
+// starts after it sees only the new one.
 func (c *Circuit) SetConfigThreadSafe(config Config) {
-	c.notThreadSafeConfigMu.Lock()
-	defer c.notThreadSafeConfigMu.Unlock()
-	c.notThreadSafeConfig = config
-	c.threadSafeConfig.reset(c.notThreadSafeConfig)
+	c.SetConfigFrom("", config)
+}
+
+// SetConfigFrom is SetConfigThreadSafe, but records source as who or what made the change in
+// GeneralConfig.ConfigAudit.  Use something like "admin:alice" or "config-service".
+func (c *Circuit) SetConfigFrom(source string, config Config) {
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	c.notifyConfigChanged(c.setConfigFromWithLock(source, config))
+}
+
+// setConfigFromWithLock is SetConfigFrom without the ConfigChanged call, which should be made with the returned change.
+// It must hold configMu.
+func (c *Circuit) setConfigFromWithLock(source string, config Config) ConfigChange {
+	var change ConfigChange
+	if config.General.ConfigAudit != nil || c.CircuitMetricsCollector.watchesConfigChanges() {
+		change = ConfigChange{
+			Time:    c.now(),
+			Circuit: c.name,
+			Source:  source,
+			Fields:  diffConfig("", reflect.ValueOf(c.config().Config), reflect.ValueOf(config)),
+		}
+		if len(change.Fields) != 0 {
+			config.General.ConfigAudit.record(change)
+		}
+	}
+	c.setConfigWithLock(config)
+	return change
+}
+
+// notifyConfigChanged tells the circuit's metrics about a change from setConfigFromWithLock
+func (c *Circuit) notifyConfigChanged(change ConfigChange) {
+	if len(change.Fields) != 0 && c.CircuitMetricsCollector.watchesConfigChanges() {
+		c.CircuitMetricsCollector.ConfigChanged(context.Background(), change.Time, change)
+	}
+}
+
+// setConfigWithLock changes the config of a running circuit.  It must hold configMu.
+func (c *Circuit) setConfigWithLock(config Config) {
+	old := c.config()
+	c.state.mu.Lock()
+	forceChanged := config.General.ForceOpen != old.General.ForceOpen || config.General.ForcedClosed != old.General.ForcedClosed
+	c.currentConfig.Store(newConfigSnapshot(config, old.version+1))
+	if forceChanged {
+		now := c.now()
+		c.state.forcedSince = now
+		c.state.moveToWithLock(c.phaseWithLock(), now)
+	}
+	c.state.mu.Unlock()
 	if cfg, ok := c.OpenToClose.(Configurable); ok {
 		cfg.SetConfigThreadSafe(config)
 	}
 	if cfg, ok := c.ClosedToOpen.(Configurable); ok {
 		cfg.SetConfigThreadSafe(config)
 	}
+	if cfg, ok := c.OpenToClose.(ThresholdsConfigurable); ok {
+		cfg.SetThresholds(config.General.Thresholds)
+	}
+	if cfg, ok := c.ClosedToOpen.(ThresholdsConfigurable); ok {
+		cfg.SetThresholds(config.General.Thresholds)
+	}
 }
 
 // Config returns the circuit's configuration.  Modifications to this configuration are not reflected by the circuit.
 // In other words, this creates a copy.
 func (c *Circuit) Config() Config {
-	c.notThreadSafeConfigMu.Lock()
-	defer c.notThreadSafeConfigMu.Unlock()
-	return c.notThreadSafeConfig
+	return c.config().Config
+}
+
+// config is the current config snapshot.  Load it once and keep it to read many fields from the same config.
+func (c *Circuit) config() *configSnapshot {
+	if snapshot := c.currentConfig.Load(); snapshot != nil {
+		return snapshot
+	}
+	return emptyConfigSnapshot
 }
 
 // SetConfigNotThreadSafe is only useful during construction before a circuit is being used.  It is not thread safe,
 // but will modify all the circuit's internal structs to match what the config wants.  It also doe *NOT* use the
 // default configuration parameters.
 func (c *Circuit) SetConfigNotThreadSafe(config Config) {
-	c.notThreadSafeConfigMu.Lock()
-	// Set, but do not reference this config inside this function, since that would not be thread safe (no mu protection)
-	c.notThreadSafeConfig = config
-	c.notThreadSafeConfigMu.Unlock()
-
 	c.goroutineWrapper.lostErrors = config.General.GoLostErrors
+	c.goroutineWrapper.circuit = c
 	c.timeNow = config.General.TimeKeeper.Now
-
-	c.OpenToClose = config.General.OpenToClosedFactory()
-	c.ClosedToOpen = config.General.ClosedToOpenFactory()
-	if cfg, ok := c.OpenToClose.(Configurable); ok {
-		cfg.SetConfigNotThreadSafe(config)
-	}
-	if cfg, ok := c.ClosedToOpen.(Configurable); ok {
-		cfg.SetConfigNotThreadSafe(config)
+	c.recentErrors = newErrorSamples(config.General.RecentErrorsSize)
+	c.recentBadRequests = newErrorSamples(config.General.RecentBadRequestsSize)
+	c.flaps = faststats.NewRollingCounter(config.General.FlapWindow/flapBuckets, flapBuckets, c.now())
+	c.retryBudget = newRetryBudget(config.Execution.RetryBudgetWindow, c.now())
+	c.counters = newCounters(config.General.Counters)
+	c.partitions = nil
+	if config.Execution.PartitionKey != nil {
+		c.partitions = newPartitions(config.Execution.MaxPartitions)
+	}
+
+	if config.General.DecisionEngineFactory != nil {
+		engine := &decisionEngineAdapter{engine: config.General.DecisionEngineFactory()}
+		c.OpenToClose = engine
+		c.ClosedToOpen = engine
+		// The engine is both, but must only see each event once
+		c.CmdMetricCollector = append(make([]RunMetrics, 0, len(config.Metrics.Run)+1), engine)
+		c.CircuitMetricsCollector = append(make([]Metrics, 0, len(config.Metrics.Circuit)+1), engine)
+	} else {
+		c.OpenToClose = config.General.OpenToClosedFactory()
+		c.ClosedToOpen = config.General.ClosedToOpenFactory()
+		if cfg, ok := c.OpenToClose.(Configurable); ok {
+			cfg.SetConfigNotThreadSafe(config)
+		}
+		if cfg, ok := c.ClosedToOpen.(Configurable); ok {
+			cfg.SetConfigNotThreadSafe(config)
+		}
+		c.CmdMetricCollector = append(make([]RunMetrics, 0, len(config.Metrics.Run)+2), c.OpenToClose, c.ClosedToOpen)
+		c.CircuitMetricsCollector = append(make([]Metrics, 0, len(config.Metrics.Circuit)+2), c.OpenToClose, c.ClosedToOpen)
 	}
-	c.CmdMetricCollector = append(
-		make([]RunMetrics, 0, len(config.Metrics.Run)+2),
-		c.OpenToClose,
-		c.ClosedToOpen)
 	c.CmdMetricCollector = append(c.CmdMetricCollector, config.Metrics.Run...)
+	c.measureOverhead = c.CmdMetricCollector.measuresOverhead()
+	c.markRunning = len(c.counters) != 0 || c.CmdMetricCollector.measuresSizes()
 
 	c.FallbackMetricCollector = append(
 		make([]FallbackMetrics, 0, len(config.Metrics.Fallback)+2),
 		config.Metrics.Fallback...)
 
-	c.CircuitMetricsCollector = append(
-		make([]Metrics, 0, len(config.Metrics.Circuit)+2),
-		c.OpenToClose,
-		c.ClosedToOpen)
 	c.CircuitMetricsCollector = append(c.CircuitMetricsCollector, config.Metrics.Circuit...)
+	if config.General.Rand != nil {
+		c.inheritRand(config.General.Rand)
+	}
 
-	c.SetConfigThreadSafe(config)
+	// Setting up the circuit is not a change to audit
+	c.configMu.Lock()
+	c.setConfigWithLock(config)
+	c.configMu.Unlock()
 }
 
 func (c *Circuit) now() time.Time {
+	if c.timeNow == nil {
+		// Only circuits that were never configured have no clock
+		return time.Now()
+	}
 	return c.timeNow()
 }
 
+// RecentErrors returns the most recent failures and timeouts of this circuit, in order backwards in time.  The number
+// of errors remembered is controlled by GeneralConfig.RecentErrorsSize.
+func (c *Circuit) RecentErrors() []ErrorSample {
+	if c == nil {
+		return nil
+	}
+	return c.recentErrors.get()
+}
+
+// RecentBadRequests returns the most recent bad requests of this circuit, in order backwards in time.  The number of
+// bad requests remembered is controlled by GeneralConfig.RecentBadRequestsSize.
+func (c *Circuit) RecentBadRequests() []ErrorSample {
+	if c == nil {
+		return nil
+	}
+	return c.recentBadRequests.get()
+}
+
+// Partitions returns the concurrency of each partition, if ExecutionConfig.PartitionKey is set
+func (c *Circuit) Partitions() []PartitionStats {
+	if c == nil {
+		return nil
+	}
+	return c.partitions.stats()
+}
+
 // Var exports that help diagnose the circuit
 func (c *Circuit) Var() expvar.Var {
 	return expvar.Func(func() interface{} {
@@ -140,7 +284,11 @@ func (c *Circuit) Var() expvar.Var {
 		}
 		ret := map[string]interface{}{
 			"config":               c.Config(),
+			"config_version":       c.config().version,
 			"is_open":              c.IsOpen(),
+			"state":                c.StateInfo(),
+			"time_in_state":        c.TimeInState(),
+			"flaps":                c.Flaps(),
 			"name":                 c.Name(),
 			"run_metrics":          expvarToVal(c.CmdMetricCollector.Var()),
 			"concurrent_commands":  c.ConcurrentCommands(),
@@ -148,6 +296,17 @@ func (c *Circuit) Var() expvar.Var {
 			"closer":               c.OpenToClose,
 			"opener":               c.ClosedToOpen,
 			"fallback_metrics":     expvarToVal(c.FallbackMetricCollector.Var()),
+			"recent_errors":        c.RecentErrors(),
+			"recent_bad_requests":  c.RecentBadRequests(),
+			"shadow_matches":       c.shadowMatches.Get(),
+			"shadow_mismatches":    c.shadowMismatches.Get(),
+			"partitions":           c.Partitions(),
+			"nested_calls":         c.NestedCalls(),
+			"retry_budget":         c.RetryBudget(),
+			"queue_depth":          c.QueueDepth(),
+			"health_score":         c.HealthScore(),
+			"counters":             c.Counters(),
+			"memory_usage":         c.MemoryUsage(),
 		}
 		return ret
 	})
@@ -166,12 +325,16 @@ func (c *Circuit) IsOpen() bool {
 	if c == nil {
 		return false
 	}
-	if c.threadSafeConfig.CircuitBreaker.ForceOpen.Get() {
+	cfg := c.config()
+	if cfg.General.ForceOpen {
 		return true
 	}
-	if c.threadSafeConfig.CircuitBreaker.ForcedClosed.Get() {
+	if cfg.General.ForcedClosed {
 		return false
 	}
+	if c.inMaintenance() {
+		return true
+	}
 	return c.isOpen.Get()
 }
 
@@ -183,13 +346,13 @@ func (c *Circuit) CloseCircuit(ctx context.Context) {
 
 // OpenCircuit will open a closed circuit.  The circuit will then try to repair itself
 func (c *Circuit) OpenCircuit(ctx context.Context) {
-	c.openCircuit(ctx, time.Now())
+	c.openCircuit(ctx, time.Now(), StateReasonManual)
 }
 
 // OpenCircuit opens a circuit, without checking error thresholds or request volume thresholds.  The circuit will, after
 // some delay, try to close again.
-func (c *Circuit) openCircuit(ctx context.Context, now time.Time) {
-	if c.threadSafeConfig.CircuitBreaker.ForcedClosed.Get() {
+func (c *Circuit) openCircuit(ctx context.Context, now time.Time, reason StateReason) {
+	if c.config().General.ForcedClosed {
 		// Don't open circuits that are forced closed
 		return
 	}
@@ -199,11 +362,18 @@ func (c *Circuit) openCircuit(ctx context.Context, now time.Time) {
 	}
 	c.CircuitMetricsCollector.Opened(ctx, now)
 	c.isOpen.Set(true)
+	c.probeNext.Set(false)
+	c.setState(reason, now)
+	c.flaps.Inc(now)
+	c.holdOpenIfFlapping(now)
 }
 
 // Go executes `Execute`, but uses spawned goroutines to end early if the context is canceled.  Use this if you don't trust
 // the runFunc to end correctly if context fails.  This is a design mirroed in the go-hystrix library, but be warned it
 // is very dangerous and could leave orphaned goroutines hanging around forever doing who knows what.
+//
+// If the circuit has no timeout and ctx can never end, there is nothing to end early for and runFunc is called on the
+// caller's goroutine.
 func (c *Circuit) Go(ctx context.Context, runFunc func(context.Context) error, fallbackFunc func(context.Context, error) error) error {
 	if c == nil {
 		var wrapper goroutineWrapper
@@ -220,30 +390,101 @@ func (c *Circuit) Run(ctx context.Context, runFunc func(context.Context) error)
 // Execute the circuit.  Prefer this over Go.  Similar to http://netflix.github.io/Hystrix/javadoc/com/netflix/hystrix/HystrixCommand.html#execute--
 // The returned error will either be the result of runFunc, the result of fallbackFunc, or an internal library error.
 // Internal library errors will match the interface Error and you can use type casting to check this.
+//
+// A successful run of a closed circuit does not allocate, with one exception: when the circuit has a timeout, the
+// context given to runFunc is allocated for each run.  runFunc may keep that context after it returns, so it cannot be
+// pooled.  Set ExecutionConfig.Timeout to -1 for a run with no allocations at all.
 func (c *Circuit) Execute(ctx context.Context, runFunc func(context.Context) error, fallbackFunc func(context.Context, error) error) error {
-	if c.isEmptyOrNil() || c.threadSafeConfig.CircuitBreaker.Disabled.Get() {
+	return c.execute(ctx, runFunc, fallbackFunc, false, nil)
+}
+
+// --------- only private functions below here
+
+// execute is Execute.  When shadow is set, a runFunc that worked (or failed with an error configured as a success) is
+// followed by a shadow fallback, compared with compare.
+func (c *Circuit) execute(ctx context.Context, runFunc func(context.Context) error, fallbackFunc func(context.Context, error) error,
+	shadow bool, compare func(runErr error, fallbackErr error) bool) error {
+	if c.isEmptyOrNil() || c.config().General.Disabled {
 		return runFunc(ctx)
 	}
+	if err := c.checkContextDone(ctx); err != nil {
+		return err
+	}
 
-	// Try to run the command in the context of the circuit
-	err := c.run(ctx, runFunc)
-	if err == nil {
-		return nil
+	var overhead *overheadTimer
+	if c.measureOverhead {
+		overhead = &overheadTimer{start: time.Now()}
+		defer c.reportOverhead(ctx, overhead)
 	}
+
+	if isFallbackForced(ctx) {
+		return c.fallback(ctx, ErrForcedFallback, FallbackReasonForced, fallbackFunc, overhead)
+	}
+
+	// Try to run the command in the context of the circuit
+	reason, err := c.run(ctx, runFunc, overhead)
 	// A bad request should not trigger fallback logic.  The user just gave bad input.
 	// The list of conditions that trigger fallbacks is documented at
 	// https://github.com/Netflix/Hystrix/wiki/Metrics-and-Monitoring#command-execution-event-types-comnetflixhystrixhystrixeventtype
-	if IsBadRequest(err) {
+	if err != nil && IsBadRequest(err) {
 		return err
 	}
-	return c.fallback(ctx, err, fallbackFunc)
+	// Errors configured as successes are a normal answer from the dependency and also skip the fallback
+	if err == nil || c.isSuccessError(err) {
+		if shadow {
+			return c.shadowFallback(ctx, err, fallbackFunc, compare, overhead)
+		}
+		return err
+	}
+	return c.fallback(ctx, err, reason, fallbackFunc, overhead)
 }
 
-// --------- only private functions below here
+func (c *Circuit) throttleConcurrentCommands(currentCommandCount int64) bool {
+	limit := c.config().Execution.MaxConcurrentRequests
+	return limit >= 0 && currentCommandCount > limit
+}
+
+func (c *Circuit) throttlePartitionCommands(currentCommandCount int64) bool {
+	limit := c.config().Execution.MaxConcurrentRequestsPerPartition
+	return limit >= 0 && currentCommandCount > limit
+}
+
+// concurrencyLimitError describes a run rejected by MaxConcurrentRequests
+func (c *Circuit) concurrencyLimitError(cfg *configSnapshot, inFlight int64) *ConcurrencyLimitError {
+	return &ConcurrencyLimitError{
+		Circuit:      c.Name(),
+		Limit:        cfg.Execution.MaxConcurrentRequests,
+		InFlight:     inFlight,
+		QueueDepth:   c.QueueDepth(),
+		MaxQueueSize: cfg.Execution.MaxQueueSize,
+	}
+}
 
-func (c *Circuit) throttleConcurrentCommands(currentCommandCount int64) error {
-	if c.threadSafeConfig.Execution.MaxConcurrentRequests.Get() >= 0 && currentCommandCount > c.threadSafeConfig.Execution.MaxConcurrentRequests.Get() {
-		return errThrottledConcurrentCommands
+// openError describes why a run at now was rejected because the circuit is open
+func (c *Circuit) openError(now time.Time) *CircuitOpenError {
+	ret := &CircuitOpenError{
+		Circuit:    c.Name(),
+		RejectedAt: now,
+	}
+	info := c.stateInfoAt(now)
+	if !info.Open {
+		// ClosedToOpen prevented the run without opening the circuit
+		return ret
+	}
+	ret.Reason = info.Reason
+	ret.OpenedAt = info.Since
+	ret.RetryAt = info.NextProbe
+	return ret
+}
+
+// checkContextDone returns a *ContextDoneError if ctx ended before the run started.  Detached runs ignore the caller's
+// cancellation, so they are started anyway.
+func (c *Circuit) checkContextDone(ctx context.Context) error {
+	if c.config().Execution.DetachContext {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return &ContextDoneError{Err: err}
 	}
 	return nil
 }
@@ -256,40 +497,109 @@ func (c *Circuit) isEmptyOrNil() bool {
 }
 
 // run is the equivalent of Java Manager's http://netflix.github.io/Hystrix/javadoc/com/netflix/hystrix/HystrixCommand.html#run()
-func (c *Circuit) run(ctx context.Context, runFunc func(context.Context) error) (retErr error) {
+// It also returns why a fallback should run for the error it returns.
+func (c *Circuit) run(ctx context.Context, runFunc func(context.Context) error, overhead *overheadTimer) (FallbackReason, error) {
 	if runFunc == nil {
-		return nil
+		return "", nil
 	}
+	// Read the config once, so a change while running does not mix two configs
+	cfg := c.config()
 	var expectedDoneBy time.Time
 	startTime := c.now()
 	originalContext := ctx
+	nested := c.recordNesting(ctx)
 
-	if !c.allowNewRun(ctx, startTime) {
-		// Rather than make this inline, return a global reference (for memory optimization sake).
+	// Bypassed runs skip every check that could reject them
+	bypass := isBypassed(ctx)
+
+	if !bypass && !c.allowNewRun(ctx, startTime) {
 		c.CmdMetricCollector.ErrShortCircuit(ctx, startTime)
-		return errCircuitOpen
+		return FallbackReasonOpen, c.openError(startTime)
 	}
 
-	if c.ClosedToOpen.Prevent(ctx, startTime) {
-		return errCircuitOpen
+	if !bypass && c.ClosedToOpen.Prevent(ctx, startTime) {
+		c.CmdMetricCollector.ErrShortCircuit(ctx, startTime)
+		return FallbackReasonOpen, c.openError(startTime)
+	}
+
+	if !bypass && !c.checkRetryBudget(ctx, startTime) {
+		return FallbackReasonRejected, ErrRetryBudgetExhausted
+	}
+
+	cost := Cost(ctx)
+	currentCommandCount := c.concurrentCommands.Add(cost)
+	if limiter := cfg.Execution.ConcurrencyLimiter; limiter != nil {
+		defer c.releaseCommands(cost)
+		if !bypass {
+			release, err := limiter.Acquire(ctx)
+			if err != nil {
+				c.CmdMetricCollector.ErrConcurrencyLimitReject(ctx, startTime)
+				if limitErr, ok := err.(*ConcurrencyLimitError); ok && limitErr.Circuit == "" {
+					limitErr.Circuit = c.Name()
+				}
+				return FallbackReasonRejected, err
+			}
+			defer release()
+		}
+	} else if c.throttleConcurrentCommands(currentCommandCount) && !bypass {
+		c.concurrentCommands.Add(-cost)
+		if !c.waitInQueue(ctx, cost, overhead) {
+			c.CmdMetricCollector.ErrConcurrencyLimitReject(ctx, startTime)
+			return FallbackReasonRejected, c.concurrencyLimitError(cfg, c.concurrentCommands.Get())
+		}
+		defer c.releaseCommands(cost)
+		// Time in the queue is not part of the run
+		startTime = c.now()
+	} else {
+		defer c.releaseCommands(cost)
+	}
+	if cfg.Execution.ConcurrencyWarningPercent > 0 && cfg.Execution.ConcurrencyLimiter == nil {
+		c.checkConcurrencyWarning(cfg, startTime)
+	}
+
+	if c.partitions != nil {
+		part, partitionCommandCount := c.partitions.acquire(cfg.Execution.PartitionKey(ctx), startTime, cost)
+		defer c.partitions.release(part, cost)
+		if c.throttlePartitionCommands(partitionCommandCount) && !bypass {
+			part.concurrencyLimitRejects.Add(1)
+			c.CmdMetricCollector.ErrConcurrencyLimitReject(ctx, startTime)
+			return FallbackReasonRejected, &ConcurrencyLimitError{
+				Circuit:   c.Name(),
+				Partition: part.key,
+				Limit:     cfg.Execution.MaxConcurrentRequestsPerPartition,
+				InFlight:  partitionCommandCount - cost,
+			}
+		}
 	}
 
-	currentCommandCount := c.concurrentCommands.Add(1)
-	defer c.concurrentCommands.Add(-1)
-	if err := c.throttleConcurrentCommands(currentCommandCount); err != nil {
-		c.CmdMetricCollector.ErrConcurrencyLimitReject(ctx, startTime)
-		return err
+	// Detached runs should not see the caller's cancellation.  They also cannot be interrupted by the caller, so the
+	// interrupt check below looks at the detached context instead.
+	if cfg.Execution.DetachContext {
+		ctx = context.WithoutCancel(ctx)
+		originalContext = ctx
 	}
 
+	timeout := c.timeout(ctx)
+	// The overrides were for this circuit, not for the circuits runFunc calls
+	ctx = withoutOverrides(ctx)
+
 	// Set timeout on the command if we have one
-	if c.threadSafeConfig.Execution.ExecutionTimeout.Get() > 0 {
-		var timeoutCancel func()
-		expectedDoneBy = startTime.Add(c.threadSafeConfig.Execution.ExecutionTimeout.Duration())
-		ctx, timeoutCancel = context.WithDeadline(ctx, expectedDoneBy)
-		defer timeoutCancel()
+	if timeout > 0 {
+		expectedDoneBy = startTime.Add(timeout)
+		timeoutCtx := newTimeoutContext(ctx, expectedDoneBy)
+		defer timeoutCtx.release()
+		ctx = timeoutCtx
+		if cfg.Execution.OnStuckExecution != nil {
+			if timer := c.watchForStuckRun(startTime, timeout); timer != nil {
+				defer timer.Stop()
+			}
+		}
 	}
+	ctx = c.withRunningCircuit(ctx, cfg, nested)
 
+	runStart := overhead.now()
 	ret := runFunc(ctx)
+	overhead.exclude(runStart)
 	endTime := c.now()
 	totalCmdTime := endTime.Sub(startTime)
 	runFuncDoneTime := c.now()
@@ -300,31 +610,37 @@ func (c *Circuit) run(ctx context.Context, runFunc func(context.Context) error)
 	// The HystrixBadRequestException is intended for use cases such as reporting illegal arguments or non-system
 	// failures that should not count against the failure metrics and should not trigger fallback logic.
 	if c.checkErrBadRequest(ctx, ret, runFuncDoneTime, totalCmdTime) {
-		return ret
+		return "", ret
 	}
 
 	// Even if there is no error (or if there is an error), if the request took too long it is always an error for the
 	// circuit.  Note that ret *MAY* actually be nil.  In that case, we still want to return nil.
-	if c.checkErrTimeout(ctx, expectedDoneBy, runFuncDoneTime, totalCmdTime) {
+	if c.checkErrTimeout(ctx, expectedDoneBy, ret, runFuncDoneTime, totalCmdTime) {
 		// Note: ret could possibly be nil.  We will still return nil, but the circuit will consider it a failure.
-		return ret
+		return FallbackReasonTimeout, ret
 	}
 
 	// The runFunc failed, but someone asked the original context to end.  This probably isn't a failure of the
 	// circuit: someone just wanted `Execute` to end early, so don't track it as a failure.
 	if c.checkErrInterrupt(ctx, originalContext, ret, runFuncDoneTime, totalCmdTime) {
-		return ret
+		return FallbackReasonError, ret
+	}
+
+	// Some errors are expected answers from a healthy dependency and count as a success for the circuit.
+	if c.isSuccessError(ret) {
+		c.checkSuccess(ctx, runFuncDoneTime, totalCmdTime)
+		return "", ret
 	}
 
 	if c.checkErrFailure(ctx, ret, runFuncDoneTime, totalCmdTime) {
-		return ret
+		return FallbackReasonError, ret
 	}
 
 	// The circuit works.  Close it!
 	// Note: Execute this *after* you check for timeouts so we can still track circuit time outs that happen to also return a
 	//       valid value later.
 	c.checkSuccess(ctx, runFuncDoneTime, totalCmdTime)
-	return nil
+	return "", nil
 }
 
 func (c *Circuit) checkSuccess(ctx context.Context, runFuncDoneTime time.Time, totalCmdTime time.Duration) {
@@ -337,15 +653,28 @@ func (c *Circuit) checkSuccess(ctx context.Context, runFuncDoneTime time.Time, t
 // checkErrInterrupt returns true if this is considered an interrupt error: interrupt errors do not open the circuit.
 // Normally if the parent context is canceled before a timeout is reached, we don't consider the circuit
 // unhealthy. But when ExecutionConfig.IgnoreInterrupts set to true we try to classify originalContext.Err()
-// with help of ExecutionConfig.IsErrInterrupt function. When this function returns true we do not open the circuit
+// with help of ExecutionConfig.IsErrInterrupt function. When this function returns true we do not open the circuit.
+// If ExecutionConfig.InterruptClassifier is set, it alone decides.
 func (c *Circuit) checkErrInterrupt(ctx context.Context, originalContext context.Context, ret error, runFuncDoneTime time.Time, totalCmdTime time.Duration) bool {
+	if ret == nil {
+		return false
+	}
+
+	if classifier := c.config().Execution.InterruptClassifier; classifier != nil {
+		if classifier(originalContext, ret) {
+			c.CmdMetricCollector.ErrInterrupt(ctx, runFuncDoneTime, totalCmdTime)
+			return true
+		}
+		return false
+	}
+
 	// We need to see an error in both the original context and the return value to consider this an "interrupt" caused
 	// error.
-	if ret == nil || originalContext.Err() == nil {
+	if originalContext.Err() == nil {
 		return false
 	}
 
-	isErrInterrupt := c.notThreadSafeConfig.Execution.IsErrInterrupt
+	isErrInterrupt := c.config().Execution.IsErrInterrupt
 	if isErrInterrupt == nil {
 		isErrInterrupt = func(_ error) bool {
 			// By default, we consider any error from the original context an interrupt causing error
@@ -353,7 +682,7 @@ func (c *Circuit) checkErrInterrupt(ctx context.Context, originalContext context
 		}
 	}
 
-	if !c.threadSafeConfig.GoSpecific.IgnoreInterrupts.Get() && isErrInterrupt(originalContext.Err()) {
+	if !c.config().Execution.IgnoreInterrupts && isErrInterrupt(originalContext.Err()) {
 		c.CmdMetricCollector.ErrInterrupt(ctx, runFuncDoneTime, totalCmdTime)
 		return true
 	}
@@ -361,9 +690,30 @@ func (c *Circuit) checkErrInterrupt(ctx context.Context, originalContext context
 	return false
 }
 
+// recordError remembers a failure or timeout and tells collectors about the error behind it
+func (c *Circuit) recordError(ctx context.Context, sample ErrorSample) {
+	sample.Err = c.redact(sample.Err)
+	c.recentErrors.add(sample)
+	c.CmdMetricCollector.RunError(ctx, sample.Time, sample.Err)
+}
+
+// isSuccessError returns true if err matches one of ExecutionConfig.SuccessErrors
+func (c *Circuit) isSuccessError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, isSuccess := range c.config().Execution.SuccessErrors {
+		if isSuccess(err) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Circuit) checkErrBadRequest(ctx context.Context, ret error, runFuncDoneTime time.Time, totalCmdTime time.Duration) bool {
 	if IsBadRequest(ret) {
 		c.CmdMetricCollector.ErrBadRequest(ctx, runFuncDoneTime, totalCmdTime)
+		c.recentBadRequests.add(ErrorSample{Time: runFuncDoneTime, Duration: totalCmdTime, Err: c.redact(ret)})
 		return true
 	}
 	return false
@@ -372,20 +722,44 @@ func (c *Circuit) checkErrBadRequest(ctx context.Context, ret error, runFuncDone
 func (c *Circuit) checkErrFailure(ctx context.Context, ret error, runFuncDoneTime time.Time, totalCmdTime time.Duration) bool {
 	if ret != nil {
 		c.CmdMetricCollector.ErrFailure(ctx, runFuncDoneTime, totalCmdTime)
+		c.recordError(ctx, ErrorSample{Time: runFuncDoneTime, Duration: totalCmdTime, Err: ret})
+		c.checkRetryAfter(ctx, ret, runFuncDoneTime)
 		if !c.IsOpen() {
 			c.attemptToOpen(ctx, runFuncDoneTime)
+		} else {
+			c.setHalfOpen(false, runFuncDoneTime)
 		}
 		return true
 	}
 	return false
 }
 
-func (c *Circuit) checkErrTimeout(ctx context.Context, expectedDoneBy time.Time, runFuncDoneTime time.Time, totalCmdTime time.Duration) bool {
+// checkRetryAfter tells RetryAfterMetrics about a failure's RetryAfter hint, if it has one
+func (c *Circuit) checkRetryAfter(ctx context.Context, ret error, now time.Time) {
+	var after time.Duration
+	if classifier := c.config().Execution.RetryAfterClassifier; classifier != nil {
+		after = classifier(ret)
+	} else {
+		after = RetryAfterOf(ret)
+	}
+	if after > 0 {
+		c.CmdMetricCollector.RetryAfter(ctx, now, after)
+	}
+}
+
+func (c *Circuit) checkErrTimeout(ctx context.Context, expectedDoneBy time.Time, ret error, runFuncDoneTime time.Time, totalCmdTime time.Duration) bool {
 	// I don't use the deadline from the context because it could be a smaller timeout from the parent context
 	if !expectedDoneBy.IsZero() && expectedDoneBy.Before(runFuncDoneTime) {
 		c.CmdMetricCollector.ErrTimeout(ctx, runFuncDoneTime, totalCmdTime)
+		if ret == nil {
+			ret = context.DeadlineExceeded
+		}
+		c.recordError(ctx, ErrorSample{Time: runFuncDoneTime, Duration: totalCmdTime, Err: ret})
+		c.checkRetryAfter(ctx, ret, runFuncDoneTime)
 		if !c.IsOpen() {
 			c.attemptToOpen(ctx, runFuncDoneTime)
+		} else {
+			c.setHalfOpen(false, runFuncDoneTime)
 		}
 		return true
 	}
@@ -394,22 +768,26 @@ func (c *Circuit) checkErrTimeout(ctx context.Context, expectedDoneBy time.Time,
 
 // Does fallback logic.  Equivalent of
 // http://netflix.github.io/Hystrix/javadoc/com/netflix/hystrix/HystrixCommand.html#getFallback
-func (c *Circuit) fallback(ctx context.Context, err error, fallbackFunc func(context.Context, error) error) error {
+func (c *Circuit) fallback(ctx context.Context, err error, reason FallbackReason, fallbackFunc func(context.Context, error) error, overhead *overheadTimer) error {
 	// Use the fallback command if available
-	if fallbackFunc == nil || c.threadSafeConfig.Fallback.Disabled.Get() {
+	if fallbackFunc == nil || c.config().Fallback.Disabled {
 		return err
 	}
+	c.FallbackMetricCollector.FallbackReason(ctx, c.now(), reason)
+	ctx = fallbackContext(ctx, reason)
 
 	// Throttle concurrent fallback calls
 	currentFallbackCount := c.concurrentFallbacks.Add(1)
 	defer c.concurrentFallbacks.Add(-1)
-	if c.threadSafeConfig.Fallback.MaxConcurrentRequests.Get() >= 0 && currentFallbackCount > c.threadSafeConfig.Fallback.MaxConcurrentRequests.Get() {
+	if limit := c.config().Fallback.MaxConcurrentRequests; limit >= 0 && currentFallbackCount > limit {
 		c.FallbackMetricCollector.ErrConcurrencyLimitReject(ctx, c.now())
 		return &circuitError{concurrencyLimitReached: true, msg: "throttling concurrency to fallbacks"}
 	}
 
 	startTime := c.now()
-	retErr := fallbackFunc(ctx, err)
+	fallbackStart := overhead.now()
+	retErr := c.callFallback(ctx, err, fallbackFunc)
+	overhead.exclude(fallbackStart)
 	totalCmdTime := c.now().Sub(startTime)
 	if retErr != nil {
 		c.FallbackMetricCollector.ErrFailure(ctx, startTime, totalCmdTime)
@@ -419,13 +797,42 @@ func (c *Circuit) fallback(ctx context.Context, err error, fallbackFunc func(con
 	return nil
 }
 
+// fallbackContext is the context given to the fallback.  The overrides were for this circuit, not for
+// Fallback.Circuit or the circuits the fallback calls.
+func fallbackContext(ctx context.Context, reason FallbackReason) context.Context {
+	return context.WithValue(withoutOverrides(ctx), fallbackReasonKey{}, reason)
+}
+
+// callFallback calls fallbackFunc, in Fallback.Circuit if one is set
+func (c *Circuit) callFallback(ctx context.Context, err error, fallbackFunc func(context.Context, error) error) error {
+	if fallbackCircuit := c.config().Fallback.Circuit; fallbackCircuit != nil {
+		return fallbackCircuit.Run(ctx, func(ctx context.Context) error {
+			return fallbackFunc(ctx, err)
+		})
+	}
+	return fallbackFunc(ctx, err)
+}
+
 // allowNewRun checks if the circuit is allowing new run commands. This happens if the circuit is closed, or
 // if it is open, but we want to explore to see if we should close it again.
 func (c *Circuit) allowNewRun(ctx context.Context, now time.Time) bool {
+	inMaintenance := c.checkMaintenance(ctx, now)
 	if !c.IsOpen() {
 		return true
 	}
+	// Forced open circuits never probe the dependency
+	if inMaintenance || c.config().General.ForceOpen {
+		return false
+	}
+	if c.isHeldOpen(now) {
+		return false
+	}
+	if c.probeNext.CompareAndSwap(true, false) {
+		c.setHalfOpen(true, now)
+		return true
+	}
 	if c.OpenToClose.Allow(ctx, now) {
+		c.setHalfOpen(true, now)
 		return true
 	}
 	return false
@@ -437,21 +844,36 @@ func (c *Circuit) close(ctx context.Context, now time.Time, forceClosed bool) {
 		// Not open.  Don't need to close it
 		return
 	}
-	if c.threadSafeConfig.CircuitBreaker.ForceOpen.Get() {
+	if c.config().General.ForceOpen || c.inMaintenance() {
 		return
 	}
 	if forceClosed || c.OpenToClose.ShouldClose(ctx, now) {
 		c.CircuitMetricsCollector.Closed(ctx, now)
 		c.isOpen.Set(false)
+		c.flaps.Inc(now)
+		c.closedAt.Set(now.UnixNano())
+		// A hold only lasts while the circuit is open
+		c.holdOpenUntil.Set(0)
+		if forceClosed {
+			c.setState(StateReasonManual, now)
+		} else {
+			c.setState(StateReasonRecovered, now)
+		}
 	}
 }
 
+// inWarmUp returns true if the circuit is too new to open.  See GeneralConfig.WarmUpDuration.
+func (c *Circuit) inWarmUp(now time.Time) bool {
+	warmUp := c.config().General.WarmUpDuration
+	return warmUp > 0 && !c.createdAt.IsZero() && now.Sub(c.createdAt) < warmUp
+}
+
 // attemptToOpen tries to open an unhealthy circuit.  Usually because we think run is having problems, and we want
 // to give run a rest for a bit.
 //
 // It is called "attemptToOpen" because the circuit may not actually open (for example if there aren't enough requests)
 func (c *Circuit) attemptToOpen(ctx context.Context, now time.Time) {
-	if c.threadSafeConfig.CircuitBreaker.ForcedClosed.Get() {
+	if c.config().General.ForcedClosed {
 		// Don't open circuits that are forced closed
 		return
 	}
@@ -462,7 +884,11 @@ func (c *Circuit) attemptToOpen(ctx context.Context, now time.Time) {
 		return
 	}
 
+	if c.inMinClosedDuration(now) || c.inWarmUp(now) {
+		return
+	}
+
 	if c.ClosedToOpen.ShouldOpen(ctx, now) {
-		c.openCircuit(ctx, now)
+		c.openCircuit(ctx, now, StateReasonThreshold)
 	}
 }
@@ -3,6 +3,7 @@ package circuit
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -86,6 +87,31 @@ func TestBadRequest(t *testing.T) {
 	}
 }
 
+func TestSuccessErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	c := NewCircuitFromConfig("TestSuccessErrors", Config{
+		General: GeneralConfig{
+			ClosedToOpenFactory: openOnFirstErrorFactory,
+		},
+		Execution: ExecutionConfig{
+			SuccessErrors: []func(error) bool{
+				func(err error) bool { return errors.Is(err, errNotFound) },
+			},
+		},
+	})
+	err := c.Execute(context.Background(), func(_ context.Context) error {
+		return fmt.Errorf("lookup: %w", errNotFound)
+	}, func(_ context.Context, _ error) error {
+		panic("fallbacks don't get called on success errors")
+	})
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("expected the run error to be returned, got %v", err)
+	}
+	if c.IsOpen() {
+		t.Error("success errors should never open the circuit")
+	}
+}
+
 func TestManyConcurrent(t *testing.T) {
 	concurrency := 20
 	c := NewCircuitFromConfig("TestManyConcurrent", Config{
@@ -329,7 +355,7 @@ func TestCircuitIgnoreContextFailures(t *testing.T) {
 			rootCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond*3)
 			err := c.Execute(rootCtx, testhelp.SleepsForX(time.Second), nil)
 
-			if err != context.DeadlineExceeded && err != errCircuitOpen {
+			if err != context.DeadlineExceeded && !isOpenError(err) {
 				t.Errorf("saw no error from circuit that should end in an error(%d):%v", i, err)
 				cancel()
 				break
@@ -349,7 +375,7 @@ func TestCircuitIgnoreContextFailures(t *testing.T) {
 			time.AfterFunc(time.Millisecond*3, func() { cancel() })
 			err := c.Execute(rootCtx, testhelp.SleepsForX(time.Second), nil)
 
-			if err != context.Canceled && err != errCircuitOpen {
+			if err != context.Canceled && !isOpenError(err) {
 				t.Errorf("saw no error from circuit that should end in an error(%d):%v", i, err)
 				cancel()
 				break
@@ -372,7 +398,7 @@ func TestCircuitIgnoreContextFailures(t *testing.T) {
 			rootCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond*3)
 			err := c.Execute(rootCtx, testhelp.SleepsForX(time.Second), nil)
 
-			if err != context.DeadlineExceeded && err != errCircuitOpen {
+			if err != context.DeadlineExceeded && !isOpenError(err) {
 				t.Errorf("saw no error from circuit that should end in an error(%d):%v", i, err)
 				cancel()
 				break
@@ -399,7 +425,7 @@ func TestCircuitIgnoreContextFailures(t *testing.T) {
 				return rootCtx.Err()
 			}, nil)
 
-			if err != context.Canceled && err != errCircuitOpen {
+			if err != context.Canceled && !isOpenError(err) {
 				t.Errorf("saw no error from circuit that should end in an error(%d):%v", i, err)
 				cancel()
 				break
@@ -412,6 +438,72 @@ func TestCircuitIgnoreContextFailures(t *testing.T) {
 	})
 }
 
+func TestCircuitDetachContext(t *testing.T) {
+	type ctxKey struct{}
+	c := NewCircuitFromConfig("TestCircuitDetachContext", Config{
+		Execution: ExecutionConfig{
+			DetachContext: true,
+			Timeout:       time.Hour,
+		},
+	})
+	rootCtx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	cancel()
+	err := c.Execute(rootCtx, func(ctx context.Context) error {
+		if ctx.Err() != nil {
+			return errors.New("detached context should not see the caller cancel")
+		}
+		if ctx.Value(ctxKey{}) != "value" {
+			return errors.New("detached context should keep the caller's values")
+		}
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			return errors.New("detached context should still use the circuit timeout")
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInterruptClassifier(t *testing.T) {
+	t.Run("open circuit on context.Canceled with InterruptClassifier", func(t *testing.T) {
+		c := circuitFactory(
+			t,
+			withInterruptClassifier(func(_ context.Context, err error) bool { return err != context.Canceled }),
+		)
+
+		rootCtx, cancel := context.WithCancel(context.Background())
+		err := c.Execute(rootCtx, func(ctx context.Context) error {
+			cancel()
+			return ctx.Err()
+		}, nil)
+		if err != context.Canceled {
+			t.Errorf("saw unexpected error: %v", err)
+		}
+		if !c.IsOpen() {
+			t.Error("InterruptClassifier should allow counting cancellations as failures")
+		}
+	})
+
+	t.Run("ignore custom errors with InterruptClassifier", func(t *testing.T) {
+		errShutdown := errors.New("shutting down")
+		c := circuitFactory(
+			t,
+			withInterruptClassifier(func(_ context.Context, err error) bool { return err == errShutdown }),
+		)
+
+		err := c.Execute(context.Background(), func(ctx context.Context) error {
+			return errShutdown
+		}, nil)
+		if err != errShutdown {
+			t.Errorf("saw unexpected error: %v", err)
+		}
+		if c.IsOpen() {
+			t.Error("InterruptClassifier errors should not open the circuit")
+		}
+	})
+}
+
 type alwaysCanceledContext struct {
 	context.Context
 }
@@ -471,7 +563,7 @@ func TestFailingFallbackCircuit(t *testing.T) {
 func TestSetConfigThreadSafe(t *testing.T) {
 	var breaker Circuit
 
-	if breaker.threadSafeConfig.CircuitBreaker.Disabled.Get() {
+	if breaker.config().General.Disabled {
 		t.Error("Circuit should start off not disabled")
 	}
 	breaker.SetConfigThreadSafe(Config{
@@ -479,7 +571,7 @@ func TestSetConfigThreadSafe(t *testing.T) {
 			Disabled: true,
 		},
 	})
-	if !breaker.threadSafeConfig.CircuitBreaker.Disabled.Get() {
+	if !breaker.config().General.Disabled {
 		t.Error("Circuit should be disabled after setting config to disabled")
 	}
 }
@@ -591,6 +683,13 @@ func withIsErrInterrupt(fn func(error) bool) configOverride {
 	}
 }
 
+func withInterruptClassifier(fn func(context.Context, error) bool) configOverride {
+	return func(c *Config) *Config {
+		c.Execution.InterruptClassifier = fn
+		return c
+	}
+}
+
 func circuitFactory(t *testing.T, cfgOpts ...configOverride) *Circuit {
 	t.Helper()
 
@@ -608,3 +707,161 @@ func circuitFactory(t *testing.T, cfgOpts ...configOverride) *Circuit {
 
 	return NewCircuitFromConfig(t.Name(), cfg)
 }
+
+func TestCircuit_ExecuteAllocations(t *testing.T) {
+	ctx := context.Background()
+	runFunc := func(ctx context.Context) error {
+		return ctx.Err()
+	}
+	t.Run("timeout", func(t *testing.T) {
+		c := NewCircuitFromConfig("TestCircuit_ExecuteAllocations", Config{})
+		allocs := testing.AllocsPerRun(100, func() {
+			if err := c.Execute(ctx, runFunc, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+		// With a timeout there is a floor of one allocation: the context given to runFunc, which runFunc may keep
+		// after it returns and so cannot be pooled.  See Execute.
+		if allocs != 1 {
+			t.Errorf("expected a healthy run to allocate only its context, saw %v allocations", allocs)
+		}
+	})
+	t.Run("no timeout", func(t *testing.T) {
+		c := NewCircuitFromConfig("TestCircuit_ExecuteAllocations", Config{Execution: ExecutionConfig{Timeout: -1}})
+		allocs := testing.AllocsPerRun(100, func() {
+			if err := c.Execute(ctx, runFunc, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > 0 {
+			t.Errorf("expected a healthy run without a timeout to not allocate, saw %v allocations", allocs)
+		}
+	})
+}
+
+func BenchmarkCircuit_Execute(b *testing.B) {
+	ctx := context.Background()
+	runFunc := func(ctx context.Context) error {
+		return nil
+	}
+	b.Run("timeout", func(b *testing.B) {
+		c := NewCircuitFromConfig("BenchmarkCircuit_Execute", Config{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = c.Execute(ctx, runFunc, nil)
+		}
+	})
+	b.Run("timeout waits on done", func(b *testing.B) {
+		c := NewCircuitFromConfig("BenchmarkCircuit_Execute", Config{})
+		doneFunc := func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+				return nil
+			}
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = c.Execute(ctx, doneFunc, nil)
+		}
+	})
+	b.Run("no timeout", func(b *testing.B) {
+		c := NewCircuitFromConfig("BenchmarkCircuit_Execute", Config{Execution: ExecutionConfig{Timeout: -1}})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = c.Execute(ctx, runFunc, nil)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		c := NewCircuitFromConfig("BenchmarkCircuit_Execute", Config{Execution: ExecutionConfig{MaxConcurrentRequests: -1}})
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = c.Execute(ctx, runFunc, nil)
+			}
+		})
+	})
+}
+
+func TestCircuit_WarmUpDuration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := flappingCircuit("TestCircuit_WarmUpDuration", GeneralConfig{
+		WarmUpDuration: time.Minute,
+	}, &now)
+	fail := func(_ context.Context) error {
+		return errors.New("bad")
+	}
+	now = now.Add(59 * time.Second)
+	_ = c.Run(ctx, fail)
+	if c.IsOpen() {
+		t.Error("expected a warming up circuit to stay closed")
+	}
+	if len(c.RecentErrors()) != 1 {
+		t.Error("expected errors to be recorded while warming up")
+	}
+	now = now.Add(time.Second)
+	_ = c.Run(ctx, fail)
+	if !c.IsOpen() {
+		t.Error("expected the circuit to open after warming up")
+	}
+}
+
+func TestFallbackConfig_Circuit(t *testing.T) {
+	h := Manager{}
+	fallbackCircuit := h.MustCreateCircuit("TestFallbackConfig_Circuit.fallback", Config{
+		Execution: ExecutionConfig{
+			Timeout: time.Millisecond,
+		},
+	})
+	c := h.MustCreateCircuit("TestFallbackConfig_Circuit", Config{
+		Fallback: FallbackConfig{
+			Circuit: fallbackCircuit,
+		},
+	})
+	// The fallback circuit's timeout bounds the fallback
+	err := c.Execute(context.Background(), testhelp.AlwaysFails, func(ctx context.Context, _ error) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the fallback to time out, got %v", err)
+	}
+
+	// An open fallback circuit sheds the fallback without calling it
+	fallbackCircuit.OpenCircuit(context.Background())
+	called := false
+	var circuitErr Error
+	err = c.Execute(context.Background(), testhelp.AlwaysFails, func(_ context.Context, _ error) error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Error("expected the open fallback circuit to not call the fallback")
+	}
+	if !errors.As(err, &circuitErr) || !circuitErr.CircuitOpen() {
+		t.Errorf("expected the fallback circuit to be open, got %v", err)
+	}
+}
+
+func TestFallbackConfig_CircuitForcedFallback(t *testing.T) {
+	h := Manager{}
+	fallbackCircuit := h.MustCreateCircuit("TestFallbackConfig_CircuitForcedFallback.fallback")
+	c := h.MustCreateCircuit("TestFallbackConfig_CircuitForcedFallback", Config{
+		Fallback: FallbackConfig{
+			Circuit: fallbackCircuit,
+		},
+	})
+	var fallbackErr error
+	err := c.Execute(WithForcedFallback(context.Background()), func(_ context.Context) error {
+		t.Error("runFunc should not be called")
+		return nil
+	}, func(_ context.Context, err error) error {
+		fallbackErr = err
+		return nil
+	})
+	if err != nil || fallbackErr != ErrForcedFallback {
+		t.Errorf("expected the fallback to run in the fallback circuit, got %v and %v", err, fallbackErr)
+	}
+}
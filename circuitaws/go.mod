@@ -0,0 +1,11 @@
+module github.com/cep21/circuit/circuitaws
+
+go 1.24
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.47.1
+	github.com/aws/smithy-go v1.28.2
+	github.com/cep21/circuit/v4 v4.0.0
+)
+
+replace github.com/cep21/circuit/v4 => ../
@@ -0,0 +1,167 @@
+/*
+Package circuitaws runs AWS SDK v2 calls in circuits, one per service and operation.  It is a separate module so the
+circuit module does not depend on the AWS SDK.
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	mw := &circuitaws.Middleware{Manager: manager}
+	cfg.APIOptions = append(cfg.APIOptions, mw.AddToStack)
+*/
+package circuitaws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Middleware runs each attempt of an AWS operation in a circuit.  Add it to clients with AddToStack.
+//
+// Throttling is a service shedding load, not failing, so by default throttled attempts do not count against the
+// circuit: the SDK's retryer already backs off from them.  Other 4xx errors are the caller's fault and do not count
+// either.  The circuit's timeout bounds how long the response headers of an attempt take.  Reading a streamed body,
+// like the body of an S3 object, is only bounded by the caller's context.
+type Middleware struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// CircuitName picks the circuit of an operation.  The default is the service and operation, like
+	// "S3.GetObject".
+	CircuitName func(service string, operation string) string
+	// ThrottleIsFailure counts throttled attempts against the circuit
+	ThrottleIsFailure bool
+	// IsThrottle decides if an error is throttling.  The default uses the SDK's retry.DefaultThrottles.
+	IsThrottle func(err error) bool
+}
+
+var _ middleware.FinalizeMiddleware = &Middleware{}
+
+// AddToStack adds the middleware to an operation's stack.  Append it to aws.Config.APIOptions, or the APIOptions of a
+// single client.
+func (m *Middleware) AddToStack(stack *middleware.Stack) error {
+	var err error
+	if _, hasRetry := stack.Finalize.Get((&retry.Attempt{}).ID()); hasRetry {
+		// After the retryer, so each attempt is its own run of the circuit
+		err = stack.Finalize.Insert(m, (&retry.Attempt{}).ID(), middleware.After)
+	} else {
+		err = stack.Finalize.Add(m, middleware.After)
+	}
+	if err != nil {
+		return err
+	}
+	// Last, so the raw response is wrapped before the operation's deserializer reads it
+	return stack.Deserialize.Add(bodyCancel{}, middleware.After)
+}
+
+// ID identifies the middleware in the stack
+func (m *Middleware) ID() string {
+	return "CircuitBreaker"
+}
+
+func (m *Middleware) circuitName(ctx context.Context) string {
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	if m.CircuitName != nil {
+		return m.CircuitName(service, operation)
+	}
+	return service + "." + operation
+}
+
+func (m *Middleware) isThrottle(err error) bool {
+	if m.IsThrottle != nil {
+		return m.IsThrottle(err)
+	}
+	return retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
+
+// isBadRequest is true for errors that should not count against the circuit
+func (m *Middleware) isBadRequest(err error) bool {
+	if m.isThrottle(err) {
+		return !m.ThrottleIsFailure
+	}
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		code := respErr.HTTPStatusCode()
+		return code >= 400 && code < 500 && code != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// HandleFinalize runs the rest of the attempt in the operation's circuit
+func (m *Middleware) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	c := m.Manager.GetOrCreateCircuit(m.circuitName(ctx), m.Config)
+	var nextErr error
+	runErr := c.Run(ctx, func(circuitCtx context.Context) error {
+		// The circuit's context ends when Run returns, but streamed bodies are read after that.  Only let the
+		// circuit cancel the attempt until the response arrives.  After that, closing the body cancels it.
+		attemptCtx, cancel := context.WithCancel(ctx)
+		state := &attemptState{cancel: cancel}
+		stopCancelOnTimeout := context.AfterFunc(circuitCtx, cancel)
+		out, metadata, nextErr = next.HandleFinalize(context.WithValue(attemptCtx, attemptStateKey{}, state), in)
+		stopCancelOnTimeout()
+		if nextErr != nil || !state.bodyOwnsCancel {
+			cancel()
+		}
+		if nextErr != nil && m.isBadRequest(nextErr) {
+			return circuit.SimpleBadRequest{Err: nextErr}
+		}
+		return nextErr
+	})
+	if nextErr != nil {
+		// Return the SDK's error, not the bad request wrapper, so the retryer and callers can classify it
+		return out, metadata, nextErr
+	}
+	return out, metadata, runErr
+}
+
+type attemptStateKey struct{}
+
+// attemptState lets bodyCancel tell HandleFinalize that it will cancel the attempt when the body is closed
+type attemptState struct {
+	cancel         func()
+	bodyOwnsCancel bool
+}
+
+// bodyCancel wraps the raw response body so closing it cancels the attempt's context
+type bodyCancel struct{}
+
+func (bodyCancel) ID() string {
+	return "CircuitBreakerBody"
+}
+
+func (bodyCancel) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	state, hasState := ctx.Value(attemptStateKey{}).(*attemptState)
+	if !hasState {
+		return out, metadata, err
+	}
+	if resp, isHTTP := out.RawResponse.(*smithyhttp.Response); isHTTP && resp.Body != nil {
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: state.cancel}
+		state.bodyOwnsCancel = true
+	}
+	return out, metadata, err
+}
+
+// cancelOnClose ends the attempt's context once the caller is done with the body
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,184 @@
+package circuitaws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type countingMetrics struct {
+	mu          sync.Mutex
+	successes   int
+	failures    int
+	badRequests int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.badRequests++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)   {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)     {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)               {}
+
+// ctxBody fails reads once the request's context ends, like a real HTTP body
+type ctxBody struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (c *ctxBody) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.Reader.Read(p)
+}
+
+func (c *ctxBody) Close() error {
+	return nil
+}
+
+// invoke runs an operation of service "Svc" through m, with a transport that responds with status and errorCode
+func invoke(t *testing.T, m *Middleware, status int, errorCode string, wait bool) (io.ReadCloser, error) {
+	t.Helper()
+	stack := middleware.NewStack("GetThing", smithyhttp.NewStackRequest)
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("metadata", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		ctx = awsmiddleware.SetServiceID(ctx, "Svc")
+		ctx = awsmiddleware.SetOperationName(ctx, "GetThing")
+		return next.HandleInitialize(ctx, in)
+	}), middleware.Before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A stand in for the operation's deserializer, which streams the body to the caller
+	err = stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("deserialize", func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleDeserialize(ctx, in)
+		if err != nil {
+			return out, metadata, err
+		}
+		resp := out.RawResponse.(*smithyhttp.Response)
+		if resp.StatusCode >= 300 {
+			_ = resp.Body.Close()
+			return out, metadata, &smithyhttp.ResponseError{
+				Response: resp,
+				Err:      &smithy.GenericAPIError{Code: errorCode},
+			}
+		}
+		out.Result = resp.Body
+		return out, metadata, nil
+	}), middleware.After)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddToStack(stack); err != nil {
+		t.Fatal(err)
+	}
+	transport := middleware.HandlerFunc(func(ctx context.Context, _ interface{}) (interface{}, middleware.Metadata, error) {
+		if wait {
+			<-ctx.Done()
+			return nil, middleware.Metadata{}, ctx.Err()
+		}
+		return &smithyhttp.Response{Response: &http.Response{
+			StatusCode: status,
+			Body:       &ctxBody{ctx: ctx, Reader: strings.NewReader("hello")},
+		}}, middleware.Metadata{}, nil
+	})
+	out, _, err := middleware.DecorateHandler(transport, stack).Handle(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return out.(io.ReadCloser), nil
+}
+
+func TestMiddleware(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &Middleware{
+		Manager: &circuit.Manager{},
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	}
+	body, err := invoke(t, m, http.StatusOK, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The circuit is done, but the body must still be readable
+	if b, err := io.ReadAll(body); err != nil || string(b) != "hello" {
+		t.Errorf("expected the body after the circuit ended, got %q %v", b, err)
+	}
+	_ = body.Close()
+	if m.Manager.GetCircuit("Svc.GetThing") == nil {
+		t.Error("expected a circuit named after the service and operation")
+	}
+
+	_, err = invoke(t, m, http.StatusBadRequest, "Throttling", false)
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "Throttling" {
+		t.Errorf("expected the SDK's error unchanged, got %v", err)
+	}
+	if _, err := invoke(t, m, http.StatusNotFound, "NoSuchKey", false); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := invoke(t, m, http.StatusInternalServerError, "InternalError", false); err == nil {
+		t.Error("expected an error")
+	}
+	m.ThrottleIsFailure = true
+	if _, err := invoke(t, m, http.StatusBadRequest, "Throttling", false); err == nil {
+		t.Error("expected an error")
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 1 || metrics.badRequests != 2 || metrics.failures != 2 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestMiddleware_timeout(t *testing.T) {
+	m := &Middleware{
+		Manager: &circuit.Manager{},
+		Config: circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				Timeout: 10 * time.Millisecond,
+			},
+		},
+		CircuitName: func(service string, operation string) string {
+			return strings.ToLower(service + "/" + operation)
+		},
+	}
+	if _, err := invoke(t, m, 0, "", true); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the circuit to cancel the attempt, got %v", err)
+	}
+	if m.Manager.GetCircuit("svc/getthing") == nil {
+		t.Error("expected CircuitName to name the circuit")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Package circuitcache puts a circuit in front of a memcache style cache, and a second circuit in front of the origin the
+cache is filled from.  Misses are a normal answer from the cache, so they do not count against it.  When the cache
+fails or its circuit is open, reads go straight to the origin.
+
+	c := &circuitcache.Cache[string, []byte]{
+		Cache:  manager.MustCreateCircuit("memcache"),
+		Origin: manager.MustCreateCircuit("users-db"),
+		Get: func(ctx context.Context, key string) ([]byte, error) {
+			item, err := mc.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			return item.Value, nil
+		},
+		IsMiss: func(err error) bool {
+			return errors.Is(err, memcache.ErrCacheMiss)
+		},
+		Fetch: loadUser,
+	}
+	user, err := c.Lookup(ctx, "user:123")
+*/
+package circuitcache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// ErrMiss is the default error Get returns when the cache has no value for a key
+var ErrMiss = errors.New("circuitcache: miss")
+
+// Cache reads values from a cache, and from the origin when the cache misses or is unhealthy.  Get and Set run in the
+// Cache circuit, and Fetch in the Origin circuit.
+type Cache[K comparable, V any] struct {
+	// Cache runs Get and Set.  A nil circuit runs them without a circuit, and without falling back to the origin.
+	Cache *circuit.Circuit
+	// Origin runs Fetch.  A nil circuit runs it without a circuit.
+	Origin *circuit.Circuit
+	// Get reads key from the cache
+	Get func(ctx context.Context, key K) (V, error)
+	// Set, if set, stores values fetched from the origin after a miss.  Its errors are counted by the Cache circuit,
+	// but do not fail Lookup.
+	Set func(ctx context.Context, key K, value V) error
+	// Fetch reads key from the origin
+	Fetch func(ctx context.Context, key K) (V, error)
+	// IsMiss decides if an error from Get is a miss.  The default is errors.Is(err, ErrMiss).
+	IsMiss func(err error) bool
+}
+
+// missError is a miss, which is a bad request to the Cache circuit so it neither counts against the cache nor falls
+// back to the origin on its own
+type missError struct {
+	err error
+}
+
+func (m *missError) Error() string {
+	return m.err.Error()
+}
+
+func (m *missError) Unwrap() error {
+	return m.err
+}
+
+func (m *missError) BadRequest() bool {
+	return true
+}
+
+var _ circuit.BadRequest = &missError{}
+
+func (c *Cache[K, V]) isMiss(err error) bool {
+	if c.IsMiss != nil {
+		return c.IsMiss(err)
+	}
+	return errors.Is(err, ErrMiss)
+}
+
+// Lookup returns the value of key.  A miss fetches the value from the origin and stores it with Set.  If the cache
+// fails, times out, or its circuit is open, the value is fetched from the origin and not stored.  Errors are the
+// origin's when it was asked, and the cache's otherwise.
+func (c *Cache[K, V]) Lookup(ctx context.Context, key K) (V, error) {
+	v, err := circuit.ExecuteValue(ctx, c.Cache, func(ctx context.Context) (V, error) {
+		v, err := c.Get(ctx, key)
+		if err != nil && c.isMiss(err) {
+			return v, &missError{err: err}
+		}
+		return v, err
+	}, func(ctx context.Context, _ error) (V, error) {
+		return c.fetch(ctx, key)
+	})
+	var miss *missError
+	if !errors.As(err, &miss) {
+		return v, err
+	}
+	v, err = c.fetch(ctx, key)
+	if err != nil || c.Set == nil {
+		return v, err
+	}
+	_ = c.Cache.Execute(ctx, func(ctx context.Context) error {
+		return c.Set(ctx, key, v)
+	}, nil)
+	return v, nil
+}
+
+func (c *Cache[K, V]) fetch(ctx context.Context, key K) (V, error) {
+	return circuit.ExecuteValue(ctx, c.Origin, func(ctx context.Context) (V, error) {
+		return c.Fetch(ctx, key)
+	}, nil)
+}
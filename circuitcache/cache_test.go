@@ -0,0 +1,148 @@
+package circuitcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type countingMetrics struct {
+	mu          sync.Mutex
+	successes   int
+	failures    int
+	badRequests int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.badRequests++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)   {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)     {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)               {}
+
+// mapCache is a cache that can be broken
+type mapCache struct {
+	mu     sync.Mutex
+	values map[string]string
+	broken bool
+}
+
+func (m *mapCache) get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.broken {
+		return "", errors.New("cache down")
+	}
+	v, exists := m.values[key]
+	if !exists {
+		return "", ErrMiss
+	}
+	return v, nil
+}
+
+func (m *mapCache) set(_ context.Context, key string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.broken {
+		return errors.New("cache down")
+	}
+	m.values[key] = value
+	return nil
+}
+
+func testCache(cacheMetrics *countingMetrics, store *mapCache, fetches *int) *Cache[string, string] {
+	return &Cache[string, string]{
+		Cache: circuit.NewCircuitFromConfig("cache", circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{cacheMetrics},
+			},
+		}),
+		Origin: circuit.NewCircuitFromConfig("origin", circuit.Config{}),
+		Get:    store.get,
+		Set:    store.set,
+		Fetch: func(_ context.Context, key string) (string, error) {
+			*fetches++
+			if key == "missing" {
+				return "", errors.New("not in origin")
+			}
+			return "origin-" + key, nil
+		},
+	}
+}
+
+func TestCache_Lookup(t *testing.T) {
+	metrics := &countingMetrics{}
+	store := &mapCache{values: map[string]string{}}
+	fetches := 0
+	c := testCache(metrics, store, &fetches)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		v, err := c.Lookup(ctx, "a")
+		if err != nil || v != "origin-a" {
+			t.Fatalf("expected the origin's value, got %q %v", v, err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the second lookup to hit the cache, got %d fetches", fetches)
+	}
+	if _, err := c.Lookup(ctx, "missing"); err == nil {
+		t.Error("expected the origin's error")
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.failures != 0 || metrics.badRequests != 2 {
+		t.Errorf("expected misses to be bad requests, got %+v", metrics)
+	}
+}
+
+func TestCache_LookupBrokenCache(t *testing.T) {
+	metrics := &countingMetrics{}
+	store := &mapCache{values: map[string]string{"a": "cached-a"}, broken: true}
+	fetches := 0
+	c := testCache(metrics, store, &fetches)
+	v, err := c.Lookup(context.Background(), "a")
+	if err != nil || v != "origin-a" {
+		t.Fatalf("expected to fall back to the origin, got %q %v", v, err)
+	}
+	store.broken = false
+	if store.values["a"] != "cached-a" {
+		t.Error("expected values fetched while the cache is broken not to be stored")
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.failures != 1 {
+		t.Errorf("expected the cache's failure to count, got %+v", metrics)
+	}
+}
+
+func TestCache_LookupNoCircuits(t *testing.T) {
+	store := &mapCache{values: map[string]string{}}
+	fetches := 0
+	c := testCache(&countingMetrics{}, store, &fetches)
+	c.Cache = nil
+	c.Origin = nil
+	v, err := c.Lookup(context.Background(), "a")
+	if err != nil || v != "origin-a" || store.values["a"] != "origin-a" {
+		t.Errorf("expected lookups to work without circuits, got %q %v", v, err)
+	}
+}
@@ -0,0 +1,114 @@
+package circuitconsumer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Consumer fetches messages one at a time and handles each in Circuit.  A message is only fetched once the circuit
+// would let it run.  A message the circuit rejects anyway, because another caller took the half open request or the
+// concurrency limit was reached, is kept and retried rather than fetched again.
+type Consumer[M any] struct {
+	// Circuit runs Handle
+	Circuit *circuit.Circuit
+	// Fetch blocks until the next message is available
+	Fetch func(ctx context.Context) (M, error)
+	// Handle processes a message.  An error counts against the circuit unless it is a circuit.BadRequest.
+	Handle func(ctx context.Context, msg M) error
+	// Failed, if set, is called with messages Handle failed on, to nack or dead letter them
+	Failed func(ctx context.Context, msg M, err error)
+	// PollInterval is how often an open circuit is checked to see if it will allow a request.  The default is one
+	// second.  Circuits whose OpenToClose is a circuit.HalfOpenScheduler are checked when their next probe is due, if
+	// that is sooner.
+	PollInterval time.Duration
+}
+
+func (c *Consumer[M]) pollInterval() time.Duration {
+	if c.PollInterval == 0 {
+		return time.Second
+	}
+	return c.PollInterval
+}
+
+// Run consumes messages until ctx ends or Fetch fails, and returns the error that stopped it
+func (c *Consumer[M]) Run(ctx context.Context) error {
+	for {
+		if err := c.waitForCircuit(ctx); err != nil {
+			return err
+		}
+		msg, err := c.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs msg in the circuit, waiting and retrying while the circuit rejects it.  It only returns ctx's error.
+func (c *Consumer[M]) handle(ctx context.Context, msg M) error {
+	for {
+		err := c.Circuit.Run(ctx, func(ctx context.Context) error {
+			return c.Handle(ctx, msg)
+		})
+		var circuitErr circuit.Error
+		if errors.As(err, &circuitErr) && (circuitErr.CircuitOpen() || circuitErr.ConcurrencyLimitReached()) {
+			if err := sleep(ctx, c.pollInterval()); err != nil {
+				return err
+			}
+			if err := c.waitForCircuit(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil && c.Failed != nil {
+			c.Failed(ctx, msg, err)
+		}
+		return ctx.Err()
+	}
+}
+
+// waitForCircuit returns once the circuit is closed or may allow a half open request
+func (c *Consumer[M]) waitForCircuit(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info := c.Circuit.StateInfo()
+		if !info.Open {
+			return nil
+		}
+		wait := c.pollInterval()
+		if !info.NextProbe.IsZero() {
+			untilProbe := time.Until(info.NextProbe)
+			if untilProbe <= 0 {
+				return nil
+			}
+			if untilProbe < wait {
+				wait = untilProbe
+			}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		if info.NextProbe.IsZero() {
+			// Nothing says when the circuit will allow a request, so try one each PollInterval
+			return nil
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package circuitconsumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConsumer_pausesWhileOpen(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("TestConsumer_pausesWhileOpen", circuit.Config{
+		General: circuit.GeneralConfig{
+			ClosedToOpenFactory: hystrix.OpenerFactory(hystrix.ConfigureOpener{
+				RequestVolumeThreshold:   1,
+				ErrorThresholdPercentage: 1,
+			}),
+			OpenToClosedFactory: hystrix.CloserFactory(hystrix.ConfigureCloser{
+				SleepWindow: 100 * time.Millisecond,
+			}),
+		},
+	})
+	msgs := make(chan int, 5)
+	for i := 1; i <= 5; i++ {
+		msgs <- i
+	}
+	var broken atomic.Bool
+	broken.Store(true)
+	var fetched atomic.Int64
+	var mu sync.Mutex
+	var handled, failed []int
+	consumer := &Consumer[int]{
+		Circuit: c,
+		Fetch: func(ctx context.Context) (int, error) {
+			fetched.Add(1)
+			select {
+			case msg := <-msgs:
+				return msg, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		},
+		Handle: func(_ context.Context, msg int) error {
+			if broken.Load() {
+				return errors.New("broken")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, msg)
+			return nil
+		},
+		Failed: func(_ context.Context, msg int, _ error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, msg)
+		},
+		PollInterval: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx)
+	}()
+
+	waitFor(t, "the first message to fail", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(failed) == 1
+	})
+	if !c.IsOpen() {
+		t.Fatal("expected the failure to open the circuit")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := fetched.Load(); n != 1 {
+		t.Errorf("expected no fetches while the circuit is open, got %d", n)
+	}
+
+	broken.Store(false)
+	waitFor(t, "the rest of the messages", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 4
+	})
+	if c.IsOpen() {
+		t.Error("expected the half open request to close the circuit")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Run to stop with the context, got %v", err)
+	}
+}
+
+func TestConsumer_retriesRejectedMessages(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("TestConsumer_retriesRejectedMessages", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			MaxConcurrentRequests: 1,
+		},
+	})
+	// Hold the only slot so the consumer's first attempt is rejected
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = c.Run(context.Background(), func(_ context.Context) error {
+			close(holding)
+			<-release
+			return nil
+		})
+	}()
+	<-holding
+
+	fetchErr := errors.New("no more messages")
+	var fetched atomic.Int64
+	var handled atomic.Int64
+	consumer := &Consumer[string]{
+		Circuit: c,
+		Fetch: func(_ context.Context) (string, error) {
+			if fetched.Add(1) > 1 {
+				return "", fetchErr
+			}
+			return "msg", nil
+		},
+		Handle: func(_ context.Context, _ string) error {
+			handled.Add(1)
+			return nil
+		},
+		PollInterval: time.Millisecond,
+	}
+	time.AfterFunc(20*time.Millisecond, func() {
+		close(release)
+	})
+	if err := consumer.Run(context.Background()); err != fetchErr {
+		t.Errorf("expected Run to return the fetch error, got %v", err)
+	}
+	if fetched.Load() != 2 || handled.Load() != 1 {
+		t.Errorf("expected the rejected message to be retried, not fetched again: fetched=%d handled=%d", fetched.Load(), handled.Load())
+	}
+}
@@ -0,0 +1,6 @@
+/*
+Package circuitconsumer runs queue and stream consumers in a circuit.  While the circuit is open the consumer stops
+fetching messages, instead of pulling messages that are destined to fail and be dead lettered, and starts again once
+the circuit lets a request through.
+*/
+package circuitconsumer
@@ -0,0 +1,38 @@
+package circuitconsumer_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitconsumer"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// This example stops reading from a queue while the database its messages are written to is failing
+func ExampleConsumer() {
+	f := hystrix.Factory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+	}
+	queue := make(chan string)
+	consumer := &circuitconsumer.Consumer[string]{
+		Circuit: m.MustCreateCircuit("write-orders"),
+		Fetch: func(ctx context.Context) (string, error) {
+			select {
+			case msg := <-queue:
+				return msg, nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		},
+		Handle: func(ctx context.Context, msg string) error {
+			// Write msg to the database
+			return nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_ = consumer.Run(ctx)
+	// Output:
+}
@@ -0,0 +1,5 @@
+/*
+Package circuitdns runs DNS lookups in circuits, one per zone, and answers from the last good lookup of a host when
+its zone's resolver is failing.
+*/
+package circuitdns
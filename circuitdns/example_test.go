@@ -0,0 +1,41 @@
+package circuitdns_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitdns"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// This example dials through a Resolver, so a struggling DNS server does not stop connections to hosts that were
+// resolved recently
+func ExampleResolver() {
+	f := hystrix.Factory{}
+	r := &circuitdns.Resolver{
+		Manager: &circuit.Manager{
+			DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+		},
+		Config: circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				Timeout: time.Second,
+			},
+		},
+	}
+	dial := func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var d net.Dialer
+		return d.DialContext(ctx, network, net.JoinHostPort(addrs[0], port))
+	}
+	_ = dial
+	// Output:
+}
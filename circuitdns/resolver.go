@@ -0,0 +1,136 @@
+package circuitdns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Resolver looks up hosts with Base, inside a circuit for the host's zone.  When a lookup fails, or the zone's circuit
+// is open, the last good answer for the host is returned if it is not older than MaxStale.  A host that does not
+// exist is the caller's mistake, not the resolver's, and does not count against the circuit.
+type Resolver struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Base does the lookups.  The default is net.DefaultResolver.
+	Base *net.Resolver
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// CircuitName picks the circuit of a host.  The default is "dns:" and the host's last two labels, like
+	// "dns:example.com" for "api.example.com".
+	CircuitName func(host string) string
+	// MaxStale is the oldest a last good answer can be and still be returned.  The default is one hour.  Set to -1 to
+	// return last good answers of any age.
+	MaxStale time.Duration
+
+	lastGood sync.Map
+}
+
+// answer is a successful lookup
+type answer struct {
+	value interface{}
+	at    time.Time
+}
+
+// answerKey is a lookup that answers are kept for
+type answerKey struct {
+	kind string
+	host string
+}
+
+func (r *Resolver) base() *net.Resolver {
+	if r.Base == nil {
+		return net.DefaultResolver
+	}
+	return r.Base
+}
+
+func (r *Resolver) circuitName(host string) string {
+	if r.CircuitName != nil {
+		return r.CircuitName(host)
+	}
+	return "dns:" + zone(host)
+}
+
+func (r *Resolver) maxStale() time.Duration {
+	if r.MaxStale == 0 {
+		return time.Hour
+	}
+	return r.MaxStale
+}
+
+// zone is the last two labels of host
+func zone(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
+
+// LookupHost is net.Resolver.LookupHost run in the host's circuit
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookup(ctx, r, "host", host, r.base().LookupHost)
+}
+
+// LookupIPAddr is net.Resolver.LookupIPAddr run in the host's circuit
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return lookup(ctx, r, "ipaddr", host, r.base().LookupIPAddr)
+}
+
+// LookupIP is net.Resolver.LookupIP run in the host's circuit
+func (r *Resolver) LookupIP(ctx context.Context, network string, host string) ([]net.IP, error) {
+	return lookup(ctx, r, "ip:"+network, host, func(ctx context.Context, host string) ([]net.IP, error) {
+		return r.base().LookupIP(ctx, network, host)
+	})
+}
+
+func lookup[T any](ctx context.Context, r *Resolver, kind string, host string, do func(context.Context, string) (T, error)) (T, error) {
+	if net.ParseIP(host) != nil {
+		// Nothing to resolve, so nothing to protect
+		return do(ctx, host)
+	}
+	key := answerKey{kind: kind, host: host}
+	c := r.Manager.GetOrCreateCircuit(r.circuitName(host), r.Config)
+	var ret T
+	var lookupErr error
+	// ran is set if the lookup happened, instead of the circuit serving the last good answer without one
+	var ran bool
+	err := c.Execute(ctx, func(ctx context.Context) error {
+		ran = true
+		ret, lookupErr = do(ctx, host)
+		var dnsErr *net.DNSError
+		if errors.As(lookupErr, &dnsErr) && dnsErr.IsNotFound {
+			return circuit.SimpleBadRequest{Err: lookupErr}
+		}
+		return lookupErr
+	}, func(_ context.Context, err error) error {
+		stored, exists := r.lastGood.Load(key)
+		if !exists {
+			return err
+		}
+		a := stored.(answer)
+		if maxStale := r.maxStale(); maxStale >= 0 && time.Since(a.at) > maxStale {
+			return err
+		}
+		ret = a.value.(T)
+		return nil
+	})
+	if err != nil {
+		if lookupErr != nil {
+			// Return the resolver's own error, so callers can inspect the *net.DNSError
+			return ret, lookupErr
+		}
+		return ret, err
+	}
+	if ran && lookupErr == nil {
+		r.lastGood.Store(key, answer{value: ret, at: time.Now()})
+	}
+	return ret, nil
+}
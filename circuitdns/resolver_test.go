@@ -0,0 +1,172 @@
+package circuitdns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// serveDNS answers DNS queries read from conn, using the stream framing the Go resolver uses on non packet conns.
+// A queries get 192.0.2.1, names starting with "missing" do not exist, and everything else has no records.
+func serveDNS(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size uint16
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		query := make([]byte, size)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+		// The question is the name's labels, then a two byte type and two byte class
+		end := 12
+		for query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+		name := string(query[13:end])
+		question := query[12 : end+5]
+		qtype := binary.BigEndian.Uint16(query[end+1:])
+
+		resp := make([]byte, 12, 512)
+		copy(resp, query[:2])
+		binary.BigEndian.PutUint16(resp[2:], 0x8180)
+		binary.BigEndian.PutUint16(resp[4:], 1)
+		if strings.HasPrefix(name, "missing") {
+			resp[3] |= 3
+		} else if qtype == 1 {
+			binary.BigEndian.PutUint16(resp[6:], 1)
+		}
+		resp = append(resp, question...)
+		if resp[7] == 1 {
+			resp = append(resp, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 192, 0, 2, 1)
+		}
+		if err := binary.Write(conn, binary.BigEndian, uint16(len(resp))); err != nil {
+			return
+		}
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func testResolver(failing *atomic.Bool) *Resolver {
+	return &Resolver{
+		Manager: &circuit.Manager{},
+		Base: &net.Resolver{
+			PreferGo: true,
+			Dial: func(_ context.Context, _ string, _ string) (net.Conn, error) {
+				if failing.Load() {
+					return nil, errors.New("resolver is down")
+				}
+				client, server := net.Pipe()
+				go serveDNS(server)
+				return client, nil
+			},
+		},
+	}
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	var failing atomic.Bool
+	r := testResolver(&failing)
+
+	addrs, err := r.LookupHost(ctx, "api.example.test.")
+	if err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.1" {
+		t.Fatalf("expected 192.0.2.1, got %v %v", addrs, err)
+	}
+	if r.Manager.GetCircuit("dns:example.test") == nil {
+		t.Error("expected a circuit for the zone")
+	}
+
+	failing.Store(true)
+	addrs, err = r.LookupHost(ctx, "api.example.test.")
+	if err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.1" {
+		t.Errorf("expected the last good answer, got %v %v", addrs, err)
+	}
+	var dnsErr *net.DNSError
+	if _, err := r.LookupHost(ctx, "other.example.test."); !errors.As(err, &dnsErr) {
+		t.Errorf("expected the resolver's error for a host never resolved, got %v", err)
+	}
+	if _, err := r.LookupIPAddr(ctx, "api.example.test."); err == nil {
+		t.Error("expected last good answers to be kept per kind of lookup")
+	}
+
+	r.MaxStale = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, err := r.LookupHost(ctx, "api.example.test."); err == nil {
+		t.Error("expected an answer older than MaxStale to not be used")
+	}
+}
+
+func TestResolver_openCircuitAges(t *testing.T) {
+	ctx := context.Background()
+	var failing atomic.Bool
+	r := testResolver(&failing)
+	r.MaxStale = 60 * time.Millisecond
+	if _, err := r.LookupHost(ctx, "api.example.test."); err != nil {
+		t.Fatal(err)
+	}
+	r.Manager.GetCircuit("dns:example.test").OpenCircuit(ctx)
+	// Keep asking while the circuit stays open, until the answer is older than MaxStale
+	start := time.Now()
+	for time.Since(start) < 30*time.Millisecond {
+		if _, err := r.LookupHost(ctx, "api.example.test."); err != nil {
+			t.Fatalf("expected the last good answer while the circuit is open, got %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(70*time.Millisecond - time.Since(start))
+	if _, err := r.LookupHost(ctx, "api.example.test."); err == nil {
+		t.Error("expected answers served by an open circuit to not renew the last good answer")
+	}
+}
+
+func TestResolver_notFound(t *testing.T) {
+	var failing atomic.Bool
+	r := testResolver(&failing)
+	_, err := r.LookupHost(context.Background(), "missing.example.test.")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	c := r.Manager.GetCircuit("dns:example.test")
+	if len(c.RecentErrors()) != 0 {
+		t.Errorf("expected a missing host to not count against the circuit, got %v", c.RecentErrors())
+	}
+}
+
+func TestResolver_ipLiteral(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	r := testResolver(&failing)
+	addrs, err := r.LookupHost(context.Background(), "192.0.2.7")
+	if err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.7" {
+		t.Errorf("expected the literal back, got %v %v", addrs, err)
+	}
+	if len(r.Manager.AllCircuits()) != 0 {
+		t.Error("expected no circuit for an IP literal")
+	}
+}
+
+func TestZone(t *testing.T) {
+	for host, expected := range map[string]string{
+		"api.example.com":  "example.com",
+		"API.Example.com.": "example.com",
+		"example.com":      "example.com",
+		"localhost":        "localhost",
+	} {
+		if z := zone(host); z != expected {
+			t.Errorf("zone(%q) = %q, expected %q", host, z, expected)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+/*
+Package circuitevents publishes circuit lifecycle events, like a circuit opening, closing or having its config changed,
+to a message bus topic.  Central tooling can consume the topic to see breaker activity across a whole fleet as it
+happens.  Connect it to Kafka, NATS or any other bus with a Publisher.
+*/
+package circuitevents
@@ -0,0 +1,33 @@
+package circuitevents_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitevents"
+)
+
+// This example publishes circuit events.  Replace the PublisherFunc with a call to your Kafka producer or NATS
+// connection.
+func ExampleExporter() {
+	e := &circuitevents.Exporter{
+		Publisher: circuitevents.PublisherFunc(func(ctx context.Context, topic string, key []byte, value []byte) error {
+			fmt.Printf("%s %s\n", topic, key)
+			return nil
+		}),
+		Topic:    "circuit-events",
+		Instance: "host-1",
+	}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{e.CreateConfig},
+	}
+	go func() {
+		m.MustCreateCircuit("db").OpenCircuit(context.Background())
+		_ = e.Close()
+	}()
+	if err := e.Start(); err != nil {
+		panic(err)
+	}
+	// Output: circuit-events db
+}
@@ -0,0 +1,192 @@
+package circuitevents
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// EventType is what happened to a circuit
+type EventType string
+
+const (
+	// EventOpened is a circuit that opened
+	EventOpened EventType = "opened"
+	// EventClosed is a circuit that closed
+	EventClosed EventType = "closed"
+	// EventConfigChanged is a circuit whose config changed while it was running
+	EventConfigChanged EventType = "config_changed"
+)
+
+// Event is a single lifecycle event of a circuit
+type Event struct {
+	Type    EventType `json:"type"`
+	Circuit string    `json:"circuit"`
+	Time    time.Time `json:"time"`
+	// Instance is Exporter.Instance, so events from many processes can be told apart
+	Instance string `json:"instance,omitempty"`
+	// Source is who or what changed the config, for EventConfigChanged
+	Source string `json:"source,omitempty"`
+	// Fields are the fields that changed, for EventConfigChanged
+	Fields []circuit.ConfigFieldChange `json:"fields,omitempty"`
+}
+
+// Publisher sends a message to a topic of a message bus.  key is the circuit name, so buses that partition by key keep
+// the events of a circuit in order.  Wrap a Kafka producer or NATS connection to implement it.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key []byte, value []byte) error
+}
+
+// PublisherFunc is a function that implements Publisher
+type PublisherFunc func(ctx context.Context, topic string, key []byte, value []byte) error
+
+// Publish calls the function
+func (p PublisherFunc) Publish(ctx context.Context, topic string, key []byte, value []byte) error {
+	return p(ctx, topic, key, value)
+}
+
+// EncodeJSON encodes an event as JSON.  It is the default Exporter.Encode.
+func EncodeJSON(e Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Exporter publishes the events of the circuits it is attached to.  Attach it with CreateConfig, and call Start so
+// events are published.  Events are published in the background, so a slow bus never slows a circuit down.  Events
+// that arrive while BufferSize events are waiting to be published are dropped and counted in Dropped.
+type Exporter struct {
+	Publisher Publisher
+	// Topic events are published to
+	Topic string
+	// Encode turns an event into a message.  The default is EncodeJSON.  See schemapb.EncodeEvent for protobuf.
+	Encode func(e Event) ([]byte, error)
+	// Instance names this process in each event, like a host name or pod name
+	Instance string
+	// BufferSize is how many events can wait to be published.  The default is 1024.
+	BufferSize int
+	// PublishTimeout limits each call to Publish.  The default is five seconds.
+	PublishTimeout time.Duration
+	// OnError, if set, is called with events that could not be encoded or published
+	OnError func(e Event, err error)
+
+	// Dropped counts events that were not published because the buffer was full
+	Dropped faststats.AtomicInt64
+
+	events    chan Event
+	closeChan chan struct{}
+	once      sync.Once
+}
+
+func (e *Exporter) doOnce() {
+	bufferSize := e.BufferSize
+	if bufferSize == 0 {
+		bufferSize = 1024
+	}
+	e.events = make(chan Event, bufferSize)
+	e.closeChan = make(chan struct{})
+}
+
+func (e *Exporter) publishTimeout() time.Duration {
+	if e.PublishTimeout == 0 {
+		return 5 * time.Second
+	}
+	return e.PublishTimeout
+}
+
+// CreateConfig is a config factory that sends the events of the circuit to the exporter.  Add it to
+// circuit.Manager.DefaultCircuitProperties.
+func (e *Exporter) CreateConfig(circuitName string) circuit.Config {
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Circuit: []circuit.Metrics{&circuitEvents{
+				exporter:    e,
+				circuitName: circuitName,
+			}},
+		},
+	}
+}
+
+// Start should be called once per Exporter.  It publishes events until Close is called, then publishes the events that
+// are still waiting before it returns.
+func (e *Exporter) Start() error {
+	e.once.Do(e.doOnce)
+	for {
+		select {
+		case ev := <-e.events:
+			e.publish(ev)
+		case <-e.closeChan:
+			for {
+				select {
+				case ev := <-e.events:
+					e.publish(ev)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Close ends the Start function
+func (e *Exporter) Close() error {
+	e.once.Do(e.doOnce)
+	close(e.closeChan)
+	return nil
+}
+
+// send queues an event to be published, dropping it if the buffer is full
+func (e *Exporter) send(ev Event) {
+	e.once.Do(e.doOnce)
+	ev.Instance = e.Instance
+	select {
+	case e.events <- ev:
+	default:
+		e.Dropped.Add(1)
+	}
+}
+
+func (e *Exporter) publish(ev Event) {
+	encode := e.Encode
+	if encode == nil {
+		encode = EncodeJSON
+	}
+	value, err := encode(ev)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), e.publishTimeout())
+		err = e.Publisher.Publish(ctx, e.Topic, []byte(ev.Circuit), value)
+		cancel()
+	}
+	if err != nil && e.OnError != nil {
+		e.OnError(ev, err)
+	}
+}
+
+// circuitEvents sends the events of one circuit to an Exporter
+type circuitEvents struct {
+	exporter    *Exporter
+	circuitName string
+}
+
+var _ circuit.Metrics = &circuitEvents{}
+var _ circuit.ConfigChangeMetrics = &circuitEvents{}
+
+func (c *circuitEvents) Closed(_ context.Context, now time.Time) {
+	c.exporter.send(Event{Type: EventClosed, Circuit: c.circuitName, Time: now})
+}
+
+func (c *circuitEvents) Opened(_ context.Context, now time.Time) {
+	c.exporter.send(Event{Type: EventOpened, Circuit: c.circuitName, Time: now})
+}
+
+func (c *circuitEvents) ConfigChanged(_ context.Context, now time.Time, change circuit.ConfigChange) {
+	c.exporter.send(Event{
+		Type:    EventConfigChanged,
+		Circuit: c.circuitName,
+		Time:    now,
+		Source:  change.Source,
+		Fields:  change.Fields,
+	})
+}
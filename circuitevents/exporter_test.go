@@ -0,0 +1,105 @@
+package circuitevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	topics   []string
+	keys     []string
+	messages []Event
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, topic string, key []byte, value []byte) error {
+	var e Event
+	if err := json.Unmarshal(value, &e); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics = append(r.topics, topic)
+	r.keys = append(r.keys, string(key))
+	r.messages = append(r.messages, e)
+	return nil
+}
+
+func TestExporter(t *testing.T) {
+	publisher := &recordingPublisher{}
+	m := &circuit.Manager{}
+	e := &Exporter{
+		Publisher: publisher,
+		Topic:     "circuit-events",
+		Instance:  "host-1",
+	}
+	m.DefaultCircuitProperties = []circuit.CommandPropertiesConstructor{e.CreateConfig}
+	c := m.MustCreateCircuit("db")
+	ctx := context.Background()
+	c.OpenCircuit(ctx)
+	cfg := c.Config()
+	cfg.Execution.MaxConcurrentRequests = 20
+	c.SetConfigFrom("admin:alice", cfg)
+	c.CloseCircuit(ctx)
+
+	done := make(chan error)
+	go func() {
+		done <- e.Start()
+	}()
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(publisher.messages) != 3 {
+		t.Fatalf("expected 3 events, got %+v", publisher.messages)
+	}
+	opened, changed, closed := publisher.messages[0], publisher.messages[1], publisher.messages[2]
+	if opened.Type != EventOpened || opened.Instance != "host-1" {
+		t.Errorf("unexpected open event %+v", opened)
+	}
+	if changed.Type != EventConfigChanged || changed.Source != "admin:alice" || changed.Fields[0].Field != "Execution.MaxConcurrentRequests" {
+		t.Errorf("unexpected config event %+v", changed)
+	}
+	if closed.Type != EventClosed {
+		t.Errorf("unexpected close event %+v", closed)
+	}
+	if publisher.topics[0] != "circuit-events" || publisher.keys[0] != "db" {
+		t.Errorf("expected events on the topic keyed by circuit, got %s and %s", publisher.topics[0], publisher.keys[0])
+	}
+}
+
+func TestExporter_Dropped(t *testing.T) {
+	var failed []Event
+	e := &Exporter{
+		Publisher: PublisherFunc(func(_ context.Context, _ string, _ []byte, _ []byte) error {
+			return errors.New("bus is down")
+		}),
+		BufferSize: 1,
+		OnError: func(e Event, _ error) {
+			failed = append(failed, e)
+		},
+	}
+	c := circuit.NewCircuitFromConfig("TestExporter_Dropped", e.CreateConfig("TestExporter_Dropped"))
+	c.OpenCircuit(context.Background())
+	c.CloseCircuit(context.Background())
+	if e.Dropped.Get() != 1 {
+		t.Errorf("expected the second event to be dropped, got %d", e.Dropped.Get())
+	}
+	go func() {
+		_ = e.Close()
+	}()
+	if err := e.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Type != EventOpened {
+		t.Errorf("expected the open event to fail to publish, got %+v", failed)
+	}
+}
@@ -0,0 +1,158 @@
+package circuitgrpc
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Outcome is how a call counts towards the health of its circuit
+type Outcome int
+
+const (
+	// OutcomeSuccess counts the call as a success
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure counts the call as a failure, like Unavailable
+	OutcomeFailure
+	// OutcomeShed is a call rejected by a server shedding load, like ResourceExhausted.  It does not count against the
+	// circuit, since the server is protecting itself.
+	OutcomeShed
+	// OutcomeBadRequest is the caller's fault, like InvalidArgument, and does not count against the circuit
+	OutcomeBadRequest
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeShed:
+		return "shed"
+	case OutcomeBadRequest:
+		return "bad request"
+	}
+	return "Outcome(" + strconv.Itoa(int(o)) + ")"
+}
+
+// defaultOutcomes are the outcomes of codes that are not failures.  Every other code is a failure.
+var defaultOutcomes = map[codes.Code]Outcome{
+	codes.OK:                 OutcomeSuccess,
+	codes.Canceled:           OutcomeBadRequest,
+	codes.InvalidArgument:    OutcomeBadRequest,
+	codes.NotFound:           OutcomeBadRequest,
+	codes.AlreadyExists:      OutcomeBadRequest,
+	codes.PermissionDenied:   OutcomeBadRequest,
+	codes.ResourceExhausted:  OutcomeShed,
+	codes.FailedPrecondition: OutcomeBadRequest,
+	codes.OutOfRange:         OutcomeBadRequest,
+	codes.Unimplemented:      OutcomeBadRequest,
+	codes.Unauthenticated:    OutcomeBadRequest,
+}
+
+// Classifier maps gRPC status codes to circuit outcomes.  By default ResourceExhausted is shed, codes that are the
+// caller's fault, like InvalidArgument or NotFound, are bad requests, and codes like Unavailable, DeadlineExceeded and
+// Internal are failures.  The zero value is ready to use.
+type Classifier struct {
+	// Overrides replaces the default outcome of the codes in it
+	Overrides map[codes.Code]Outcome
+}
+
+// DefaultClassifier is a Classifier without overrides
+var DefaultClassifier = Classifier{}
+
+// Outcome returns how a call that ended with code counts
+func (c Classifier) Outcome(code codes.Code) Outcome {
+	if outcome, exists := c.Overrides[code]; exists {
+		return outcome
+	}
+	if outcome, exists := defaultOutcomes[code]; exists {
+		return outcome
+	}
+	return OutcomeFailure
+}
+
+// Classify returns the error a run function should return for a call that ended with err.  Calls that are not a
+// success return a *StatusError wrapping err.  Errors without a gRPC status are classified as codes.Unknown.
+//
+//	err := c.Run(ctx, func(ctx context.Context) error {
+//		resp, err = client.GetThing(ctx, req)
+//		return circuitgrpc.DefaultClassifier.Classify(err)
+//	})
+func (c Classifier) Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, _ := status.FromError(err)
+	outcome := c.Outcome(st.Code())
+	if outcome == OutcomeSuccess {
+		return nil
+	}
+	return &StatusError{
+		Err:     err,
+		Outcome: outcome,
+		After:   retryDelay(st),
+	}
+}
+
+// StatusError is a call that was not a success.  Bad requests and shed calls implement circuit.BadRequest, so they do
+// not count against the circuit.  It keeps the gRPC status of Err, so status.FromError and status.Code still work.
+type StatusError struct {
+	Err     error
+	Outcome Outcome
+	// After is the RetryInfo delay in the status details, or 0 if there was none
+	After time.Duration
+}
+
+var _ circuit.BadRequest = &StatusError{}
+var _ circuit.RetryAfter = &StatusError{}
+
+func (s *StatusError) Error() string {
+	return s.Err.Error()
+}
+
+// Unwrap returns Err
+func (s *StatusError) Unwrap() error {
+	return s.Err
+}
+
+// GRPCStatus returns the status of Err
+func (s *StatusError) GRPCStatus() *status.Status {
+	st, _ := status.FromError(s.Err)
+	return st
+}
+
+// BadRequest is true unless the call is a failure
+func (s *StatusError) BadRequest() bool {
+	return s.Outcome == OutcomeShed || s.Outcome == OutcomeBadRequest
+}
+
+// RetryAfter is the RetryInfo delay the server sent, so the circuit's closer waits as long as the server asked
+func (s *StatusError) RetryAfter() time.Duration {
+	return s.After
+}
+
+// retryDelay returns the delay of a RetryInfo detail in st, or 0 if it has none
+func retryDelay(st *status.Status) time.Duration {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	return 0
+}
+
+// unwrapStatusError returns the error a call ended with, instead of the StatusError Classify wrapped it in
+func unwrapStatusError(err error) error {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Err
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package circuitgrpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestClassifier_Outcome(t *testing.T) {
+	c := Classifier{
+		Overrides: map[codes.Code]Outcome{
+			codes.NotFound:         OutcomeSuccess,
+			codes.DeadlineExceeded: OutcomeBadRequest,
+		},
+	}
+	tests := map[codes.Code]Outcome{
+		codes.OK:                OutcomeSuccess,
+		codes.InvalidArgument:   OutcomeBadRequest,
+		codes.NotFound:          OutcomeSuccess,
+		codes.ResourceExhausted: OutcomeShed,
+		codes.Unavailable:       OutcomeFailure,
+		codes.Internal:          OutcomeFailure,
+		codes.Unknown:           OutcomeFailure,
+		codes.DeadlineExceeded:  OutcomeBadRequest,
+	}
+	for code, expected := range tests {
+		if outcome := c.Outcome(code); outcome != expected {
+			t.Errorf("expected %s for %s, got %s", expected, code, outcome)
+		}
+	}
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	if err := DefaultClassifier.Classify(nil); err != nil {
+		t.Errorf("expected a success, got %v", err)
+	}
+	st, err := status.New(codes.ResourceExhausted, "slow down").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(3 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shed := DefaultClassifier.Classify(st.Err())
+	if !circuit.IsBadRequest(shed) || circuit.RetryAfterOf(shed) != 3*time.Second {
+		t.Errorf("expected a bad request with a hint, got %v", shed)
+	}
+	if code := status.Code(shed); code != codes.ResourceExhausted {
+		t.Errorf("expected the status to be kept, got %s", code)
+	}
+	failure := DefaultClassifier.Classify(errors.New("not a status"))
+	var statusErr *StatusError
+	if !errors.As(failure, &statusErr) || statusErr.Outcome != OutcomeFailure || circuit.IsBadRequest(failure) {
+		t.Errorf("expected errors without a status to be failures, got %v", failure)
+	}
+}
@@ -0,0 +1,21 @@
+/*
+Package circuitgrpc runs gRPC calls in circuits, one per method.  It is a separate module so the circuit module
+does not depend on gRPC.
+
+	i := &circuitgrpc.Interceptor{Manager: manager}
+	conn, err := grpc.NewClient(target,
+		grpc.WithUnaryInterceptor(i.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(i.StreamClientInterceptor()))
+
+ServerInterceptor does the same for the handlers of a server, shedding calls with codes.ResourceExhausted while a
+method's circuit is open or at its concurrency limit.
+
+	si := &circuitgrpc.ServerInterceptor{Manager: manager}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(si.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(si.StreamServerInterceptor()))
+
+Classifier maps status codes to circuit outcomes.  The interceptors use it, and it can be used on its own in hand
+written run functions.
+*/
+package circuitgrpc
@@ -0,0 +1,32 @@
+package circuitgrpc_test
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cep21/circuit/circuitgrpc"
+	"github.com/cep21/circuit/v4"
+)
+
+// This example classifies the error of a hand written gRPC call
+func ExampleClassifier() {
+	c := circuit.NewCircuitFromConfig("users.Get", circuit.Config{})
+	classifier := circuitgrpc.Classifier{
+		Overrides: map[codes.Code]circuitgrpc.Outcome{
+			// This service returns Aborted for transactions the caller should retry, not because it is unhealthy
+			codes.Aborted: circuitgrpc.OutcomeBadRequest,
+		},
+	}
+	for _, code := range []codes.Code{codes.NotFound, codes.Aborted, codes.Unavailable} {
+		err := c.Run(context.Background(), func(_ context.Context) error {
+			return classifier.Classify(status.Error(code, "call failed"))
+		})
+		fmt.Println(status.Code(err), circuit.IsBadRequest(err))
+	}
+	// Output: NotFound true
+	// Aborted true
+	// Unavailable false
+}
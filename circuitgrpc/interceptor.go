@@ -0,0 +1,148 @@
+package circuitgrpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Interceptor runs gRPC client calls in a circuit per method.  Add its interceptors to a connection with
+// grpc.WithUnaryInterceptor and grpc.WithStreamInterceptor.
+//
+// Calls rejected by the circuit, because it is open or at its concurrency limit, fail with codes.Unavailable.  The
+// circuit.Error is still available with errors.As.  Other errors are returned as the call returned them.
+type Interceptor struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// CircuitName picks the circuit of a call.  The default is the full method, like "/pkg.Service/Method".
+	CircuitName func(method string) string
+	// Classifier decides how calls count.  The default is DefaultClassifier.
+	Classifier *Classifier
+}
+
+func (i *Interceptor) circuitName(method string) string {
+	if i.CircuitName != nil {
+		return i.CircuitName(method)
+	}
+	return method
+}
+
+func (i *Interceptor) classifier() *Classifier {
+	if i.Classifier == nil {
+		return &DefaultClassifier
+	}
+	return i.Classifier
+}
+
+// UnaryClientInterceptor runs each unary call in its method's circuit.  The circuit's timeout bounds the call.  The
+// encoded sizes of protobuf requests and replies are reported with circuit.Circuit.ReportSize.
+func (i *Interceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c := i.Manager.GetOrCreateCircuit(i.circuitName(method), i.Config)
+		err := c.Run(ctx, func(ctx context.Context) error {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			var received int64
+			if err == nil {
+				received = messageSize(reply)
+			}
+			c.ReportSize(ctx, messageSize(req), received)
+			return i.classifier().Classify(err)
+		})
+		return callError(err)
+	}
+}
+
+// messageSize is the encoded size of m, or zero if it is not a protobuf message
+func messageSize(m interface{}) int64 {
+	if msg, ok := m.(proto.Message); ok {
+		return int64(proto.Size(msg))
+	}
+	return 0
+}
+
+// StreamClientInterceptor runs the start of each stream in its method's circuit.  The circuit's timeout bounds how
+// long the stream takes to start.  Messages sent and received after that are only bounded by the caller's context,
+// and do not count towards the circuit.
+func (i *Interceptor) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := i.Manager.GetOrCreateCircuit(i.circuitName(method), i.Config)
+		var stream grpc.ClientStream
+		err := c.Run(ctx, func(runCtx context.Context) error {
+			// The circuit's context ends when Run returns, but the stream is used after that.  Only let the circuit
+			// cancel the stream until it starts.
+			streamCtx, cancel := context.WithCancel(ctx)
+			stopCancelOnTimeout := context.AfterFunc(runCtx, cancel)
+			var err error
+			stream, err = streamer(streamCtx, desc, cc, method, opts...)
+			stopped := stopCancelOnTimeout()
+			if err != nil {
+				cancel()
+				return i.classifier().Classify(err)
+			}
+			if !stopped || runCtx.Err() != nil {
+				// The circuit timed out as the stream started, and the stream was canceled
+				cancel()
+				stream = nil
+				return runCtx.Err()
+			}
+			stream = &cancelOnFinish{ClientStream: stream, cancel: cancel}
+			return nil
+		})
+		if err != nil {
+			return nil, callError(err)
+		}
+		return stream, nil
+	}
+}
+
+// callError returns the error a call through a circuit should fail with
+func callError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var circuitErr circuit.Error
+	if errors.As(err, &circuitErr) && (circuitErr.CircuitOpen() || circuitErr.ConcurrencyLimitReached()) {
+		return &rejectedError{err: err}
+	}
+	return unwrapStatusError(err)
+}
+
+// rejectedError is a call the circuit did not let through.  It has codes.Unavailable, so gRPC callers can handle it
+// like any other unavailable dependency.
+type rejectedError struct {
+	err error
+}
+
+func (r *rejectedError) Error() string {
+	return r.err.Error()
+}
+
+func (r *rejectedError) Unwrap() error {
+	return r.err
+}
+
+func (r *rejectedError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, r.err.Error())
+}
+
+// cancelOnFinish releases a stream's context once the stream ends
+type cancelOnFinish struct {
+	grpc.ClientStream
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnFinish) RecvMsg(m interface{}) error {
+	err := c.ClientStream.RecvMsg(m)
+	if err != nil {
+		c.cancel()
+	}
+	return err
+}
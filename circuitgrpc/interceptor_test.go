@@ -0,0 +1,221 @@
+package circuitgrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type countingMetrics struct {
+	mu          sync.Mutex
+	successes   int
+	failures    int
+	badRequests int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.badRequests++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)   {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)     {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)               {}
+
+// healthServer fails calls for services named after a code
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (h *healthServer) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "unavailable":
+		return nil, status.Error(codes.Unavailable, "down")
+	case "invalid":
+		return nil, status.Error(codes.InvalidArgument, "bad service")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if req.GetService() == "unavailable" {
+		return status.Error(codes.Unavailable, "down")
+	}
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func testClient(t *testing.T, i *Interceptor) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(i.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(i.StreamClientInterceptor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestInterceptor_Unary(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	client := testClient(t, &Interceptor{
+		Manager: m,
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	})
+	ctx := context.Background()
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetCircuit("/grpc.health.v1.Health/Check") == nil {
+		t.Error("expected a circuit named after the method")
+	}
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "invalid"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the call's error, got %v", err)
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		t.Error("expected the StatusError to be unwrapped")
+	}
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unavailable"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the call's error, got %v", err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 1 || metrics.badRequests != 1 || metrics.failures != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestInterceptor_open(t *testing.T) {
+	m := &circuit.Manager{}
+	i := &Interceptor{
+		Manager: m,
+		CircuitName: func(method string) string {
+			return "health"
+		},
+	}
+	client := testClient(t, i)
+	m.MustCreateCircuit("health").OpenCircuit(context.Background())
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	var circuitErr circuit.Error
+	if status.Code(err) != codes.Unavailable || !errors.As(err, &circuitErr) || !circuitErr.CircuitOpen() {
+		t.Errorf("expected an unavailable open circuit, got %v", err)
+	}
+	_, err = client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected streams to be rejected too, got %v", err)
+	}
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	metrics := &countingMetrics{}
+	client := testClient(t, &Interceptor{
+		Manager: &circuit.Manager{},
+		Config: circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				Timeout: time.Millisecond * 100,
+			},
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	})
+	ctx := context.Background()
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Receiving after the circuit's run has ended still works
+	time.Sleep(150 * time.Millisecond)
+	resp, err := stream.Recv()
+	if err != nil || resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("expected a response, got %v %v", resp, err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+type sizeMetrics struct {
+	countingMetrics
+	sent     int64
+	received int64
+}
+
+func (s *sizeMetrics) Size(_ context.Context, _ time.Time, sent int64, received int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent += sent
+	s.received += received
+}
+
+func TestInterceptor_size(t *testing.T) {
+	metrics := &sizeMetrics{}
+	client := testClient(t, &Interceptor{
+		Manager: &circuit.Manager{},
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	})
+	req := &grpc_health_v1.HealthCheckRequest{Service: "db"}
+	reply, err := client.Check(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.sent != int64(proto.Size(req)) || metrics.received != int64(proto.Size(reply)) {
+		t.Errorf("expected the message sizes, got %d sent and %d received", metrics.sent, metrics.received)
+	}
+}
@@ -0,0 +1,123 @@
+package circuitgrpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// ServerInterceptor runs gRPC handlers in a circuit per method, so a server sheds load on methods that are overloaded
+// or failing.  Add its interceptors to a server with grpc.UnaryInterceptor and grpc.StreamInterceptor.
+//
+// Calls rejected by the circuit, because it is open or at its concurrency limit, fail with codes.ResourceExhausted
+// and never reach the handler.  If the circuit knows when it will let calls through again, the status has a RetryInfo
+// detail, which Classifier gives clients as the RetryAfter of their own circuit.
+type ServerInterceptor struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// CircuitName picks the circuit of a call.  The default is the full method, like "/pkg.Service/Method".
+	CircuitName func(method string) string
+	// Classifier decides how the handler's errors count.  The default is DefaultClassifier.
+	Classifier *Classifier
+}
+
+func (i *ServerInterceptor) circuitName(method string) string {
+	if i.CircuitName != nil {
+		return i.CircuitName(method)
+	}
+	return method
+}
+
+func (i *ServerInterceptor) classifier() *Classifier {
+	if i.Classifier == nil {
+		return &DefaultClassifier
+	}
+	return i.Classifier
+}
+
+// UnaryServerInterceptor runs each unary handler in its method's circuit.  The circuit's timeout cancels the
+// handler's context, but the handler is trusted to return once it does.
+func (i *ServerInterceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		c := i.Manager.GetOrCreateCircuit(i.circuitName(info.FullMethod), i.Config)
+		var resp interface{}
+		err := c.Run(ctx, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return i.classifier().Classify(err)
+		})
+		if err != nil {
+			return nil, handlerError(err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor runs each stream handler in its method's circuit.  The whole stream is one run, so the
+// circuit's timeout bounds how long a stream can stay open.  Set Execution.Timeout to -1 for long lived streams.
+func (i *ServerInterceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c := i.Manager.GetOrCreateCircuit(i.circuitName(info.FullMethod), i.Config)
+		err := c.Run(ss.Context(), func(ctx context.Context) error {
+			return i.classifier().Classify(handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx}))
+		})
+		return handlerError(err)
+	}
+}
+
+// handlerError returns the error a handler run through a circuit should fail with
+func handlerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var circuitErr circuit.Error
+	if errors.As(err, &circuitErr) && (circuitErr.CircuitOpen() || circuitErr.ConcurrencyLimitReached()) {
+		return &shedError{err: err}
+	}
+	return unwrapStatusError(err)
+}
+
+// shedError is a call the server's circuit did not let through.  It has codes.ResourceExhausted, which clients
+// classify as shed load rather than a failure of the server.
+type shedError struct {
+	err error
+}
+
+func (s *shedError) Error() string {
+	return s.err.Error()
+}
+
+func (s *shedError) Unwrap() error {
+	return s.err
+}
+
+func (s *shedError) GRPCStatus() *status.Status {
+	st := status.New(codes.ResourceExhausted, s.err.Error())
+	retryAfter := circuit.RetryAfterOf(s.err)
+	if retryAfter <= 0 {
+		return st
+	}
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// contextServerStream gives a stream handler the circuit's context
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextServerStream) Context() context.Context {
+	return c.ctx
+}
@@ -0,0 +1,109 @@
+package circuitgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func testServer(t *testing.T, i *ServerInterceptor) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(i.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(i.StreamServerInterceptor()))
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestServerInterceptor_Unary(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	client := testServer(t, &ServerInterceptor{
+		Manager: m,
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	})
+	ctx := context.Background()
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetCircuit("/grpc.health.v1.Health/Check") == nil {
+		t.Error("expected a circuit named after the method")
+	}
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "invalid"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unavailable"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 1 || metrics.badRequests != 1 || metrics.failures != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestServerInterceptor_shed(t *testing.T) {
+	m := &circuit.Manager{}
+	client := testServer(t, &ServerInterceptor{
+		Manager: m,
+		CircuitName: func(_ string) string {
+			return "health"
+		},
+	})
+	m.MustCreateCircuit("health").OpenCircuit(context.Background())
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the call to be shed, got %v", err)
+	}
+	if outcome := DefaultClassifier.Outcome(status.Code(err)); outcome != OutcomeShed {
+		t.Errorf("expected clients to see shed load, got %v", outcome)
+	}
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected streams to be shed too, got %v", err)
+	}
+}
+
+func TestShedError_retryInfo(t *testing.T) {
+	err := &shedError{err: &circuit.SimpleRetryAfter{Err: circuit.ErrCircuitOpen, After: 1500 * time.Millisecond}}
+	st := err.GRPCStatus()
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", st.Code())
+	}
+	if after := retryDelay(st); after.Milliseconds() != 1500 {
+		t.Errorf("expected a RetryInfo of 1.5s, got %v", after)
+	}
+}
@@ -0,0 +1,134 @@
+package circuithttp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Outcome is how a response counts towards the health of its circuit
+type Outcome int
+
+const (
+	// OutcomeSuccess counts the response as a success
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure counts the response as a failure, like a 5xx
+	OutcomeFailure
+	// OutcomeShed is a response from a server shedding load, like a 429.  It does not count against the circuit, since
+	// the server is protecting itself, but its Retry-After header is still available from ResponseError.
+	OutcomeShed
+	// OutcomeBadRequest is the caller's fault, like most 4xx, and does not count against the circuit
+	OutcomeBadRequest
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeShed:
+		return "shed"
+	case OutcomeBadRequest:
+		return "bad request"
+	}
+	return "Outcome(" + strconv.Itoa(int(o)) + ")"
+}
+
+// Classifier maps HTTP responses to circuit outcomes.  By default 5xx responses are failures, 429 is shed, other 4xx
+// responses are bad requests, and everything else is a success.  The zero value is ready to use.
+type Classifier struct {
+	// Overrides replaces the default outcome of the status codes in it
+	Overrides map[int]Outcome
+}
+
+// DefaultClassifier is a Classifier without overrides
+var DefaultClassifier = Classifier{}
+
+// Outcome returns how a response with statusCode counts
+func (c Classifier) Outcome(statusCode int) Outcome {
+	if outcome, exists := c.Overrides[statusCode]; exists {
+		return outcome
+	}
+	switch {
+	case statusCode >= 500:
+		return OutcomeFailure
+	case statusCode == http.StatusTooManyRequests:
+		return OutcomeShed
+	case statusCode >= 400:
+		return OutcomeBadRequest
+	}
+	return OutcomeSuccess
+}
+
+// Classify returns the error a run function should return for a call that gave resp and err.  Errors making the call
+// are returned as they are, so they count as failures.  Responses that are not a success return a *ResponseError.
+//
+//	err := c.Run(ctx, func(ctx context.Context) error {
+//		resp, err = client.Do(req.WithContext(ctx))
+//		return circuithttp.DefaultClassifier.Classify(resp, err)
+//	})
+func (c Classifier) Classify(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return nil
+	}
+	outcome := c.Outcome(resp.StatusCode)
+	if outcome == OutcomeSuccess {
+		return nil
+	}
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		Outcome:    outcome,
+		After:      retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// ResponseError is a response that was not a success.  Bad requests and shed responses implement circuit.BadRequest,
+// so they do not count against the circuit.
+type ResponseError struct {
+	StatusCode int
+	Outcome    Outcome
+	// After is the response's Retry-After header, or 0 if it had none
+	After time.Duration
+}
+
+var _ circuit.BadRequest = &ResponseError{}
+var _ circuit.RetryAfter = &ResponseError{}
+
+func (r *ResponseError) Error() string {
+	return fmt.Sprintf("response status %d (%s)", r.StatusCode, r.Outcome)
+}
+
+// BadRequest is true unless the response is a failure
+func (r *ResponseError) BadRequest() bool {
+	return r.Outcome == OutcomeShed || r.Outcome == OutcomeBadRequest
+}
+
+// RetryAfter is the response's Retry-After header, so the circuit's closer waits as long as the server asked
+func (r *ResponseError) RetryAfter() time.Duration {
+	return r.After
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.  It returns 0 if the
+// header is missing or invalid.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil && when.After(now) {
+		return when.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package circuithttp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestClassifier_Outcome(t *testing.T) {
+	c := Classifier{
+		Overrides: map[int]Outcome{
+			http.StatusNotFound:           OutcomeSuccess,
+			http.StatusNotImplemented:     OutcomeBadRequest,
+			http.StatusServiceUnavailable: OutcomeShed,
+		},
+	}
+	tests := map[int]Outcome{
+		http.StatusOK:                  OutcomeSuccess,
+		http.StatusFound:               OutcomeSuccess,
+		http.StatusBadRequest:          OutcomeBadRequest,
+		http.StatusNotFound:            OutcomeSuccess,
+		http.StatusTooManyRequests:     OutcomeShed,
+		http.StatusInternalServerError: OutcomeFailure,
+		http.StatusNotImplemented:      OutcomeBadRequest,
+		http.StatusServiceUnavailable:  OutcomeShed,
+	}
+	for code, expected := range tests {
+		if outcome := c.Outcome(code); outcome != expected {
+			t.Errorf("expected %s for %d, got %s", expected, code, outcome)
+		}
+	}
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	callErr := errors.New("connection refused")
+	if err := DefaultClassifier.Classify(nil, callErr); err != callErr {
+		t.Errorf("expected the call's error, got %v", err)
+	}
+	if err := DefaultClassifier.Classify(&http.Response{StatusCode: http.StatusOK}, nil); err != nil {
+		t.Errorf("expected a success, got %v", err)
+	}
+	shed := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+	}
+	err := DefaultClassifier.Classify(shed, nil)
+	if !circuit.IsBadRequest(err) || circuit.RetryAfterOf(err) != 5*time.Second {
+		t.Errorf("expected a bad request with a hint, got %v", err)
+	}
+	err = DefaultClassifier.Classify(&http.Response{StatusCode: http.StatusBadGateway}, nil)
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || respErr.Outcome != OutcomeFailure || circuit.IsBadRequest(err) {
+		t.Errorf("expected a failure, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-1":                            0,
+		"soon":                          0,
+		"Wed, 01 Jan 2020 00:00:30 GMT": 30 * time.Second,
+		"Tue, 31 Dec 2019 23:00:00 GMT": 0,
+	}
+	for header, expected := range tests {
+		if after := retryAfter(header, now); after != expected {
+			t.Errorf("expected %s for %q, got %s", expected, header, after)
+		}
+	}
+}
+
+func TestTransport_Classifier(t *testing.T) {
+	server := testServer(t)
+	metrics := &countingMetrics{}
+	client := &http.Client{
+		Transport: &Transport{
+			Manager: &circuit.Manager{},
+			Config: circuit.Config{
+				Metrics: circuit.MetricsCollectors{
+					Run: []circuit.RunMetrics{metrics},
+				},
+			},
+			Classifier: &Classifier{
+				Overrides: map[int]Outcome{
+					http.StatusInternalServerError: OutcomeBadRequest,
+				},
+			},
+		},
+	}
+	resp, body, err := get(t, client, server.URL+"/fail")
+	if err != nil || resp.StatusCode != http.StatusInternalServerError || body != "broken" {
+		t.Errorf("expected the response to be returned, got %v %q %v", resp, body, err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 0 || metrics.failures != 0 {
+		t.Errorf("expected a bad request, got %+v", metrics)
+	}
+}
@@ -0,0 +1,12 @@
+/*
+Package circuithttp wraps HTTP clients in circuits, one per host.  Circuits are created the first time a host is
+called and forgotten once the host is not called for a while, so clients that talk to many hosts do not need to know
+them up front.
+
+Handler does the same for servers, running inbound requests in a circuit so an overloaded handler sheds load with a
+503 instead of queueing requests it cannot serve.
+
+Classifier maps responses to circuit outcomes, so 4xx responses do not count against a circuit.  Use it with Transport,
+or in hand written run functions.
+*/
+package circuithttp
@@ -0,0 +1,54 @@
+package circuithttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuithttp"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// This example creates a client with a hystrix circuit per host it talks to
+func ExampleTransport() {
+	f := hystrix.Factory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+	}
+	client := &http.Client{
+		Transport: &circuithttp.Transport{
+			Manager: m,
+			Config: circuit.Config{
+				Execution: circuit.ExecutionConfig{
+					Timeout: 2 * time.Second,
+				},
+			},
+		},
+	}
+	// Each host gets its own circuit, named like "https://example.com"
+	_ = client
+	// Output:
+}
+
+// This example classifies responses in a hand written run function
+func ExampleClassifier() {
+	c := circuit.NewCircuitFromConfig("api", circuit.Config{})
+	classifier := circuithttp.Classifier{
+		Overrides: map[int]circuithttp.Outcome{
+			// This API returns 404 for missing records, which is a normal answer
+			http.StatusNotFound: circuithttp.OutcomeSuccess,
+		},
+	}
+	for _, code := range []int{http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		resp := &http.Response{StatusCode: code}
+		err := c.Run(context.Background(), func(_ context.Context) error {
+			return classifier.Classify(resp, nil)
+		})
+		fmt.Println(code, err, circuit.IsBadRequest(err))
+	}
+	// Output: 404 <nil> false
+	// 429 response status 429 (shed) true
+	// 500 response status 500 (failure) false
+}
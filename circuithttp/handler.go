@@ -0,0 +1,118 @@
+package circuithttp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Handler is http.Handler middleware that runs each inbound request in a circuit, shedding load when the circuit is
+// open or its concurrency limit is reached.  Shed requests get a 503 with a Retry-After header and never reach Next.
+// Use ExecutionConfig.ConcurrencyLimiter on the circuit for an adaptive limit instead of MaxConcurrentRequests.
+type Handler struct {
+	// Circuit runs each request
+	Circuit *circuit.Circuit
+	// Next serves the requests the circuit lets through
+	Next http.Handler
+	// IsFailure decides if a response counts against the health of the circuit.  The default counts 5xx responses
+	// as failures.
+	IsFailure func(statusCode int) bool
+	// RetryAfter is sent to shed requests as the Retry-After header, unless the circuit is open and knows when it
+	// will next let a request through.  The default is one second.
+	RetryAfter time.Duration
+}
+
+var _ http.Handler = &Handler{}
+
+func (h *Handler) isFailure(statusCode int) bool {
+	if h.IsFailure != nil {
+		return h.IsFailure(statusCode)
+	}
+	return statusCode >= 500
+}
+
+func (h *Handler) retryAfter() time.Duration {
+	if h.RetryAfter == 0 {
+		return time.Second
+	}
+	return h.RetryAfter
+}
+
+// ServeHTTP runs Next in the circuit.  The circuit's timeout cancels the request's context, but Next is trusted to
+// return once it does.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	sw := &statusWriter{ResponseWriter: rw}
+	err := h.Circuit.Run(req.Context(), func(ctx context.Context) error {
+		h.Next.ServeHTTP(sw, req.WithContext(ctx))
+		if h.isFailure(sw.status()) {
+			return &ResponseError{StatusCode: sw.status(), Outcome: OutcomeFailure}
+		}
+		return nil
+	})
+	var circuitErr circuit.Error
+	if !errors.As(err, &circuitErr) || !(circuitErr.CircuitOpen() || circuitErr.ConcurrencyLimitReached()) {
+		return
+	}
+	retryAfter := circuit.RetryAfterOf(err)
+	if retryAfter <= 0 {
+		retryAfter = h.retryAfter()
+	}
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
+
+// statusWriter remembers the status code Next writes.  It is an http.Flusher and http.Hijacker so streaming handlers
+// that check for those still work; they do nothing if the original writer does not support them.
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+var _ http.Flusher = &statusWriter{}
+var _ http.Hijacker = &statusWriter{}
+
+func (s *statusWriter) WriteHeader(statusCode int) {
+	if s.statusCode == 0 {
+		s.statusCode = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusWriter) status() int {
+	if s.statusCode == 0 {
+		return http.StatusOK
+	}
+	return s.statusCode
+}
+
+// Unwrap lets http.ResponseController reach the original writer, for things like Flush
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Flush sends buffered data to the client
+func (s *statusWriter) Flush() {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+// Hijack lets Next take over the connection
+func (s *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(s.ResponseWriter).Hijack()
+}
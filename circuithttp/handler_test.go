@@ -0,0 +1,172 @@
+package circuithttp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func TestHandler(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	c := m.MustCreateCircuit("server", circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Run: []circuit.RunMetrics{metrics},
+		},
+	})
+	h := &Handler{
+		Circuit: c,
+		Next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/fail" {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if err := http.NewResponseController(rw).Flush(); err != nil {
+				t.Errorf("expected Flush to reach the original writer: %v", err)
+			}
+			_, _ = io.WriteString(rw, "hello")
+		}),
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" || !rec.Flushed {
+		t.Errorf("expected a flushed hello, got %d %q", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected the handler's 500, got %d", rec.Code)
+	}
+	metrics.mu.Lock()
+	if metrics.successes != 1 || metrics.failures != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+	metrics.mu.Unlock()
+
+	c.OpenCircuit(context.Background())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected an open circuit to shed the request, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestHandler_streaming(t *testing.T) {
+	m := &circuit.Manager{}
+	c := m.MustCreateCircuit("streaming", circuit.Config{
+		Execution: circuit.ExecutionConfig{Timeout: -1},
+	})
+	release := make(chan struct{})
+	h := &Handler{
+		Circuit: c,
+		Next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/hijack" {
+				conn, buf, err := rw.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Errorf("expected to hijack the connection: %v", err)
+					return
+				}
+				defer conn.Close()
+				_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nhi")
+				_ = buf.Flush()
+				return
+			}
+			flusher, ok := rw.(http.Flusher)
+			if !ok {
+				t.Error("expected the writer given to Next to be an http.Flusher")
+				return
+			}
+			rw.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(rw, "data: first\n\n")
+			flusher.Flush()
+			<-release
+		}),
+	}
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The handler only returns after the event is read, so the event must have been flushed
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	close(release)
+	if err != nil || line != "data: first\n" {
+		t.Errorf("expected the first event before the handler returned, got %q %v", line, err)
+	}
+	_ = resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil || string(body) != "hi" {
+		t.Errorf("expected the hijacked response, got %q %v", body, err)
+	}
+}
+
+func TestHandler_concurrencyLimit(t *testing.T) {
+	m := &circuit.Manager{}
+	c := m.MustCreateCircuit("server", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			MaxConcurrentRequests: 1,
+		},
+	})
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	h := &Handler{
+		Circuit: c,
+		Next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			close(started)
+			<-finish
+		}),
+		RetryAfter: 1500 * time.Millisecond,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("expected the second request to be shed, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	close(finish)
+	<-done
+}
+
+func TestHandler_openRetryAfter(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("server", circuit.Config{
+		General: circuit.GeneralConfig{
+			OpenToClosedFactory: hystrix.CloserFactory(hystrix.ConfigureCloser{
+				SleepWindow: 30 * time.Second,
+			}),
+		},
+	})
+	c.OpenCircuit(context.Background())
+	h := &Handler{
+		Circuit: c,
+		Next: http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}),
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("expected the open circuit's sleep window as Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,204 @@
+package circuithttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// Transport is an http.RoundTripper that runs each request in a circuit for the request's host.  The circuit's timeout
+// bounds how long the response headers take.  Reading the body is only bounded by the request's context.
+type Transport struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Base makes the requests.  The default is http.DefaultTransport.
+	Base http.RoundTripper
+	// CircuitName picks the circuit of a request.  The default is the scheme and host, like "https://example.com".
+	CircuitName func(req *http.Request) string
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// IsFailure decides if a response counts against the health of its circuit.  The response is still returned to
+	// the caller.  The default counts 5xx responses as failures.
+	IsFailure func(resp *http.Response) bool
+	// Classifier, if set, decides how responses count instead of IsFailure, so 4xx responses can be bad requests
+	// that count neither for nor against the circuit.  The response is still returned to the caller.
+	Classifier *Classifier
+	// IdleTimeout is how long a host can go without requests before its circuit is removed from Manager.  The default
+	// is ten minutes.  Set to -1 to never remove circuits.
+	IdleTimeout time.Duration
+
+	// lastUsed is when each circuit created by this transport last started a request, in unix nanoseconds
+	lastUsed  sync.Map
+	lastSweep faststats.AtomicInt64
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// NewClient returns a copy of client that runs its requests in circuits created by m.  A nil client copies
+// http.DefaultClient.
+func NewClient(client *http.Client, m *circuit.Manager) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ret := *client
+	ret.Transport = &Transport{
+		Manager: m,
+		Base:    client.Transport,
+	}
+	return &ret
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *Transport) circuitName(req *http.Request) string {
+	if t.CircuitName != nil {
+		return t.CircuitName(req)
+	}
+	return req.URL.Scheme + "://" + req.URL.Host
+}
+
+// classify returns the error runFunc returns for resp, or nil if it is a success
+func (t *Transport) classify(resp *http.Response) error {
+	if t.Classifier != nil {
+		return t.Classifier.Classify(resp, nil)
+	}
+	isFailure := resp.StatusCode >= 500
+	if t.IsFailure != nil {
+		isFailure = t.IsFailure(resp)
+	}
+	if !isFailure {
+		return nil
+	}
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		Outcome:    OutcomeFailure,
+		After:      retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+func (t *Transport) idleTimeout() time.Duration {
+	if t.IdleTimeout == 0 {
+		return 10 * time.Minute
+	}
+	return t.IdleTimeout
+}
+
+// RoundTrip runs the request in its host's circuit.  Errors from the circuit, like an open circuit, are returned
+// without a response.  When the response body is closed, the request's ContentLength and the bytes of body read are
+// reported with circuit.Circuit.ReportSize.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	now := time.Now()
+	t.removeIdle(now)
+	c := t.Manager.GetOrCreateCircuit(t.circuitName(req), t.Config)
+	t.markUsed(c.Name(), now)
+
+	var resp *http.Response
+	err := c.Run(req.Context(), func(ctx context.Context) error {
+		// The circuit's context ends when Run returns, but the body is read after that.  Only let the circuit cancel
+		// the request until the response headers arrive.
+		reqCtx, cancel := context.WithCancel(req.Context())
+		stopCancelOnTimeout := context.AfterFunc(ctx, cancel)
+		var err error
+		resp, err = t.base().RoundTrip(req.WithContext(reqCtx))
+		stopCancelOnTimeout()
+		if err != nil {
+			cancel()
+			return err
+		}
+		if ctx.Err() != nil {
+			// The circuit timed out as the response arrived.  The request was canceled, so the body cannot be read.
+			cancel()
+			_ = resp.Body.Close()
+			resp = nil
+			return ctx.Err()
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel, onClose: func(read int64) {
+			c.ReportSize(req.Context(), requestSize(req), read)
+		}}
+		return t.classify(resp)
+	})
+	if _, isResponseError := err.(*ResponseError); isResponseError {
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *Transport) markUsed(name string, now time.Time) {
+	if lastUsed, exists := t.lastUsed.Load(name); exists {
+		lastUsed.(*faststats.AtomicInt64).Set(now.UnixNano())
+		return
+	}
+	lastUsed := &faststats.AtomicInt64{}
+	lastUsed.Set(now.UnixNano())
+	t.lastUsed.Store(name, lastUsed)
+}
+
+// removeIdle removes circuits with no requests for IdleTimeout from Manager.  It looks at most once per IdleTimeout.
+func (t *Transport) removeIdle(now time.Time) {
+	idleTimeout := t.idleTimeout()
+	if idleTimeout < 0 {
+		return
+	}
+	lastSweep := t.lastSweep.Get()
+	if now.UnixNano()-lastSweep < idleTimeout.Nanoseconds() || !t.lastSweep.CompareAndSwap(lastSweep, now.UnixNano()) {
+		return
+	}
+	oldest := now.Add(-idleTimeout).UnixNano()
+	t.lastUsed.Range(func(key, value interface{}) bool {
+		if value.(*faststats.AtomicInt64).Get() >= oldest {
+			return true
+		}
+		name := key.(string)
+		if c := t.Manager.GetCircuit(name); c != nil && c.ConcurrentCommands() == 0 {
+			t.Manager.RemoveCircuit(c)
+		}
+		t.lastUsed.Delete(name)
+		return true
+	})
+}
+
+// requestSize is how many bytes of body req sends, if known
+func requestSize(req *http.Request) int64 {
+	if req.ContentLength < 0 {
+		return 0
+	}
+	return req.ContentLength
+}
+
+// cancelOnClose ends the request's context once the caller is done with the body, and reports how much of the body
+// was read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel  func()
+	read    int64
+	onClose func(read int64)
+}
+
+func (c *cancelOnClose) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	if c.onClose != nil {
+		c.onClose(c.read)
+		c.onClose = nil
+	}
+	return err
+}
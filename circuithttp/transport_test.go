@@ -0,0 +1,225 @@
+package circuithttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type countingMetrics struct {
+	mu        sync.Mutex
+	successes int
+	failures  int
+	timeouts  int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeouts++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration)  {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)      {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)                {}
+
+func testServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/fail":
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(rw, "broken")
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		case "/slow-body":
+			rw.WriteHeader(http.StatusOK)
+			rw.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+			_, _ = io.WriteString(rw, "finally")
+		default:
+			_, _ = io.WriteString(rw, "hello")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp, string(body), err
+}
+
+func TestTransport(t *testing.T) {
+	server := testServer(t)
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	client := &http.Client{
+		Transport: &Transport{
+			Manager: m,
+			Config: circuit.Config{
+				Execution: circuit.ExecutionConfig{
+					Timeout: 20 * time.Millisecond,
+				},
+				Metrics: circuit.MetricsCollectors{
+					Run: []circuit.RunMetrics{metrics},
+				},
+			},
+		},
+	}
+
+	if _, body, err := get(t, client, server.URL+"/"); err != nil || body != "hello" {
+		t.Fatalf("expected hello, got %q %v", body, err)
+	}
+	if m.GetCircuit(server.URL) == nil {
+		t.Errorf("expected a circuit named %s", server.URL)
+	}
+	resp, body, err := get(t, client, server.URL+"/fail")
+	if err != nil || resp.StatusCode != http.StatusInternalServerError || body != "broken" {
+		t.Errorf("expected the failed response to be returned, got %v %q %v", resp, body, err)
+	}
+	if _, _, err := get(t, client, server.URL+"/slow"); err == nil {
+		t.Error("expected the circuit to time out")
+	}
+	if _, body, err := get(t, client, server.URL+"/slow-body"); err != nil || body != "finally" {
+		t.Errorf("expected the body to be readable after the circuit ends, got %q %v", body, err)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 2 || metrics.failures != 1 || metrics.timeouts != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestTransport_removesIdleCircuits(t *testing.T) {
+	server := testServer(t)
+	m := &circuit.Manager{}
+	client := &http.Client{
+		Transport: &Transport{
+			Manager:     m,
+			IdleTimeout: 10 * time.Millisecond,
+			CircuitName: func(req *http.Request) string {
+				return req.URL.Path
+			},
+		},
+	}
+	if _, _, err := get(t, client, server.URL+"/old"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, _, err := get(t, client, server.URL+"/new"); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetCircuit("/old") != nil || m.GetCircuit("/new") == nil {
+		t.Errorf("expected only the idle circuit to be removed, got %v", m.AllCircuits())
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := testServer(t)
+	m := &circuit.Manager{}
+	client := NewClient(nil, m)
+	if client == http.DefaultClient {
+		t.Fatal("expected a copy of the default client")
+	}
+	if _, body, err := get(t, client, server.URL+"/"); err != nil || body != "hello" {
+		t.Fatalf("expected hello, got %q %v", body, err)
+	}
+	if len(m.AllCircuits()) != 1 {
+		t.Errorf("expected one circuit, got %d", len(m.AllCircuits()))
+	}
+}
+
+func TestTransport_retryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Retry-After", "30")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	var hint time.Duration
+	client := &http.Client{
+		Transport: &Transport{
+			Manager: &circuit.Manager{},
+			Config: circuit.Config{
+				Execution: circuit.ExecutionConfig{
+					RetryAfterClassifier: func(err error) time.Duration {
+						hint = circuit.RetryAfterOf(err)
+						return hint
+					},
+				},
+			},
+		},
+	}
+	if _, _, err := get(t, client, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if hint != 30*time.Second {
+		t.Errorf("expected the Retry-After header as a hint, got %s", hint)
+	}
+}
+
+type sizeMetrics struct {
+	countingMetrics
+	sent     int64
+	received int64
+}
+
+func (s *sizeMetrics) Size(_ context.Context, _ time.Time, sent int64, received int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent += sent
+	s.received += received
+}
+
+func TestTransport_size(t *testing.T) {
+	server := testServer(t)
+	metrics := &sizeMetrics{}
+	client := &http.Client{
+		Transport: &Transport{
+			Manager: &circuit.Manager{},
+			Config: circuit.Config{
+				Metrics: circuit.MetricsCollectors{
+					Run: []circuit.RunMetrics{metrics},
+				},
+			},
+		},
+	}
+	resp, err := client.Post(server.URL+"/", "text/plain", strings.NewReader("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.sent != int64(len("ping")) || metrics.received != int64(len("hello")) {
+		t.Errorf("expected 4 bytes sent and 5 received, got %d and %d", metrics.sent, metrics.received)
+	}
+}
@@ -0,0 +1,6 @@
+/*
+Package circuitjob runs periodic jobs in a circuit.  A job that keeps failing opens its circuit, which skips its runs
+until the circuit lets one through to test it, and its health shows up in the same metrics and alerts as any other
+circuit.
+*/
+package circuitjob
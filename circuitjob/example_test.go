@@ -0,0 +1,34 @@
+package circuitjob_test
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitjob"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// This example refreshes a cache every minute, and stops trying while the refresh keeps failing
+func ExampleJob() {
+	f := hystrix.Factory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.Configure},
+	}
+	job := &circuitjob.Job{
+		Circuit:  m.MustCreateCircuit("refresh-cache"),
+		Interval: time.Minute,
+		Do: func(ctx context.Context) error {
+			// Reload the cache
+			return nil
+		},
+		OnError: func(err error) {
+			log.Println("cache refresh failed:", err)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_ = job.Run(ctx)
+	// Output:
+}
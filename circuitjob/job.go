@@ -0,0 +1,47 @@
+package circuitjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Job calls Do every Interval inside Circuit.  Runs never overlap: if Do takes longer than Interval, the runs it
+// covered are skipped.  While the circuit is open, runs are short circuited and Do is not called.
+type Job struct {
+	// Circuit runs Do.  Its timeout bounds each run.
+	Circuit *circuit.Circuit
+	// Interval is the time between the starts of runs
+	Interval time.Duration
+	// Do is the job
+	Do func(ctx context.Context) error
+	// OnError, if set, is called with the error of each run that fails, including runs short circuited by an open
+	// circuit
+	OnError func(err error)
+	// RunOnStart runs the job when Run is called, instead of waiting for the first Interval
+	RunOnStart bool
+}
+
+// Run calls the job on schedule until ctx ends, then returns ctx's error
+func (j *Job) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	if j.RunOnStart {
+		j.runOnce(ctx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+func (j *Job) runOnce(ctx context.Context) {
+	if err := j.Circuit.Run(ctx, j.Do); err != nil && j.OnError != nil && ctx.Err() == nil {
+		j.OnError(err)
+	}
+}
@@ -0,0 +1,73 @@
+package circuitjob
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func TestJob(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("TestJob", circuit.Config{
+		General: circuit.GeneralConfig{
+			ClosedToOpenFactory: hystrix.OpenerFactory(hystrix.ConfigureOpener{
+				RequestVolumeThreshold:   2,
+				ErrorThresholdPercentage: 1,
+			}),
+			OpenToClosedFactory: hystrix.CloserFactory(hystrix.ConfigureCloser{
+				SleepWindow: time.Hour,
+			}),
+		},
+	})
+	var calls, shortCircuits atomic.Int64
+	job := &Job{
+		Circuit:  c,
+		Interval: time.Millisecond,
+		Do: func(_ context.Context) error {
+			calls.Add(1)
+			return errors.New("failed")
+		},
+		OnError: func(err error) {
+			var circuitErr circuit.Error
+			if errors.As(err, &circuitErr) && circuitErr.CircuitOpen() {
+				shortCircuits.Add(1)
+			}
+		},
+		RunOnStart: true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := job.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Run to stop with the context, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the circuit to open after two failed runs, got %d runs", calls.Load())
+	}
+	if shortCircuits.Load() == 0 {
+		t.Error("expected later runs to be short circuited")
+	}
+}
+
+func TestJob_RunOnStart(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("TestJob_RunOnStart", circuit.Config{})
+	var calls atomic.Int64
+	job := &Job{
+		Circuit:  c,
+		Interval: time.Hour,
+		Do: func(_ context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+		RunOnStart: true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = job.Run(ctx)
+	if calls.Load() != 1 {
+		t.Errorf("expected one run on start, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,114 @@
+package circuitmongo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Outcome is how an operation counts towards the health of its circuit
+type Outcome int
+
+const (
+	// OutcomeSuccess counts the operation as a success, like a find with no documents
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure counts the operation as a failure, like a server selection timeout
+	OutcomeFailure
+	// OutcomeBadRequest is the caller's fault, like a duplicate key, and does not count against the circuit
+	OutcomeBadRequest
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeBadRequest:
+		return "bad request"
+	}
+	return "Outcome(" + strconv.Itoa(int(o)) + ")"
+}
+
+// failureCodes are server error codes that mean the deployment is unhealthy, not that the operation was wrong.  Every
+// other server error is a bad request.
+var failureCodes = map[int]struct{}{
+	6:     {}, // HostUnreachable
+	7:     {}, // HostNotFound
+	89:    {}, // NetworkTimeout
+	91:    {}, // ShutdownInProgress
+	134:   {}, // ReadConcernMajorityNotAvailableYet
+	189:   {}, // PrimarySteppedDown
+	262:   {}, // ExceededTimeLimit
+	462:   {}, // IngressRequestRateLimitExceeded
+	9001:  {}, // SocketException
+	10107: {}, // NotWritablePrimary
+	11600: {}, // InterruptedAtShutdown
+	11602: {}, // InterruptedDueToReplStateChange
+	13435: {}, // NotPrimaryNoSecondaryOk
+	13436: {}, // NotPrimaryOrSecondary
+}
+
+// Classifier maps driver errors to circuit outcomes.  By default server selection errors, timeouts, network errors and
+// server errors like NotWritablePrimary are failures.  Server errors that are the operation's fault, like a duplicate
+// key or a failed document validation, are bad requests, and so are canceled contexts.  mongo.ErrNoDocuments is a
+// success.  Other errors from the driver are failures.  The zero value is ready to use.
+type Classifier struct {
+	// Overrides replaces the default outcome of server errors with the codes in it
+	Overrides map[int]Outcome
+}
+
+// DefaultClassifier is a Classifier without overrides
+var DefaultClassifier = Classifier{}
+
+// Outcome returns how an operation that ended with err counts
+func (c Classifier) Outcome(err error) Outcome {
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) {
+		return OutcomeSuccess
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range serverErr.ErrorCodes() {
+			if outcome, exists := c.Overrides[code]; exists {
+				return outcome
+			}
+		}
+	}
+	if errors.As(err, &topology.ServerSelectionError{}) || mongo.IsTimeout(err) || mongo.IsNetworkError(err) {
+		return OutcomeFailure
+	}
+	if errors.Is(err, context.Canceled) || errors.As(err, &mongo.MarshalError{}) {
+		return OutcomeBadRequest
+	}
+	if serverErr != nil {
+		for _, code := range serverErr.ErrorCodes() {
+			if _, isFailure := failureCodes[code]; isFailure {
+				return OutcomeFailure
+			}
+		}
+		return OutcomeBadRequest
+	}
+	return OutcomeFailure
+}
+
+// Classify returns the error a run function should return for an operation that ended with err.  Bad requests are
+// wrapped in circuit.SimpleBadRequest, and successes return nil.
+//
+//	err := c.Run(ctx, func(ctx context.Context) error {
+//		_, err := collection.InsertOne(ctx, doc)
+//		return circuitmongo.DefaultClassifier.Classify(err)
+//	})
+func (c Classifier) Classify(err error) error {
+	switch c.Outcome(err) {
+	case OutcomeSuccess:
+		return nil
+	case OutcomeBadRequest:
+		return circuit.SimpleBadRequest{Err: err}
+	}
+	return err
+}
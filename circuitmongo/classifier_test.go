@@ -0,0 +1,65 @@
+package circuitmongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestClassifier_Outcome(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected Outcome
+	}{
+		{name: "nil", err: nil, expected: OutcomeSuccess},
+		{name: "no documents", err: mongo.ErrNoDocuments, expected: OutcomeSuccess},
+		{name: "server selection", err: topology.ServerSelectionError{Wrapped: errors.New("no servers")}, expected: OutcomeFailure},
+		{name: "deadline", err: context.DeadlineExceeded, expected: OutcomeFailure},
+		{name: "canceled", err: context.Canceled, expected: OutcomeBadRequest},
+		{name: "network", err: mongo.CommandError{Labels: []string{"NetworkError"}}, expected: OutcomeFailure},
+		{name: "duplicate key", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}, expected: OutcomeBadRequest},
+		{name: "validation", err: mongo.CommandError{Code: 121}, expected: OutcomeBadRequest},
+		{name: "stepped down", err: mongo.CommandError{Code: 189}, expected: OutcomeFailure},
+		{name: "not primary", err: mongo.WriteException{WriteConcernError: &mongo.WriteConcernError{Code: 10107}}, expected: OutcomeFailure},
+		{name: "unknown", err: errors.New("driver error"), expected: OutcomeFailure},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if outcome := DefaultClassifier.Outcome(tc.err); outcome != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, outcome)
+			}
+		})
+	}
+}
+
+func TestClassifier_Overrides(t *testing.T) {
+	c := Classifier{
+		Overrides: map[int]Outcome{
+			11000: OutcomeFailure,
+		},
+	}
+	if outcome := c.Outcome(mongo.CommandError{Code: 11000}); outcome != OutcomeFailure {
+		t.Errorf("expected the override, got %v", outcome)
+	}
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	if err := DefaultClassifier.Classify(mongo.ErrNoDocuments); err != nil {
+		t.Errorf("expected no documents to be a success, got %v", err)
+	}
+	dup := mongo.CommandError{Code: 11000}
+	if err, ok := DefaultClassifier.Classify(dup).(circuit.SimpleBadRequest); !ok || !mongo.IsDuplicateKeyError(err.Err) {
+		t.Errorf("expected a bad request wrapping the error, got %v", err)
+	}
+	down := mongo.CommandError{Code: 91}
+	if err := DefaultClassifier.Classify(down); circuit.IsBadRequest(err) {
+		t.Errorf("expected a failure, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Package circuitmongo runs MongoDB driver operations in circuits, one per operation type.  It is a separate module so
+the circuit module does not depend on the driver.
+
+Operations.Run runs an operation in its circuit, so the circuit can reject it:
+
+	ops := &circuitmongo.Operations{Manager: manager}
+	err := ops.Run(ctx, "insert", func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, doc)
+		return err
+	})
+
+Operations.CommandMonitor tracks the health of every command the client sends, without changing the calling code.
+It cannot reject commands, but the circuits it feeds can be checked, or used by Run.
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri).SetMonitor(ops.CommandMonitor()))
+*/
+package circuitmongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Operations runs MongoDB operations in a circuit per operation type, like "find" or "insert"
+type Operations struct {
+	// Manager creates and tracks the circuits
+	Manager *circuit.Manager
+	// Config is given to Manager.GetOrCreateCircuit when a circuit is created
+	Config circuit.Config
+	// CircuitName picks the circuit of an operation.  The default is "mongodb." and the operation, like
+	// "mongodb.find".
+	CircuitName func(operation string) string
+	// Classifier decides how errors count.  The default is DefaultClassifier.
+	Classifier *Classifier
+}
+
+func (o *Operations) circuitName(operation string) string {
+	if o.CircuitName != nil {
+		return o.CircuitName(operation)
+	}
+	return "mongodb." + operation
+}
+
+func (o *Operations) classifier() *Classifier {
+	if o.Classifier == nil {
+		return &DefaultClassifier
+	}
+	return o.Classifier
+}
+
+// runningKey marks the context of operations run by Run, so CommandMonitor does not count them twice
+type runningKey struct{}
+
+// Run runs fn in the circuit of operation.  The circuit's timeout cancels fn's context.  The error of fn is returned
+// as the driver returned it, so helpers like mongo.IsDuplicateKeyError still work.  Errors from the circuit, like an
+// open circuit, are returned when fn never ran.
+func (o *Operations) Run(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	c := o.Manager.GetOrCreateCircuit(o.circuitName(operation), o.Config)
+	var fnErr error
+	err := c.Run(ctx, func(ctx context.Context) error {
+		fnErr = fn(context.WithValue(ctx, runningKey{}, o))
+		return o.classifier().Classify(fnErr)
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// CommandMonitor returns a monitor that records each command the client sends in the circuit of its command name.
+// Commands sent from inside Run are already counted, and are skipped.  Set it with options.ClientOptions.SetMonitor.
+func (o *Operations) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			o.record(ctx, evt.CommandName, evt.Duration, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			o.record(ctx, evt.CommandName, evt.Duration, evt.Failure)
+		},
+	}
+}
+
+// record counts a command sent outside Run towards its circuit
+func (o *Operations) record(ctx context.Context, command string, duration time.Duration, err error) {
+	if ctx.Value(runningKey{}) == o {
+		return
+	}
+	c := o.Manager.GetOrCreateCircuit(o.circuitName(command), o.Config)
+	if classified := o.classifier().Classify(err); classified != nil {
+		c.RecordFailure(ctx, duration, classified)
+		return
+	}
+	c.RecordSuccess(ctx, duration)
+}
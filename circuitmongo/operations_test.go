@@ -0,0 +1,125 @@
+package circuitmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type countingMetrics struct {
+	mu          sync.Mutex
+	successes   int
+	failures    int
+	badRequests int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.badRequests++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)   {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)     {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)               {}
+
+func TestOperations_Run(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	ops := &Operations{
+		Manager: m,
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	}
+	ctx := context.Background()
+	if err := ops.Run(ctx, "find", func(_ context.Context) error {
+		return mongo.ErrNoDocuments
+	}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("expected the driver's error, got %v", err)
+	}
+	if m.GetCircuit("mongodb.find") == nil {
+		t.Error("expected a circuit named after the operation")
+	}
+	err := ops.Run(ctx, "insert", func(_ context.Context) error {
+		return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("expected the duplicate key error, got %v", err)
+	}
+	if err := ops.Run(ctx, "insert", func(_ context.Context) error {
+		return mongo.CommandError{Code: 91}
+	}); err == nil {
+		t.Error("expected the shutdown error")
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 1 || metrics.badRequests != 1 || metrics.failures != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestOperations_open(t *testing.T) {
+	m := &circuit.Manager{}
+	ops := &Operations{Manager: m}
+	m.MustCreateCircuit("mongodb.find").OpenCircuit(context.Background())
+	called := false
+	err := ops.Run(context.Background(), "find", func(_ context.Context) error {
+		called = true
+		return nil
+	})
+	var circuitErr circuit.Error
+	if called || !errors.As(err, &circuitErr) || !circuitErr.CircuitOpen() {
+		t.Errorf("expected the open circuit to reject the operation, got %v", err)
+	}
+}
+
+func TestOperations_CommandMonitor(t *testing.T) {
+	metrics := &countingMetrics{}
+	ops := &Operations{
+		Manager: &circuit.Manager{},
+		Config: circuit.Config{
+			Metrics: circuit.MetricsCollectors{
+				Run: []circuit.RunMetrics{metrics},
+			},
+		},
+	}
+	monitor := ops.CommandMonitor()
+	ctx := context.Background()
+	finished := event.CommandFinishedEvent{CommandName: "find", Duration: time.Millisecond}
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: mongo.CommandError{Code: 189}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: mongo.CommandError{Code: 2}})
+	// Commands sent from inside Run are counted by Run
+	_ = ops.Run(ctx, "find", func(ctx context.Context) error {
+		monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		return nil
+	})
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 2 || metrics.failures != 1 || metrics.badRequests != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Package circuitnotify sends a message when a circuit opens or closes, through a webhook, Slack or email.  It is for
+teams without an alerting stack, who still want to hear when a breaker fires.  Notifications are rate limited per
+circuit, so a flapping circuit does not flood a channel.
+*/
+package circuitnotify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// State is what a circuit did
+type State string
+
+const (
+	// StateOpened is a circuit that opened
+	StateOpened State = "opened"
+	// StateClosed is a circuit that closed
+	StateClosed State = "closed"
+)
+
+// Notification is a circuit that opened or closed
+type Notification struct {
+	Circuit string    `json:"circuit"`
+	State   State     `json:"state"`
+	Time    time.Time `json:"time"`
+	// Instance is Notifier.Instance, so notifications from many processes can be told apart
+	Instance string `json:"instance,omitempty"`
+	// Suppressed counts the changes of the circuit that were not sent, because of Notifier.MinInterval, since the last
+	// notification
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Text is the notification as a short message, like "circuit db opened on host-1"
+func (n Notification) Text() string {
+	msg := fmt.Sprintf("circuit %s %s", n.Circuit, n.State)
+	if n.Instance != "" {
+		msg += " on " + n.Instance
+	}
+	msg += " at " + n.Time.UTC().Format(time.RFC3339)
+	if n.Suppressed > 0 {
+		msg += fmt.Sprintf(" (%d more changes not sent)", n.Suppressed)
+	}
+	return msg
+}
+
+// Sender delivers a notification, like a webhook or an email
+type Sender interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// SenderFunc is a function that implements Sender
+type SenderFunc func(ctx context.Context, n Notification) error
+
+// Send calls the function
+func (s SenderFunc) Send(ctx context.Context, n Notification) error {
+	return s(ctx, n)
+}
+
+// pendingCheckInterval is how often notifications held back by MinInterval are looked at
+const pendingCheckInterval = time.Second
+
+// Notifier sends notifications for the circuits it is attached to.  Attach it with CreateConfig, and call Start so
+// notifications are sent.  Notifications are sent in the background, so a slow sender never slows a circuit down.
+//
+// After a circuit's notification is sent, its changes for the next MinInterval are held back.  Once MinInterval
+// passes, the last of them is sent, with the count of the changes that were not, unless the circuit is back in the
+// state that was last sent.
+type Notifier struct {
+	Sender Sender
+	// Instance names this process in each notification, like a host name
+	Instance string
+	// MinInterval is the least time between two notifications of a circuit.  The default is five minutes.  Set to -1
+	// to send every change.
+	MinInterval time.Duration
+	// BufferSize is how many changes can wait to be looked at.  The default is 1024.
+	BufferSize int
+	// SendTimeout limits each call to Send.  The default is ten seconds.
+	SendTimeout time.Duration
+	// OnError, if set, is called with notifications that could not be sent
+	OnError func(n Notification, err error)
+
+	// Dropped counts changes that were not looked at because the buffer was full
+	Dropped faststats.AtomicInt64
+
+	changes   chan Notification
+	closeChan chan struct{}
+	once      sync.Once
+	// circuits is only used by Start
+	circuits map[string]*circuitNotifications
+}
+
+// circuitNotifications is what was sent, and is held back, for one circuit
+type circuitNotifications struct {
+	lastSent      time.Time
+	lastSentState State
+	pending       *Notification
+	suppressed    int
+}
+
+func (n *Notifier) doOnce() {
+	bufferSize := n.BufferSize
+	if bufferSize == 0 {
+		bufferSize = 1024
+	}
+	n.changes = make(chan Notification, bufferSize)
+	n.closeChan = make(chan struct{})
+	n.circuits = make(map[string]*circuitNotifications)
+}
+
+func (n *Notifier) minInterval() time.Duration {
+	if n.MinInterval == 0 {
+		return 5 * time.Minute
+	}
+	return n.MinInterval
+}
+
+func (n *Notifier) sendTimeout() time.Duration {
+	if n.SendTimeout == 0 {
+		return 10 * time.Second
+	}
+	return n.SendTimeout
+}
+
+// CreateConfig is a config factory that sends notifications for the circuit.  Add it to
+// circuit.Manager.DefaultCircuitProperties.
+func (n *Notifier) CreateConfig(circuitName string) circuit.Config {
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Circuit: []circuit.Metrics{&circuitNotifier{
+				notifier:    n,
+				circuitName: circuitName,
+			}},
+		},
+	}
+}
+
+// Start should be called once per Notifier.  It sends notifications until Close is called, then sends the
+// notifications that are still waiting or held back before it returns.
+func (n *Notifier) Start() error {
+	n.once.Do(n.doOnce)
+	ticker := time.NewTicker(pendingCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case change := <-n.changes:
+			n.handle(change, time.Now())
+		case now := <-ticker.C:
+			n.sendPending(now, false)
+		case <-n.closeChan:
+			for {
+				select {
+				case change := <-n.changes:
+					n.handle(change, time.Now())
+				default:
+					n.sendPending(time.Now(), true)
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Close ends the Start function
+func (n *Notifier) Close() error {
+	n.once.Do(n.doOnce)
+	close(n.closeChan)
+	return nil
+}
+
+// queue queues a change to be looked at, dropping it if the buffer is full
+func (n *Notifier) queue(change Notification) {
+	n.once.Do(n.doOnce)
+	change.Instance = n.Instance
+	select {
+	case n.changes <- change:
+	default:
+		n.Dropped.Add(1)
+	}
+}
+
+// handle sends change, or holds it back if its circuit was notified less than MinInterval ago
+func (n *Notifier) handle(change Notification, now time.Time) {
+	c, exists := n.circuits[change.Circuit]
+	if !exists {
+		c = &circuitNotifications{}
+		n.circuits[change.Circuit] = c
+	}
+	if minInterval := n.minInterval(); minInterval > 0 && !c.lastSent.IsZero() && now.Sub(c.lastSent) < minInterval {
+		if c.pending != nil {
+			c.suppressed++
+		}
+		c.pending = &change
+		return
+	}
+	n.sendFor(c, change, now)
+}
+
+// sendPending sends the changes held back for MinInterval, or all of them if force is true
+func (n *Notifier) sendPending(now time.Time, force bool) {
+	for name, c := range n.circuits {
+		if c.pending == nil {
+			if now.Sub(c.lastSent) >= n.minInterval() {
+				delete(n.circuits, name)
+			}
+			continue
+		}
+		if !force && now.Sub(c.lastSent) < n.minInterval() {
+			continue
+		}
+		pending := *c.pending
+		c.pending = nil
+		if pending.State == c.lastSentState {
+			// The circuit is back where it was last said to be
+			c.suppressed++
+			continue
+		}
+		n.sendFor(c, pending, now)
+	}
+}
+
+func (n *Notifier) sendFor(c *circuitNotifications, change Notification, now time.Time) {
+	change.Suppressed = c.suppressed
+	c.suppressed = 0
+	c.lastSent = now
+	c.lastSentState = change.State
+	ctx, cancel := context.WithTimeout(context.Background(), n.sendTimeout())
+	err := n.Sender.Send(ctx, change)
+	cancel()
+	if err != nil && n.OnError != nil {
+		n.OnError(change, err)
+	}
+}
+
+// circuitNotifier sends the changes of one circuit to a Notifier
+type circuitNotifier struct {
+	notifier    *Notifier
+	circuitName string
+}
+
+var _ circuit.Metrics = &circuitNotifier{}
+
+func (c *circuitNotifier) Closed(_ context.Context, now time.Time) {
+	c.notifier.queue(Notification{Circuit: c.circuitName, State: StateClosed, Time: now})
+}
+
+func (c *circuitNotifier) Opened(_ context.Context, now time.Time) {
+	c.notifier.queue(Notification{Circuit: c.circuitName, State: StateOpened, Time: now})
+}
@@ -0,0 +1,123 @@
+package circuitnotify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (r *recordingSender) Send(_ context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{
+		Sender:      sender,
+		Instance:    "host-1",
+		MinInterval: -1,
+	}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{n.CreateConfig},
+	}
+	c := m.MustCreateCircuit("db")
+	c.OpenCircuit(context.Background())
+	c.CloseCircuit(context.Background())
+	go func() {
+		_ = n.Close()
+	}()
+	if err := n.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 2 || sender.sent[0].State != StateOpened || sender.sent[1].State != StateClosed {
+		t.Fatalf("expected open then close, got %+v", sender.sent)
+	}
+	if sender.sent[0].Circuit != "db" || sender.sent[0].Instance != "host-1" {
+		t.Errorf("unexpected notification %+v", sender.sent[0])
+	}
+}
+
+func TestNotifier_MinInterval(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{
+		Sender:      sender,
+		MinInterval: time.Minute,
+	}
+	n.once.Do(n.doOnce)
+	now := time.Now()
+	n.handle(Notification{Circuit: "db", State: StateOpened}, now)
+	n.handle(Notification{Circuit: "db", State: StateClosed}, now.Add(time.Second))
+	n.handle(Notification{Circuit: "db", State: StateOpened}, now.Add(2*time.Second))
+	n.handle(Notification{Circuit: "db", State: StateClosed}, now.Add(3*time.Second))
+	n.handle(Notification{Circuit: "cache", State: StateOpened}, now.Add(3*time.Second))
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected the first change of each circuit to be sent, got %+v", sender.sent)
+	}
+	n.sendPending(now.Add(30*time.Second), false)
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected changes to be held back for MinInterval, got %+v", sender.sent)
+	}
+	n.sendPending(now.Add(time.Minute), false)
+	if len(sender.sent) != 3 {
+		t.Fatalf("expected the last held back change to be sent, got %+v", sender.sent)
+	}
+	if last := sender.sent[2]; last.State != StateClosed || last.Suppressed != 2 {
+		t.Errorf("expected the close with 2 changes not sent, got %+v", last)
+	}
+}
+
+func TestNotifier_MinIntervalBackToLastSent(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{
+		Sender:      sender,
+		MinInterval: time.Minute,
+	}
+	n.once.Do(n.doOnce)
+	now := time.Now()
+	n.handle(Notification{Circuit: "db", State: StateOpened}, now)
+	n.handle(Notification{Circuit: "db", State: StateClosed}, now.Add(time.Second))
+	n.handle(Notification{Circuit: "db", State: StateOpened}, now.Add(2*time.Second))
+	n.sendPending(now.Add(time.Minute), false)
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected nothing new to be sent for a circuit back where it was, got %+v", sender.sent)
+	}
+	n.handle(Notification{Circuit: "db", State: StateClosed}, now.Add(2*time.Minute))
+	if len(sender.sent) != 2 || sender.sent[1].Suppressed != 2 {
+		t.Errorf("expected the next change to count the ones not sent, got %+v", sender.sent)
+	}
+}
+
+func TestNotifier_OnError(t *testing.T) {
+	var failed []Notification
+	n := &Notifier{
+		Sender: SenderFunc(func(_ context.Context, _ Notification) error {
+			return errors.New("webhook down")
+		}),
+		OnError: func(n Notification, _ error) {
+			failed = append(failed, n)
+		},
+	}
+	c := circuit.NewCircuitFromConfig("TestNotifier_OnError", n.CreateConfig("TestNotifier_OnError"))
+	c.OpenCircuit(context.Background())
+	go func() {
+		_ = n.Close()
+	}()
+	if err := n.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].State != StateOpened {
+		t.Errorf("expected the open notification to fail, got %+v", failed)
+	}
+}
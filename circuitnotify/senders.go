@@ -0,0 +1,138 @@
+package circuitnotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+)
+
+// Webhook posts each notification as JSON to URL
+type Webhook struct {
+	URL string
+	// Client makes the requests.  The default is http.DefaultClient.
+	Client *http.Client
+	// Header is added to each request, like an Authorization header
+	Header http.Header
+}
+
+var _ Sender = &Webhook{}
+
+// Send posts n as JSON.  Responses that are not 2xx are errors.
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.Client, w.URL, w.Header, n)
+}
+
+// Slack posts each notification to a Slack incoming webhook
+type Slack struct {
+	// WebhookURL is the incoming webhook of the channel
+	WebhookURL string
+	// Client makes the requests.  The default is http.DefaultClient.
+	Client *http.Client
+}
+
+var _ Sender = &Slack{}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Send posts the Text of n, with an emoji for its state
+func (s *Slack) Send(ctx context.Context, n Notification) error {
+	emoji := ":white_check_mark:"
+	if n.State == StateOpened {
+		emoji = ":rotating_light:"
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, slackMessage{Text: emoji + " " + n.Text()})
+}
+
+// postJSON posts body to rawURL.  Errors only name the scheme and host of rawURL, since webhook URLs, like Slack's,
+// often carry a secret in their path or query.
+func postJSON(ctx context.Context, client *http.Client, rawURL string, header http.Header, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	redacted := redactURL(rawURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		// The error quotes the URL
+		return fmt.Errorf("circuitnotify: invalid URL for %s", redacted)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redacted
+		}
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("circuitnotify: %s answered %d", redacted, resp.StatusCode)
+	}
+	return nil
+}
+
+// redactURL returns the scheme and host of rawURL, without credentials, path, or query
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Email sends each notification as a plain text email through an SMTP server.  The context of Send is not respected
+// by net/smtp, so a stuck server can hold up the notifications after it.
+type Email struct {
+	// Addr is the SMTP server, like "smtp.example.com:587"
+	Addr string
+	// Auth, if set, authenticates with the server, like smtp.PlainAuth
+	Auth smtp.Auth
+	From string
+	To   []string
+	// SendMail sends the message.  The default is smtp.SendMail.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+var _ Sender = &Email{}
+
+// Send emails the Text of n
+func (e *Email) Send(_ context.Context, n Notification) error {
+	sendMail := e.SendMail
+	if sendMail == nil {
+		sendMail = smtp.SendMail
+	}
+	return sendMail(e.Addr, e.Auth, e.From, e.To, e.message(n))
+}
+
+// headerValue removes line breaks from a header value
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+func (e *Email) message(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	// Circuit names come from callers, so keep them from adding headers
+	fmt.Fprintf(&b, "Subject: circuit %s %s\r\n", headerValue.Replace(n.Circuit), n.State)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(n.Text())
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,96 @@
+package circuitnotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	var got Notification
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		auth = req.Header.Get("Authorization")
+		_ = json.NewDecoder(req.Body).Decode(&got)
+	}))
+	defer server.Close()
+	w := &Webhook{URL: server.URL, Header: http.Header{"Authorization": []string{"Bearer token"}}}
+	n := Notification{Circuit: "db", State: StateOpened, Time: time.Now().UTC().Truncate(time.Second)}
+	if err := w.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if got != n || auth != "Bearer token" {
+		t.Errorf("expected the notification with the header, got %+v %q", got, auth)
+	}
+}
+
+func TestWebhook_errorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	w := &Webhook{URL: server.URL}
+	if err := w.Send(context.Background(), Notification{}); err == nil {
+		t.Error("expected a 502 to be an error")
+	}
+}
+
+func TestSlack_errorsHideURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	secret := "/services/T000/B000/secret-token?key=secret-key"
+	for _, webhookURL := range []string{server.URL + secret, "http://127.0.0.1:0" + secret, "http://user:secret-pass@[::1" + secret} {
+		s := &Slack{WebhookURL: webhookURL}
+		err := s.Send(context.Background(), Notification{})
+		if err == nil {
+			t.Fatalf("expected an error posting to %s", webhookURL)
+		}
+		if msg := err.Error(); strings.Contains(msg, "secret") {
+			t.Errorf("expected the error to leave out the webhook's path, query and credentials, got %q", msg)
+		}
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var got slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		_ = json.NewDecoder(req.Body).Decode(&got)
+	}))
+	defer server.Close()
+	s := &Slack{WebhookURL: server.URL}
+	n := Notification{Circuit: "db", State: StateOpened, Instance: "host-1", Time: time.Now(), Suppressed: 3}
+	if err := s.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got.Text, ":rotating_light: circuit db opened on host-1") || !strings.Contains(got.Text, "3 more changes") {
+		t.Errorf("unexpected message %q", got.Text)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	var msg string
+	var to []string
+	e := &Email{
+		Addr: "smtp.example.com:587",
+		From: "breakers@example.com",
+		To:   []string{"oncall@example.com"},
+		SendMail: func(_ string, _ smtp.Auth, _ string, recipients []string, m []byte) error {
+			to = recipients
+			msg = string(m)
+			return nil
+		},
+	}
+	if err := e.Send(context.Background(), Notification{Circuit: "db\r\nBcc: x@example.com", State: StateClosed, Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(to) != 1 || !strings.Contains(msg, "Subject: circuit dbBcc: x@example.com closed\r\n") {
+		t.Errorf("unexpected email to %v: %q", to, msg)
+	}
+}
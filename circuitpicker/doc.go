@@ -0,0 +1,9 @@
+/*
+Package circuitpicker picks which host of a pool to send a request to, skipping hosts whose circuit is open.  Use it
+in a gRPC balancer's picker or a hand written HTTP pool, with a circuit per host like circuithttp.Transport makes.
+
+Among the hosts that can take requests, two are picked at random and the one running fewer commands wins.  This
+"power of two choices" spreads load almost as well as looking at every host, without every picker piling onto the
+same least loaded host.
+*/
+package circuitpicker
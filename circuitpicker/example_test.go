@@ -0,0 +1,20 @@
+package circuitpicker_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitpicker"
+)
+
+// This example sends requests to a pool of hosts, skipping the host whose circuit is open
+func ExamplePicker() {
+	var m circuit.Manager
+	m.MustCreateCircuit("10.0.0.1:8080")
+	m.MustCreateCircuit("10.0.0.2:8080").OpenCircuit(context.Background())
+	p := circuitpicker.Picker{Manager: &m}
+	host, err := p.Pick([]string{"10.0.0.2:8080", "10.0.0.1:8080"})
+	fmt.Println(host, err)
+	// Output: 10.0.0.1:8080 <nil>
+}
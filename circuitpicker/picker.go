@@ -0,0 +1,126 @@
+package circuitpicker
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// ErrNoHealthyHosts is returned when every host's circuit is open
+var ErrNoHealthyHosts = errors.New("circuitpicker: no host can take requests")
+
+// Picker picks hosts whose circuits, in Manager, are closed
+type Picker struct {
+	// Manager has the circuit of each host
+	Manager *circuit.Manager
+	// CircuitName gives the name of a host's circuit.  The default is the host itself.
+	CircuitName func(host string) string
+	// Now is used to see if an open circuit is ready for a half open request.  The default is time.Now.
+	Now func() time.Time
+	// Rand picks between equally good hosts.  The default is a math/rand source seeded with the current time.  Use
+	// circuit.NewRand for reproducible picks.
+	Rand circuit.Rand
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Pick returns the host to send a request to.  It returns ErrNoHealthyHosts if every host's circuit is open.
+func (p *Picker) Pick(hosts []string) (string, error) {
+	idx, err := p.PickIndex(len(hosts), func(i int) string {
+		return hosts[i]
+	})
+	if err != nil {
+		return "", err
+	}
+	return hosts[idx], nil
+}
+
+// PickIndex is Pick for any list of n endpoints.  host returns the host of the endpoint at an index.  It returns the
+// index of the endpoint to send a request to.
+//
+// A host can take requests if its circuit is closed, or does not exist yet.  An open circuit whose OpenToClose
+// implements circuit.HalfOpenScheduler can also take requests once its next half open request is allowed, so the
+// circuit gets the request it needs to close again.
+func (p *Picker) PickIndex(n int, host func(i int) string) (int, error) {
+	now := p.now()
+	first, second := -1, -1
+	var firstLoad, secondLoad int64
+	available := 0
+	for i := 0; i < n; i++ {
+		load, ok := p.load(host(i), now)
+		if !ok {
+			continue
+		}
+		available++
+		// Reservoir sample two hosts, so hosts that cannot take requests are never picked and nothing is allocated
+		switch {
+		case available == 1:
+			first, firstLoad = i, load
+		case available == 2:
+			second, secondLoad = i, load
+		default:
+			if j := p.intn(available); j == 0 {
+				first, firstLoad = i, load
+			} else if j == 1 {
+				second, secondLoad = i, load
+			}
+		}
+	}
+	if first == -1 {
+		return -1, ErrNoHealthyHosts
+	}
+	if second == -1 || firstLoad < secondLoad {
+		return first, nil
+	}
+	if secondLoad < firstLoad {
+		return second, nil
+	}
+	// Break ties at random, so an idle pool does not always pick the same host
+	if p.intn(2) == 0 {
+		return first, nil
+	}
+	return second, nil
+}
+
+// load returns how many commands a host's circuit is running, and false if the host cannot take requests
+func (p *Picker) load(host string, now time.Time) (int64, bool) {
+	name := host
+	if p.CircuitName != nil {
+		name = p.CircuitName(host)
+	}
+	c := p.Manager.GetCircuit(name)
+	if c == nil {
+		return 0, true
+	}
+	if !c.IsOpen() {
+		return c.ConcurrentCommands(), true
+	}
+	nextProbe := c.StateInfo().NextProbe
+	if nextProbe.IsZero() || now.Before(nextProbe) {
+		return 0, false
+	}
+	return c.ConcurrentCommands(), true
+}
+
+func (p *Picker) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+func (p *Picker) intn(n int) int {
+	if p.Rand != nil {
+		return int(p.Rand.Int63n(int64(n)))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rand == nil {
+		p.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return p.rand.Intn(n)
+}
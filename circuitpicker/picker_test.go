@@ -0,0 +1,141 @@
+package circuitpicker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func TestPicker_SkipsOpenHosts(t *testing.T) {
+	var m circuit.Manager
+	m.MustCreateCircuit("a").OpenCircuit(context.Background())
+	m.MustCreateCircuit("b")
+	m.MustCreateCircuit("c").OpenCircuit(context.Background())
+	p := Picker{Manager: &m}
+	for i := 0; i < 100; i++ {
+		host, err := p.Pick([]string{"a", "b", "c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if host != "b" {
+			t.Fatalf("expected only b to be picked, got %s", host)
+		}
+	}
+}
+
+func TestPicker_NoHealthyHosts(t *testing.T) {
+	var m circuit.Manager
+	m.MustCreateCircuit("a").OpenCircuit(context.Background())
+	p := Picker{Manager: &m}
+	if _, err := p.Pick([]string{"a"}); !errors.Is(err, ErrNoHealthyHosts) {
+		t.Errorf("expected ErrNoHealthyHosts, got %v", err)
+	}
+	if _, err := p.Pick(nil); !errors.Is(err, ErrNoHealthyHosts) {
+		t.Errorf("expected ErrNoHealthyHosts for no hosts, got %v", err)
+	}
+}
+
+func TestPicker_PicksLessLoaded(t *testing.T) {
+	var m circuit.Manager
+	busy := m.MustCreateCircuit("busy")
+	m.MustCreateCircuit("idle")
+	p := Picker{Manager: &m}
+	_ = busy.Run(context.Background(), func(_ context.Context) error {
+		for i := 0; i < 100; i++ {
+			host, err := p.Pick([]string{"busy", "idle"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if host != "idle" {
+				t.Fatalf("expected the idle host to be picked, got %s", host)
+			}
+		}
+		return nil
+	})
+}
+
+func TestPicker_SpreadsLoad(t *testing.T) {
+	var m circuit.Manager
+	p := Picker{Manager: &m}
+	hosts := []string{"a", "b", "c", "d"}
+	picked := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		host, err := p.Pick(hosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[host]++
+	}
+	for _, host := range hosts {
+		if picked[host] < 100 {
+			t.Errorf("expected every idle host to be picked often, got %v", picked)
+		}
+	}
+}
+
+func TestPicker_Rand(t *testing.T) {
+	var m circuit.Manager
+	hosts := []string{"a", "b", "c", "d"}
+	picks := func() []string {
+		p := Picker{Manager: &m, Rand: circuit.NewRand(3)}
+		var ret []string
+		for i := 0; i < 50; i++ {
+			host, err := p.Pick(hosts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ret = append(ret, host)
+		}
+		return ret
+	}
+	first, second := picks(), picks()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same seed to make the same picks, pick %d differed", i)
+		}
+	}
+}
+
+func TestPicker_HalfOpen(t *testing.T) {
+	var m circuit.Manager
+	c := m.MustCreateCircuit("a", circuit.Config{
+		General: circuit.GeneralConfig{
+			OpenToClosedFactory: hystrix.CloserFactory(hystrix.ConfigureCloser{
+				SleepWindow: time.Minute,
+			}),
+		},
+	})
+	c.OpenCircuit(context.Background())
+	now := time.Now()
+	p := Picker{
+		Manager: &m,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	if _, err := p.Pick([]string{"a"}); !errors.Is(err, ErrNoHealthyHosts) {
+		t.Errorf("expected the open host to be skipped, got %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if host, err := p.Pick([]string{"a"}); err != nil || host != "a" {
+		t.Errorf("expected the host to be picked for a half open request, got %s %v", host, err)
+	}
+}
+
+func TestPicker_CircuitName(t *testing.T) {
+	var m circuit.Manager
+	m.MustCreateCircuit("https://a").OpenCircuit(context.Background())
+	p := Picker{
+		Manager: &m,
+		CircuitName: func(host string) string {
+			return "https://" + host
+		},
+	}
+	if host, err := p.Pick([]string{"a", "b"}); err != nil || host != "b" {
+		t.Errorf("expected b to be picked, got %s %v", host, err)
+	}
+}
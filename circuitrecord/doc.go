@@ -0,0 +1,9 @@
+/*
+Package circuitrecord samples the outcomes of circuit runs to a file, one JSON record per line, so real traffic can be
+analyzed offline when tuning thresholds and timeouts.  Each record is the circuit, time, duration, outcome and a class
+of the error.  Read them back with ReadAll.
+
+Replay runs recorded traffic through circuits with other configs, and reports how often each would have opened and
+how many runs it would have shed.  Command circuitreplay does this for hystrix thresholds.
+*/
+package circuitrecord
@@ -0,0 +1,32 @@
+package circuitrecord_test
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitrecord"
+)
+
+// This example records one run in ten of every circuit to a file
+func ExampleRecorder() {
+	f, err := os.CreateTemp("", "circuit-traffic-*.jsonl")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	r := circuitrecord.NewRecorder(f)
+	r.SampleRate = 0.1
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{r.CommandProperties},
+	}
+	c := h.MustCreateCircuit("db")
+	_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+	if err := r.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,220 @@
+package circuitrecord
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Record is one sampled run.  Field names are short to keep recordings small.
+type Record struct {
+	Circuit string              `json:"c"`
+	Time    time.Time           `json:"t"`
+	Outcome circuit.OutcomeType `json:"o"`
+	// Duration is how long the run took.  It is zero for runs that were never started.
+	Duration time.Duration `json:"d,omitempty"`
+	// ErrorClass is Recorder.ErrorClass of the error of failures and timeouts
+	ErrorClass string `json:"e,omitempty"`
+}
+
+// ErrorType is the default Recorder.ErrorClass.  It is the Go type of err, like *net.OpError, so recordings do not
+// hold error messages, which may have private data in them.  Errors redacted by circuit.GeneralConfig.RedactError are
+// classed by the type of the original error.
+func ErrorType(err error) string {
+	if redacted, ok := err.(*circuit.RedactedError); ok {
+		err = redacted.Err
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// Recorder writes a sample of the runs of the circuits it is attached to.  Attach it with CommandProperties.  Records
+// are buffered: call Flush before closing the writer.
+type Recorder struct {
+	// SampleRate is the share [0.0 - 1.0] of runs recorded.  The default of 0 records every run.
+	SampleRate float64
+	// Rand picks which runs are sampled.  The default is the GeneralConfig.Rand of each circuit, or math/rand if that
+	// is not set.  Use circuit.NewRand for reproducible samples.
+	Rand circuit.Rand
+	// ErrorClass groups the errors of failures and timeouts.  The default is ErrorType.
+	ErrorClass func(err error) string
+
+	mu      sync.Mutex
+	w       *bufio.Writer
+	encoder *json.Encoder
+	err     error
+	// pending are failures and timeouts waiting for their error.  circuit.RunErrorMetrics is called right after them,
+	// with the same context.
+	pending map[context.Context][]Record
+}
+
+// NewRecorder returns a Recorder that writes to w
+func NewRecorder(w io.Writer) *Recorder {
+	bw := bufio.NewWriter(w)
+	return &Recorder{
+		w:       bw,
+		encoder: json.NewEncoder(bw),
+		pending: make(map[context.Context][]Record),
+	}
+}
+
+// CommandProperties records the runs of a circuit
+func (r *Recorder) CommandProperties(circuitName string) circuit.Config {
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Run: []circuit.RunMetrics{&circuitRecorder{recorder: r, name: circuitName}},
+		},
+	}
+}
+
+// Flush writes buffered records.  It returns the first error seen writing records.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// sampled picks if a run is recorded.  inherited is the Rand of the run's circuit, if it has one.
+func (r *Recorder) sampled(inherited circuit.Rand) bool {
+	return r.SampleRate <= 0 || r.SampleRate >= 1 || r.float64(inherited) < r.SampleRate
+}
+
+func (r *Recorder) float64(inherited circuit.Rand) float64 {
+	if r.Rand != nil {
+		return r.Rand.Float64()
+	}
+	if inherited != nil {
+		return inherited.Float64()
+	}
+	return rand.Float64()
+}
+
+func (r *Recorder) write(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeWithLock(rec)
+}
+
+func (r *Recorder) writeWithLock(rec Record) {
+	if r.err != nil {
+		return
+	}
+	r.err = r.encoder.Encode(rec)
+}
+
+// addPending holds a failure or timeout until RunError gives its error
+func (r *Recorder) addPending(ctx context.Context, rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[ctx] = append(r.pending[ctx], rec)
+}
+
+// writePending writes the oldest failure or timeout of ctx with the class of err
+func (r *Recorder) writePending(ctx context.Context, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recs := r.pending[ctx]
+	if len(recs) == 0 {
+		return
+	}
+	rec := recs[0]
+	if len(recs) == 1 {
+		delete(r.pending, ctx)
+	} else {
+		r.pending[ctx] = recs[1:]
+	}
+	if r.ErrorClass != nil {
+		rec.ErrorClass = r.ErrorClass(err)
+	} else {
+		rec.ErrorClass = ErrorType(err)
+	}
+	r.writeWithLock(rec)
+}
+
+// ReadAll reads every record written by a Recorder
+func ReadAll(in io.Reader) ([]Record, error) {
+	var ret []Record
+	decoder := json.NewDecoder(in)
+	for {
+		var rec Record
+		if err := decoder.Decode(&rec); err != nil {
+			if err == io.EOF {
+				return ret, nil
+			}
+			return ret, err
+		}
+		ret = append(ret, rec)
+	}
+}
+
+// circuitRecorder records the runs of one circuit
+type circuitRecorder struct {
+	recorder *Recorder
+	name     string
+	// rand is the circuit's GeneralConfig.Rand.  It is only set while the circuit is set up.
+	rand circuit.Rand
+}
+
+var _ circuit.RunMetrics = &circuitRecorder{}
+var _ circuit.RunErrorMetrics = &circuitRecorder{}
+var _ circuit.RandInheritor = &circuitRecorder{}
+
+// InheritRand samples the circuit's runs with its Rand if Recorder.Rand is not set
+func (c *circuitRecorder) InheritRand(r circuit.Rand) {
+	c.rand = r
+}
+
+func (c *circuitRecorder) record(now time.Time, outcome circuit.OutcomeType, duration time.Duration) {
+	if c.recorder.sampled(c.rand) {
+		c.recorder.write(Record{Circuit: c.name, Time: now, Outcome: outcome, Duration: duration})
+	}
+}
+
+// recordWithError waits for RunError to record a failure or timeout.  Only sampled runs wait, so RunError of a run
+// that was not sampled finds nothing to write.
+func (c *circuitRecorder) recordWithError(ctx context.Context, now time.Time, outcome circuit.OutcomeType, duration time.Duration) {
+	if c.recorder.sampled(c.rand) {
+		c.recorder.addPending(ctx, Record{Circuit: c.name, Time: now, Outcome: outcome, Duration: duration})
+	}
+}
+
+func (c *circuitRecorder) Success(_ context.Context, now time.Time, duration time.Duration) {
+	c.record(now, circuit.OutcomeSuccess, duration)
+}
+
+func (c *circuitRecorder) ErrFailure(ctx context.Context, now time.Time, duration time.Duration) {
+	c.recordWithError(ctx, now, circuit.OutcomeFailure, duration)
+}
+
+func (c *circuitRecorder) ErrTimeout(ctx context.Context, now time.Time, duration time.Duration) {
+	c.recordWithError(ctx, now, circuit.OutcomeTimeout, duration)
+}
+
+func (c *circuitRecorder) ErrBadRequest(_ context.Context, now time.Time, duration time.Duration) {
+	c.record(now, circuit.OutcomeBadRequest, duration)
+}
+
+func (c *circuitRecorder) ErrInterrupt(_ context.Context, now time.Time, duration time.Duration) {
+	c.record(now, circuit.OutcomeInterrupt, duration)
+}
+
+func (c *circuitRecorder) ErrConcurrencyLimitReject(_ context.Context, now time.Time) {
+	c.record(now, circuit.OutcomeConcurrencyLimitReject, 0)
+}
+
+func (c *circuitRecorder) ErrShortCircuit(_ context.Context, now time.Time) {
+	c.record(now, circuit.OutcomeShortCircuit, 0)
+}
+
+func (c *circuitRecorder) RunError(ctx context.Context, _ time.Time, err error) {
+	c.recorder.writePending(ctx, err)
+}
@@ -0,0 +1,161 @@
+package circuitrecord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+type testError struct{}
+
+func (testError) Error() string {
+	return "private details"
+}
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	c := circuit.NewCircuitFromConfig("db", r.CommandProperties("db"))
+	ctx := context.Background()
+	_ = c.Execute(ctx, func(_ context.Context) error { return nil }, nil)
+	_ = c.Execute(ctx, func(_ context.Context) error { return testError{} }, nil)
+	_ = c.Execute(ctx, func(_ context.Context) error {
+		return circuit.SimpleBadRequest{Err: errors.New("bad input")}
+	}, nil)
+	c.OpenCircuit(ctx)
+	_ = c.Execute(ctx, func(_ context.Context) error { return nil }, nil)
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []circuit.OutcomeType{circuit.OutcomeSuccess, circuit.OutcomeFailure, circuit.OutcomeBadRequest, circuit.OutcomeShortCircuit}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %v", len(expected), records)
+	}
+	for i, rec := range records {
+		if rec.Outcome != expected[i] || rec.Circuit != "db" || rec.Time.IsZero() {
+			t.Errorf("unexpected record %d: %v", i, rec)
+		}
+	}
+	if records[1].ErrorClass != "circuitrecord.testError" {
+		t.Errorf("expected the failure to have the error's type, got %q", records[1].ErrorClass)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("private details")) {
+		t.Error("expected error messages to not be recorded")
+	}
+}
+
+func TestRecorder_Timeout(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	r.ErrorClass = func(_ error) string {
+		return "slow"
+	}
+	cfg := r.CommandProperties("db")
+	cfg.Execution.Timeout = time.Millisecond
+	c := circuit.NewCircuitFromConfig("db", cfg)
+	_ = c.Execute(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, nil)
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Outcome != circuit.OutcomeTimeout || records[0].ErrorClass != "slow" || records[0].Duration < time.Millisecond {
+		t.Errorf("expected one timeout, got %v", records)
+	}
+	if len(r.pending) != 0 {
+		t.Errorf("expected no pending records, got %v", r.pending)
+	}
+}
+
+func TestRecorder_SampleRate(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	r.SampleRate = 0.1
+	c := circuit.NewCircuitFromConfig("db", r.CommandProperties("db"))
+	for i := 0; i < 1000; i++ {
+		_ = c.Execute(context.Background(), func(_ context.Context) error { return errors.New("bad") }, nil)
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) < 30 || len(records) > 300 {
+		t.Errorf("expected about a tenth of runs to be recorded, got %d", len(records))
+	}
+	if len(r.pending) != 0 {
+		t.Errorf("expected no pending records, got %d", len(r.pending))
+	}
+}
+
+// fixedRand always picks the same number
+type fixedRand float64
+
+func (f fixedRand) Int63n(n int64) int64 { return int64(float64(f) * float64(n)) }
+func (f fixedRand) Float64() float64     { return float64(f) }
+
+func TestRecorder_InheritRand(t *testing.T) {
+	recorded := func(rnd circuit.Rand) int {
+		var buf bytes.Buffer
+		r := NewRecorder(&buf)
+		r.SampleRate = 0.5
+		cfg := r.CommandProperties("db")
+		cfg.General.Rand = rnd
+		c := circuit.NewCircuitFromConfig("db", cfg)
+		for i := 0; i < 10; i++ {
+			_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+		}
+		if err := r.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		records, err := ReadAll(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(records)
+	}
+	if n := recorded(fixedRand(0.1)); n != 10 {
+		t.Errorf("expected the circuit's Rand to sample every run, got %d", n)
+	}
+	if n := recorded(fixedRand(0.9)); n != 0 {
+		t.Errorf("expected the circuit's Rand to sample no run, got %d", n)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecorder_WriteError(t *testing.T) {
+	r := NewRecorder(failingWriter{})
+	c := circuit.NewCircuitFromConfig("db", r.CommandProperties("db"))
+	_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+	if err := r.Flush(); err == nil {
+		t.Error("expected the write error from Flush")
+	}
+}
+
+func TestErrorType_redacted(t *testing.T) {
+	err := &circuit.RedactedError{Err: testError{}, Message: "redacted"}
+	if class := ErrorType(err); class != "circuitrecord.testError" {
+		t.Errorf("expected the type of the original error, got %s", class)
+	}
+}
@@ -0,0 +1,183 @@
+package circuitrecord
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/clock"
+)
+
+// ReplayProperties creates the config of a circuit to replay records against.  Time in a replay is the time of the
+// records, so openers and closers that take their own clock, like the hystrix ones, must be given timeKeeper.Now and
+// timeKeeper.AfterFunc.
+type ReplayProperties func(circuitName string, timeKeeper circuit.TimeKeeper) circuit.Config
+
+// ReplayReport is what a circuit would have done with the recorded traffic
+type ReplayReport struct {
+	Circuit string
+	// Runs is how many records were replayed
+	Runs int64
+	// Opens is how many times the circuit opened
+	Opens int64
+	// Shed is how many runs the circuit short circuited or rejected instead of calling the dependency
+	Shed int64
+	// RecordedShed is how many runs were short circuited or rejected when the records were recorded
+	RecordedShed int64
+	// Failures and Timeouts are runs that called the dependency and failed
+	Failures int64
+	Timeouts int64
+	// Assumed is how many runs were never called when they were recorded.  The dependency is assumed to have answered
+	// them like the run before them that was called.
+	Assumed int64
+	// OpenTime is how long the circuit was open or half open
+	OpenTime time.Duration
+}
+
+// Replay runs the records of each circuit, in order, through a new circuit with the config from properties, and
+// reports what it would have done.  Use it to tune thresholds, sleep windows and timeouts by simulation instead of in
+// production.  Reports are sorted by circuit.
+//
+// Each run takes as long as it did when recorded, so a shorter timeout turns slow successes into timeouts.  Recorded
+// timeouts fail, since how long the dependency would have taken is not known.  Runs are replayed one at a time, so
+// concurrency limits are never reached.
+func Replay(records []Record, properties ReplayProperties) []ReplayReport {
+	byCircuit := make(map[string][]Record)
+	for _, rec := range records {
+		byCircuit[rec.Circuit] = append(byCircuit[rec.Circuit], rec)
+	}
+	ret := make([]ReplayReport, 0, len(byCircuit))
+	for name, recs := range byCircuit {
+		ret = append(ret, replayCircuit(name, recs, properties))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Circuit < ret[j].Circuit
+	})
+	return ret
+}
+
+// startTime is when a recorded run started.  Runs that were never called start when they were recorded.
+func startTime(rec Record) time.Time {
+	return rec.Time.Add(-rec.Duration)
+}
+
+func replayCircuit(name string, records []Record, properties ReplayProperties) ReplayReport {
+	sort.SliceStable(records, func(i, j int) bool {
+		return startTime(records[i]).Before(startTime(records[j]))
+	})
+	mockClock := &clock.MockClock{}
+	mockClock.Set(startTime(records[0]))
+	timeKeeper := circuit.TimeKeeper{
+		Now:       mockClock.Now,
+		AfterFunc: mockClock.AfterFunc,
+	}
+	counter := &replayCounter{}
+	cfg := properties(name, timeKeeper)
+	cfg.General.TimeKeeper = timeKeeper
+	cfg.Metrics.Run = append(cfg.Metrics.Run, counter)
+	cfg.Metrics.Circuit = append(cfg.Metrics.Circuit, counter)
+	c := circuit.NewCircuitFromConfig(name, cfg)
+
+	ret := ReplayReport{
+		Circuit: name,
+	}
+	// The last run that called the dependency.  Until there is one, the dependency is assumed to work.
+	last := Record{Outcome: circuit.OutcomeSuccess}
+	for _, rec := range records {
+		ret.Runs++
+		if rec.Outcome == circuit.OutcomeShortCircuit || rec.Outcome == circuit.OutcomeConcurrencyLimitReject {
+			ret.RecordedShed++
+			ret.Assumed++
+			rec.Outcome, rec.Duration, rec.Time = last.Outcome, last.Duration, rec.Time.Add(last.Duration)
+		} else {
+			last = rec
+		}
+		// Runs that overlapped start when the one before them started, so time never goes backwards
+		start := startTime(rec)
+		if now := mockClock.Now(); start.Before(now) {
+			start = now
+		}
+		mockClock.Set(start)
+		replayRun(c, mockClock, rec.Outcome, start.Add(rec.Duration))
+	}
+	timeInState := c.TimeInState()
+	ret.OpenTime = timeInState.Open + timeInState.HalfOpen
+	ret.Opens, ret.Shed, ret.Failures, ret.Timeouts = counter.get()
+	return ret
+}
+
+// replayRun runs c once, ending at end with outcome
+func replayRun(c *circuit.Circuit, mockClock *clock.MockClock, outcome circuit.OutcomeType, end time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = c.Run(ctx, func(_ context.Context) error {
+		mockClock.Set(end)
+		switch outcome {
+		case circuit.OutcomeFailure:
+			return errReplayFailure
+		case circuit.OutcomeTimeout:
+			return context.DeadlineExceeded
+		case circuit.OutcomeBadRequest:
+			return circuit.SimpleBadRequest{Err: errReplayFailure}
+		case circuit.OutcomeInterrupt:
+			cancel()
+			return context.Canceled
+		}
+		return nil
+	})
+}
+
+// errReplayFailure is the error of replayed failures
+var errReplayFailure = errors.New("replayed failure")
+
+// replayCounter counts what a replayed circuit did
+type replayCounter struct {
+	mu       sync.Mutex
+	opens    int64
+	shed     int64
+	failures int64
+	timeouts int64
+}
+
+var _ circuit.RunMetrics = &replayCounter{}
+var _ circuit.Metrics = &replayCounter{}
+
+func (r *replayCounter) get() (opens int64, shed int64, failures int64, timeouts int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.opens, r.shed, r.failures, r.timeouts
+}
+
+func (r *replayCounter) inc(into *int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*into++
+}
+
+func (r *replayCounter) Success(_ context.Context, _ time.Time, _ time.Duration)       {}
+func (r *replayCounter) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {}
+func (r *replayCounter) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration)  {}
+func (r *replayCounter) Closed(_ context.Context, _ time.Time)                         {}
+
+func (r *replayCounter) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(&r.failures)
+}
+
+func (r *replayCounter) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(&r.timeouts)
+}
+
+func (r *replayCounter) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {
+	r.inc(&r.shed)
+}
+
+func (r *replayCounter) ErrShortCircuit(_ context.Context, _ time.Time) {
+	r.inc(&r.shed)
+}
+
+func (r *replayCounter) Opened(_ context.Context, _ time.Time) {
+	r.inc(&r.opens)
+}
@@ -0,0 +1,93 @@
+package circuitrecord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+// testRecords is a minute of traffic to db: 20 seconds of successes, 10 seconds of failures, 10 seconds the recording
+// circuit short circuited, and 20 seconds of successes
+func testRecords(start time.Time) []Record {
+	var ret []Record
+	add := func(n int, outcome circuit.OutcomeType, duration time.Duration) {
+		for i := 0; i < n; i++ {
+			start = start.Add(time.Second)
+			ret = append(ret, Record{Circuit: "db", Time: start, Outcome: outcome, Duration: duration})
+		}
+	}
+	add(20, circuit.OutcomeSuccess, 100*time.Millisecond)
+	add(10, circuit.OutcomeFailure, 100*time.Millisecond)
+	add(10, circuit.OutcomeShortCircuit, 0)
+	add(20, circuit.OutcomeSuccess, 100*time.Millisecond)
+	return ret
+}
+
+func hystrixProperties(_ string, timeKeeper circuit.TimeKeeper) circuit.Config {
+	f := hystrix.Factory{
+		ConfigureOpener: hystrix.ConfigureOpener{
+			RequestVolumeThreshold: 5,
+			Now:                    timeKeeper.Now,
+		},
+		ConfigureCloser: hystrix.ConfigureCloser{
+			SleepWindow: 5 * time.Second,
+			AfterFunc:   timeKeeper.AfterFunc,
+		},
+	}
+	return f.Configure("db")
+}
+
+func TestReplay(t *testing.T) {
+	reports := Replay(testRecords(time.Now()), hystrixProperties)
+	if len(reports) != 1 {
+		t.Fatalf("expected one report, got %v", reports)
+	}
+	r := reports[0]
+	if r.Circuit != "db" || r.Runs != 60 || r.RecordedShed != 10 || r.Assumed != 10 {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if r.Opens == 0 || r.Shed == 0 || r.OpenTime == 0 {
+		t.Errorf("expected the circuit to open and shed runs, got %+v", r)
+	}
+	if r.Failures+r.Shed < 20 || r.Failures >= 20 {
+		t.Errorf("expected the failures and assumed failures to be failed or shed, got %+v", r)
+	}
+}
+
+func TestReplay_NeverOpens(t *testing.T) {
+	reports := Replay(testRecords(time.Now()), func(_ string, _ circuit.TimeKeeper) circuit.Config {
+		return circuit.Config{}
+	})
+	r := reports[0]
+	if r.Opens != 0 || r.Shed != 0 || r.OpenTime != 0 {
+		t.Errorf("expected a circuit that never opens to shed nothing, got %+v", r)
+	}
+	// The short circuited runs are assumed to fail like the runs before them
+	if r.Failures != 20 {
+		t.Errorf("expected 20 failures, got %+v", r)
+	}
+}
+
+func TestReplay_Timeout(t *testing.T) {
+	start := time.Now()
+	records := []Record{
+		{Circuit: "db", Time: start.Add(time.Second), Outcome: circuit.OutcomeSuccess, Duration: 200 * time.Millisecond},
+		{Circuit: "db", Time: start.Add(2 * time.Second), Outcome: circuit.OutcomeSuccess, Duration: 50 * time.Millisecond},
+		{Circuit: "cache", Time: start.Add(time.Second), Outcome: circuit.OutcomeSuccess, Duration: 50 * time.Millisecond},
+	}
+	reports := Replay(records, func(_ string, _ circuit.TimeKeeper) circuit.Config {
+		return circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				Timeout: 100 * time.Millisecond,
+			},
+		}
+	})
+	if len(reports) != 2 || reports[0].Circuit != "cache" || reports[1].Circuit != "db" {
+		t.Fatalf("expected a report per circuit, sorted, got %v", reports)
+	}
+	if reports[1].Timeouts != 1 || reports[0].Timeouts != 0 {
+		t.Errorf("expected the slow run to time out, got %v", reports)
+	}
+}
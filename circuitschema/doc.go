@@ -0,0 +1,9 @@
+/*
+Package circuitschema is the versioned wire format for circuit configuration and health.  Config services, admin
+tools, and servers that aggregate many processes' circuits can exchange Config and Snapshot as JSON, or as protobuf
+with the circuitschema/schemapb module, and agree on what each field means.
+
+Durations are encoded as strings time.ParseDuration understands, like "1.5s", so documents are easy to write by hand.
+Unmarshal rejects documents from a newer Version than this package knows.
+*/
+package circuitschema
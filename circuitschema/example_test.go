@@ -0,0 +1,22 @@
+package circuitschema_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitschema"
+)
+
+// This example reads a circuit's config from a document a config service would serve
+func ExampleUnmarshalConfig() {
+	doc := []byte(`{"version": 1, "execution": {"timeout": "250ms", "max_concurrent_requests": 20}}`)
+	cfg, err := circuitschema.UnmarshalConfig(doc)
+	if err != nil {
+		panic(err)
+	}
+	m := &circuit.Manager{}
+	c := m.MustCreateCircuit("db", cfg.CircuitConfig())
+	fmt.Println(c.Config().Execution.Timeout == 250*time.Millisecond)
+	// Output: true
+}
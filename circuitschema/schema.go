@@ -0,0 +1,312 @@
+package circuitschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Version is the schema version this package writes.  Documents without a version are read as this version.
+const Version = 1
+
+// Duration is a time.Duration encoded as a string like "250ms".  A number is read as nanoseconds.
+type Duration time.Duration
+
+// MarshalJSON encodes d as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string, or a number of nanoseconds
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	if !strings.HasPrefix(string(b), `"`) {
+		var nanos int64
+		if err := json.Unmarshal(b, &nanos); err != nil {
+			return err
+		}
+		*d = Duration(nanos)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config is the part of circuit.Config that is data.  Functions, like openers and metrics collectors, are not part
+// of the schema and are set by the process that creates the circuit.
+type Config struct {
+	Version   int             `json:"version"`
+	General   GeneralConfig   `json:"general"`
+	Execution ExecutionConfig `json:"execution"`
+	Fallback  FallbackConfig  `json:"fallback"`
+}
+
+// GeneralConfig is circuit.GeneralConfig
+type GeneralConfig struct {
+	Disabled              bool                `json:"disabled,omitempty"`
+	ForceOpen             bool                `json:"force_open,omitempty"`
+	ForcedClosed          bool                `json:"forced_closed,omitempty"`
+	DependsOn             []string            `json:"depends_on,omitempty"`
+	RecentErrorsSize      int64               `json:"recent_errors_size,omitempty"`
+	RecentBadRequestsSize int64               `json:"recent_bad_requests_size,omitempty"`
+	FlapWindow            Duration            `json:"flap_window,omitempty"`
+	MinClosedDuration     Duration            `json:"min_closed_duration,omitempty"`
+	FlapThreshold         int64               `json:"flap_threshold,omitempty"`
+	FlapHoldOpen          Duration            `json:"flap_hold_open,omitempty"`
+	MaintenanceWindows    []MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	WarmUpDuration        Duration            `json:"warm_up_duration,omitempty"`
+	InitialState          string              `json:"initial_state,omitempty"`
+	Counters              []string            `json:"counters,omitempty"`
+	Thresholds            map[string]int64    `json:"thresholds,omitempty"`
+	DetectNesting         bool                `json:"detect_nesting,omitempty"`
+}
+
+// MaintenanceWindow is circuit.MaintenanceWindow
+type MaintenanceWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ExecutionConfig is circuit.ExecutionConfig
+type ExecutionConfig struct {
+	Timeout                           Duration `json:"timeout,omitempty"`
+	MaxConcurrentRequests             int64    `json:"max_concurrent_requests,omitempty"`
+	IgnoreInterrupts                  bool     `json:"ignore_interrupts,omitempty"`
+	MaxConcurrentRequestsPerPartition int64    `json:"max_concurrent_requests_per_partition,omitempty"`
+	MaxPartitions                     int64    `json:"max_partitions,omitempty"`
+	DetachContext                     bool     `json:"detach_context,omitempty"`
+	StuckTimeoutMultiple              int64    `json:"stuck_timeout_multiple,omitempty"`
+	MaxTimeoutOverride                Duration `json:"max_timeout_override,omitempty"`
+	RetryBudgetPercent                int64    `json:"retry_budget_percent,omitempty"`
+	RetryBudgetMinPerSecond           int64    `json:"retry_budget_min_per_second,omitempty"`
+	RetryBudgetWindow                 Duration `json:"retry_budget_window,omitempty"`
+	MaxQueueSize                      int64    `json:"max_queue_size,omitempty"`
+	MaxConcurrentWait                 Duration `json:"max_concurrent_wait,omitempty"`
+	QueueOrder                        string   `json:"queue_order,omitempty"`
+	TimeoutGracePeriod                Duration `json:"timeout_grace_period,omitempty"`
+	ConcurrencyWarningPercent         int64    `json:"concurrency_warning_percent,omitempty"`
+}
+
+// FallbackConfig is circuit.FallbackConfig
+type FallbackConfig struct {
+	Disabled              bool  `json:"disabled,omitempty"`
+	MaxConcurrentRequests int64 `json:"max_concurrent_requests,omitempty"`
+	Shadow                bool  `json:"shadow,omitempty"`
+	ShadowServesFallback  bool  `json:"shadow_serves_fallback,omitempty"`
+}
+
+// FromConfig returns the schema form of c
+func FromConfig(c circuit.Config) Config {
+	ret := Config{
+		Version: Version,
+		General: GeneralConfig{
+			Disabled:              c.General.Disabled,
+			ForceOpen:             c.General.ForceOpen,
+			ForcedClosed:          c.General.ForcedClosed,
+			DependsOn:             c.General.DependsOn,
+			RecentErrorsSize:      c.General.RecentErrorsSize,
+			RecentBadRequestsSize: c.General.RecentBadRequestsSize,
+			FlapWindow:            Duration(c.General.FlapWindow),
+			MinClosedDuration:     Duration(c.General.MinClosedDuration),
+			FlapThreshold:         c.General.FlapThreshold,
+			FlapHoldOpen:          Duration(c.General.FlapHoldOpen),
+			WarmUpDuration:        Duration(c.General.WarmUpDuration),
+			InitialState:          string(c.General.InitialState),
+			Counters:              c.General.Counters,
+			Thresholds:            c.General.Thresholds,
+			DetectNesting:         c.General.DetectNesting,
+		},
+		Execution: ExecutionConfig{
+			Timeout:                           Duration(c.Execution.Timeout),
+			MaxConcurrentRequests:             c.Execution.MaxConcurrentRequests,
+			IgnoreInterrupts:                  c.Execution.IgnoreInterrupts,
+			MaxConcurrentRequestsPerPartition: c.Execution.MaxConcurrentRequestsPerPartition,
+			MaxPartitions:                     int64(c.Execution.MaxPartitions),
+			DetachContext:                     c.Execution.DetachContext,
+			StuckTimeoutMultiple:              c.Execution.StuckTimeoutMultiple,
+			MaxTimeoutOverride:                Duration(c.Execution.MaxTimeoutOverride),
+			RetryBudgetPercent:                c.Execution.RetryBudgetPercent,
+			RetryBudgetMinPerSecond:           c.Execution.RetryBudgetMinPerSecond,
+			RetryBudgetWindow:                 Duration(c.Execution.RetryBudgetWindow),
+			MaxQueueSize:                      c.Execution.MaxQueueSize,
+			MaxConcurrentWait:                 Duration(c.Execution.MaxConcurrentWait),
+			QueueOrder:                        string(c.Execution.QueueOrder),
+			TimeoutGracePeriod:                Duration(c.Execution.TimeoutGracePeriod),
+			ConcurrencyWarningPercent:         c.Execution.ConcurrencyWarningPercent,
+		},
+		Fallback: FallbackConfig{
+			Disabled:              c.Fallback.Disabled,
+			MaxConcurrentRequests: c.Fallback.MaxConcurrentRequests,
+			Shadow:                c.Fallback.Shadow,
+			ShadowServesFallback:  c.Fallback.ShadowServesFallback,
+		},
+	}
+	for _, w := range c.General.MaintenanceWindows {
+		ret.General.MaintenanceWindows = append(ret.General.MaintenanceWindows, MaintenanceWindow{Start: w.Start, End: w.End})
+	}
+	return ret
+}
+
+// CircuitConfig returns c as a circuit.Config, with no functions set.  Merge it with a config that sets them.
+func (c Config) CircuitConfig() circuit.Config {
+	ret := circuit.Config{
+		General: circuit.GeneralConfig{
+			Disabled:              c.General.Disabled,
+			ForceOpen:             c.General.ForceOpen,
+			ForcedClosed:          c.General.ForcedClosed,
+			DependsOn:             c.General.DependsOn,
+			RecentErrorsSize:      c.General.RecentErrorsSize,
+			RecentBadRequestsSize: c.General.RecentBadRequestsSize,
+			FlapWindow:            time.Duration(c.General.FlapWindow),
+			MinClosedDuration:     time.Duration(c.General.MinClosedDuration),
+			FlapThreshold:         c.General.FlapThreshold,
+			FlapHoldOpen:          time.Duration(c.General.FlapHoldOpen),
+			WarmUpDuration:        time.Duration(c.General.WarmUpDuration),
+			InitialState:          circuit.CircuitState(c.General.InitialState),
+			Counters:              c.General.Counters,
+			Thresholds:            c.General.Thresholds,
+			DetectNesting:         c.General.DetectNesting,
+		},
+		Execution: circuit.ExecutionConfig{
+			Timeout:                           time.Duration(c.Execution.Timeout),
+			MaxConcurrentRequests:             c.Execution.MaxConcurrentRequests,
+			IgnoreInterrupts:                  c.Execution.IgnoreInterrupts,
+			MaxConcurrentRequestsPerPartition: c.Execution.MaxConcurrentRequestsPerPartition,
+			MaxPartitions:                     int(c.Execution.MaxPartitions),
+			DetachContext:                     c.Execution.DetachContext,
+			StuckTimeoutMultiple:              c.Execution.StuckTimeoutMultiple,
+			MaxTimeoutOverride:                time.Duration(c.Execution.MaxTimeoutOverride),
+			RetryBudgetPercent:                c.Execution.RetryBudgetPercent,
+			RetryBudgetMinPerSecond:           c.Execution.RetryBudgetMinPerSecond,
+			RetryBudgetWindow:                 time.Duration(c.Execution.RetryBudgetWindow),
+			MaxQueueSize:                      c.Execution.MaxQueueSize,
+			MaxConcurrentWait:                 time.Duration(c.Execution.MaxConcurrentWait),
+			QueueOrder:                        circuit.QueueOrder(c.Execution.QueueOrder),
+			TimeoutGracePeriod:                time.Duration(c.Execution.TimeoutGracePeriod),
+			ConcurrencyWarningPercent:         c.Execution.ConcurrencyWarningPercent,
+		},
+		Fallback: circuit.FallbackConfig{
+			Disabled:              c.Fallback.Disabled,
+			MaxConcurrentRequests: c.Fallback.MaxConcurrentRequests,
+			Shadow:                c.Fallback.Shadow,
+			ShadowServesFallback:  c.Fallback.ShadowServesFallback,
+		},
+	}
+	for _, w := range c.General.MaintenanceWindows {
+		ret.General.MaintenanceWindows = append(ret.General.MaintenanceWindows, circuit.MaintenanceWindow{Start: w.Start, End: w.End})
+	}
+	return ret
+}
+
+// Snapshot is the health of one circuit at one time
+type Snapshot struct {
+	Version int       `json:"version"`
+	Name    string    `json:"name"`
+	Time    time.Time `json:"time"`
+	Open    bool      `json:"open"`
+	Reason  string    `json:"reason"`
+	Since   time.Time `json:"since"`
+	// NextProbe is unset if the circuit is closed, or its closer does not schedule half open requests
+	NextProbe           *time.Time  `json:"next_probe,omitempty"`
+	ConcurrentCommands  int64       `json:"concurrent_commands"`
+	ConcurrentFallbacks int64       `json:"concurrent_fallbacks"`
+	TimeInState         TimeInState `json:"time_in_state"`
+	Config              Config      `json:"config"`
+}
+
+// TimeInState is circuit.TimeInState
+type TimeInState struct {
+	Current  string   `json:"current"`
+	Closed   Duration `json:"closed"`
+	Open     Duration `json:"open"`
+	HalfOpen Duration `json:"half_open"`
+}
+
+// TakeSnapshot returns the current health of c
+func TakeSnapshot(c *circuit.Circuit) Snapshot {
+	info := c.StateInfo()
+	inState := c.TimeInState()
+	ret := Snapshot{
+		Version:             Version,
+		Name:                c.Name(),
+		Time:                time.Now(),
+		Open:                info.Open,
+		Reason:              string(info.Reason),
+		Since:               info.Since,
+		ConcurrentCommands:  c.ConcurrentCommands(),
+		ConcurrentFallbacks: c.ConcurrentFallbacks(),
+		TimeInState: TimeInState{
+			Current:  string(inState.Current),
+			Closed:   Duration(inState.Closed),
+			Open:     Duration(inState.Open),
+			HalfOpen: Duration(inState.HalfOpen),
+		},
+		Config: FromConfig(c.Config()),
+	}
+	if !info.NextProbe.IsZero() {
+		nextProbe := info.NextProbe
+		ret.NextProbe = &nextProbe
+	}
+	return ret
+}
+
+// TakeSnapshots returns the current health of every circuit in m, sorted by name
+func TakeSnapshots(m *circuit.Manager) []Snapshot {
+	circuits := m.AllCircuits()
+	ret := make([]Snapshot, 0, len(circuits))
+	for _, c := range circuits {
+		ret = append(ret, TakeSnapshot(c))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// UnmarshalConfig reads a Config written by this or an older version of the schema
+func UnmarshalConfig(data []byte) (Config, error) {
+	var ret Config
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return ret, err
+	}
+	return ret, checkVersion(&ret.Version)
+}
+
+// UnmarshalSnapshots reads a list of Snapshot written by this or an older version of the schema
+func UnmarshalSnapshots(data []byte) ([]Snapshot, error) {
+	var ret []Snapshot
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		if err := checkVersion(&ret[i].Version); err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", ret[i].Name, err)
+		}
+		if err := checkVersion(&ret[i].Config.Version); err != nil {
+			return nil, fmt.Errorf("snapshot %s config: %w", ret[i].Name, err)
+		}
+	}
+	return ret, nil
+}
+
+// checkVersion fills in a missing version and rejects versions newer than this package
+func checkVersion(version *int) error {
+	if *version == 0 {
+		*version = Version
+	}
+	if *version > Version {
+		return fmt.Errorf("schema version %d is newer than the supported version %d", *version, Version)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package circuitschema
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestConfig_roundTrip(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := circuit.Config{
+		General: circuit.GeneralConfig{
+			Disabled:              true,
+			ForceOpen:             true,
+			ForcedClosed:          true,
+			DependsOn:             []string{"db"},
+			RecentErrorsSize:      3,
+			RecentBadRequestsSize: 2,
+			FlapWindow:            time.Minute,
+			MinClosedDuration:     time.Second,
+			FlapThreshold:         4,
+			FlapHoldOpen:          time.Hour,
+			MaintenanceWindows:    []circuit.MaintenanceWindow{{Start: start, End: start.Add(time.Hour)}},
+			WarmUpDuration:        5 * time.Second,
+			InitialState:          circuit.StateOpen,
+			Counters:              []string{"cache_hits"},
+			Thresholds:            map[string]int64{"hystrix.ErrorThresholdPercentage": 20},
+			DetectNesting:         true,
+		},
+		Execution: circuit.ExecutionConfig{
+			Timeout:                           1500 * time.Millisecond,
+			MaxConcurrentRequests:             10,
+			IgnoreInterrupts:                  true,
+			MaxConcurrentRequestsPerPartition: 2,
+			MaxPartitions:                     100,
+			DetachContext:                     true,
+			StuckTimeoutMultiple:              3,
+			MaxTimeoutOverride:                -1,
+			RetryBudgetPercent:                25,
+			RetryBudgetMinPerSecond:           -1,
+			RetryBudgetWindow:                 time.Minute,
+			MaxQueueSize:                      5,
+			MaxConcurrentWait:                 time.Second,
+			QueueOrder:                        circuit.QueueAdaptiveLIFO,
+			TimeoutGracePeriod:                time.Second,
+			ConcurrencyWarningPercent:         80,
+		},
+		Fallback: circuit.FallbackConfig{
+			Disabled:              true,
+			MaxConcurrentRequests: 7,
+			Shadow:                true,
+			ShadowServesFallback:  true,
+		},
+	}
+	b, err := json.Marshal(FromConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"timeout":"1.5s"`) {
+		t.Errorf("expected durations as strings, got %s", b)
+	}
+	back, err := UnmarshalConfig(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := back.CircuitConfig(); !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round trip changed the config\nexpected %+v\ngot      %+v", cfg, got)
+	}
+}
+
+// TestConfig_coversCircuitConfig fails when circuit.Config gains a serializable field the schema does not have
+func TestConfig_coversCircuitConfig(t *testing.T) {
+	pairs := []struct {
+		circuitType reflect.Type
+		schemaType  reflect.Type
+	}{
+		{reflect.TypeOf(circuit.GeneralConfig{}), reflect.TypeOf(GeneralConfig{})},
+		{reflect.TypeOf(circuit.ExecutionConfig{}), reflect.TypeOf(ExecutionConfig{})},
+		{reflect.TypeOf(circuit.FallbackConfig{}), reflect.TypeOf(FallbackConfig{})},
+	}
+	for _, p := range pairs {
+		for i := 0; i < p.circuitType.NumField(); i++ {
+			f := p.circuitType.Field(i)
+			if f.Tag.Get("json") == "-" || !f.IsExported() {
+				continue
+			}
+			if _, exists := p.schemaType.FieldByName(f.Name); !exists {
+				t.Errorf("%s.%s is not in the schema", p.circuitType.Name(), f.Name)
+			}
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var d Duration
+	if err := json.Unmarshal([]byte(`"250ms"`), &d); err != nil || time.Duration(d) != 250*time.Millisecond {
+		t.Errorf("expected 250ms, got %v %v", time.Duration(d), err)
+	}
+	if err := json.Unmarshal([]byte(`1000`), &d); err != nil || time.Duration(d) != time.Microsecond {
+		t.Errorf("expected nanoseconds, got %v %v", time.Duration(d), err)
+	}
+	if err := json.Unmarshal([]byte(`"soon"`), &d); err == nil {
+		t.Error("expected an invalid duration to fail")
+	}
+}
+
+func TestUnmarshalConfig_versions(t *testing.T) {
+	cfg, err := UnmarshalConfig([]byte(`{"execution":{"timeout":"2s"}}`))
+	if err != nil || cfg.Version != Version || time.Duration(cfg.Execution.Timeout) != 2*time.Second {
+		t.Errorf("expected a document without a version to be read as the current version, got %+v %v", cfg, err)
+	}
+	if _, err := UnmarshalConfig([]byte(`{"version":99}`)); err == nil {
+		t.Error("expected a newer version to be rejected")
+	}
+}
+
+func TestTakeSnapshots(t *testing.T) {
+	m := &circuit.Manager{}
+	m.MustCreateCircuit("b")
+	opened := m.MustCreateCircuit("a", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			Timeout: time.Second,
+		},
+	})
+	opened.OpenCircuit(context.Background())
+
+	b, err := json.Marshal(TakeSnapshots(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := UnmarshalSnapshots(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "a" || snapshots[1].Name != "b" {
+		t.Fatalf("expected snapshots sorted by name, got %+v", snapshots)
+	}
+	a := snapshots[0]
+	if !a.Open || a.Reason != string(circuit.StateReasonManual) || a.TimeInState.Current != string(circuit.StateOpen) {
+		t.Errorf("expected an open snapshot, got %+v", a)
+	}
+	if time.Duration(a.Config.Execution.Timeout) != time.Second {
+		t.Errorf("expected the circuit's config, got %+v", a.Config)
+	}
+}
@@ -0,0 +1,214 @@
+package schemapb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative schema.proto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cep21/circuit/v4/circuitschema"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromConfig returns the protobuf form of c
+func FromConfig(c circuitschema.Config) *Config {
+	ret := &Config{
+		Version: int32(c.Version),
+		General: &GeneralConfig{
+			Disabled:              c.General.Disabled,
+			ForceOpen:             c.General.ForceOpen,
+			ForcedClosed:          c.General.ForcedClosed,
+			DependsOn:             c.General.DependsOn,
+			RecentErrorsSize:      c.General.RecentErrorsSize,
+			RecentBadRequestsSize: c.General.RecentBadRequestsSize,
+			FlapWindow:            duration(c.General.FlapWindow),
+			MinClosedDuration:     duration(c.General.MinClosedDuration),
+			FlapThreshold:         c.General.FlapThreshold,
+			FlapHoldOpen:          duration(c.General.FlapHoldOpen),
+			WarmUpDuration:        duration(c.General.WarmUpDuration),
+			InitialState:          c.General.InitialState,
+			Counters:              c.General.Counters,
+			Thresholds:            c.General.Thresholds,
+			DetectNesting:         c.General.DetectNesting,
+		},
+		Execution: &ExecutionConfig{
+			Timeout:                           duration(c.Execution.Timeout),
+			MaxConcurrentRequests:             c.Execution.MaxConcurrentRequests,
+			IgnoreInterrupts:                  c.Execution.IgnoreInterrupts,
+			MaxConcurrentRequestsPerPartition: c.Execution.MaxConcurrentRequestsPerPartition,
+			MaxPartitions:                     c.Execution.MaxPartitions,
+			DetachContext:                     c.Execution.DetachContext,
+			StuckTimeoutMultiple:              c.Execution.StuckTimeoutMultiple,
+			MaxTimeoutOverride:                duration(c.Execution.MaxTimeoutOverride),
+			RetryBudgetPercent:                c.Execution.RetryBudgetPercent,
+			RetryBudgetMinPerSecond:           c.Execution.RetryBudgetMinPerSecond,
+			RetryBudgetWindow:                 duration(c.Execution.RetryBudgetWindow),
+			MaxQueueSize:                      c.Execution.MaxQueueSize,
+			MaxConcurrentWait:                 duration(c.Execution.MaxConcurrentWait),
+			QueueOrder:                        c.Execution.QueueOrder,
+			TimeoutGracePeriod:                duration(c.Execution.TimeoutGracePeriod),
+			ConcurrencyWarningPercent:         c.Execution.ConcurrencyWarningPercent,
+		},
+		Fallback: &FallbackConfig{
+			Disabled:              c.Fallback.Disabled,
+			MaxConcurrentRequests: c.Fallback.MaxConcurrentRequests,
+			Shadow:                c.Fallback.Shadow,
+			ShadowServesFallback:  c.Fallback.ShadowServesFallback,
+		},
+	}
+	for _, w := range c.General.MaintenanceWindows {
+		ret.General.MaintenanceWindows = append(ret.General.MaintenanceWindows, &MaintenanceWindow{
+			Start: timestamppb.New(w.Start),
+			End:   timestamppb.New(w.End),
+		})
+	}
+	return ret
+}
+
+// ToConfig returns p as a circuitschema.Config.  It fails if p is from a newer version of the schema.
+func ToConfig(p *Config) (circuitschema.Config, error) {
+	version, err := checkVersion(p.GetVersion())
+	if err != nil {
+		return circuitschema.Config{}, err
+	}
+	general := p.GetGeneral()
+	execution := p.GetExecution()
+	fallback := p.GetFallback()
+	ret := circuitschema.Config{
+		Version: version,
+		General: circuitschema.GeneralConfig{
+			Disabled:              general.GetDisabled(),
+			ForceOpen:             general.GetForceOpen(),
+			ForcedClosed:          general.GetForcedClosed(),
+			DependsOn:             general.GetDependsOn(),
+			RecentErrorsSize:      general.GetRecentErrorsSize(),
+			RecentBadRequestsSize: general.GetRecentBadRequestsSize(),
+			FlapWindow:            fromDuration(general.GetFlapWindow()),
+			MinClosedDuration:     fromDuration(general.GetMinClosedDuration()),
+			FlapThreshold:         general.GetFlapThreshold(),
+			FlapHoldOpen:          fromDuration(general.GetFlapHoldOpen()),
+			WarmUpDuration:        fromDuration(general.GetWarmUpDuration()),
+			InitialState:          general.GetInitialState(),
+			Counters:              general.GetCounters(),
+			Thresholds:            general.GetThresholds(),
+			DetectNesting:         general.GetDetectNesting(),
+		},
+		Execution: circuitschema.ExecutionConfig{
+			Timeout:                           fromDuration(execution.GetTimeout()),
+			MaxConcurrentRequests:             execution.GetMaxConcurrentRequests(),
+			IgnoreInterrupts:                  execution.GetIgnoreInterrupts(),
+			MaxConcurrentRequestsPerPartition: execution.GetMaxConcurrentRequestsPerPartition(),
+			MaxPartitions:                     execution.GetMaxPartitions(),
+			DetachContext:                     execution.GetDetachContext(),
+			StuckTimeoutMultiple:              execution.GetStuckTimeoutMultiple(),
+			MaxTimeoutOverride:                fromDuration(execution.GetMaxTimeoutOverride()),
+			RetryBudgetPercent:                execution.GetRetryBudgetPercent(),
+			RetryBudgetMinPerSecond:           execution.GetRetryBudgetMinPerSecond(),
+			RetryBudgetWindow:                 fromDuration(execution.GetRetryBudgetWindow()),
+			MaxQueueSize:                      execution.GetMaxQueueSize(),
+			MaxConcurrentWait:                 fromDuration(execution.GetMaxConcurrentWait()),
+			QueueOrder:                        execution.GetQueueOrder(),
+			TimeoutGracePeriod:                fromDuration(execution.GetTimeoutGracePeriod()),
+			ConcurrencyWarningPercent:         execution.GetConcurrencyWarningPercent(),
+		},
+		Fallback: circuitschema.FallbackConfig{
+			Disabled:              fallback.GetDisabled(),
+			MaxConcurrentRequests: fallback.GetMaxConcurrentRequests(),
+			Shadow:                fallback.GetShadow(),
+			ShadowServesFallback:  fallback.GetShadowServesFallback(),
+		},
+	}
+	for _, w := range general.GetMaintenanceWindows() {
+		ret.General.MaintenanceWindows = append(ret.General.MaintenanceWindows, circuitschema.MaintenanceWindow{
+			Start: w.GetStart().AsTime(),
+			End:   w.GetEnd().AsTime(),
+		})
+	}
+	return ret, nil
+}
+
+// FromSnapshot returns the protobuf form of s
+func FromSnapshot(s circuitschema.Snapshot) *Snapshot {
+	ret := &Snapshot{
+		Version:             int32(s.Version),
+		Name:                s.Name,
+		Time:                timestamppb.New(s.Time),
+		Open:                s.Open,
+		Reason:              s.Reason,
+		Since:               timestamppb.New(s.Since),
+		ConcurrentCommands:  s.ConcurrentCommands,
+		ConcurrentFallbacks: s.ConcurrentFallbacks,
+		TimeInState: &TimeInState{
+			Current:  s.TimeInState.Current,
+			Closed:   duration(s.TimeInState.Closed),
+			Open:     duration(s.TimeInState.Open),
+			HalfOpen: duration(s.TimeInState.HalfOpen),
+		},
+		Config: FromConfig(s.Config),
+	}
+	if s.NextProbe != nil {
+		ret.NextProbe = timestamppb.New(*s.NextProbe)
+	}
+	return ret
+}
+
+// ToSnapshot returns p as a circuitschema.Snapshot.  It fails if p is from a newer version of the schema.
+func ToSnapshot(p *Snapshot) (circuitschema.Snapshot, error) {
+	version, err := checkVersion(p.GetVersion())
+	if err != nil {
+		return circuitschema.Snapshot{}, err
+	}
+	config, err := ToConfig(p.GetConfig())
+	if err != nil {
+		return circuitschema.Snapshot{}, fmt.Errorf("snapshot %s config: %w", p.GetName(), err)
+	}
+	inState := p.GetTimeInState()
+	ret := circuitschema.Snapshot{
+		Version:             version,
+		Name:                p.GetName(),
+		Time:                p.GetTime().AsTime(),
+		Open:                p.GetOpen(),
+		Reason:              p.GetReason(),
+		Since:               p.GetSince().AsTime(),
+		ConcurrentCommands:  p.GetConcurrentCommands(),
+		ConcurrentFallbacks: p.GetConcurrentFallbacks(),
+		TimeInState: circuitschema.TimeInState{
+			Current:  inState.GetCurrent(),
+			Closed:   fromDuration(inState.GetClosed()),
+			Open:     fromDuration(inState.GetOpen()),
+			HalfOpen: fromDuration(inState.GetHalfOpen()),
+		},
+		Config: config,
+	}
+	if p.GetNextProbe() != nil {
+		nextProbe := p.GetNextProbe().AsTime()
+		ret.NextProbe = &nextProbe
+	}
+	return ret, nil
+}
+
+func checkVersion(version int32) (int, error) {
+	if version == 0 {
+		return circuitschema.Version, nil
+	}
+	if version > circuitschema.Version {
+		return 0, fmt.Errorf("schema version %d is newer than the supported version %d", version, circuitschema.Version)
+	}
+	return int(version), nil
+}
+
+// duration leaves zero durations unset, so they are left out of the encoding
+func duration(d circuitschema.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(time.Duration(d))
+}
+
+func fromDuration(d *durationpb.Duration) circuitschema.Duration {
+	if d == nil {
+		return 0
+	}
+	return circuitschema.Duration(d.AsDuration())
+}
@@ -0,0 +1,79 @@
+package schemapb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/circuitschema"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSnapshot_roundTrip(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextProbe := now.Add(5 * time.Second)
+	s := circuitschema.Snapshot{
+		Version:            circuitschema.Version,
+		Name:               "db",
+		Time:               now,
+		Open:               true,
+		Reason:             "threshold",
+		Since:              now.Add(-time.Minute),
+		NextProbe:          &nextProbe,
+		ConcurrentCommands: 3,
+		TimeInState: circuitschema.TimeInState{
+			Current: "open",
+			Closed:  circuitschema.Duration(time.Hour),
+			Open:    circuitschema.Duration(time.Minute),
+		},
+		Config: circuitschema.Config{
+			Version: circuitschema.Version,
+			General: circuitschema.GeneralConfig{
+				DependsOn:          []string{"network"},
+				FlapWindow:         circuitschema.Duration(time.Minute),
+				MaintenanceWindows: []circuitschema.MaintenanceWindow{{Start: now, End: now.Add(time.Hour)}},
+				InitialState:       "open",
+				Counters:           []string{"cache_hits"},
+				Thresholds:         map[string]int64{"hystrix.SleepWindow": int64(time.Second)},
+				DetectNesting:      true,
+			},
+			Execution: circuitschema.ExecutionConfig{
+				Timeout:               circuitschema.Duration(time.Second),
+				MaxConcurrentRequests: 10,
+				MaxTimeoutOverride:    -1,
+				RetryBudgetWindow:     circuitschema.Duration(time.Minute),
+			},
+			Fallback: circuitschema.FallbackConfig{
+				Shadow: true,
+			},
+		},
+	}
+	b, err := proto.Marshal(FromSnapshot(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Snapshot
+	if err := proto.Unmarshal(b, &p); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ToSnapshot(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, s) {
+		t.Errorf("round trip changed the snapshot\nexpected %+v\ngot      %+v", s, back)
+	}
+}
+
+func TestToConfig_versions(t *testing.T) {
+	cfg, err := ToConfig(&Config{})
+	if err != nil || cfg.Version != circuitschema.Version {
+		t.Errorf("expected a missing version to be read as the current version, got %+v %v", cfg, err)
+	}
+	if _, err := ToConfig(&Config{Version: circuitschema.Version + 1}); err == nil {
+		t.Error("expected a newer version to be rejected")
+	}
+	if _, err := ToSnapshot(&Snapshot{Config: &Config{Version: circuitschema.Version + 1}}); err == nil {
+		t.Error("expected a snapshot with a newer config version to be rejected")
+	}
+}
@@ -0,0 +1,45 @@
+package schemapb
+
+import (
+	"encoding/json"
+
+	"github.com/cep21/circuit/v4/circuitevents"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromEvent returns the protobuf form of e.  It fails if a changed config field cannot be encoded as JSON.
+func FromEvent(e circuitevents.Event) (*Event, error) {
+	ret := &Event{
+		Type:     string(e.Type),
+		Circuit:  e.Circuit,
+		Time:     timestamppb.New(e.Time),
+		Instance: e.Instance,
+		Source:   e.Source,
+	}
+	for _, f := range e.Fields {
+		oldJSON, err := json.Marshal(f.Old)
+		if err != nil {
+			return nil, err
+		}
+		newJSON, err := json.Marshal(f.New)
+		if err != nil {
+			return nil, err
+		}
+		ret.Fields = append(ret.Fields, &ConfigFieldChange{
+			Field:   f.Field,
+			OldJson: string(oldJSON),
+			NewJson: string(newJSON),
+		})
+	}
+	return ret, nil
+}
+
+// EncodeEvent encodes an event as protobuf.  Use it as circuitevents.Exporter.Encode.
+func EncodeEvent(e circuitevents.Event) ([]byte, error) {
+	p, err := FromEvent(e)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(p)
+}
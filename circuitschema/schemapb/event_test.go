@@ -0,0 +1,39 @@
+package schemapb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitevents"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := EncodeEvent(circuitevents.Event{
+		Type:     circuitevents.EventConfigChanged,
+		Circuit:  "db",
+		Time:     now,
+		Instance: "host-1",
+		Source:   "admin:alice",
+		Fields: []circuit.ConfigFieldChange{{
+			Field: "Execution.MaxConcurrentRequests",
+			Old:   int64(10),
+			New:   int64(20),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Event
+	if err := proto.Unmarshal(b, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.GetType() != "config_changed" || e.GetCircuit() != "db" || !e.GetTime().AsTime().Equal(now) || e.GetInstance() != "host-1" {
+		t.Errorf("unexpected event %v", &e)
+	}
+	if len(e.GetFields()) != 1 || e.GetFields()[0].GetOldJson() != "10" || e.GetFields()[0].GetNewJson() != "20" {
+		t.Errorf("unexpected fields %v", e.GetFields())
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/cep21/circuit/circuitschema/schemapb
+
+go 1.23
+
+require (
+	github.com/cep21/circuit/v4 v4.0.0
+	google.golang.org/protobuf v1.36.11
+)
+
+replace github.com/cep21/circuit/v4 => ../..
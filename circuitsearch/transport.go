@@ -0,0 +1,40 @@
+/*
+Package circuitsearch runs the requests of Elasticsearch and OpenSearch clients in a circuit per cluster.  The clients
+take any http.RoundTripper, so the package does not depend on them.
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: addresses,
+		Transport: circuitsearch.NewTransport(manager, "logs", nil),
+	})
+*/
+package circuitsearch
+
+import (
+	"net/http"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuithttp"
+)
+
+// NewTransport returns a transport that runs every request to a cluster in the circuit named cluster.  The clients
+// spread requests over the cluster's nodes, so one circuit for the cluster sees its health as a whole, where a circuit
+// per node would not.  base makes the requests, and defaults to http.DefaultTransport.  Change the returned
+// transport's Config or Classifier before using it to change how the circuit is created or how responses count.
+//
+// Each transport gets its own Classifier, with circuithttp's defaults.  A cluster rejecting work answers 429, both when
+// a thread pool queue is full (es_rejected_execution_exception) and when a memory circuit breaker trips
+// (circuit_breaking_exception).  circuithttp counts 429 as shed: the cluster is protecting itself, so it does not count
+// against the circuit, and the client still sees the 429 to back off from.  5xx responses are failures, and other 4xx
+// responses are bad requests.
+func NewTransport(m *circuit.Manager, cluster string, base http.RoundTripper) *circuithttp.Transport {
+	return &circuithttp.Transport{
+		Manager: m,
+		Base:    base,
+		CircuitName: func(_ *http.Request) string {
+			return cluster
+		},
+		Classifier: &circuithttp.Classifier{},
+		// The cluster's circuit is not per host, so it is never idle long enough to remove
+		IdleTimeout: -1,
+	}
+}
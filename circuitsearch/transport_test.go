@@ -0,0 +1,106 @@
+package circuitsearch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuithttp"
+)
+
+type countingMetrics struct {
+	mu          sync.Mutex
+	successes   int
+	failures    int
+	badRequests int
+}
+
+func (c *countingMetrics) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingMetrics) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+}
+
+func (c *countingMetrics) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.badRequests++
+}
+
+func (c *countingMetrics) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)   {}
+func (c *countingMetrics) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+func (c *countingMetrics) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)     {}
+func (c *countingMetrics) ErrShortCircuit(_ context.Context, _ time.Time)               {}
+
+// testNode answers like a cluster node
+func testNode(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/_bulk":
+			rw.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(rw, `{"error":{"type":"es_rejected_execution_exception"},"status":429}`)
+		case "/broken/_search":
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(rw, `{"error":{"type":"cluster_block_exception"},"status":503}`)
+		default:
+			_, _ = io.WriteString(rw, `{}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewTransport(t *testing.T) {
+	metrics := &countingMetrics{}
+	m := &circuit.Manager{}
+	transport := NewTransport(m, "logs", nil)
+	transport.Config = circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Run: []circuit.RunMetrics{metrics},
+		},
+	}
+	client := &http.Client{Transport: transport}
+	nodes := []*httptest.Server{testNode(t), testNode(t)}
+	for _, path := range []string{"/_search", "/_bulk", "/broken/_search"} {
+		for _, node := range nodes {
+			resp, err := client.Get(node.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			if path == "/_bulk" && resp.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("expected the client to see the 429, got %d", resp.StatusCode)
+			}
+		}
+	}
+	if m.GetCircuit("logs") == nil || len(m.AllCircuits()) != 1 {
+		t.Errorf("expected one circuit for the cluster, got %d", len(m.AllCircuits()))
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.successes != 2 || metrics.badRequests != 2 || metrics.failures != 2 {
+		t.Errorf("expected rejected executions to be shed, got %+v", metrics)
+	}
+}
+
+func TestNewTransport_ownClassifier(t *testing.T) {
+	m := &circuit.Manager{}
+	first := NewTransport(m, "logs", nil)
+	second := NewTransport(m, "metrics", nil)
+	first.Classifier.Overrides = map[int]circuithttp.Outcome{http.StatusTooManyRequests: circuithttp.OutcomeFailure}
+	if outcome := second.Classifier.Outcome(http.StatusTooManyRequests); outcome != circuithttp.OutcomeShed {
+		t.Errorf("expected changing one transport's classifier to leave the others alone, got %s", outcome)
+	}
+}
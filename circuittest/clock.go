@@ -0,0 +1,53 @@
+package circuittest
+
+import (
+	"math"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/clock"
+)
+
+// Clock is a fake clock.  Time only moves when Advance or Set is called.
+type Clock struct {
+	mock clock.MockClock
+}
+
+// NewClock returns a Clock that starts at now
+func NewClock(now time.Time) *Clock {
+	ret := &Clock{}
+	ret.mock.Set(now)
+	return ret
+}
+
+// Now returns the clock's time
+func (c *Clock) Now() time.Time {
+	return c.mock.Now()
+}
+
+// AfterFunc calls f once the clock is advanced by d.  Stopping the returned timer does not stop f.
+func (c *Clock) AfterFunc(d time.Duration, f func()) *time.Timer {
+	c.mock.AfterFunc(d, f)
+	// Callers may stop the timer, so it cannot be nil
+	ret := time.NewTimer(math.MaxInt64)
+	ret.Stop()
+	return ret
+}
+
+// Advance moves the clock forward by d, calling the AfterFunc callbacks that are now due
+func (c *Clock) Advance(d time.Duration) time.Time {
+	return c.mock.Add(d)
+}
+
+// Set moves the clock to now, calling the AfterFunc callbacks that are now due
+func (c *Clock) Set(now time.Time) time.Time {
+	return c.mock.Set(now)
+}
+
+// TimeKeeper returns a circuit.TimeKeeper that keeps time with the clock
+func (c *Clock) TimeKeeper() circuit.TimeKeeper {
+	return circuit.TimeKeeper{
+		Now:       c.Now,
+		AfterFunc: c.AfterFunc,
+	}
+}
@@ -0,0 +1,17 @@
+/*
+Package circuittest helps test code that uses circuits, and opener and closer policies.
+
+A Harness is a circuit with a fake Clock and a Recorder of its metrics.  Its runs end with the outcome a test asks for,
+and time only moves when the test moves it, so tests of timeouts, sleep windows and rolling windows are deterministic.
+
+	h := circuittest.NewHarness("db", circuit.Config{
+		General: circuit.GeneralConfig{
+			ClosedToOpenFactory: hystrix.OpenerFactory(hystrix.ConfigureOpener{RequestVolumeThreshold: 2}),
+		},
+	})
+	h.RunSteps(t, []circuittest.Step{
+		{Outcome: circuit.OutcomeFailure, Times: 2, ExpectOpen: true},
+		{Advance: time.Minute, Outcome: circuit.OutcomeSuccess, ExpectOpen: false},
+	})
+*/
+package circuittest
@@ -0,0 +1,25 @@
+package circuittest_test
+
+import (
+	"fmt"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuittest"
+	"github.com/cep21/circuit/v4/closers/simplelogic"
+)
+
+// This example checks that a circuit opens after three failures in a row
+func ExampleHarness() {
+	h := circuittest.NewHarness("db", circuit.Config{
+		General: circuit.GeneralConfig{
+			ClosedToOpenFactory: simplelogic.ConsecutiveErrOpenerFactory(simplelogic.ConfigConsecutiveErrOpener{
+				ErrorThreshold: 3,
+			}),
+		},
+	})
+	for i := 0; i < 3; i++ {
+		_ = h.Run(circuit.OutcomeFailure)
+	}
+	fmt.Println(h.Circuit.IsOpen(), h.Metrics.Opens())
+	// Output: true 1
+}
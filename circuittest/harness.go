@@ -0,0 +1,131 @@
+package circuittest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// ErrFailure is the error runs return for circuit.OutcomeFailure
+var ErrFailure = errors.New("circuittest: failure")
+
+// Harness is a circuit that keeps time with a fake Clock, and whose metrics are counted by a Recorder
+type Harness struct {
+	Circuit *circuit.Circuit
+	Clock   *Clock
+	Metrics *Recorder
+}
+
+// NewHarness creates a circuit from config with a fake Clock and a Recorder.  The clock starts at the current time.
+// Openers and closers that take their own clock, like the hystrix ones, should be given Clock.Now and
+// Clock.AfterFunc.
+func NewHarness(name string, config circuit.Config) *Harness {
+	return NewHarnessWithClock(name, config, NewClock(time.Now()))
+}
+
+// NewHarnessWithClock is NewHarness with a clock made by the caller, so it can be given to openers and closers in
+// config
+func NewHarnessWithClock(name string, config circuit.Config, clock *Clock) *Harness {
+	ret := &Harness{
+		Clock:   clock,
+		Metrics: &Recorder{},
+	}
+	config.General.TimeKeeper = clock.TimeKeeper()
+	config.Metrics.Run = append(config.Metrics.Run, ret.Metrics)
+	config.Metrics.Circuit = append(config.Metrics.Circuit, ret.Metrics)
+	ret.Circuit = circuit.NewCircuitFromConfig(name, config)
+	return ret
+}
+
+// AdvanceTime moves the harness's clock forward by d
+func (h *Harness) AdvanceTime(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// ForceOpen opens the circuit, as if OpenCircuit were called
+func (h *Harness) ForceOpen() {
+	h.Circuit.OpenCircuit(context.Background())
+}
+
+// ForceClosed closes the circuit, as if CloseCircuit were called
+func (h *Harness) ForceClosed() {
+	h.Circuit.CloseCircuit(context.Background())
+}
+
+// Run runs the circuit once with a runFunc that ends with outcome, and returns what Run returned.  The circuit may
+// still short circuit or reject the run.  Only outcomes of runs that are called can be asked for: success, failure,
+// timeout, bad request and interrupt.  A timeout moves the clock past the circuit's timeout while the run is going.
+func (h *Harness) Run(outcome circuit.OutcomeType) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	return h.Circuit.Run(ctx, func(ctx context.Context) error {
+		switch outcome {
+		case circuit.OutcomeSuccess:
+			return nil
+		case circuit.OutcomeFailure:
+			return ErrFailure
+		case circuit.OutcomeTimeout:
+			h.Clock.Advance(h.Circuit.Config().Execution.Timeout + time.Nanosecond)
+			return ctx.Err()
+		case circuit.OutcomeBadRequest:
+			return circuit.SimpleBadRequest{Err: ErrFailure}
+		case circuit.OutcomeInterrupt:
+			cancel()
+			return ctx.Err()
+		}
+		panic(fmt.Sprintf("circuittest: runs cannot end with outcome %q", outcome))
+	})
+}
+
+// Step is one step of a table driven test of a circuit.  The clock is moved forward by Advance, then the circuit is
+// run Times times with Outcome.  After that, the circuit must be open if ExpectOpen is set, and closed if it is not.
+type Step struct {
+	// Advance is how far to move the clock before the runs
+	Advance time.Duration
+	// Outcome is how the runs end.  Leave it empty to run nothing.
+	Outcome circuit.OutcomeType
+	// Times is how many times to run.  The default is once.
+	Times int
+	// ExpectOpen is whether the circuit is open after the runs
+	ExpectOpen bool
+}
+
+// RunSteps runs each step in order, and fails t at the first step that leaves the circuit in the wrong state
+func (h *Harness) RunSteps(t testing.TB, steps []Step) {
+	t.Helper()
+	for i, step := range steps {
+		h.AdvanceTime(step.Advance)
+		if step.Outcome != "" {
+			times := step.Times
+			if times == 0 {
+				times = 1
+			}
+			for j := 0; j < times; j++ {
+				_ = h.Run(step.Outcome)
+			}
+		}
+		if h.Circuit.IsOpen() != step.ExpectOpen {
+			t.Fatalf("step %d: expected open to be %t, got %t", i, step.ExpectOpen, h.Circuit.IsOpen())
+		}
+	}
+}
+
+// AssertOpened fails t if c is not open
+func AssertOpened(t testing.TB, c *circuit.Circuit) {
+	t.Helper()
+	if !c.IsOpen() {
+		t.Errorf("expected circuit %s to be open", c.Name())
+	}
+}
+
+// AssertClosed fails t if c is not closed
+func AssertClosed(t testing.TB, c *circuit.Circuit) {
+	t.Helper()
+	if c.IsOpen() {
+		t.Errorf("expected circuit %s to be closed", c.Name())
+	}
+}
@@ -0,0 +1,80 @@
+package circuittest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func TestHarness_Outcomes(t *testing.T) {
+	h := NewHarness("TestHarness_Outcomes", circuit.Config{})
+	if err := h.Run(circuit.OutcomeSuccess); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := h.Run(circuit.OutcomeFailure); !errors.Is(err, ErrFailure) {
+		t.Errorf("expected ErrFailure, got %v", err)
+	}
+	_ = h.Run(circuit.OutcomeTimeout)
+	if err := h.Run(circuit.OutcomeBadRequest); !circuit.IsBadRequest(err) {
+		t.Errorf("expected a bad request, got %v", err)
+	}
+	_ = h.Run(circuit.OutcomeInterrupt)
+	for _, outcome := range []circuit.OutcomeType{circuit.OutcomeSuccess, circuit.OutcomeFailure, circuit.OutcomeTimeout, circuit.OutcomeBadRequest, circuit.OutcomeInterrupt} {
+		h.Metrics.AssertCount(t, outcome, 1)
+	}
+}
+
+func TestHarness_ForceOpen(t *testing.T) {
+	h := NewHarness("TestHarness_ForceOpen", circuit.Config{})
+	h.ForceOpen()
+	AssertOpened(t, h.Circuit)
+	_ = h.Run(circuit.OutcomeSuccess)
+	h.Metrics.AssertCount(t, circuit.OutcomeShortCircuit, 1)
+	h.ForceClosed()
+	AssertClosed(t, h.Circuit)
+	if h.Metrics.Opens() != 1 || h.Metrics.Closes() != 1 {
+		t.Errorf("expected one open and one close, got %d and %d", h.Metrics.Opens(), h.Metrics.Closes())
+	}
+}
+
+func TestHarness_RunSteps(t *testing.T) {
+	clock := NewClock(time.Now())
+	h := NewHarnessWithClock("TestHarness_RunSteps", circuit.Config{
+		General: circuit.GeneralConfig{
+			ClosedToOpenFactory: hystrix.OpenerFactory(hystrix.ConfigureOpener{
+				RequestVolumeThreshold: 3,
+				Now:                    clock.Now,
+			}),
+			OpenToClosedFactory: hystrix.CloserFactory(hystrix.ConfigureCloser{
+				SleepWindow: time.Second,
+				AfterFunc:   clock.AfterFunc,
+			}),
+		},
+	}, clock)
+	h.RunSteps(t, []Step{
+		{Outcome: circuit.OutcomeFailure, Times: 2, ExpectOpen: false},
+		{Outcome: circuit.OutcomeTimeout, ExpectOpen: true},
+		{Outcome: circuit.OutcomeSuccess, ExpectOpen: true},
+		{Advance: 2 * time.Second, Outcome: circuit.OutcomeSuccess, ExpectOpen: false},
+	})
+	h.Metrics.AssertCount(t, circuit.OutcomeShortCircuit, 1)
+}
+
+func TestClock_AfterFunc(t *testing.T) {
+	clock := NewClock(time.Now())
+	called := false
+	clock.AfterFunc(time.Second, func() {
+		called = true
+	}).Stop()
+	clock.Advance(time.Second / 2)
+	if called {
+		t.Error("expected the callback to wait for the clock")
+	}
+	clock.Advance(time.Second)
+	if !called {
+		t.Error("expected the callback once the clock passed it")
+	}
+}
@@ -0,0 +1,108 @@
+package circuittest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Recorder counts a circuit's metrics.  Add it to MetricsCollectors.Run and MetricsCollectors.Circuit.
+type Recorder struct {
+	mu       sync.Mutex
+	outcomes map[circuit.OutcomeType]int64
+	opens    int64
+	closes   int64
+}
+
+var _ circuit.RunMetrics = &Recorder{}
+var _ circuit.Metrics = &Recorder{}
+
+// Count returns how many runs ended with outcome
+func (r *Recorder) Count(outcome circuit.OutcomeType) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outcomes[outcome]
+}
+
+// Opens returns how many times the circuit opened
+func (r *Recorder) Opens() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.opens
+}
+
+// Closes returns how many times the circuit closed
+func (r *Recorder) Closes() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closes
+}
+
+// AssertCount fails t if the number of runs that ended with outcome is not expected
+func (r *Recorder) AssertCount(t testing.TB, outcome circuit.OutcomeType, expected int64) {
+	t.Helper()
+	if count := r.Count(outcome); count != expected {
+		t.Errorf("expected %d %s runs, got %d", expected, outcome, count)
+	}
+}
+
+func (r *Recorder) inc(outcome circuit.OutcomeType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.outcomes == nil {
+		r.outcomes = make(map[circuit.OutcomeType]int64)
+	}
+	r.outcomes[outcome]++
+}
+
+// Success counts a success
+func (r *Recorder) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(circuit.OutcomeSuccess)
+}
+
+// ErrFailure counts a failure
+func (r *Recorder) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(circuit.OutcomeFailure)
+}
+
+// ErrTimeout counts a timeout
+func (r *Recorder) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(circuit.OutcomeTimeout)
+}
+
+// ErrBadRequest counts a bad request
+func (r *Recorder) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(circuit.OutcomeBadRequest)
+}
+
+// ErrInterrupt counts an interrupt
+func (r *Recorder) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {
+	r.inc(circuit.OutcomeInterrupt)
+}
+
+// ErrConcurrencyLimitReject counts a concurrency limit reject
+func (r *Recorder) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {
+	r.inc(circuit.OutcomeConcurrencyLimitReject)
+}
+
+// ErrShortCircuit counts a short circuit
+func (r *Recorder) ErrShortCircuit(_ context.Context, _ time.Time) {
+	r.inc(circuit.OutcomeShortCircuit)
+}
+
+// Opened counts an open
+func (r *Recorder) Opened(_ context.Context, _ time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opens++
+}
+
+// Closed counts a close
+func (r *Recorder) Closed(_ context.Context, _ time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closes++
+}
@@ -0,0 +1,197 @@
+/*
+Package circuitxds applies circuit configs streamed from a control plane, for organizations that manage resilience
+policy centrally.  It is modeled on xDS: the client subscribes with a node name, and the control plane sends the whole
+set of circuit configs for that node each time it changes.  The client applies each set live, creating circuits that
+are new and removing circuits it created that are no longer sent, then acks or nacks it.  The service is defined in
+configpb/config.proto, using the config schema of package schemapb.  It is a separate module so the circuit module does
+not depend on gRPC.
+*/
+package circuitxds
+
+//go:generate protoc -I ../circuitschema/schemapb -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative configpb/config.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/circuitschema/schemapb"
+	"github.com/cep21/circuit/circuitxds/configpb"
+	"github.com/cep21/circuit/v4"
+	"google.golang.org/grpc"
+)
+
+// Client subscribes to a control plane and applies the circuit configs it sends to Manager.  Fields a config does not
+// set keep the value the circuit had before the control plane managed it.  For circuits the client created, that is
+// the value from the first config, since some settings only take effect when a circuit is created.
+type Client struct {
+	Manager *circuit.Manager
+	// Conn is the connection to the control plane
+	Conn grpc.ClientConnInterface
+	// Node names this process to the control plane, like a service or pod name
+	Node string
+	// RetryInterval is how long to wait before subscribing again after the stream ends.  The default is five seconds.
+	RetryInterval time.Duration
+	// OnError, if set, is called with errors from the stream and with configs that could not be applied
+	OnError func(err error)
+
+	mu sync.Mutex
+	// managed are the circuits the control plane has sent configs for, by name
+	managed map[string]*managedCircuit
+	// version is the version of the last config set that was applied in full
+	version string
+}
+
+// managedCircuit is a circuit the control plane sent a config for
+type managedCircuit struct {
+	circuit *circuit.Circuit
+	// base is the config that fields the control plane does not set come from
+	base circuit.Config
+	// created is true if the client created the circuit, so it is removed when the control plane stops sending it
+	created bool
+}
+
+func (c *Client) retryInterval() time.Duration {
+	if c.RetryInterval == 0 {
+		return 5 * time.Second
+	}
+	return c.RetryInterval
+}
+
+func (c *Client) onError(err error) {
+	if c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+// Version returns the version of the last config set that was applied in full, or "" if none was.  It is the version
+// acks and nacks report as accepted.
+func (c *Client) Version() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Run subscribes to the control plane and applies configs until ctx ends.  If the stream ends, it subscribes again
+// after RetryInterval.  It returns ctx's error.
+func (c *Client) Run(ctx context.Context) error {
+	for {
+		if err := c.stream(ctx); err != nil && ctx.Err() == nil {
+			c.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retryInterval()):
+		}
+	}
+}
+
+// stream subscribes once, and applies configs until the stream ends
+func (c *Client) stream(ctx context.Context) error {
+	stream, err := configpb.NewConfigDiscoveryClient(c.Conn).StreamConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&configpb.ConfigRequest{Node: c.Node, VersionInfo: c.Version()}); err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		ack := &configpb.ConfigRequest{
+			Node:          c.Node,
+			ResponseNonce: resp.GetNonce(),
+		}
+		if err := c.Apply(resp); err != nil {
+			c.onError(err)
+			ack.ErrorDetail = err.Error()
+		}
+		// A nack reports the last version applied in full, which is what is still running
+		ack.VersionInfo = c.Version()
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+}
+
+// Apply applies a set of configs, as if the control plane sent it.  If any config of the set is invalid, none is
+// applied.  If a circuit cannot be created, the rest of the set is still applied, but Version does not move to the
+// set's version.
+func (c *Client) Apply(resp *configpb.ConfigResponse) error {
+	configs := make(map[string]circuit.Config, len(resp.GetCircuits()))
+	for name, p := range resp.GetCircuits() {
+		cfg, err := schemapb.ToConfig(p)
+		if err != nil {
+			return fmt.Errorf("circuit %s: %w", name, err)
+		}
+		configs[name] = cfg.CircuitConfig()
+	}
+	source := "xds:" + resp.GetVersionInfo()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.managed == nil {
+		c.managed = make(map[string]*managedCircuit)
+	}
+	var errs []error
+	for _, name := range sortedNames(configs) {
+		if err := c.applyWithLock(name, configs[name], source); err != nil {
+			errs = append(errs, fmt.Errorf("circuit %s: %w", name, err))
+		}
+	}
+	for name, m := range c.managed {
+		if _, exists := configs[name]; exists {
+			continue
+		}
+		delete(c.managed, name)
+		if m.created {
+			c.Manager.RemoveCircuit(m.circuit)
+		} else {
+			m.circuit.SetConfigFrom(source, m.base)
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	c.version = resp.GetVersionInfo()
+	return nil
+}
+
+// applyWithLock applies the config of one circuit, creating the circuit if it does not exist
+func (c *Client) applyWithLock(name string, cfg circuit.Config, source string) error {
+	m, exists := c.managed[name]
+	if !exists {
+		existing := c.Manager.GetCircuit(name)
+		if existing == nil {
+			created, err := c.Manager.CreateCircuit(name, cfg)
+			if err == nil {
+				c.managed[name] = &managedCircuit{circuit: created, base: created.Config(), created: true}
+				return nil
+			}
+			// Someone else created it first
+			if existing = c.Manager.GetCircuit(name); existing == nil {
+				return err
+			}
+		}
+		m = &managedCircuit{circuit: existing, base: existing.Config()}
+		c.managed[name] = m
+	}
+	cfg.Merge(m.base)
+	m.circuit.SetConfigFrom(source, cfg)
+	return nil
+}
+
+func sortedNames(configs map[string]circuit.Config) []string {
+	ret := make([]string, 0, len(configs))
+	for name := range configs {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,151 @@
+package circuitxds
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/cep21/circuit/circuitschema/schemapb"
+	"github.com/cep21/circuit/circuitxds/configpb"
+	"github.com/cep21/circuit/v4"
+)
+
+// controlPlane sends each response in responses, and records the requests it gets
+type controlPlane struct {
+	configpb.UnimplementedConfigDiscoveryServer
+	responses chan *configpb.ConfigResponse
+	requests  chan *configpb.ConfigRequest
+}
+
+func (c *controlPlane) StreamConfigs(stream configpb.ConfigDiscovery_StreamConfigsServer) error {
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			c.requests <- req
+		}
+	}()
+	for {
+		select {
+		case resp := <-c.responses:
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func newTestClient(t *testing.T, m *circuit.Manager) (*Client, *controlPlane) {
+	cp := &controlPlane{
+		responses: make(chan *configpb.ConfigResponse),
+		requests:  make(chan *configpb.ConfigRequest, 10),
+	}
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	configpb.RegisterConfigDiscoveryServer(server, cp)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return &Client{Manager: m, Conn: conn, Node: "test-node", RetryInterval: time.Millisecond}, cp
+}
+
+func nextRequest(t *testing.T, cp *controlPlane) *configpb.ConfigRequest {
+	t.Helper()
+	select {
+	case req := <-cp.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a request")
+		return nil
+	}
+}
+
+func timeoutConfig(d time.Duration) *schemapb.Config {
+	return &schemapb.Config{
+		Execution: &schemapb.ExecutionConfig{
+			Timeout: durationpb.New(d),
+		},
+	}
+}
+
+func TestClient(t *testing.T) {
+	m := &circuit.Manager{}
+	existing := m.MustCreateCircuit("existing", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			MaxConcurrentRequests: 5,
+		},
+	})
+	client, cp := newTestClient(t, m)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = client.Run(ctx)
+	}()
+
+	if req := nextRequest(t, cp); req.GetNode() != "test-node" || req.GetVersionInfo() != "" {
+		t.Errorf("unexpected subscribe %v", req)
+	}
+	cp.responses <- &configpb.ConfigResponse{
+		VersionInfo: "1",
+		Nonce:       "a",
+		Circuits: map[string]*schemapb.Config{
+			"existing": timeoutConfig(2 * time.Second),
+			"new":      timeoutConfig(3 * time.Second),
+		},
+	}
+	if req := nextRequest(t, cp); req.GetVersionInfo() != "1" || req.GetResponseNonce() != "a" || req.GetErrorDetail() != "" {
+		t.Errorf("expected an ack, got %v", req)
+	}
+	if cfg := existing.Config(); cfg.Execution.Timeout != 2*time.Second || cfg.Execution.MaxConcurrentRequests != 5 {
+		t.Errorf("expected the timeout to change and the rest to stay, got %+v", cfg.Execution)
+	}
+	if c := m.GetCircuit("new"); c == nil || c.Config().Execution.Timeout != 3*time.Second {
+		t.Error("expected a new circuit with the pushed timeout")
+	}
+
+	// A newer schema version is nacked, and nothing changes
+	cp.responses <- &configpb.ConfigResponse{
+		VersionInfo: "2",
+		Nonce:       "b",
+		Circuits: map[string]*schemapb.Config{
+			"existing": {Version: 1000},
+		},
+	}
+	if req := nextRequest(t, cp); req.GetVersionInfo() != "1" || req.GetResponseNonce() != "b" || req.GetErrorDetail() == "" {
+		t.Errorf("expected a nack, got %v", req)
+	}
+
+	// Circuits that are no longer sent are removed if the client created them, and reset if not
+	cp.responses <- &configpb.ConfigResponse{VersionInfo: "3", Nonce: "c"}
+	if req := nextRequest(t, cp); req.GetVersionInfo() != "3" {
+		t.Errorf("expected an ack, got %v", req)
+	}
+	if m.GetCircuit("new") != nil {
+		t.Error("expected the created circuit to be removed")
+	}
+	if existing.Config().Execution.Timeout != time.Second || m.GetCircuit("existing") != existing {
+		t.Errorf("expected the existing circuit to go back to its own config, got %s", existing.Config().Execution.Timeout)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/clock"
 	"github.com/cep21/circuit/v4/internal/testhelp"
 )
 
@@ -304,3 +305,147 @@ func TestCircuitRecovers(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestCircuitRequiresConsecutiveSuccessesToClose(t *testing.T) {
+	mockClock := clock.MockClock{}
+	mockClock.Set(time.Now())
+	c := circuit.NewCircuitFromConfig("TestCircuitRequiresConsecutiveSuccessesToClose", circuit.Config{
+		General: circuit.GeneralConfig{
+			TimeKeeper: circuit.TimeKeeper{
+				Now:       mockClock.Now,
+				AfterFunc: mockClock.AfterFunc,
+			},
+			OpenToClosedFactory: CloserFactory(ConfigureCloser{
+				SleepWindow:                  time.Second,
+				RequiredConcurrentSuccessful: 3,
+				AfterFunc:                    mockClock.AfterFunc,
+			}),
+			ClosedToOpenFactory: OpenerFactory(ConfigureOpener{
+				RequestVolumeThreshold: 1,
+				Now:                    mockClock.Now,
+			}),
+		},
+	})
+	ctx := context.Background()
+	if err := c.Execute(ctx, testhelp.AlwaysFails, nil); err == nil {
+		t.Fatal("expected a failure")
+	}
+	if !c.IsOpen() {
+		t.Fatal("circuit should open after a failure")
+	}
+	probe := func(runFunc func(context.Context) error) {
+		mockClock.Add(time.Second)
+		_ = c.Execute(ctx, runFunc, nil)
+	}
+	probe(testhelp.AlwaysPasses)
+	probe(testhelp.AlwaysPasses)
+	if !c.IsOpen() {
+		t.Fatal("two successes should not close the circuit")
+	}
+	probe(testhelp.AlwaysFails)
+	probe(testhelp.AlwaysPasses)
+	probe(testhelp.AlwaysPasses)
+	if !c.IsOpen() {
+		t.Fatal("a failed probe should restart the count")
+	}
+	probe(testhelp.AlwaysPasses)
+	if c.IsOpen() {
+		t.Fatal("three successes in a row should close the circuit")
+	}
+}
+
+func TestManagerExportImportState(t *testing.T) {
+	mockClock := clock.MockClock{}
+	mockClock.Set(time.Now())
+	newManager := func() *circuit.Manager {
+		return &circuit.Manager{
+			DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{
+				func(_ string) circuit.Config {
+					return circuit.Config{
+						General: circuit.GeneralConfig{
+							TimeKeeper: circuit.TimeKeeper{
+								Now:       mockClock.Now,
+								AfterFunc: mockClock.AfterFunc,
+							},
+							OpenToClosedFactory: CloserFactory(ConfigureCloser{
+								SleepWindow: time.Minute,
+								AfterFunc:   mockClock.AfterFunc,
+							}),
+							ClosedToOpenFactory: OpenerFactory(ConfigureOpener{
+								RequestVolumeThreshold: 4,
+								Now:                    mockClock.Now,
+							}),
+						},
+					}
+				},
+			},
+		}
+	}
+	ctx := context.Background()
+	old := newManager()
+	open := old.MustCreateCircuit("open")
+	counting := old.MustCreateCircuit("counting")
+	old.MustCreateCircuit("removed")
+	for i := 0; i < 4; i++ {
+		_ = open.Execute(ctx, testhelp.AlwaysFails, nil)
+	}
+	if !open.IsOpen() {
+		t.Fatal("expected the circuit to open")
+	}
+	for i := 0; i < 3; i++ {
+		_ = counting.Execute(ctx, testhelp.AlwaysFails, nil)
+	}
+	state, err := old.ExportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockClock.Add(time.Second)
+	replacement := newManager()
+	newOpen := replacement.MustCreateCircuit("open")
+	newCounting := replacement.MustCreateCircuit("counting")
+	if err := replacement.ImportState(state); err != nil {
+		t.Fatal(err)
+	}
+	if !newOpen.IsOpen() {
+		t.Error("expected the open circuit to stay open")
+	}
+	if !newOpen.StateInfo().NextProbe.Equal(open.StateInfo().NextProbe) {
+		t.Error("expected the half open schedule to carry over")
+	}
+	_ = newCounting.Execute(ctx, testhelp.AlwaysFails, nil)
+	if !newCounting.IsOpen() {
+		t.Error("expected imported errors to count toward opening")
+	}
+	mockClock.Add(time.Minute)
+	if err := newOpen.Execute(ctx, testhelp.AlwaysPasses, nil); err != nil || newOpen.IsOpen() {
+		t.Error("expected the imported circuit to close after the sleep window")
+	}
+	if err := replacement.ImportState([]byte("not json")); err == nil {
+		t.Error("expected an error for a bad state")
+	}
+}
+
+func TestManagerImportPartialState(t *testing.T) {
+	counter := `{"Buckets":[0],"RollingSum":0,"TotalSum":0,"RollingBucket":{"NumBuckets":1,"BucketWidth":1000}}`
+	for _, state := range []string{
+		`{"Circuits":{"x":{"Opener":{"Attempts":{}}}}}`,
+		`{"Circuits":{"x":{"Opener":{}}}}`,
+		`{"Circuits":{"x":{"Opener":{"Attempts":` + counter + `}}}}`,
+		`{"Circuits":{"x":{"Opener":{"Attempts":` + counter + `,"Errors":{"Buckets":[1]}}}}}`,
+		`{"Circuits":{"x":{"Opener":{"Attempts":{"Buckets":[1,2],"RollingSum":0,"TotalSum":0,"RollingBucket":{"NumBuckets":1}}}}}}`,
+		`{"Circuits":{"x":{"Closer":{}}}}`,
+		`{"Circuits":{"x":{"Closer":{"ReopenCircuitCheck":{}}}}}`,
+		`{"Circuits":{"x":{"Closer":{"ReopenCircuitCheck":null}}}}`,
+	} {
+		t.Run(state, func(t *testing.T) {
+			h := circuit.Manager{
+				DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{(&Factory{}).Configure},
+			}
+			h.MustCreateCircuit("x")
+			if err := h.ImportState([]byte(state)); err == nil {
+				t.Error("expected an error for an incomplete state")
+			}
+		})
+	}
+}
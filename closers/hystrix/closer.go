@@ -3,6 +3,7 @@ package hystrix
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"time"
 
@@ -10,16 +11,24 @@ import (
 	"github.com/cep21/circuit/v4/faststats"
 )
 
-// Closer is hystrix's default half-open logic: try again ever X ms
+// Closer is hystrix's default half-open logic: try again ever X ms.  The circuit closes once
+// ConfigureCloser.RequiredConcurrentSuccessful attempts in a row succeed.
 type Closer struct {
 	// Tracks when we should try to close an open circuit again
 	reopenCircuitCheck faststats.TimedCheck
 
 	concurrentSuccessfulAttempts faststats.AtomicInt64
 	closeOnCurrentCount          faststats.AtomicInt64
+	// No half open requests are allowed before this time, in unix nanoseconds, because of a RetryAfter hint
+	retryAfterUntil faststats.AtomicInt64
+	maxRetryAfter   faststats.AtomicInt64
 
 	mu     sync.Mutex
 	config ConfigureCloser
+	// inheritedRand is the circuit's GeneralConfig.Rand, used if ConfigureCloser.Rand is not set
+	inheritedRand circuit.Rand
+	// thresholds is the circuit's GeneralConfig.Thresholds
+	thresholds map[string]int64
 }
 
 // CloserFactory creates Closer closer
@@ -33,18 +42,33 @@ func CloserFactory(config ConfigureCloser) func() circuit.OpenToClosed {
 }
 
 var _ circuit.OpenToClosed = &Closer{}
+var _ circuit.HalfOpenScheduler = &Closer{}
+var _ circuit.RandInheritor = &Closer{}
+var _ circuit.ThresholdsConfigurable = &Closer{}
+var _ circuit.RetryAfterMetrics = &Closer{}
 
 // ConfigureCloser configures values for Closer
 type ConfigureCloser struct {
 	// AfterFunc should simulate time.AfterFunc
 	AfterFunc func(time.Duration, func()) *time.Timer `json:"-"`
+	// Rand picks the SleepWindowJitter.  The default is the circuit's GeneralConfig.Rand, or math/rand if that is not
+	// set.  Use circuit.NewRand for reproducible jitter.
+	Rand circuit.Rand `json:"-"`
 
 	// SleepWindow is https://github.com/Netflix/Hystrix/wiki/Configuration#circuitbreakersleepwindowinmilliseconds
 	SleepWindow time.Duration
+	// SleepWindowJitter adds a random extra wait, up to SleepWindowJitter, to each SleepWindow.  Use this so many
+	// instances that opened at the same time don't all try the recovering dependency at the same instant.
+	SleepWindowJitter time.Duration
 	// HalfOpenAttempts is how many attempts to allow per SleepWindow
 	HalfOpenAttempts int64
-	// RequiredConcurrentSuccessful is how may consecutive passing requests are required before the circuit is closed
+	// RequiredConcurrentSuccessful is how may consecutive passing requests are required before the circuit is closed.
+	// Set this above 1 so a single lucky half-open attempt cannot close the circuit on a dependency that is still
+	// broken.  Any failure or timeout while open starts the count over.
 	RequiredConcurrentSuccessful int64
+	// MaxRetryAfter is the longest RetryAfter hint from a failure that is respected.  Longer hints are cut to it.  Set
+	// to -1 for no limit.
+	MaxRetryAfter time.Duration
 }
 
 // Merge this configuration with another
@@ -52,21 +76,31 @@ func (c *ConfigureCloser) Merge(other ConfigureCloser) {
 	if c.SleepWindow == 0 {
 		c.SleepWindow = other.SleepWindow
 	}
+	if c.SleepWindowJitter == 0 {
+		c.SleepWindowJitter = other.SleepWindowJitter
+	}
 	if c.HalfOpenAttempts == 0 {
 		c.HalfOpenAttempts = other.HalfOpenAttempts
 	}
 	if c.RequiredConcurrentSuccessful == 0 {
 		c.RequiredConcurrentSuccessful = other.RequiredConcurrentSuccessful
 	}
+	if c.MaxRetryAfter == 0 {
+		c.MaxRetryAfter = other.MaxRetryAfter
+	}
 	if c.AfterFunc == nil {
 		c.AfterFunc = other.AfterFunc
 	}
+	if c.Rand == nil {
+		c.Rand = other.Rand
+	}
 }
 
 var defaultConfigureCloser = ConfigureCloser{
 	SleepWindow:                  5 * time.Second,
 	HalfOpenAttempts:             1,
 	RequiredConcurrentSuccessful: 1,
+	MaxRetryAfter:                5 * time.Minute,
 }
 
 // MarshalJSON returns closer information in a JSON format
@@ -78,6 +112,41 @@ func (s *Closer) MarshalJSON() ([]byte, error) {
 }
 
 var _ json.Marshaler = &Closer{}
+var _ circuit.StateExporter = &Closer{}
+
+type closerState struct {
+	ReopenCircuitCheck           json.RawMessage
+	ConcurrentSuccessfulAttempts int64
+}
+
+// ExportState encodes when the next half open request is allowed and how many have passed
+func (s *Closer) ExportState() (json.RawMessage, error) {
+	check, err := json.Marshal(&s.reopenCircuitCheck)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(closerState{
+		ReopenCircuitCheck:           check,
+		ConcurrentSuccessfulAttempts: s.concurrentSuccessfulAttempts.Get(),
+	})
+}
+
+// ImportState restores when the next half open request is allowed and how many have passed.  The sleep window and
+// half open attempts of this closer's config are kept.  It is not safe to call while the circuit is active.
+func (s *Closer) ImportState(state json.RawMessage) error {
+	var into closerState
+	if err := json.Unmarshal(state, &into); err != nil {
+		return err
+	}
+	if len(into.ReopenCircuitCheck) == 0 {
+		return errors.New("incomplete closer state: missing ReopenCircuitCheck")
+	}
+	if err := s.reopenCircuitCheck.RestoreJSON(into.ReopenCircuitCheck); err != nil {
+		return err
+	}
+	s.concurrentSuccessfulAttempts.Set(into.ConcurrentSuccessfulAttempts)
+	return nil
+}
 
 // Opened circuit. It should now check to see if it should ever allow various requests in an attempt to become closed
 func (s *Closer) Opened(_ context.Context, now time.Time) {
@@ -96,9 +165,36 @@ func (s *Closer) Closed(_ context.Context, now time.Time) {
 // the circuit in a "half-open" state, allowing that one request.
 // If any requests are allowed, the circuit moves into a half open state.
 func (s *Closer) Allow(_ context.Context, now time.Time) (shouldAllow bool) {
+	if now.UnixNano() < s.retryAfterUntil.Get() {
+		return false
+	}
 	return s.reopenCircuitCheck.Check(now)
 }
 
+// NextAllowed returns when the next half open request is allowed
+func (s *Closer) NextAllowed() time.Time {
+	next := s.reopenCircuitCheck.NextOpenTime()
+	if until := s.retryAfterUntil.Get(); until != 0 && until > next.UnixNano() {
+		return time.Unix(0, until)
+	}
+	return next
+}
+
+// RetryAfter stops half open requests until after has passed, so the sleep window is at least as long as the
+// dependency asked for.  It is capped at MaxRetryAfter.
+func (s *Closer) RetryAfter(_ context.Context, now time.Time, after time.Duration) {
+	if maxAfter := s.maxRetryAfter.Duration(); maxAfter >= 0 && after > maxAfter {
+		after = maxAfter
+	}
+	until := now.Add(after).UnixNano()
+	for {
+		current := s.retryAfterUntil.Get()
+		if current >= until || s.retryAfterUntil.CompareAndSwap(current, until) {
+			return
+		}
+	}
+}
+
 // Success any time runFunc was called and appeared healthy
 func (s *Closer) Success(_ context.Context, _ time.Time, _ time.Duration) {
 	s.concurrentSuccessfulAttempts.Add(1)
@@ -135,22 +231,68 @@ func (s *Closer) ShouldClose(_ context.Context, _ time.Time) bool {
 	return s.concurrentSuccessfulAttempts.Get() >= s.closeOnCurrentCount.Get()
 }
 
-// Config returns the current configuration.  Use SetConfigThreadSafe to modify the current configuration.
+// Config returns the current configuration, with the circuit's GeneralConfig.Thresholds applied.  Use
+// SetConfigThreadSafe to modify the current configuration.
 func (s *Closer) Config() ConfigureCloser {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.config
+	return s.configWithLock()
 }
 
-// SetConfigThreadSafe resets the sleep duration during reopen attempts
+// SetConfigThreadSafe resets the sleep duration during reopen attempts.  ThresholdSleepWindow in the circuit's
+// GeneralConfig.Thresholds still overrides SleepWindow.
 func (s *Closer) SetConfigThreadSafe(config ConfigureCloser) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.config = config
+	s.applyWithLock()
+}
+
+// SetThresholds overrides ConfigureCloser.SleepWindow with ThresholdSleepWindow
+func (s *Closer) SetThresholds(thresholds map[string]int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.thresholds = thresholds
+	s.applyWithLock()
+}
+
+// configWithLock is config with the circuit's thresholds applied
+func (s *Closer) configWithLock() ConfigureCloser {
+	ret := s.config
+	if v, exists := s.thresholds[ThresholdSleepWindow]; exists {
+		ret.SleepWindow = time.Duration(v)
+	}
+	return ret
+}
+
+func (s *Closer) applyWithLock() {
+	config := s.configWithLock()
 	s.reopenCircuitCheck.TimeAfterFunc = config.AfterFunc
+	s.setRandWithLock()
 	s.reopenCircuitCheck.SetSleepDuration(config.SleepWindow)
+	s.reopenCircuitCheck.SetSleepJitter(config.SleepWindowJitter)
 	s.reopenCircuitCheck.SetEventCountToAllow(config.HalfOpenAttempts)
 	s.closeOnCurrentCount.Set(config.RequiredConcurrentSuccessful)
+	s.maxRetryAfter.Set(config.MaxRetryAfter.Nanoseconds())
+}
+
+// InheritRand uses the circuit's Rand to pick the SleepWindowJitter if ConfigureCloser.Rand is not set
+func (s *Closer) InheritRand(r circuit.Rand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inheritedRand = r
+	s.setRandWithLock()
+}
+
+func (s *Closer) setRandWithLock() {
+	r := s.config.Rand
+	if r == nil {
+		r = s.inheritedRand
+	}
+	s.reopenCircuitCheck.RandInt63n = nil
+	if r != nil {
+		s.reopenCircuitCheck.RandInt63n = r.Int63n
+	}
 }
 
 // SetConfigNotThreadSafe just calls SetConfigThreadSafe. It is not safe to call while the circuit is active.
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/cep21/circuit/v4"
 )
 
 func TestCloser_MarshalJSON(t *testing.T) {
@@ -144,3 +146,86 @@ func TestCloser_AfterFunc(t *testing.T) {
 		}
 	})
 }
+
+func TestCloser_NextAllowed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	c := Closer{}
+	c.SetConfigNotThreadSafe(ConfigureCloser{
+		SleepWindow: time.Minute,
+	})
+	c.Opened(ctx, now)
+	if !c.NextAllowed().Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the next attempt after the sleep window, got %s", c.NextAllowed())
+	}
+}
+
+// halfRand always picks the middle
+type halfRand struct{}
+
+func (halfRand) Int63n(n int64) int64 { return n / 2 }
+func (halfRand) Float64() float64     { return 0.5 }
+
+func TestCloser_InheritRand(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	f := Factory{
+		ConfigureCloser: ConfigureCloser{
+			SleepWindow:       time.Minute,
+			SleepWindowJitter: time.Minute,
+		},
+	}
+	cfg := f.Configure("TestCloser_InheritRand")
+	cfg.General.Rand = halfRand{}
+	c := circuit.NewCircuitFromConfig("TestCloser_InheritRand", cfg).OpenToClose.(*Closer)
+	c.Opened(ctx, now)
+	if !c.NextAllowed().Equal(now.Add(time.Minute + 30*time.Second)) {
+		t.Errorf("expected the jitter picked by the circuit's Rand, got %s", c.NextAllowed().Sub(now))
+	}
+	// A Rand of its own wins
+	c.SetConfigThreadSafe(ConfigureCloser{
+		SleepWindow:       time.Minute,
+		SleepWindowJitter: time.Minute,
+		Rand:              zeroRand{},
+	})
+	c.Opened(ctx, now)
+	if !c.NextAllowed().Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the jitter picked by ConfigureCloser.Rand, got %s", c.NextAllowed().Sub(now))
+	}
+}
+
+// zeroRand always picks the lowest
+type zeroRand struct{}
+
+func (zeroRand) Int63n(int64) int64 { return 0 }
+func (zeroRand) Float64() float64   { return 0 }
+
+func TestCloser_RetryAfter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	c := Closer{}
+	c.SetConfigNotThreadSafe(ConfigureCloser{
+		SleepWindow:   time.Second,
+		MaxRetryAfter: time.Hour,
+	})
+	c.RetryAfter(ctx, now, time.Minute)
+	c.Opened(ctx, now)
+	if !c.NextAllowed().Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the hint to extend the sleep window, got %s", c.NextAllowed())
+	}
+	if c.Allow(ctx, now.Add(30*time.Second)) {
+		t.Error("expected no requests before the hint ends")
+	}
+	// A shorter hint does not shorten the wait
+	c.RetryAfter(ctx, now, time.Second)
+	if !c.NextAllowed().Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the longer hint to be kept, got %s", c.NextAllowed())
+	}
+
+	c.RetryAfter(ctx, now, 24*time.Hour)
+	if !c.NextAllowed().Equal(now.Add(time.Hour)) {
+		t.Errorf("expected the hint to be capped at MaxRetryAfter, got %s", c.NextAllowed())
+	}
+}
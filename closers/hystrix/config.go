@@ -2,6 +2,16 @@ package hystrix
 
 import "github.com/cep21/circuit/v4"
 
+// Names of the settings circuit.GeneralConfig.Thresholds can override
+const (
+	// ThresholdErrorPercentage overrides ConfigureOpener.ErrorThresholdPercentage
+	ThresholdErrorPercentage = "hystrix.ErrorThresholdPercentage"
+	// ThresholdRequestVolume overrides ConfigureOpener.RequestVolumeThreshold
+	ThresholdRequestVolume = "hystrix.RequestVolumeThreshold"
+	// ThresholdSleepWindow overrides ConfigureCloser.SleepWindow, in nanoseconds
+	ThresholdSleepWindow = "hystrix.SleepWindow"
+)
+
 // Factory aids making hystrix circuit logic
 type Factory struct {
 	ConfigureCloser       ConfigureCloser
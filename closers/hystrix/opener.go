@@ -3,7 +3,11 @@ package hystrix
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cep21/circuit/v4"
@@ -15,15 +19,35 @@ import (
 type Opener struct {
 	errorsCount             faststats.RollingCounter
 	legitimateAttemptsCount faststats.RollingCounter
+	// recentAttempts replaces the rolling counters when ConfigureOpener.RollingCount is set
+	recentAttempts faststats.CountWindow
+	// exactLog replaces the rolling counters when ConfigureOpener.ExactLogSize is set and it covers the window
+	exactLog *faststats.SlidingLog
+	// exactFrom is when exactLog started logging.  Counts imported with ImportState are not in the log, so it is not
+	// used until a whole window has passed since.
+	exactFrom faststats.AtomicInt64
 
 	errorPercentage        faststats.AtomicInt64
 	requestVolumeThreshold faststats.AtomicInt64
 
+	// Controls the gradual ramp of traffic after the circuit closes
+	closedAt           faststats.AtomicInt64
+	rampUpDuration     faststats.AtomicInt64
+	rampUpStartPercent faststats.AtomicInt64
+	// rand is ConfigureOpener.Rand, kept outside mu so Prevent does not lock
+	rand atomic.Pointer[circuit.Rand]
+	// inheritedRand is the circuit's GeneralConfig.Rand, used if ConfigureOpener.Rand is not set
+	inheritedRand atomic.Pointer[circuit.Rand]
+
 	mu     sync.Mutex
 	config ConfigureOpener
+	// thresholds is the circuit's GeneralConfig.Thresholds
+	thresholds map[string]int64
 }
 
 var _ circuit.ClosedToOpen = &Opener{}
+var _ circuit.RandInheritor = &Opener{}
+var _ circuit.ThresholdsConfigurable = &Opener{}
 
 // OpenerFactory creates a err % opener
 func OpenerFactory(config ConfigureOpener) func() circuit.ClosedToOpen {
@@ -43,10 +67,32 @@ type ConfigureOpener struct {
 	RequestVolumeThreshold int64
 	// Now should simulate time.Now
 	Now func() time.Time `json:"-"`
+	// Rand picks which requests are let through while traffic ramps up.  The default is the circuit's
+	// GeneralConfig.Rand, or math/rand if that is not set.  Use circuit.NewRand for reproducible ramps.
+	Rand circuit.Rand `json:"-"`
 	// RollingDuration is https://github.com/Netflix/Hystrix/wiki/Configuration#metricsrollingstatstimeinmilliseconds
 	RollingDuration time.Duration
 	// NumBuckets is https://github.com/Netflix/Hystrix/wiki/Configuration#metricsrollingstatsnumbuckets
 	NumBuckets int
+	// RampUpDuration, if set, slowly lets traffic back in after the circuit closes.  Right after closing, only
+	// RampUpStartPercentage of requests are allowed, and the rest are short circuited to the fallback.  The allowed
+	// percentage grows evenly to 100% over RampUpDuration.  This keeps a dependency that just came back from being
+	// overloaded again right away.
+	RampUpDuration time.Duration
+	// RampUpStartPercentage is the percentage of requests allowed right after the circuit closes.  0 means the default
+	// of 10%, so set it to -1 to start at 0%.
+	RampUpStartPercentage int64
+	// RollingCount, if set, computes the error percentage over the last RollingCount requests instead of over
+	// RollingDuration.  Use this for low traffic circuits, where a time window is mostly empty.  RollingCount should
+	// be at least RequestVolumeThreshold, or the circuit can never open.  Like RollingDuration and NumBuckets, it is
+	// only read when the opener is created: SetConfigThreadSafe cannot change it on a running circuit.
+	RollingCount int
+	// ExactLogSize, if set, logs the time of up to this many recent requests so the error percentage over
+	// RollingDuration is exact.  It is meant for circuits doing less than a request a second, where a bucket leaving the
+	// window moves the error percentage by a large step.  Once more requests than this happen within the window, the
+	// buckets are used instead.  It is the opener's version of rolling.RunStatsConfig.ExactLogSize.  It is only read
+	// when the opener is created.
+	ExactLogSize int
 }
 
 func (c *ConfigureOpener) now() time.Time {
@@ -67,12 +113,27 @@ func (c *ConfigureOpener) Merge(other ConfigureOpener) {
 	if c.Now == nil {
 		c.Now = other.Now
 	}
+	if c.Rand == nil {
+		c.Rand = other.Rand
+	}
 	if c.RollingDuration == 0 {
 		c.RollingDuration = other.RollingDuration
 	}
 	if c.NumBuckets == 0 {
 		c.NumBuckets = other.NumBuckets
 	}
+	if c.RollingCount == 0 {
+		c.RollingCount = other.RollingCount
+	}
+	if c.ExactLogSize == 0 {
+		c.ExactLogSize = other.ExactLogSize
+	}
+	if c.RampUpDuration == 0 {
+		c.RampUpDuration = other.RampUpDuration
+	}
+	if c.RampUpStartPercentage == 0 {
+		c.RampUpStartPercentage = other.RampUpStartPercentage
+	}
 }
 
 var defaultConfigureOpener = ConfigureOpener{
@@ -81,41 +142,159 @@ var defaultConfigureOpener = ConfigureOpener{
 	Now:                      time.Now,
 	NumBuckets:               10,
 	RollingDuration:          10 * time.Second,
+	RampUpStartPercentage:    10,
 }
 
 // MarshalJSON returns opener information in a JSON format
 func (e *Opener) MarshalJSON() ([]byte, error) {
 	cfg := e.Config()
+	if e.recentAttempts.Size() > 0 {
+		return json.Marshal(map[string]interface{}{
+			"config":          cfg,
+			"recent_attempts": &e.recentAttempts,
+			"err_%":           e.errPercentage(cfg.now()),
+			"ramp_%":          e.rampUpPercentage(cfg.now()),
+		})
+	}
 	return json.Marshal(map[string]interface{}{
 		"config":   cfg,
 		"attempts": &e.legitimateAttemptsCount,
 		"errors":   &e.errorsCount,
 		"err_%":    e.errPercentage(cfg.now()),
+		"ramp_%":   e.rampUpPercentage(cfg.now()),
 	})
 }
 
 var _ json.Marshaler = &Opener{}
+var _ circuit.StateExporter = &Opener{}
+var _ circuit.MemoryReporter = &Opener{}
+
+type openerState struct {
+	Attempts json.RawMessage
+	Errors   json.RawMessage
+	ClosedAt int64
+}
+
+// ExportState encodes the rolling error and attempt counts.  Counts for RollingCount and ExactLogSize are not exported.
+func (e *Opener) ExportState() (json.RawMessage, error) {
+	attempts, err := json.Marshal(&e.legitimateAttemptsCount)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := json.Marshal(&e.errorsCount)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(openerState{
+		Attempts: attempts,
+		Errors:   errs,
+		ClosedAt: e.closedAt.Get(),
+	})
+}
+
+// ImportState restores counts from ExportState into the buckets this opener's config built, so the bucket width and
+// count of the new config are kept.  It is not safe to call while the circuit is active.
+func (e *Opener) ImportState(state json.RawMessage) error {
+	var into openerState
+	if err := json.Unmarshal(state, &into); err != nil {
+		return err
+	}
+	if len(into.Attempts) == 0 || len(into.Errors) == 0 {
+		return errors.New("incomplete opener state: missing Attempts or Errors")
+	}
+	cfg := e.Config()
+	now := cfg.now()
+	if err := e.legitimateAttemptsCount.RestoreJSON(into.Attempts, now); err != nil {
+		return fmt.Errorf("attempts: %w", err)
+	}
+	if err := e.errorsCount.RestoreJSON(into.Errors, now); err != nil {
+		e.legitimateAttemptsCount.Reset(now)
+		return fmt.Errorf("errors: %w", err)
+	}
+	e.closedAt.Set(into.ClosedAt)
+	e.exactFrom.Set(now.UnixNano())
+	return nil
+}
 
 // Closed resets the error and attempt count
 func (e *Opener) Closed(_ context.Context, now time.Time) {
 	e.errorsCount.Reset(now)
 	e.legitimateAttemptsCount.Reset(now)
+	e.recentAttempts.Reset()
+	e.resetExactLog()
+	e.closedAt.Set(now.UnixNano())
 }
 
 // Opened resets the error and attempt count
 func (e *Opener) Opened(_ context.Context, now time.Time) {
 	e.errorsCount.Reset(now)
 	e.legitimateAttemptsCount.Reset(now)
+	e.recentAttempts.Reset()
+	e.resetExactLog()
+	e.closedAt.Set(0)
+}
+
+func (e *Opener) resetExactLog() {
+	if e.exactLog != nil {
+		e.exactLog.Reset()
+	}
 }
 
 // Success increases the number of correct attempts
 func (e *Opener) Success(_ context.Context, now time.Time, _ time.Duration) {
 	e.legitimateAttemptsCount.Inc(now)
+	e.recentAttempts.Success()
+	if e.exactLog != nil {
+		e.exactLog.Success(now)
+	}
+}
+
+// Prevent short circuits a share of requests while traffic ramps up after the circuit closes.  Without a
+// RampUpDuration, it never returns true.
+func (e *Opener) Prevent(_ context.Context, now time.Time) (shouldAllow bool) {
+	allowed := e.rampUpPercentage(now)
+	if allowed >= 100 {
+		return false
+	}
+	return e.int63n(100) >= allowed
+}
+
+func (e *Opener) int63n(n int64) int64 {
+	if r := e.rand.Load(); r != nil && *r != nil {
+		return (*r).Int63n(n)
+	}
+	if r := e.inheritedRand.Load(); r != nil {
+		return (*r).Int63n(n)
+	}
+	return rand.Int63n(n)
+}
+
+// InheritRand uses the circuit's Rand to ramp up traffic if ConfigureOpener.Rand is not set
+func (e *Opener) InheritRand(r circuit.Rand) {
+	e.inheritedRand.Store(&r)
 }
 
-// Prevent never returns true
-func (e *Opener) Prevent(_ context.Context, _ time.Time) (shouldAllow bool) {
-	return false
+// Recovering returns true if the circuit recently closed and traffic is still ramping up
+func (e *Opener) Recovering(now time.Time) bool {
+	return e.rampUpPercentage(now) < 100
+}
+
+// rampUpPercentage is the % of requests [0 - 100] allowed through at a moment in time
+func (e *Opener) rampUpPercentage(now time.Time) int64 {
+	closedAt := e.closedAt.Get()
+	rampUpDuration := e.rampUpDuration.Get()
+	if closedAt == 0 || rampUpDuration <= 0 {
+		return 100
+	}
+	elapsed := now.UnixNano() - closedAt
+	if elapsed >= rampUpDuration {
+		return 100
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	start := e.rampUpStartPercent.Get()
+	return start + (100-start)*elapsed/rampUpDuration
 }
 
 // ErrBadRequest is ignored
@@ -128,12 +307,20 @@ func (e *Opener) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {
 func (e *Opener) ErrFailure(_ context.Context, now time.Time, _ time.Duration) {
 	e.legitimateAttemptsCount.Inc(now)
 	e.errorsCount.Inc(now)
+	e.recentAttempts.Failure()
+	if e.exactLog != nil {
+		e.exactLog.Failure(now)
+	}
 }
 
 // ErrTimeout increases error count for the circuit
 func (e *Opener) ErrTimeout(_ context.Context, now time.Time, _ time.Duration) {
 	e.legitimateAttemptsCount.Inc(now)
 	e.errorsCount.Inc(now)
+	e.recentAttempts.Failure()
+	if e.exactLog != nil {
+		e.exactLog.Failure(now)
+	}
 }
 
 // ErrConcurrencyLimitReject is ignored
@@ -145,7 +332,7 @@ func (e *Opener) ErrShortCircuit(_ context.Context, _ time.Time) {}
 // ShouldOpen returns true if rolling count >= threshold and
 // error % is high enough.
 func (e *Opener) ShouldOpen(_ context.Context, now time.Time) bool {
-	attemptCount := e.legitimateAttemptsCount.RollingSumAt(now)
+	attemptCount := e.attemptsAt(now)
 	if attemptCount == 0 || attemptCount < e.requestVolumeThreshold.Get() {
 		// not enough requests. Will not open circuit
 		return false
@@ -154,23 +341,98 @@ func (e *Opener) ShouldOpen(_ context.Context, now time.Time) bool {
 }
 
 func (e *Opener) errPercentage(now time.Time) float64 {
-	attemptCount := e.legitimateAttemptsCount.RollingSumAt(now)
+	attemptCount := e.attemptsAt(now)
 	if attemptCount == 0 {
 		// not enough requests (can't make a percent of zero)
 		return -1
 	}
 
-	errCount := e.errorsCount.RollingSumAt(now)
+	errCount := e.errorsAt(now)
 	return float64(errCount) / float64(attemptCount)
 }
 
-// SetConfigThreadSafe modifies error % and request volume threshold
+func (e *Opener) attemptsAt(now time.Time) int64 {
+	if e.recentAttempts.Size() > 0 {
+		return e.recentAttempts.Total()
+	}
+	if total, _, exact := e.exactCountsAt(now); exact {
+		return total
+	}
+	return e.legitimateAttemptsCount.RollingSumAt(now)
+}
+
+func (e *Opener) errorsAt(now time.Time) int64 {
+	if e.recentAttempts.Size() > 0 {
+		return e.recentAttempts.Failures()
+	}
+	if _, failures, exact := e.exactCountsAt(now); exact {
+		return failures
+	}
+	return e.errorsCount.RollingSumAt(now)
+}
+
+// exactCountsAt returns the counts of the exact log.  exact is false if the log is not set, or does not have every
+// request in the window.
+func (e *Opener) exactCountsAt(now time.Time) (total int64, failures int64, exact bool) {
+	if e.exactLog == nil || e.exactLog.Size() == 0 {
+		return 0, 0, false
+	}
+	if now.Add(-e.exactLog.Window()).UnixNano() < e.exactFrom.Get() {
+		return 0, 0, false
+	}
+	return e.exactLog.CountsAt(now)
+}
+
+// MemoryBytes estimates the memory held by the rolling counters and the window of recent attempts
+func (e *Opener) MemoryBytes() int64 {
+	ret := e.errorsCount.MemoryBytes() + e.legitimateAttemptsCount.MemoryBytes() + e.recentAttempts.MemoryBytes()
+	if e.exactLog != nil {
+		ret += e.exactLog.MemoryBytes()
+	}
+	return ret
+}
+
+// SetConfigThreadSafe modifies error % and request volume threshold.  ThresholdErrorPercentage and
+// ThresholdRequestVolume in the circuit's GeneralConfig.Thresholds still override them.
 func (e *Opener) SetConfigThreadSafe(props ConfigureOpener) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.config = props
+	e.applyWithLock()
+}
+
+// SetThresholds overrides ConfigureOpener.ErrorThresholdPercentage and RequestVolumeThreshold with
+// ThresholdErrorPercentage and ThresholdRequestVolume
+func (e *Opener) SetThresholds(thresholds map[string]int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.thresholds = thresholds
+	e.applyWithLock()
+}
+
+// configWithLock is config with the circuit's thresholds applied
+func (e *Opener) configWithLock() ConfigureOpener {
+	ret := e.config
+	if v, exists := e.thresholds[ThresholdErrorPercentage]; exists {
+		ret.ErrorThresholdPercentage = v
+	}
+	if v, exists := e.thresholds[ThresholdRequestVolume]; exists {
+		ret.RequestVolumeThreshold = v
+	}
+	return ret
+}
+
+func (e *Opener) applyWithLock() {
+	props := e.configWithLock()
 	e.errorPercentage.Set(props.ErrorThresholdPercentage)
 	e.requestVolumeThreshold.Set(props.RequestVolumeThreshold)
+	e.rampUpDuration.Set(props.RampUpDuration.Nanoseconds())
+	rampUpStartPercent := props.RampUpStartPercentage
+	if rampUpStartPercent < 0 {
+		rampUpStartPercent = 0
+	}
+	e.rampUpStartPercent.Set(rampUpStartPercent)
+	e.rand.Store(&props.Rand)
 }
 
 // SetConfigNotThreadSafe recreates the buckets.  It is not safe to call while the circuit is active.
@@ -180,12 +442,18 @@ func (e *Opener) SetConfigNotThreadSafe(props ConfigureOpener) {
 	rollingCounterBucketWidth := time.Duration(props.RollingDuration.Nanoseconds() / int64(props.NumBuckets))
 	e.errorsCount = faststats.NewRollingCounter(rollingCounterBucketWidth, props.NumBuckets, now)
 	e.legitimateAttemptsCount = faststats.NewRollingCounter(rollingCounterBucketWidth, props.NumBuckets, now)
+	e.recentAttempts = faststats.NewCountWindow(props.RollingCount)
+	e.exactLog = nil
+	if props.ExactLogSize > 0 {
+		e.exactLog = faststats.NewSlidingLog(props.RollingDuration, props.ExactLogSize)
+	}
+	e.exactFrom.Set(0)
 }
 
-// Config returns the current configuration.  To update configuration, please call SetConfigThreadSafe or
-// SetConfigNotThreadSafe
+// Config returns the current configuration, with the circuit's GeneralConfig.Thresholds applied.  To update
+// configuration, please call SetConfigThreadSafe or SetConfigNotThreadSafe
 func (e *Opener) Config() ConfigureOpener {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	return e.config
+	return e.configWithLock()
 }
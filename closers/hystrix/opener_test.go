@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/cep21/circuit/v4"
 )
 
 func TestOpener_MarshalJSON(t *testing.T) {
@@ -56,3 +58,219 @@ func TestOpener(t *testing.T) {
 		t.Fatal("should now open")
 	}
 }
+
+func TestOpener_RollingCount(t *testing.T) {
+	ctx := context.Background()
+	o := OpenerFactory(ConfigureOpener{
+		RequestVolumeThreshold:   3,
+		ErrorThresholdPercentage: 50,
+		RollingCount:             4,
+	})().(*Opener)
+	// Long ago failures still count, since the window is by count and not by time
+	start := time.Now()
+	o.ErrFailure(ctx, start, time.Second)
+	o.ErrFailure(ctx, start, time.Second)
+	later := start.Add(time.Hour)
+	o.Success(ctx, later, time.Second)
+	if !o.ShouldOpen(ctx, later) {
+		t.Fatal("2 of the last 3 requests failing should open")
+	}
+	o.Success(ctx, later, time.Second)
+	o.Success(ctx, later, time.Second)
+	if o.ShouldOpen(ctx, later) {
+		t.Fatal("1 of the last 4 requests failing should not open")
+	}
+}
+
+func TestOpener_ExactLogSize(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	newOpener := func(exactLogSize int) *Opener {
+		return OpenerFactory(ConfigureOpener{
+			RequestVolumeThreshold:   2,
+			ErrorThresholdPercentage: 30,
+			RollingDuration:          10 * time.Second,
+			NumBuckets:               2,
+			ExactLogSize:             exactLogSize,
+			Now:                      func() time.Time { return start },
+		})().(*Opener)
+	}
+	for _, size := range []int{0, 10} {
+		o := newOpener(size)
+		// Both failures are in the bucket that starts at start, and the successes in the next one.  Once the first
+		// bucket leaves the window, the buckets lose both failures while the window still has one.
+		o.ErrFailure(ctx, start, time.Second)
+		o.ErrFailure(ctx, start.Add(4*time.Second), time.Second)
+		o.Success(ctx, start.Add(6*time.Second), time.Second)
+		o.Success(ctx, start.Add(7*time.Second), time.Second)
+		now := start.Add(12 * time.Second)
+		if shouldOpen := o.ShouldOpen(ctx, now); shouldOpen != (size != 0) {
+			t.Errorf("size %d: expected ShouldOpen=%t from 1 failure and 2 successes in the window", size, size != 0)
+		}
+	}
+
+	o := newOpener(1)
+	o.ErrFailure(ctx, start, time.Second)
+	o.ErrFailure(ctx, start.Add(time.Second), time.Second)
+	if !o.ShouldOpen(ctx, start.Add(time.Second)) {
+		t.Error("expected a full log to fall back to the buckets")
+	}
+
+	state, err := o.ExportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := newOpener(10)
+	if err := imported.ImportState(state); err != nil {
+		t.Fatal(err)
+	}
+	if !imported.ShouldOpen(ctx, start.Add(time.Second)) {
+		t.Error("expected imported counts to be used until the log covers the window")
+	}
+}
+
+func TestOpener_RampUp(t *testing.T) {
+	ctx := context.Background()
+	o := OpenerFactory(ConfigureOpener{
+		RampUpDuration:        10 * time.Second,
+		RampUpStartPercentage: 20,
+	})().(*Opener)
+	now := time.Now()
+	if o.Recovering(now) || o.Prevent(ctx, now) {
+		t.Fatal("circuits that never opened should not ramp up")
+	}
+	o.Opened(ctx, now)
+	o.Closed(ctx, now)
+	if !o.Recovering(now) {
+		t.Fatal("circuit should be recovering right after it closes")
+	}
+	if p := o.rampUpPercentage(now); p != 20 {
+		t.Fatalf("expected to start at 20%%, got %d", p)
+	}
+	if p := o.rampUpPercentage(now.Add(5 * time.Second)); p != 60 {
+		t.Fatalf("expected 60%% halfway through the ramp, got %d", p)
+	}
+	prevented := 0
+	for i := 0; i < 1000; i++ {
+		if o.Prevent(ctx, now) {
+			prevented++
+		}
+	}
+	if prevented < 600 || prevented > 950 {
+		t.Fatalf("expected about 80%% of requests prevented, got %d/1000", prevented)
+	}
+	end := now.Add(10 * time.Second)
+	if o.Recovering(end) || o.Prevent(ctx, end) {
+		t.Fatal("ramp should be over")
+	}
+}
+
+func TestOpener_RampUpFromZero(t *testing.T) {
+	ctx := context.Background()
+	o := OpenerFactory(ConfigureOpener{
+		RampUpDuration:        10 * time.Second,
+		RampUpStartPercentage: -1,
+	})().(*Opener)
+	now := time.Now()
+	o.Opened(ctx, now)
+	o.Closed(ctx, now)
+	if p := o.rampUpPercentage(now); p != 0 {
+		t.Fatalf("expected -1 to start at 0%%, got %d", p)
+	}
+	if p := o.rampUpPercentage(now.Add(5 * time.Second)); p != 50 {
+		t.Fatalf("expected 50%% halfway through the ramp, got %d", p)
+	}
+	for i := 0; i < 100; i++ {
+		if !o.Prevent(ctx, now) {
+			t.Fatal("expected every request to be prevented right after closing")
+		}
+	}
+}
+
+func TestOpener_InheritRand(t *testing.T) {
+	ctx := context.Background()
+	prevented := func(seed int64) []bool {
+		f := Factory{
+			ConfigureOpener: ConfigureOpener{
+				RampUpDuration:        10 * time.Second,
+				RampUpStartPercentage: 50,
+			},
+		}
+		cfg := f.Configure("TestOpener_InheritRand")
+		cfg.General.Rand = circuit.NewRand(seed)
+		o := circuit.NewCircuitFromConfig("TestOpener_InheritRand", cfg).ClosedToOpen.(*Opener)
+		now := time.Now()
+		o.Opened(ctx, now)
+		o.Closed(ctx, now)
+		var ret []bool
+		for i := 0; i < 100; i++ {
+			ret = append(ret, o.Prevent(ctx, now))
+		}
+		return ret
+	}
+	first, second := prevented(7), prevented(7)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the circuit's Rand to prevent the same requests, request %d differed", i)
+		}
+	}
+}
+
+func TestOpener_RampUpRand(t *testing.T) {
+	ctx := context.Background()
+	prevented := func() []bool {
+		o := OpenerFactory(ConfigureOpener{
+			RampUpDuration:        10 * time.Second,
+			RampUpStartPercentage: 50,
+			Rand:                  circuit.NewRand(7),
+		})().(*Opener)
+		now := time.Now()
+		o.Opened(ctx, now)
+		o.Closed(ctx, now)
+		var ret []bool
+		for i := 0; i < 100; i++ {
+			ret = append(ret, o.Prevent(ctx, now))
+		}
+		return ret
+	}
+	first, second := prevented(), prevented()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same seed to prevent the same requests, request %d differed", i)
+		}
+	}
+}
+
+func TestOpener_MemoryBytes(t *testing.T) {
+	buckets := OpenerFactory(ConfigureOpener{})().(*Opener)
+	window := OpenerFactory(ConfigureOpener{RollingCount: 1000})().(*Opener)
+	if window.MemoryBytes()-buckets.MemoryBytes() != 1000*8 {
+		t.Errorf("expected 8 bytes for each attempt in the window, got %d and %d", buckets.MemoryBytes(), window.MemoryBytes())
+	}
+}
+
+func TestOpener_SetThresholds(t *testing.T) {
+	f := Factory{
+		ConfigureOpener: ConfigureOpener{
+			ErrorThresholdPercentage: 50,
+		},
+	}
+	cfg := f.Configure("TestOpener_SetThresholds")
+	cfg.General.Thresholds = map[string]int64{ThresholdErrorPercentage: 20}
+	c := circuit.NewCircuitFromConfig("TestOpener_SetThresholds", cfg)
+	o := c.ClosedToOpen.(*Opener)
+	if o.Config().ErrorThresholdPercentage != 20 || o.errorPercentage.Get() != 20 {
+		t.Errorf("expected the circuit's threshold, got %d", o.Config().ErrorThresholdPercentage)
+	}
+	// The opener's own config does not replace the circuit's threshold
+	o.SetConfigThreadSafe(ConfigureOpener{ErrorThresholdPercentage: 30})
+	if o.errorPercentage.Get() != 20 {
+		t.Errorf("expected the circuit's threshold to still apply, got %d", o.errorPercentage.Get())
+	}
+	cfg = c.Config()
+	cfg.General.Thresholds = nil
+	c.SetConfigThreadSafe(cfg)
+	if o.errorPercentage.Get() != 30 {
+		t.Errorf("expected the opener's own threshold once the circuit's is removed, got %d", o.errorPercentage.Get())
+	}
+}
@@ -0,0 +1,288 @@
+package outlier
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// Config controls when a Detector ejects hosts.  The defaults are Envoy's.
+type Config struct {
+	// Interval is how often hosts are compared.  Each comparison looks at the runs since the one before.
+	Interval time.Duration
+	// MinRequests is how many runs a host needs in an interval to be compared
+	MinRequests int64
+	// MinHosts is how many hosts need MinRequests in an interval before any are compared
+	MinHosts int
+	// StdevFactor ejects hosts whose success rate is more than StdevFactor standard deviations below the mean
+	StdevFactor float64
+	// BaseEjectionTime is how long a host is ejected the first time.  Each later ejection of the same host is longer
+	// by BaseEjectionTime, up to MaxEjectionTime.
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime is the longest a host is ejected
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent is the most hosts, as a percent of every host, that are ejected at once.  One host can always
+	// be ejected.
+	MaxEjectionPercent float64
+}
+
+// Merge this config with another
+func (c *Config) Merge(other Config) {
+	if c.Interval == 0 {
+		c.Interval = other.Interval
+	}
+	if c.MinRequests == 0 {
+		c.MinRequests = other.MinRequests
+	}
+	if c.MinHosts == 0 {
+		c.MinHosts = other.MinHosts
+	}
+	if c.StdevFactor == 0 {
+		c.StdevFactor = other.StdevFactor
+	}
+	if c.BaseEjectionTime == 0 {
+		c.BaseEjectionTime = other.BaseEjectionTime
+	}
+	if c.MaxEjectionTime == 0 {
+		c.MaxEjectionTime = other.MaxEjectionTime
+	}
+	if c.MaxEjectionPercent == 0 {
+		c.MaxEjectionPercent = other.MaxEjectionPercent
+	}
+}
+
+var defaultConfig = Config{
+	Interval:           10 * time.Second,
+	MinRequests:        100,
+	MinHosts:           5,
+	StdevFactor:        1.9,
+	BaseEjectionTime:   30 * time.Second,
+	MaxEjectionTime:    300 * time.Second,
+	MaxEjectionPercent: 10,
+}
+
+// Detector compares the circuits of a Manager, one per host, and ejects outliers by opening their circuit.  Add
+// CommandProperties to the Manager's DefaultCircuitProperties.  Every circuit of the Manager is a host, so use a
+// Manager for each group of hosts that serve the same thing.
+//
+// A host's circuit still opens and closes on its own.  The Detector holds the circuits it ejects open with
+// Circuit.HoldOpen, so their OpenToClose cannot let them back in early, and closes them once their ejection time is
+// over.  It only closes circuits it opened.  Hosts are compared, and let back in, on the first run of any host after
+// each Interval.
+type Detector struct {
+	Manager *circuit.Manager
+	Config  Config
+
+	lastEvaluation faststats.AtomicInt64
+	mu             sync.Mutex
+	hosts          map[string]*host
+}
+
+// CommandProperties tracks a circuit as a host
+func (d *Detector) CommandProperties(circuitName string) circuit.Config {
+	h := &host{
+		detector: d,
+		name:     circuitName,
+	}
+	d.mu.Lock()
+	if d.hosts == nil {
+		d.hosts = make(map[string]*host)
+	}
+	// A circuit made again with the same name is a new host
+	d.hosts[circuitName] = h
+	d.mu.Unlock()
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Run:     []circuit.RunMetrics{h},
+			Circuit: []circuit.Metrics{h},
+		},
+	}
+}
+
+// Ejected returns the names of the circuits the Detector has ejected, sorted
+func (d *Detector) Ejected() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ret []string
+	for name, h := range d.hosts {
+		if h.isEjected() {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (d *Detector) config() Config {
+	cfg := d.Config
+	cfg.Merge(defaultConfig)
+	return cfg
+}
+
+// maybeEvaluate compares hosts if it has been Interval since they were last compared.  Only one caller compares them.
+func (d *Detector) maybeEvaluate(ctx context.Context, now time.Time) {
+	cfg := d.config()
+	lastEvaluation := d.lastEvaluation.Get()
+	if lastEvaluation == 0 {
+		// The first interval starts with the first run
+		d.lastEvaluation.CompareAndSwap(0, now.UnixNano())
+		return
+	}
+	if now.UnixNano()-lastEvaluation < cfg.Interval.Nanoseconds() || !d.lastEvaluation.CompareAndSwap(lastEvaluation, now.UnixNano()) {
+		return
+	}
+	eject, readmit := d.evaluate(cfg, now)
+	// Opening and closing circuits calls back into hosts, so it must happen without holding mu
+	for _, c := range readmit {
+		c.CloseCircuit(ctx)
+	}
+	for _, e := range eject {
+		e.circuit.HoldOpen(ctx, e.until)
+	}
+}
+
+// ejection is a circuit to hold open until a host's ejection is over
+type ejection struct {
+	circuit *circuit.Circuit
+	until   time.Time
+}
+
+// evaluate returns the circuits of hosts to eject, and of hosts whose ejection is over
+func (d *Detector) evaluate(cfg Config, now time.Time) (eject []ejection, readmit []*circuit.Circuit) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	type candidate struct {
+		host        *host
+		circuit     *circuit.Circuit
+		successRate float64
+	}
+	var candidates []candidate
+	ejected := 0
+	for name, h := range d.hosts {
+		successes := h.successes.Swap(0)
+		failures := h.failures.Swap(0)
+		c := d.Manager.GetCircuit(name)
+		if c == nil {
+			// The Manager forgot the circuit, so this host is gone
+			delete(d.hosts, name)
+			continue
+		}
+		if h.isEjected() {
+			if now.Before(h.ejectedUntil) {
+				ejected++
+				continue
+			}
+			h.ejectedUntil = time.Time{}
+			readmit = append(readmit, c)
+			continue
+		}
+		if successes+failures < cfg.MinRequests || c.IsOpen() {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			host:        h,
+			circuit:     c,
+			successRate: float64(successes) / float64(successes+failures),
+		})
+	}
+	if len(candidates) == 0 || len(candidates) < cfg.MinHosts {
+		return nil, readmit
+	}
+	var mean float64
+	for _, c := range candidates {
+		mean += c.successRate
+	}
+	mean /= float64(len(candidates))
+	var variance float64
+	for _, c := range candidates {
+		variance += (c.successRate - mean) * (c.successRate - mean)
+	}
+	threshold := mean - cfg.StdevFactor*math.Sqrt(variance/float64(len(candidates)))
+
+	// Eject the worst hosts first, in case MaxEjectionPercent stops some of them
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].successRate < candidates[j].successRate
+	})
+	maxEjected := int(float64(len(d.hosts)) * cfg.MaxEjectionPercent / 100)
+	if maxEjected < 1 {
+		maxEjected = 1
+	}
+	for _, c := range candidates {
+		if c.successRate >= threshold || ejected >= maxEjected {
+			break
+		}
+		c.host.ejections++
+		ejectionTime := cfg.BaseEjectionTime * time.Duration(c.host.ejections)
+		if ejectionTime > cfg.MaxEjectionTime {
+			ejectionTime = cfg.MaxEjectionTime
+		}
+		c.host.ejectedUntil = now.Add(ejectionTime)
+		ejected++
+		eject = append(eject, ejection{circuit: c.circuit, until: c.host.ejectedUntil})
+	}
+	return eject, readmit
+}
+
+// host counts the runs of one circuit
+type host struct {
+	detector  *Detector
+	name      string
+	successes faststats.AtomicInt64
+	failures  faststats.AtomicInt64
+
+	// ejectedUntil and ejections are protected by detector.mu
+	ejectedUntil time.Time
+	ejections    int64
+}
+
+var _ circuit.RunMetrics = &host{}
+var _ circuit.Metrics = &host{}
+
+func (h *host) isEjected() bool {
+	return !h.ejectedUntil.IsZero()
+}
+
+func (h *host) Success(ctx context.Context, now time.Time, _ time.Duration) {
+	h.successes.Add(1)
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrFailure(ctx context.Context, now time.Time, _ time.Duration) {
+	h.failures.Add(1)
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrTimeout(ctx context.Context, now time.Time, _ time.Duration) {
+	h.failures.Add(1)
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrBadRequest(ctx context.Context, now time.Time, _ time.Duration) {
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrInterrupt(ctx context.Context, now time.Time, _ time.Duration) {
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrConcurrencyLimitReject(ctx context.Context, now time.Time) {
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) ErrShortCircuit(ctx context.Context, now time.Time) {
+	h.detector.maybeEvaluate(ctx, now)
+}
+
+func (h *host) Opened(_ context.Context, _ time.Time) {}
+
+// Closed ends the ejection of a host whose circuit was closed by something else
+func (h *host) Closed(_ context.Context, _ time.Time) {
+	h.detector.mu.Lock()
+	h.ejectedUntil = time.Time{}
+	h.detector.mu.Unlock()
+}
@@ -0,0 +1,196 @@
+package outlier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+	"github.com/cep21/circuit/v4/internal/clock"
+)
+
+// newTestManager makes a Manager whose circuits are hosts of d, and that keep time with mockClock
+func newTestManager(d *Detector, mockClock *clock.MockClock) *circuit.Manager {
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{
+			d.CommandProperties,
+			func(_ string) circuit.Config {
+				return circuit.Config{
+					General: circuit.GeneralConfig{
+						TimeKeeper: circuit.TimeKeeper{
+							Now:       mockClock.Now,
+							AfterFunc: mockClock.AfterFunc,
+						},
+					},
+				}
+			},
+		},
+	}
+	d.Manager = m
+	return m
+}
+
+// runHosts runs every host n times.  The host named bad fails every other run.
+func runHosts(m *circuit.Manager, hosts []string, bad string, n int) {
+	for _, name := range hosts {
+		c := m.GetOrCreateCircuit(name)
+		for i := 0; i < n; i++ {
+			_ = c.Run(context.Background(), func(_ context.Context) error {
+				if name == bad && i%2 == 0 {
+					return errors.New("bad host")
+				}
+				return nil
+			})
+		}
+	}
+}
+
+func TestDetector(t *testing.T) {
+	mockClock := &clock.MockClock{}
+	mockClock.Set(time.Now())
+	d := &Detector{
+		Config: Config{
+			Interval:    time.Second,
+			MinRequests: 10,
+		},
+	}
+	m := newTestManager(d, mockClock)
+	hosts := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		hosts = append(hosts, fmt.Sprintf("host-%d", i))
+	}
+
+	runHosts(m, hosts, "host-3", 10)
+	if ejected := d.Ejected(); len(ejected) != 0 {
+		t.Errorf("expected nothing ejected before an interval, got %v", ejected)
+	}
+	mockClock.Add(time.Second)
+	runHosts(m, hosts[:1], "", 1)
+	if ejected := d.Ejected(); !reflect.DeepEqual(ejected, []string{"host-3"}) {
+		t.Errorf("expected host-3 to be ejected, got %v", ejected)
+	}
+	if !m.GetCircuit("host-3").IsOpen() {
+		t.Error("expected the circuit of an ejected host to be open")
+	}
+	if m.GetCircuit("host-2").IsOpen() {
+		t.Error("expected healthy hosts to stay closed")
+	}
+
+	mockClock.Add(defaultConfig.BaseEjectionTime)
+	runHosts(m, hosts[:1], "", 1)
+	if ejected := d.Ejected(); len(ejected) != 0 {
+		t.Errorf("expected host-3 to be let back in, got %v", ejected)
+	}
+	if m.GetCircuit("host-3").IsOpen() {
+		t.Error("expected the circuit of a readmitted host to close")
+	}
+}
+
+func TestDetector_HoldsEjectedOpen(t *testing.T) {
+	mockClock := &clock.MockClock{}
+	mockClock.Set(time.Now())
+	d := &Detector{
+		Config: Config{
+			Interval:    time.Second,
+			MinRequests: 10,
+		},
+	}
+	m := newTestManager(d, mockClock)
+	// The closer would let an ejected host back in after a second, long before its ejection is over
+	closers := hystrix.Factory{
+		ConfigureCloser: hystrix.ConfigureCloser{
+			SleepWindow: time.Second,
+			AfterFunc:   mockClock.AfterFunc,
+		},
+	}
+	m.DefaultCircuitProperties = append(m.DefaultCircuitProperties, closers.Configure)
+	hosts := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		hosts = append(hosts, fmt.Sprintf("host-%d", i))
+	}
+	runHosts(m, hosts, "host-3", 10)
+	mockClock.Add(time.Second)
+	runHosts(m, hosts[:1], "", 1)
+	if ejected := d.Ejected(); !reflect.DeepEqual(ejected, []string{"host-3"}) {
+		t.Fatalf("expected host-3 to be ejected, got %v", ejected)
+	}
+
+	mockClock.Add(defaultConfig.BaseEjectionTime / 2)
+	runHosts(m, []string{"host-3"}, "", 1)
+	if !m.GetCircuit("host-3").IsOpen() {
+		t.Error("expected an ejected host to stay open after the closer's sleep window")
+	}
+	if ejected := d.Ejected(); !reflect.DeepEqual(ejected, []string{"host-3"}) {
+		t.Errorf("expected host-3 to stay ejected, got %v", ejected)
+	}
+
+	mockClock.Add(defaultConfig.BaseEjectionTime / 2)
+	runHosts(m, hosts[:1], "", 1)
+	if ejected := d.Ejected(); len(ejected) != 0 {
+		t.Errorf("expected host-3 to be let back in, got %v", ejected)
+	}
+	if m.GetCircuit("host-3").IsOpen() {
+		t.Error("expected the circuit of a readmitted host to close")
+	}
+}
+
+func TestDetector_MinHosts(t *testing.T) {
+	mockClock := &clock.MockClock{}
+	mockClock.Set(time.Now())
+	d := &Detector{
+		Config: Config{
+			Interval:    time.Second,
+			MinRequests: 10,
+		},
+	}
+	m := newTestManager(d, mockClock)
+	hosts := []string{"host-0", "host-1", "host-2", "host-3"}
+	runHosts(m, hosts, "host-3", 10)
+	mockClock.Add(time.Second)
+	runHosts(m, hosts[:1], "", 1)
+	if ejected := d.Ejected(); len(ejected) != 0 {
+		t.Errorf("expected too few hosts to be compared, got %v", ejected)
+	}
+}
+
+func TestDetector_OnlyClosesEjectedCircuits(t *testing.T) {
+	mockClock := &clock.MockClock{}
+	mockClock.Set(time.Now())
+	d := &Detector{
+		Config: Config{
+			Interval: time.Second,
+		},
+	}
+	m := newTestManager(d, mockClock)
+	runHosts(m, []string{"host-0"}, "", 1)
+	m.GetCircuit("host-0").OpenCircuit(context.Background())
+	mockClock.Add(time.Hour)
+	runHosts(m, []string{"host-1"}, "", 1)
+	if !m.GetCircuit("host-0").IsOpen() {
+		t.Error("expected a circuit the detector did not open to stay open")
+	}
+}
+
+func TestDetector_ForgetsRemovedCircuits(t *testing.T) {
+	mockClock := &clock.MockClock{}
+	mockClock.Set(time.Now())
+	d := &Detector{
+		Config: Config{
+			Interval: time.Second,
+		},
+	}
+	m := newTestManager(d, mockClock)
+	runHosts(m, []string{"host-0", "host-1"}, "", 1)
+	m.RemoveCircuit(m.GetCircuit("host-1"))
+	mockClock.Add(time.Second)
+	runHosts(m, []string{"host-0"}, "", 1)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.hosts["host-1"]; exists || len(d.hosts) != 1 {
+		t.Errorf("expected the removed host to be forgotten, got %v", d.hosts)
+	}
+}
@@ -0,0 +1,7 @@
+/*
+Package outlier ejects hosts whose error rate stands out from the other hosts of the same dependency, like Envoy's
+success rate outlier detection.  Each host has its own circuit, such as the circuits circuithttp.Transport makes.  A
+host whose success rate is far enough below the others has its circuit opened, even when it is not failing enough
+to open on its own, and is let back in after an ejection time.
+*/
+package outlier
@@ -0,0 +1,22 @@
+package outlier_test
+
+import (
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuithttp"
+	"github.com/cep21/circuit/v4/closers/outlier"
+)
+
+// This example ejects the hosts of an HTTP client that fail much more often than the others.  Each host gets a circuit
+// from circuithttp.Transport, and the Detector compares them.
+func ExampleDetector() {
+	d := &outlier.Detector{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{d.CommandProperties},
+	}
+	d.Manager = m
+	client := circuithttp.NewClient(nil, m)
+	resp, err := client.Get("http://localhost:8080/health")
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,219 @@
+package simplelogic
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// WindowOpener opens a circuit when the error percentage of recent requests is too high.  Requests are recent if they
+// are both one of the last MaxSamples requests and no older than MaxSampleAge.  It will not open unless there are at
+// least MinSamples recent requests.  This combines count windows, which stay meaningful during quiet periods, with time
+// windows, which forget bursts of errors that happened long ago.
+type WindowOpener struct {
+	errorPercentage faststats.AtomicInt64
+	minSamples      faststats.AtomicInt64
+	maxSampleAge    faststats.AtomicInt64
+
+	// All 3 of these variables must be accessed with the mutex
+	samples []windowSample
+	next    int
+	count   int
+	mu      sync.Mutex
+
+	configMu sync.Mutex
+	config   ConfigWindowOpener
+}
+
+type windowSample struct {
+	when   time.Time
+	failed bool
+}
+
+// WindowOpenerFactory constructs a new WindowOpener
+func WindowOpenerFactory(config ConfigWindowOpener) func() circuit.ClosedToOpen {
+	return func() circuit.ClosedToOpen {
+		ret := &WindowOpener{}
+		config.Merge(defaultConfigWindowOpener)
+		ret.SetConfigNotThreadSafe(config)
+		return ret
+	}
+}
+
+// ConfigWindowOpener configures a WindowOpener
+type ConfigWindowOpener struct {
+	// ErrorThresholdPercentage is the % of recent requests that must fail to open the circuit
+	ErrorThresholdPercentage int64
+	// MinSamples is how many recent requests are needed before the circuit can open
+	MinSamples int64
+	// MaxSamples is the most requests remembered.  It cannot change while the circuit is running.
+	MaxSamples int
+	// MaxSampleAge is how long a request is remembered
+	MaxSampleAge time.Duration
+}
+
+// Merge this config with another
+func (c *ConfigWindowOpener) Merge(other ConfigWindowOpener) {
+	if c.ErrorThresholdPercentage == 0 {
+		c.ErrorThresholdPercentage = other.ErrorThresholdPercentage
+	}
+	if c.MinSamples == 0 {
+		c.MinSamples = other.MinSamples
+	}
+	if c.MaxSamples == 0 {
+		c.MaxSamples = other.MaxSamples
+	}
+	if c.MaxSampleAge == 0 {
+		c.MaxSampleAge = other.MaxSampleAge
+	}
+}
+
+var defaultConfigWindowOpener = ConfigWindowOpener{
+	ErrorThresholdPercentage: 50,
+	MinSamples:               10,
+	MaxSamples:               100,
+	MaxSampleAge:             time.Minute,
+}
+
+var _ circuit.ClosedToOpen = &WindowOpener{}
+var _ json.Marshaler = &WindowOpener{}
+
+// MarshalJSON returns opener information in a JSON format
+func (w *WindowOpener) MarshalJSON() ([]byte, error) {
+	w.mu.Lock()
+	count := w.count
+	w.mu.Unlock()
+	return json.Marshal(map[string]interface{}{
+		"config":  w.Config(),
+		"samples": count,
+	})
+}
+
+func (w *WindowOpener) add(now time.Time, failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) == 0 {
+		return
+	}
+	w.samples[w.next] = windowSample{when: now, failed: failed}
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+}
+
+func (w *WindowOpener) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.next = 0
+	w.count = 0
+}
+
+// recent returns how many samples, and how many failed samples, are newer than now - MaxSampleAge
+func (w *WindowOpener) recent(now time.Time) (total int64, failed int64) {
+	oldest := now.Add(-w.maxSampleAge.Duration())
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := 1; i <= w.count; i++ {
+		idx := w.next - i
+		if idx < 0 {
+			idx += len(w.samples)
+		}
+		s := w.samples[idx]
+		if s.when.Before(oldest) {
+			// Samples are in time order, so every remaining sample is too old
+			break
+		}
+		total++
+		if s.failed {
+			failed++
+		}
+	}
+	return total, failed
+}
+
+// Closed resets the window
+func (w *WindowOpener) Closed(_ context.Context, _ time.Time) {
+	w.reset()
+}
+
+// Opened resets the window
+func (w *WindowOpener) Opened(_ context.Context, _ time.Time) {
+	w.reset()
+}
+
+// Prevent always returns false
+func (w *WindowOpener) Prevent(_ context.Context, _ time.Time) bool {
+	return false
+}
+
+// Success adds a healthy request to the window
+func (w *WindowOpener) Success(_ context.Context, now time.Time, _ time.Duration) {
+	w.add(now, false)
+}
+
+// ErrBadRequest is ignored
+func (w *WindowOpener) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {}
+
+// ErrInterrupt is ignored
+func (w *WindowOpener) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+
+// ErrConcurrencyLimitReject is ignored
+func (w *WindowOpener) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {}
+
+// ErrShortCircuit is ignored
+func (w *WindowOpener) ErrShortCircuit(_ context.Context, _ time.Time) {}
+
+// ErrFailure adds a failed request to the window
+func (w *WindowOpener) ErrFailure(_ context.Context, now time.Time, _ time.Duration) {
+	w.add(now, true)
+}
+
+// ErrTimeout adds a failed request to the window
+func (w *WindowOpener) ErrTimeout(_ context.Context, now time.Time, _ time.Duration) {
+	w.add(now, true)
+}
+
+// ShouldOpen returns true if there are enough recent requests and enough of them failed
+func (w *WindowOpener) ShouldOpen(_ context.Context, now time.Time) bool {
+	total, failed := w.recent(now)
+	if total == 0 || total < w.minSamples.Get() {
+		return false
+	}
+	return failed*100 >= total*w.errorPercentage.Get()
+}
+
+// Config returns the current configuration
+func (w *WindowOpener) Config() ConfigWindowOpener {
+	w.configMu.Lock()
+	defer w.configMu.Unlock()
+	return w.config
+}
+
+// SetConfigThreadSafe updates the thresholds and sample age.  MaxSamples is not changed, and Config keeps reporting the
+// size of the current window.
+func (w *WindowOpener) SetConfigThreadSafe(props ConfigWindowOpener) {
+	w.mu.Lock()
+	props.MaxSamples = len(w.samples)
+	w.mu.Unlock()
+	w.configMu.Lock()
+	defer w.configMu.Unlock()
+	w.config = props
+	w.errorPercentage.Set(props.ErrorThresholdPercentage)
+	w.minSamples.Set(props.MinSamples)
+	w.maxSampleAge.Set(props.MaxSampleAge.Nanoseconds())
+}
+
+// SetConfigNotThreadSafe recreates the window.  It is not safe to call while the circuit is active.
+func (w *WindowOpener) SetConfigNotThreadSafe(props ConfigWindowOpener) {
+	w.mu.Lock()
+	w.samples = make([]windowSample, props.MaxSamples)
+	w.next = 0
+	w.count = 0
+	w.mu.Unlock()
+	w.SetConfigThreadSafe(props)
+}
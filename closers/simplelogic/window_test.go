@@ -0,0 +1,50 @@
+package simplelogic
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowOpener(t *testing.T) {
+	ctx := context.Background()
+	o := WindowOpenerFactory(ConfigWindowOpener{
+		ErrorThresholdPercentage: 50,
+		MinSamples:               3,
+		MaxSamples:               4,
+		MaxSampleAge:             time.Minute,
+	})().(*WindowOpener)
+	now := time.Now()
+	o.ErrFailure(ctx, now, time.Second)
+	o.ErrTimeout(ctx, now, time.Second)
+	if o.ShouldOpen(ctx, now) {
+		t.Fatal("should not open without enough samples")
+	}
+	o.Success(ctx, now, time.Second)
+	if !o.ShouldOpen(ctx, now) {
+		t.Fatal("should open with 2 of 3 failing")
+	}
+	if o.ShouldOpen(ctx, now.Add(time.Hour)) {
+		t.Fatal("old samples should not count")
+	}
+	for i := 0; i < 4; i++ {
+		o.Success(ctx, now, time.Second)
+	}
+	if o.ShouldOpen(ctx, now) {
+		t.Fatal("failures should be pushed out of the window by newer requests")
+	}
+	o.ErrFailure(ctx, now, time.Second)
+	o.ErrFailure(ctx, now, time.Second)
+	o.Opened(ctx, now)
+	if o.ShouldOpen(ctx, now) {
+		t.Fatal("opening should reset the window")
+	}
+}
+
+func TestWindowOpener_ConfigMaxSamples(t *testing.T) {
+	o := WindowOpenerFactory(ConfigWindowOpener{MaxSamples: 10})().(*WindowOpener)
+	o.SetConfigThreadSafe(ConfigWindowOpener{ErrorThresholdPercentage: 20, MaxSamples: 500})
+	if cfg := o.Config(); cfg.MaxSamples != 10 || cfg.ErrorThresholdPercentage != 20 {
+		t.Errorf("expected the window to keep its size, got %+v", cfg)
+	}
+}
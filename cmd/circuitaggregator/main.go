@@ -0,0 +1,59 @@
+/*
+Command circuitaggregator combines the circuits of many processes, using package aggregator.  Processes push their
+circuits to it with an aggregator.Pusher, and it serves the combined circuits.
+
+	POST /push     where pushers send reports
+	GET  /metrics  the combined circuits in the Prometheus text format
+	GET  /         the dashboard of the combined circuits
+
+The -addr flag is the address to listen on.  The -stale flag is how long an instance that stops pushing stays in the
+aggregate.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cep21/circuit/v4/aggregator"
+)
+
+func main() {
+	server, err := newServer(os.Args[1:], os.Stderr)
+	if err == nil {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "circuitaggregator:", err)
+		os.Exit(1)
+	}
+}
+
+// newServer parses flags into a server that is ready to listen
+func newServer(args []string, out io.Writer) (*http.Server, error) {
+	flags := flag.NewFlagSet("circuitaggregator", flag.ContinueOnError)
+	flags.SetOutput(out)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	stale := flags.Duration("stale", 30*time.Second, "how long the last report of an instance is used")
+	tick := flags.Duration("tick", time.Second, "how often the dashboard is updated")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	return &http.Server{
+		Addr: *addr,
+		Handler: &aggregator.Aggregator{
+			StaleAfter:   *stale,
+			TickDuration: *tick,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
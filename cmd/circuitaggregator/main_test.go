@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/aggregator"
+)
+
+func TestNewServer(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := newServer([]string{"-stale", "time.Minute"}, &out); err == nil {
+		t.Fatal("expected a bad duration to fail")
+	}
+	server, err := newServer([]string{"-addr", ":9090", "-stale", "1m"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Addr != ":9090" || server.Handler.(*aggregator.Aggregator).StaleAfter != time.Minute {
+		t.Errorf("unexpected server %+v", server)
+	}
+	if _, err := newServer([]string{"extra"}, &out); err == nil {
+		t.Error("expected extra arguments to fail")
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Command circuitctl talks to the admin API of package admin, so operators can inspect and change circuits during an
+incident.
+
+	circuitctl list
+	circuitctl show <name>
+	circuitctl open <name>
+	circuitctl close <name>
+	circuitctl reset <name>
+	circuitctl passthrough <name> on|off
+	circuitctl set <name> timeout=2s max_concurrent_requests=20
+	circuitctl watch
+
+The -addr flag, or the CIRCUITCTL_ADDR environment variable, is the URL the admin handler is served at.  The -auth
+flag, or CIRCUITCTL_AUTH, is sent as the Authorization header.  The -timeout flag limits how long each command waits
+for the admin API, except watch, which runs until it is stopped.
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cep21/circuit/v4/admin"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "circuitctl:", err)
+		os.Exit(1)
+	}
+}
+
+const usage = `usage: circuitctl [-addr url] [-auth header] [-timeout duration] <command> [args]
+
+commands:
+  list                                 list every circuit
+  show <name>                          show one circuit as JSON
+  open <name>                          force a circuit open
+  close <name>                         force a circuit closed
+  reset <name>                         stop forcing a circuit open or closed
+  passthrough <name> on|off            run every request with no checks, or stop doing that
+  set <name> key=value...              change timeout, max_concurrent_requests, fallback_max_concurrent_requests,
+                                       error_threshold_percentage, request_volume_threshold, or sleep_window
+  watch                                print circuits as they open and close`
+
+type client struct {
+	addr string
+	auth string
+	http *http.Client
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("circuitctl", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		fmt.Fprintln(out, usage)
+		flags.PrintDefaults()
+	}
+	addr := flags.String("addr", envOr("CIRCUITCTL_ADDR", "http://localhost:8080/admin"), "URL of the admin handler")
+	auth := flags.String("auth", os.Getenv("CIRCUITCTL_AUTH"), "Authorization header to send")
+	timeout := flags.Duration("timeout", 10*time.Second, "how long to wait for the admin API, except for watch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		return errors.New("missing command")
+	}
+	c := &client{
+		addr: strings.TrimSuffix(*addr, "/"),
+		auth: *auth,
+		http: &http.Client{Timeout: *timeout},
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		var statuses []admin.CircuitStatus
+		if err := c.do(http.MethodGet, "/circuits", nil, &statuses); err != nil {
+			return err
+		}
+		return printStatuses(out, statuses)
+	case "show":
+		name, err := oneName(command, args)
+		if err != nil {
+			return err
+		}
+		var status json.RawMessage
+		if err := c.do(http.MethodGet, circuitPath(name, ""), nil, &status); err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, status, "", "  "); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, indented.String())
+		return err
+	case "open", "close", "reset":
+		name, err := oneName(command, args)
+		if err != nil {
+			return err
+		}
+		return c.change(out, circuitPath(name, command), nil)
+	case "passthrough":
+		if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+			return errors.New("usage: circuitctl passthrough <name> on|off")
+		}
+		return c.change(out, circuitPath(args[0], "passthrough"), map[string]bool{"enabled": args[1] == "on"})
+	case "set":
+		if len(args) < 2 {
+			return errors.New("usage: circuitctl set <name> key=value...")
+		}
+		update, err := parseUpdate(args[1:])
+		if err != nil {
+			return err
+		}
+		return c.change(out, circuitPath(args[0], "config"), update)
+	case "watch":
+		return c.watch(out)
+	}
+	flags.Usage()
+	return fmt.Errorf("unknown command %s", command)
+}
+
+func envOr(key string, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func oneName(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: circuitctl %s <name>", command)
+	}
+	return args[0], nil
+}
+
+func circuitPath(name string, action string) string {
+	p := "/circuits/" + url.PathEscape(name)
+	if action != "" {
+		p += "/" + action
+	}
+	return p
+}
+
+// parseUpdate turns key=value arguments into an admin.ConfigUpdate
+func parseUpdate(pairs []string) (admin.ConfigUpdate, error) {
+	var update admin.ConfigUpdate
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return update, fmt.Errorf("expected key=value, got %s", pair)
+		}
+		var err error
+		switch key {
+		case "timeout":
+			_, err = time.ParseDuration(value)
+			update.Timeout = value
+		case "sleep_window":
+			_, err = time.ParseDuration(value)
+			update.SleepWindow = value
+		case "max_concurrent_requests":
+			update.MaxConcurrentRequests, err = parseInt(value)
+		case "fallback_max_concurrent_requests":
+			update.FallbackMaxConcurrentRequests, err = parseInt(value)
+		case "error_threshold_percentage":
+			update.ErrorThresholdPercentage, err = parseInt(value)
+		case "request_volume_threshold":
+			update.RequestVolumeThreshold, err = parseInt(value)
+		default:
+			return update, fmt.Errorf("unknown setting %s", key)
+		}
+		if err != nil {
+			return update, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return update, nil
+}
+
+func parseInt(value string) (*int64, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (c *client) newRequest(method string, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		asJSON, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(asJSON)
+	}
+	req, err := http.NewRequest(method, c.addr+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	return req, nil
+}
+
+func (c *client) do(method string, path string, body interface{}, into interface{}) error {
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Error)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
+
+// change sends a change and prints the circuit it changed
+func (c *client) change(out io.Writer, path string, body interface{}) error {
+	var status admin.CircuitStatus
+	if err := c.do(http.MethodPost, path, body, &status); err != nil {
+		return err
+	}
+	return printStatuses(out, []admin.CircuitStatus{status})
+}
+
+func (c *client) watch(out io.Writer) error {
+	req, err := c.newRequest(http.MethodGet, "/watch", nil)
+	if err != nil {
+		return err
+	}
+	// The stream never ends on its own, so the client's timeout would cut it off
+	streaming := *c.http
+	streaming.Timeout = 0
+	resp, err := streaming.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	events := bufio.NewScanner(resp.Body)
+	for events.Scan() {
+		data, isData := strings.CutPrefix(events.Text(), "data: ")
+		if !isData {
+			continue
+		}
+		var status admin.CircuitStatus
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), status.Name, state(status), status.State.Reason); err != nil {
+			return err
+		}
+	}
+	return events.Err()
+}
+
+func state(status admin.CircuitStatus) string {
+	if status.State.Open {
+		return "open"
+	}
+	return "closed"
+}
+
+func printStatuses(out io.Writer, statuses []admin.CircuitStatus) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATE\tREASON\tSINCE\tTIMEOUT\tCONCURRENT")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", s.Name, state(s), s.State.Reason, s.State.Since.Format(time.RFC3339), s.Timeout, s.ConcurrentCommands)
+	}
+	return w.Flush()
+}
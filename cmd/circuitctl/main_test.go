@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/admin"
+)
+
+func testServer(t *testing.T) (*circuit.Manager, *httptest.Server) {
+	m := &circuit.Manager{}
+	m.MustCreateCircuit("a/with/slashes")
+	m.MustCreateCircuit("b")
+	server := httptest.NewServer(&admin.Handler{
+		Manager: m,
+		Authorize: func(req *http.Request, _ admin.Action, _ string) error {
+			if req.Header.Get("Authorization") != "secret" {
+				return errors.New("bad credentials")
+			}
+			return nil
+		},
+	})
+	t.Cleanup(server.Close)
+	return m, server
+}
+
+func TestRun(t *testing.T) {
+	m, server := testServer(t)
+	runOK := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := run(append([]string{"-addr", server.URL, "-auth", "secret"}, args...), &out); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+	if out := runOK("list"); !strings.Contains(out, "a/with/slashes") || !strings.Contains(out, "b ") {
+		t.Errorf("expected both circuits listed, got %s", out)
+	}
+	runOK("open", "a/with/slashes")
+	if !m.GetCircuit("a/with/slashes").IsOpen() {
+		t.Error("expected circuit to be opened")
+	}
+	runOK("reset", "a/with/slashes")
+	if m.GetCircuit("a/with/slashes").IsOpen() {
+		t.Error("expected circuit to be reset")
+	}
+	runOK("set", "b", "timeout=2s", "max_concurrent_requests=20")
+	if cfg := m.GetCircuit("b").Config(); cfg.Execution.Timeout != 2*time.Second || cfg.Execution.MaxConcurrentRequests != 20 {
+		t.Errorf("expected config to change, got %+v", cfg.Execution)
+	}
+	if out := runOK("show", "b"); !strings.Contains(out, `"timeout": "2s"`) {
+		t.Errorf("expected the new timeout, got %s", out)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	_, server := testServer(t)
+	var out bytes.Buffer
+	if err := run([]string{"-addr", server.URL, "open", "b"}, &out); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected forbidden without auth, got %v", err)
+	}
+	if err := run([]string{"-addr", server.URL, "-auth", "secret", "set", "b", "timeout=soon"}, &out); err == nil {
+		t.Error("expected invalid durations to fail")
+	}
+	if err := run([]string{"-addr", server.URL, "-auth", "secret", "show", "missing"}, &out); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if err := run([]string{"unknown"}, &out); err == nil {
+		t.Error("expected unknown commands to fail")
+	}
+}
+
+func TestRun_timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/watch" {
+			<-release
+			return
+		}
+		rw.Header().Set("Content-Type", "text/event-stream")
+		for _, name := range []string{"a", "b"} {
+			_, _ = fmt.Fprintf(rw, "data: {\"name\":%q}\n\n", name)
+			rw.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	var out bytes.Buffer
+	if err := run([]string{"-addr", server.URL, "-timeout", "20ms", "list"}, &out); err == nil {
+		t.Error("expected a hung admin API to time out")
+	}
+	out.Reset()
+	if err := run([]string{"-addr", server.URL, "-timeout", "20ms", "watch"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 2 {
+		t.Errorf("expected watch to outlive the timeout, got %q", out.String())
+	}
+}
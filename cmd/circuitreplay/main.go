@@ -0,0 +1,91 @@
+/*
+Command circuitreplay replays traffic recorded by package circuitrecord against hystrix thresholds, and prints how
+often each circuit would have opened and how many runs it would have shed.
+
+	circuitreplay -error-percent 30 -volume 50 -sleep-window 10s traffic.jsonl
+
+Try a few settings on the same recording to tune a circuit before changing it in production.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitrecord"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "circuitreplay:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("circuitreplay", flag.ContinueOnError)
+	flags.SetOutput(out)
+	timeout := flags.Duration("timeout", time.Second, "execution timeout")
+	errorPercent := flags.Int64("error-percent", 50, "error percentage that opens a circuit")
+	volume := flags.Int64("volume", 20, "requests in the rolling window before a circuit can open")
+	rolling := flags.Duration("rolling", 10*time.Second, "rolling window errors are counted in")
+	sleepWindow := flags.Duration("sleep-window", 5*time.Second, "how long an open circuit waits to try a request")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected recordings to replay")
+	}
+	var records []circuitrecord.Record
+	for _, name := range flags.Args() {
+		recs, err := readFile(name)
+		if err != nil {
+			return err
+		}
+		records = append(records, recs...)
+	}
+	reports := circuitrecord.Replay(records, func(_ string, timeKeeper circuit.TimeKeeper) circuit.Config {
+		f := hystrix.Factory{
+			ConfigureOpener: hystrix.ConfigureOpener{
+				ErrorThresholdPercentage: *errorPercent,
+				RequestVolumeThreshold:   *volume,
+				RollingDuration:          *rolling,
+				Now:                      timeKeeper.Now,
+			},
+			ConfigureCloser: hystrix.ConfigureCloser{
+				SleepWindow: *sleepWindow,
+				AfterFunc:   timeKeeper.AfterFunc,
+			},
+		}
+		cfg := f.Configure("")
+		cfg.Execution.Timeout = *timeout
+		return cfg
+	})
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CIRCUIT\tRUNS\tOPENS\tSHED\tRECORDED SHED\tFAILURES\tTIMEOUTS\tOPEN TIME")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", r.Circuit, r.Runs, r.Opens, r.Shed, r.RecordedShed, r.Failures,
+			r.Timeouts, r.OpenTime)
+	}
+	return w.Flush()
+}
+
+func readFile(name string) ([]circuitrecord.Record, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := circuitrecord.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/circuitrecord"
+)
+
+func writeRecording(t *testing.T) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "traffic.jsonl")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	now := time.Now()
+	for i := 0; i < 30; i++ {
+		now = now.Add(100 * time.Millisecond)
+		rec := circuitrecord.Record{Circuit: "db", Time: now, Outcome: circuit.OutcomeSuccess, Duration: time.Millisecond}
+		if err := encoder.Encode(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return name
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-timeout", "1ms", writeRecording(t)}, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "db") {
+		t.Fatalf("expected a row for db, got %q", out.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[1] != "30" {
+		t.Errorf("expected 30 runs, got %q", lines[1])
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(nil, &out); err == nil {
+		t.Error("expected no recordings to fail")
+	}
+	if err := run([]string{filepath.Join(t.TempDir(), "missing.jsonl")}, &out); err == nil {
+		t.Error("expected a missing recording to fail")
+	}
+}
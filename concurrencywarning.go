@@ -0,0 +1,55 @@
+package circuit
+
+import "time"
+
+// ConcurrencyWarning is a circuit whose runs in flight rose to ExecutionConfig.ConcurrencyWarningPercent of
+// MaxConcurrentRequests.  See ExecutionConfig.OnConcurrencyWarning.
+type ConcurrencyWarning struct {
+	// CircuitName is the name of the circuit
+	CircuitName string
+	// InFlight is the cost of the runs in flight, counting the run that crossed the threshold
+	InFlight int64
+	// Limit is MaxConcurrentRequests
+	Limit int64
+	// Percent is ConcurrencyWarningPercent
+	Percent int64
+	// Time is when the run that crossed the threshold started
+	Time time.Time
+}
+
+// concurrencyWarningThreshold is the runs in flight that warn, or 0 if there is no limit to warn about
+func concurrencyWarningThreshold(limit int64, percent int64) int64 {
+	if limit <= 0 {
+		return 0
+	}
+	threshold := limit * percent / 100
+	if threshold < 1 {
+		return 1
+	}
+	return threshold
+}
+
+// checkConcurrencyWarning calls OnConcurrencyWarning if the runs in flight just rose to the warning threshold
+func (c *Circuit) checkConcurrencyWarning(cfg *configSnapshot, now time.Time) {
+	threshold := concurrencyWarningThreshold(cfg.Execution.MaxConcurrentRequests, cfg.Execution.ConcurrencyWarningPercent)
+	if threshold == 0 {
+		return
+	}
+	inFlight := c.concurrentCommands.Get()
+	if inFlight < threshold {
+		if c.concurrencyWarned.Load() {
+			c.concurrencyWarned.Store(false)
+		}
+		return
+	}
+	if !c.concurrencyWarned.CompareAndSwap(false, true) || cfg.Execution.OnConcurrencyWarning == nil {
+		return
+	}
+	cfg.Execution.OnConcurrencyWarning(ConcurrencyWarning{
+		CircuitName: c.Name(),
+		InFlight:    inFlight,
+		Limit:       cfg.Execution.MaxConcurrentRequests,
+		Percent:     cfg.Execution.ConcurrencyWarningPercent,
+		Time:        now,
+	})
+}
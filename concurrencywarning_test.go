@@ -0,0 +1,76 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestCircuit_OnConcurrencyWarning(t *testing.T) {
+	var mu sync.Mutex
+	var warnings []ConcurrencyWarning
+	c := NewCircuitFromConfig("TestCircuit_OnConcurrencyWarning", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests:     10,
+			ConcurrencyWarningPercent: 50,
+			OnConcurrencyWarning: func(warning ConcurrencyWarning) {
+				mu.Lock()
+				defer mu.Unlock()
+				warnings = append(warnings, warning)
+			},
+		},
+	})
+	// fill starts n runs that block until release is closed
+	fill := func(n int) (release func()) {
+		var started, done sync.WaitGroup
+		unblock := make(chan struct{})
+		for i := 0; i < n; i++ {
+			started.Add(1)
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				_ = c.Execute(context.Background(), func(_ context.Context) error {
+					started.Done()
+					<-unblock
+					return nil
+				}, nil)
+			}()
+		}
+		started.Wait()
+		return func() {
+			close(unblock)
+			done.Wait()
+		}
+	}
+	release := fill(4)
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warning under 50%%, got %+v", warnings)
+	}
+	release()
+	release = fill(7)
+	release()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning while over the threshold, got %+v", warnings)
+	}
+	if w := warnings[0]; w.CircuitName != "TestCircuit_OnConcurrencyWarning" || w.InFlight < 5 || w.Limit != 10 || w.Percent != 50 {
+		t.Errorf("unexpected warning %+v", w)
+	}
+	// A run below the threshold rearms the warning
+	_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+	fill(5)()
+	if len(warnings) != 2 {
+		t.Errorf("expected a second warning after dropping below the threshold, got %+v", warnings)
+	}
+}
+
+func TestConcurrencyWarningThreshold(t *testing.T) {
+	if threshold := concurrencyWarningThreshold(-1, 80); threshold != 0 {
+		t.Errorf("expected no threshold without a limit, got %d", threshold)
+	}
+	if threshold := concurrencyWarningThreshold(10, 80); threshold != 8 {
+		t.Errorf("expected 8, got %d", threshold)
+	}
+	if threshold := concurrencyWarningThreshold(1, 10); threshold != 1 {
+		t.Errorf("expected at least 1, got %d", threshold)
+	}
+}
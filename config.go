@@ -1,9 +1,8 @@
 package circuit
 
 import (
+	"context"
 	"time"
-
-	"github.com/cep21/circuit/v4/faststats"
 )
 
 // Config controls how a circuit operates
@@ -35,16 +34,98 @@ type GeneralConfig struct {
 	OpenToClosedFactory func() OpenToClosed `json:"-"`
 	// CustomConfig is anything you want.
 	CustomConfig map[interface{}]interface{} `json:"-"`
+	// Thresholds overrides settings of the ClosedToOpen and OpenToClosed by name, like the error threshold of a hystrix
+	// opener.  Unlike changes made on the opener or closer directly, changes to it are audited, versioned, and rolled
+	// back like the rest of the config.  It is given to openers and closers that implement ThresholdsConfigurable.
+	// Settings it does not name keep the opener's or closer's own value.
+	Thresholds map[string]int64 `json:",omitempty"`
 	// TimeKeeper returns the current way to keep time.  You only want to modify this for testing.
 	TimeKeeper TimeKeeper `json:"-"`
+	// Rand makes the random choices of the opener, closer, and metrics collectors that implement RandInheritor and
+	// were not given a Rand of their own.  Set it to one seeded Rand, like NewRand(CircuitSeed(seed, name)), to make the
+	// whole circuit reproducible.  The default leaves them to math/rand.  It cannot change while the circuit is running.
+	Rand Rand `json:"-"`
+	// DependsOn names other circuits, in the same Manager, that this circuit calls through.  If one of them is open
+	// when this circuit opens, that circuit is the more likely cause.  See Manager.OpenCircuitCauses.
+	DependsOn []string `json:",omitempty"`
+	// RecentErrorsSize is how many of the most recent failures and timeouts the circuit remembers.  They are exposed
+	// with RecentErrors and on expvar.  Set to a negative number to not remember any errors.
+	RecentErrorsSize int64
+	// RecentBadRequestsSize is how many of the most recent bad requests the circuit remembers.  Bad requests do not
+	// change the health of a circuit, so a spike of them is easy to miss.  They are exposed with RecentBadRequests and
+	// on expvar.  Set to a negative number to not remember any bad requests.
+	RecentBadRequestsSize int64
+	// RedactError, if set, rewrites error messages before collectors see them and before RecentErrors and
+	// RecentBadRequests remember them.  Use it to remove private data, like credentials in URLs, from errors that
+	// are exported.  Errors returned to callers are not changed.  See RedactedError.
+	RedactError func(msg string) string `json:"-"`
+	// FlapWindow is how far back Flaps counts opens and closes.  It cannot change while the circuit is running.
+	FlapWindow time.Duration
+	// MinClosedDuration stops a circuit from opening again until it has been closed this long.  Failures are still
+	// recorded, but ClosedToOpen is not asked if the circuit should open.  It does not stop OpenCircuit.
+	MinClosedDuration time.Duration
+	// FlapThreshold holds a circuit open for FlapHoldOpen when it opens after FlapThreshold opens and closes inside
+	// FlapWindow.  OpenToClose is not asked to allow requests while the circuit is held open.  Zero disables this.
+	FlapThreshold int64
+	// FlapHoldOpen is how long a flapping circuit is held open.  See FlapThreshold.
+	FlapHoldOpen time.Duration
+	// MaintenanceWindows are times the circuit is forced open, so fallbacks serve traffic during planned maintenance
+	// of the dependency.  See Manager.ScheduleMaintenance to change them on a running circuit.
+	MaintenanceWindows []MaintenanceWindow `json:",omitempty"`
+	// WarmUpDuration stops a new circuit from opening until it is this old.  Errors are still recorded.  Use this when
+	// connection pools and DNS caches warming up at process start cause errors that do not mean the dependency is
+	// unhealthy.
+	WarmUpDuration time.Duration
+	// InitialState is the state a circuit created with NewCircuitFromConfig starts in.  StateOpen starts open, and
+	// OpenToClose decides when to try the dependency like for any other open circuit.  StateHalfOpen also starts open,
+	// but lets the first request through to test the dependency.  Use these when restoring saved state, or when the
+	// dependency is known to be down at deploy time.  The default is StateClosed.
+	InitialState CircuitState `json:",omitempty"`
+	// Counters names custom counters runs can add to with AddCounter, like "cache_hits".  They are sent to run
+	// collectors that implement CounterMetrics, so they share the window of the circuit's other stats.  Names not
+	// listed here are ignored.  It cannot change while the circuit is running.
+	Counters []string `json:",omitempty"`
+	// DetectNesting makes a circuit without a timeout count calls to other circuits from inside its runs, for
+	// NestedCalls and Manager.CallGraph.  Circuits with a timeout always count them.  It costs an allocation per run.
+	DetectNesting bool `json:",omitempty"`
+	// DecisionEngineFactory, if set, creates a DecisionEngine that decides when the circuit opens and closes, instead
+	// of ClosedToOpenFactory and OpenToClosedFactory
+	DecisionEngineFactory func() DecisionEngine `json:"-"`
+	// ConfigAudit, if set, records every change to the circuit's config made while it runs.  Share one log between
+	// circuits to see every change in one place.
+	ConfigAudit *ConfigAuditLog `json:"-"`
 }
 
 // ExecutionConfig is https://github.com/Netflix/Hystrix/wiki/Configuration#execution
 type ExecutionConfig struct {
 	// ExecutionTimeout is https://github.com/Netflix/Hystrix/wiki/Configuration#execution.isolation.thread.timeoutInMilliseconds
+	//
+	// The context given to runFunc ends at the timeout.
 	Timeout time.Duration
 	// MaxConcurrentRequests is https://github.com/Netflix/Hystrix/wiki/Configuration#executionisolationsemaphoremaxconcurrentrequests
+	// Runs given a cost with WithCost use that much of the limit.
 	MaxConcurrentRequests int64
+	// MaxConcurrentWait is how long a run over MaxConcurrentRequests waits for a slot, respecting its context, before
+	// it is rejected.  Waiting runs get slots in QueueOrder.  The default of 0 rejects runs right away, unless
+	// MaxQueueSize is set.  It has no effect when ConcurrencyLimiter is set.
+	MaxConcurrentWait time.Duration
+	// MaxQueueSize is how many runs can wait for a slot at once.  Runs over it are rejected right away.  If it is set
+	// without MaxConcurrentWait, runs wait up to their timeout.  With MaxConcurrentWait set, the default of 0 is no
+	// limit.
+	MaxQueueSize int64
+	// QueueOrder is the order waiting runs get slots in.  The default is QueueFIFO.
+	QueueOrder QueueOrder `json:",omitempty"`
+	// ConcurrencyLimiter, if set, decides which runs may start instead of MaxConcurrentRequests.  See Semaphore for a
+	// limiter that can be shared by many circuits.
+	ConcurrencyLimiter ConcurrencyLimiter `json:"-"`
+	// ConcurrencyWarningPercent, if set, calls OnConcurrencyWarning when runs in flight rise to this percentage of
+	// MaxConcurrentRequests, like 80, so there is a warning before runs are rejected.  It has no effect when
+	// ConcurrencyLimiter is set or there is no limit.
+	ConcurrencyWarningPercent int64 `json:",omitempty"`
+	// OnConcurrencyWarning is called by the run that brings the runs in flight to ConcurrencyWarningPercent.  It is
+	// called again the next time they rise to it after dropping below.  It runs before the run's runFunc, so it
+	// should be fast.
+	OnConcurrencyWarning func(warning ConcurrencyWarning) `json:"-"`
 	// Normally if the parent context is canceled before a timeout is reached, we don't consider the circuit
 	// unhealthy.  Set this to true to consider those circuits unhealthy.
 	IgnoreInterrupts bool `json:",omitempty"`
@@ -54,6 +135,63 @@ type ExecutionConfig struct {
 	// Default behaviour:
 	// 		IsErrInterrupt: function(e err) bool { return true }
 	IsErrInterrupt func(originalContextError error) bool `json:"-"`
+	// InterruptClassifier, if set, replaces the IgnoreInterrupts and IsErrInterrupt logic entirely.  It is called with
+	// the context passed into Execute and the non-nil error returned by runFunc, and should return true if the error
+	// is an interrupt that should not count against circuit health.  Use this to, for example, count your own
+	// client side cancellations as failures.
+	InterruptClassifier func(originalContext context.Context, err error) bool `json:"-"`
+	// RetryAfterClassifier, if set, returns how long the dependency asked callers to back off after err, or 0 for no
+	// hint.  It is called with the non-nil error of each failure and timeout.  The default uses RetryAfterOf.
+	RetryAfterClassifier func(err error) time.Duration `json:"-"`
+	// PartitionKey, if set, gives each request a partition (for example a tenant ID or API key) taken from the
+	// context passed into Execute.  Each partition can only run MaxConcurrentRequestsPerPartition commands at once,
+	// so one noisy partition cannot use up MaxConcurrentRequests for everyone else.
+	PartitionKey func(ctx context.Context) string `json:"-"`
+	// MaxConcurrentRequestsPerPartition limits the concurrent commands of a single partition.  Set to -1 for no
+	// limit.  It has no effect unless PartitionKey is set.
+	MaxConcurrentRequestsPerPartition int64
+	// MaxPartitions is how many partitions are tracked.  The least recently used idle partitions are forgotten after
+	// that.  It cannot change while the circuit is running.
+	MaxPartitions int
+	// SuccessErrors lists checks for errors that should be recorded as a success, even though runFunc returned them.
+	// The error is still returned to the caller, but no fallback is run.  Use this for errors like sql.ErrNoRows
+	// that are a normal answer from a healthy dependency, without wrapping them in SimpleBadRequest at every call
+	// site.
+	SuccessErrors []func(err error) bool `json:"-"`
+	// ResultClassifier, if set, is a ResultClassifier[T] that ExecuteValue calls with every value of type T returned
+	// without an error.  It lets a structurally successful answer, like a response flagged as partial, count as a
+	// failure.  It is ignored by Execute, and by ExecuteValue for other types of values.
+	ResultClassifier interface{} `json:"-"`
+	// DetachContext runs runFunc with a context that keeps the values of the caller's context, but is not canceled
+	// when the caller's context ends.  The run is then only bounded by Timeout.  Use this for work that should
+	// finish even if the request that started it goes away.  The default is to run with a child of the caller's
+	// context.
+	DetachContext bool `json:",omitempty"`
+	// OnStuckExecution, if set, is called when a run has not returned StuckTimeoutMultiple times Timeout after it
+	// started.  These are runs that ignore their context's cancellation.  The report includes the stack of the
+	// goroutine running runFunc so the code that cannot be canceled can be found.  It has no effect without a Timeout,
+	// or on runFunc passed to Go, which stops waiting for the run at the timeout.
+	OnStuckExecution func(stuck StuckExecution) `json:"-"`
+	// StuckTimeoutMultiple is how many multiples of Timeout a run can take before OnStuckExecution is called
+	StuckTimeoutMultiple int64
+	// TimeoutGracePeriod is how long Go waits for runFunc to return after the run's context ends, from a timeout or
+	// the caller, before abandoning it.  Use it for run functions that must not be abandoned mid-flight, like ones
+	// holding locks.  The default of 0 abandons the run right away.  Set to -1 to never abandon runs.  Execute always
+	// waits for runFunc.
+	TimeoutGracePeriod time.Duration
+	// MaxTimeoutOverride is the longest timeout WithTimeoutOverride can give a run.  Longer overrides are cut to it.
+	// The default of 0 only lets overrides shorten Timeout.  Set to -1 for no limit.
+	MaxTimeoutOverride time.Duration
+	// RetryBudgetPercent is how much extra load, as a percent of first attempts, retries marked with WithRetry may add
+	// over RetryBudgetWindow.  Retries over the budget are rejected with ErrRetryBudgetExhausted.  Set to -1 for no
+	// budget.
+	RetryBudgetPercent int64
+	// RetryBudgetMinPerSecond lets this many retries a second through on top of RetryBudgetPercent, so circuits with
+	// little traffic can still retry.  Set to -1 for none.
+	RetryBudgetMinPerSecond int64
+	// RetryBudgetWindow is how far back first attempts and retries are counted.  It cannot change while the circuit is
+	// running.
+	RetryBudgetWindow time.Duration
 }
 
 // FallbackConfig is https://github.com/Netflix/Hystrix/wiki/Configuration#fallback
@@ -63,6 +201,16 @@ type FallbackConfig struct {
 	Disabled bool `json:",omitempty"`
 	// MaxConcurrentRequests is https://github.com/Netflix/Hystrix/wiki/Configuration#fallback.isolation.semaphore.maxConcurrentRequests
 	MaxConcurrentRequests int64
+	// Shadow makes ExecuteShadow run the fallback even when runFunc works, so the two can be compared.  Use this to
+	// validate a new fallback, or a replacement dependency, before relying on it.
+	Shadow bool `json:",omitempty"`
+	// ShadowServesFallback makes ExecuteShadow return the fallback's result instead of runFunc's.  runFunc still runs
+	// through the circuit, but its result is only compared.  It has no effect unless Shadow is set.
+	ShadowServesFallback bool `json:",omitempty"`
+	// Circuit, if set, runs the fallback inside it.  Fallbacks often call another dependency, and this times out,
+	// limits and sheds those calls like any other, instead of letting a failing fallback stack up timeouts.  A
+	// fallback the circuit rejects returns the circuit's Error.  It must not be the circuit the fallback belongs to.
+	Circuit *Circuit `json:"-"`
 }
 
 // MetricsCollectors can receive metrics during a circuit.  They should be fast, as they will
@@ -91,6 +239,14 @@ type Configurable interface {
 	SetConfigNotThreadSafe(props Config)
 }
 
+// ThresholdsConfigurable is optionally implemented by ClosedToOpen and OpenToClosed implementations with settings that
+// GeneralConfig.Thresholds can override
+type ThresholdsConfigurable interface {
+	// SetThresholds is called with GeneralConfig.Thresholds when the circuit is set up, and each time its config
+	// changes.  It must not change thresholds, which belong to the config.
+	SetThresholds(thresholds map[string]int64)
+}
+
 func (t *TimeKeeper) merge(other TimeKeeper) {
 	if t.Now == nil {
 		t.Now = other.Now
@@ -107,9 +263,72 @@ func (c *ExecutionConfig) merge(other ExecutionConfig) {
 	if c.IsErrInterrupt == nil {
 		c.IsErrInterrupt = other.IsErrInterrupt
 	}
+	if c.InterruptClassifier == nil {
+		c.InterruptClassifier = other.InterruptClassifier
+	}
+	if c.RetryAfterClassifier == nil {
+		c.RetryAfterClassifier = other.RetryAfterClassifier
+	}
+	if c.PartitionKey == nil {
+		c.PartitionKey = other.PartitionKey
+	}
+	if c.MaxConcurrentRequestsPerPartition == 0 {
+		c.MaxConcurrentRequestsPerPartition = other.MaxConcurrentRequestsPerPartition
+	}
+	if c.MaxPartitions == 0 {
+		c.MaxPartitions = other.MaxPartitions
+	}
+	if len(c.SuccessErrors) == 0 {
+		c.SuccessErrors = other.SuccessErrors
+	}
+	if c.ResultClassifier == nil {
+		c.ResultClassifier = other.ResultClassifier
+	}
+	if !c.DetachContext {
+		c.DetachContext = other.DetachContext
+	}
+	if c.OnStuckExecution == nil {
+		c.OnStuckExecution = other.OnStuckExecution
+	}
+	if c.StuckTimeoutMultiple == 0 {
+		c.StuckTimeoutMultiple = other.StuckTimeoutMultiple
+	}
+	if c.TimeoutGracePeriod == 0 {
+		c.TimeoutGracePeriod = other.TimeoutGracePeriod
+	}
+	if c.MaxTimeoutOverride == 0 {
+		c.MaxTimeoutOverride = other.MaxTimeoutOverride
+	}
+	if c.RetryBudgetPercent == 0 {
+		c.RetryBudgetPercent = other.RetryBudgetPercent
+	}
+	if c.RetryBudgetMinPerSecond == 0 {
+		c.RetryBudgetMinPerSecond = other.RetryBudgetMinPerSecond
+	}
+	if c.RetryBudgetWindow == 0 {
+		c.RetryBudgetWindow = other.RetryBudgetWindow
+	}
 	if c.MaxConcurrentRequests == 0 {
 		c.MaxConcurrentRequests = other.MaxConcurrentRequests
 	}
+	if c.MaxQueueSize == 0 {
+		c.MaxQueueSize = other.MaxQueueSize
+	}
+	if c.MaxConcurrentWait == 0 {
+		c.MaxConcurrentWait = other.MaxConcurrentWait
+	}
+	if c.QueueOrder == "" {
+		c.QueueOrder = other.QueueOrder
+	}
+	if c.ConcurrencyLimiter == nil {
+		c.ConcurrencyLimiter = other.ConcurrencyLimiter
+	}
+	if c.ConcurrencyWarningPercent == 0 {
+		c.ConcurrencyWarningPercent = other.ConcurrencyWarningPercent
+	}
+	if c.OnConcurrencyWarning == nil {
+		c.OnConcurrencyWarning = other.OnConcurrencyWarning
+	}
 	if c.Timeout == 0 {
 		c.Timeout = other.Timeout
 	}
@@ -122,6 +341,15 @@ func (c *FallbackConfig) merge(other FallbackConfig) {
 	if !c.Disabled {
 		c.Disabled = other.Disabled
 	}
+	if !c.Shadow {
+		c.Shadow = other.Shadow
+	}
+	if !c.ShadowServesFallback {
+		c.ShadowServesFallback = other.ShadowServesFallback
+	}
+	if c.Circuit == nil {
+		c.Circuit = other.Circuit
+	}
 }
 
 func (g *GeneralConfig) mergeCustomConfig(other GeneralConfig) {
@@ -137,6 +365,19 @@ func (g *GeneralConfig) mergeCustomConfig(other GeneralConfig) {
 	}
 }
 
+func (g *GeneralConfig) mergeThresholds(other GeneralConfig) {
+	if len(other.Thresholds) != 0 {
+		merged := make(map[string]int64, len(g.Thresholds)+len(other.Thresholds))
+		for k, v := range other.Thresholds {
+			merged[k] = v
+		}
+		for k, v := range g.Thresholds {
+			merged[k] = v
+		}
+		g.Thresholds = merged
+	}
+}
+
 func (g *GeneralConfig) merge(other GeneralConfig) {
 	if g.ClosedToOpenFactory == nil {
 		g.ClosedToOpenFactory = other.ClosedToOpenFactory
@@ -145,6 +386,10 @@ func (g *GeneralConfig) merge(other GeneralConfig) {
 		g.OpenToClosedFactory = other.OpenToClosedFactory
 	}
 	g.mergeCustomConfig(other)
+	g.mergeThresholds(other)
+	if g.Rand == nil {
+		g.Rand = other.Rand
+	}
 
 	if !g.ForceOpen {
 		g.ForceOpen = other.ForceOpen
@@ -161,6 +406,53 @@ func (g *GeneralConfig) merge(other GeneralConfig) {
 	if g.GoLostErrors == nil {
 		g.GoLostErrors = other.GoLostErrors
 	}
+
+	if len(g.DependsOn) == 0 {
+		g.DependsOn = other.DependsOn
+	}
+
+	if g.RecentErrorsSize == 0 {
+		g.RecentErrorsSize = other.RecentErrorsSize
+	}
+	if g.RecentBadRequestsSize == 0 {
+		g.RecentBadRequestsSize = other.RecentBadRequestsSize
+	}
+	if g.RedactError == nil {
+		g.RedactError = other.RedactError
+	}
+	if g.FlapWindow == 0 {
+		g.FlapWindow = other.FlapWindow
+	}
+	if g.MinClosedDuration == 0 {
+		g.MinClosedDuration = other.MinClosedDuration
+	}
+	if g.FlapThreshold == 0 {
+		g.FlapThreshold = other.FlapThreshold
+	}
+	if g.FlapHoldOpen == 0 {
+		g.FlapHoldOpen = other.FlapHoldOpen
+	}
+	if len(g.MaintenanceWindows) == 0 {
+		g.MaintenanceWindows = other.MaintenanceWindows
+	}
+	if g.WarmUpDuration == 0 {
+		g.WarmUpDuration = other.WarmUpDuration
+	}
+	if g.InitialState == "" {
+		g.InitialState = other.InitialState
+	}
+	if len(g.Counters) == 0 {
+		g.Counters = other.Counters
+	}
+	if !g.DetectNesting {
+		g.DetectNesting = other.DetectNesting
+	}
+	if g.DecisionEngineFactory == nil {
+		g.DecisionEngineFactory = other.DecisionEngineFactory
+	}
+	if g.ConfigAudit == nil {
+		g.ConfigAudit = other.ConfigAudit
+	}
 	g.TimeKeeper.merge(other.TimeKeeper)
 }
 
@@ -180,44 +472,41 @@ func (c *Config) Merge(other Config) *Config {
 	return c
 }
 
-// atomicCircuitConfig is used during circuit operations and allows atomic read/write operations.  This lets users
-// change config at runtime without requiring locks on common operations
-type atomicCircuitConfig struct {
-	Execution struct {
-		ExecutionTimeout      faststats.AtomicInt64
-		MaxConcurrentRequests faststats.AtomicInt64
-	}
-	Fallback struct {
-		Disabled              faststats.AtomicBoolean
-		MaxConcurrentRequests faststats.AtomicInt64
-	}
-	CircuitBreaker struct {
-		ForceOpen    faststats.AtomicBoolean
-		ForcedClosed faststats.AtomicBoolean
-		Disabled     faststats.AtomicBoolean
-	}
-	GoSpecific struct {
-		IgnoreInterrupts faststats.AtomicBoolean
-	}
+// configSnapshot is a circuit's config as runs see it.  It is never changed once it is stored: SetConfigThreadSafe
+// stores a new snapshot, so a run reads every field from one config instead of a mix of an old one and a new one.
+type configSnapshot struct {
+	Config
+	// version counts the configs the circuit has had
+	version int64
 }
 
-func (a *atomicCircuitConfig) reset(config Config) {
-	a.CircuitBreaker.ForcedClosed.Set(config.General.ForcedClosed)
-	a.CircuitBreaker.ForceOpen.Set(config.General.ForceOpen)
-	a.CircuitBreaker.Disabled.Set(config.General.Disabled)
-
-	a.Execution.ExecutionTimeout.Set(config.Execution.Timeout.Nanoseconds())
-	a.Execution.MaxConcurrentRequests.Set(config.Execution.MaxConcurrentRequests)
-
-	a.GoSpecific.IgnoreInterrupts.Set(config.Execution.IgnoreInterrupts)
+// emptyConfigSnapshot is the config of a circuit that was never configured
+var emptyConfigSnapshot = &configSnapshot{}
 
-	a.Fallback.Disabled.Set(config.Fallback.Disabled)
-	a.Fallback.MaxConcurrentRequests.Set(config.Fallback.MaxConcurrentRequests)
+// newConfigSnapshot copies config into a snapshot.  Slices the circuit reads while running are copied, so the caller
+// changing them later does not change the snapshot.
+func newConfigSnapshot(config Config, version int64) *configSnapshot {
+	if len(config.General.MaintenanceWindows) == 0 {
+		config.General.MaintenanceWindows = nil
+	} else {
+		config.General.MaintenanceWindows = append([]MaintenanceWindow(nil), config.General.MaintenanceWindows...)
+	}
+	config.Execution.SuccessErrors = append([]func(error) bool(nil), config.Execution.SuccessErrors...)
+	return &configSnapshot{
+		Config:  config,
+		version: version,
+	}
 }
 
 var defaultExecutionConfig = ExecutionConfig{
-	Timeout:               time.Second,
-	MaxConcurrentRequests: 10,
+	Timeout:                           time.Second,
+	MaxConcurrentRequests:             10,
+	MaxConcurrentRequestsPerPartition: 10,
+	MaxPartitions:                     1000,
+	StuckTimeoutMultiple:              3,
+	RetryBudgetPercent:                20,
+	RetryBudgetMinPerSecond:           10,
+	RetryBudgetWindow:                 10 * time.Second,
 }
 
 var defaultFallbackConfig = FallbackConfig{
@@ -225,8 +514,12 @@ var defaultFallbackConfig = FallbackConfig{
 }
 
 var defaultGoSpecificConfig = GeneralConfig{
-	ClosedToOpenFactory: neverOpensFactory,
-	OpenToClosedFactory: neverClosesFactory,
+	ClosedToOpenFactory:   neverOpensFactory,
+	OpenToClosedFactory:   neverClosesFactory,
+	RecentErrorsSize:      10,
+	RecentBadRequestsSize: 10,
+	FlapWindow:            time.Minute,
+	FlapHoldOpen:          time.Minute,
 	TimeKeeper: TimeKeeper{
 		Now:       time.Now,
 		AfterFunc: time.AfterFunc,
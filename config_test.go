@@ -1,7 +1,10 @@
 package circuit
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -81,4 +84,65 @@ func TestExecutionConfig_Merge(t *testing.T) {
 		assert.NotNil(t, fn1, cfg.IsErrInterrupt)
 		assert.True(t, cfg.IsErrInterrupt(nil))
 	})
+
+	t.Run("respect DetachContext field of args cfg", func(t *testing.T) {
+		cfg := ExecutionConfig{}
+
+		cfg.merge(ExecutionConfig{DetachContext: true})
+
+		assert.True(t, cfg.DetachContext, "expect to be true")
+	})
+}
+
+func TestCircuit_ConfigSnapshot(t *testing.T) {
+	windows := []MaintenanceWindow{{Start: time.Now().Add(time.Hour), End: time.Now().Add(2 * time.Hour)}}
+	c := NewCircuitFromConfig("TestCircuit_ConfigSnapshot", Config{
+		General: GeneralConfig{
+			MaintenanceWindows: windows,
+		},
+	})
+	version := c.config().version
+	// Changing the caller's slice must not change the running config
+	windows[0].Start = time.Now().Add(-time.Hour)
+	if c.IsOpen() {
+		t.Error("expected the circuit to keep its own copy of the maintenance windows")
+	}
+	cfg := c.Config()
+	cfg.Execution.MaxConcurrentRequests = 5
+	c.SetConfigThreadSafe(cfg)
+	if c.config().version != version+1 {
+		t.Errorf("expected the config version to go from %d to %d, got %d", version, version+1, c.config().version)
+	}
+	if c.Config().Execution.MaxConcurrentRequests != 5 {
+		t.Error("expected the new config to be used")
+	}
+}
+
+func TestCircuit_ConfigSnapshotConcurrent(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_ConfigSnapshotConcurrent", Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ctx.Err() == nil; i++ {
+			cfg := c.Config()
+			cfg.Execution.MaxConcurrentRequests = int64(100 + i%2)
+			cfg.Execution.Timeout = time.Duration(100+i%2) * time.Second
+			c.SetConfigThreadSafe(cfg)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		// Every field of a snapshot comes from the same config
+		cfg := c.config()
+		if cfg.Execution.MaxConcurrentRequests != int64(cfg.Execution.Timeout/time.Second) && cfg.version > 1 {
+			t.Fatalf("saw a torn config: %d and %s", cfg.Execution.MaxConcurrentRequests, cfg.Execution.Timeout)
+		}
+		if err := c.Execute(ctx, func(_ context.Context) error { return nil }, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	wg.Wait()
 }
@@ -0,0 +1,150 @@
+package circuit
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// maxConfigVersions is how many versions a Manager remembers for RollbackTo
+const maxConfigVersions = 100
+
+// configVersions remembers the config of every circuit at each version.  The zero value is ready to use.
+type configVersions struct {
+	mu       sync.Mutex
+	versions []configVersion
+}
+
+type configVersion struct {
+	version int64
+	configs map[string]Config
+}
+
+// currentConfigs is the config of every circuit in h
+func (h *Manager) currentConfigs() map[string]Config {
+	circuits := h.AllCircuits()
+	ret := make(map[string]Config, len(circuits))
+	for _, c := range circuits {
+		ret[c.Name()] = c.Config()
+	}
+	return ret
+}
+
+// ConfigVersion returns a number for the current config of every circuit.  It only changes when a config changes.
+// Versions are only recorded when ConfigVersion or RollbackTo is called, so ConfigVersion must be called before a
+// config push: a config that was never seen by ConfigVersion cannot be compared with DiffConfig or restored with
+// RollbackTo.  The last 100 versions are remembered.  Only fields that can be encoded as JSON are compared, like in
+// ConfigAuditLog.
+func (h *Manager) ConfigVersion() int64 {
+	h.configVersions.mu.Lock()
+	defer h.configVersions.mu.Unlock()
+	return h.configVersionWithLock()
+}
+
+func (h *Manager) configVersionWithLock() int64 {
+	current := h.currentConfigs()
+	v := &h.configVersions
+	if len(v.versions) != 0 {
+		last := v.versions[len(v.versions)-1]
+		if sameCircuits(last.configs, current) && len(diffConfigs(last.configs, current)) == 0 {
+			return last.version
+		}
+	}
+	next := configVersion{version: 1, configs: current}
+	if len(v.versions) != 0 {
+		next.version = v.versions[len(v.versions)-1].version + 1
+	}
+	if len(v.versions) == maxConfigVersions {
+		v.versions = append(v.versions[:0], v.versions[1:]...)
+	}
+	v.versions = append(v.versions, next)
+	return next.version
+}
+
+func (h *Manager) findConfigVersion(version int64) (configVersion, error) {
+	for _, v := range h.configVersions.versions {
+		if v.version == version {
+			return v, nil
+		}
+	}
+	return configVersion{}, fmt.Errorf("config version %d is not remembered", version)
+}
+
+// DiffConfig returns how the config of each circuit changed from version to now, sorted by circuit name.  Circuits
+// created or removed since version are not included.  The changes have no Time or Source.
+func (h *Manager) DiffConfig(version int64) ([]ConfigChange, error) {
+	h.configVersions.mu.Lock()
+	defer h.configVersions.mu.Unlock()
+	old, err := h.findConfigVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return diffConfigs(old.configs, h.currentConfigs()), nil
+}
+
+// RollbackTo sets the config of every circuit back to what it was at version.  Every circuit is locked against other
+// config changes first, so the rollback never interleaves with another SetConfigThreadSafe and is never half applied.
+// Each circuit still swaps its own config, so for the moment the new configs are being stored, a request that goes
+// through many circuits can see some of them reverted and others not yet.  Circuits created since version are left
+// alone.  Each change is recorded in ConfigAudit with the source "rollback".
+func (h *Manager) RollbackTo(version int64) error {
+	h.configVersions.mu.Lock()
+	defer h.configVersions.mu.Unlock()
+	old, err := h.findConfigVersion(version)
+	if err != nil {
+		return err
+	}
+	circuits := make([]*Circuit, 0, len(old.configs))
+	for name := range old.configs {
+		if c := h.GetCircuit(name); c != nil {
+			circuits = append(circuits, c)
+		}
+	}
+	// Only RollbackTo locks more than one circuit, and it holds configVersions.mu, so the order does not matter
+	for _, c := range circuits {
+		c.configMu.Lock()
+	}
+	changes := make([]ConfigChange, len(circuits))
+	for i, c := range circuits {
+		changes[i] = c.setConfigFromWithLock("rollback", old.configs[c.Name()])
+	}
+	for _, c := range circuits {
+		c.configMu.Unlock()
+	}
+	for i, c := range circuits {
+		c.notifyConfigChanged(changes[i])
+	}
+	h.configVersionWithLock()
+	return nil
+}
+
+func sameCircuits(a map[string]Config, b map[string]Config) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, exists := b[name]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// diffConfigs compares the circuits in both old and new
+func diffConfigs(old map[string]Config, new map[string]Config) []ConfigChange {
+	var ret []ConfigChange
+	for name, oldConfig := range old {
+		newConfig, exists := new[name]
+		if !exists {
+			continue
+		}
+		if fields := diffConfig("", reflect.ValueOf(oldConfig), reflect.ValueOf(newConfig)); len(fields) != 0 {
+			ret = append(ret, ConfigChange{Circuit: name, Fields: fields})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Circuit < ret[j].Circuit
+	})
+	return ret
+}
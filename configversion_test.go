@@ -0,0 +1,104 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestManager_RollbackTo(t *testing.T) {
+	audit := NewConfigAuditLog(10)
+	h := Manager{
+		DefaultCircuitProperties: []CommandPropertiesConstructor{func(_ string) Config {
+			return Config{General: GeneralConfig{ConfigAudit: audit}}
+		}},
+	}
+	db := h.MustCreateCircuit("db")
+	cache := h.MustCreateCircuit("cache")
+	before := h.ConfigVersion()
+	if again := h.ConfigVersion(); again != before {
+		t.Errorf("expected the version to not change without a config change, got %d and %d", before, again)
+	}
+
+	cfg := db.Config()
+	cfg.Execution.Timeout = 5 * time.Second
+	db.SetConfigThreadSafe(cfg)
+	after := h.ConfigVersion()
+	if after == before {
+		t.Fatal("expected a config change to make a new version")
+	}
+	changes, err := h.DiffConfig(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Circuit != "db" || changes[0].Fields[0].Field != "Execution.Timeout" || changes[0].Fields[0].New != 5*time.Second {
+		t.Errorf("expected the db timeout change, got %+v", changes)
+	}
+
+	h.MustCreateCircuit("new")
+	if err := h.RollbackTo(before); err != nil {
+		t.Fatal(err)
+	}
+	if db.Config().Execution.Timeout != time.Second || cache.Config().Execution.Timeout != time.Second {
+		t.Error("expected the timeout to be rolled back")
+	}
+	if changes, _ := h.DiffConfig(before); len(changes) != 0 {
+		t.Errorf("expected no difference after the rollback, got %+v", changes)
+	}
+	if latest := audit.Changes(); latest[len(latest)-1].Source != "rollback" {
+		t.Errorf("expected the rollback to be audited, got %+v", latest)
+	}
+	if _, err := h.DiffConfig(1000); err == nil {
+		t.Error("expected an unknown version to fail")
+	}
+}
+
+// configWatcher calls onChange for every config change of a circuit
+type configWatcher struct {
+	onChange func(change ConfigChange)
+}
+
+func (w configWatcher) Closed(_ context.Context, _ time.Time) {}
+func (w configWatcher) Opened(_ context.Context, _ time.Time) {}
+func (w configWatcher) ConfigChanged(_ context.Context, _ time.Time, change ConfigChange) {
+	w.onChange(change)
+}
+
+func TestManager_RollbackToAllCircuits(t *testing.T) {
+	h := Manager{}
+	var circuits []*Circuit
+	var rollingBack bool
+	var notified int
+	onChange := func(change ConfigChange) {
+		if !rollingBack {
+			return
+		}
+		notified++
+		for _, c := range circuits {
+			if timeout := c.Config().Execution.Timeout; timeout != time.Second {
+				t.Errorf("expected %s to be rolled back before %s was told, got %s", c.Name(), change.Circuit, timeout)
+			}
+		}
+		// Watchers are told once the circuits are unlocked, so they can change the config of other circuits
+		if change.Circuit == "a" {
+			h.GetCircuit("unchanged").SetConfigThreadSafe(Config{})
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		circuits = append(circuits, h.MustCreateCircuit(name, Config{
+			Metrics: MetricsCollectors{Circuit: []Metrics{configWatcher{onChange: onChange}}},
+		}))
+	}
+	h.MustCreateCircuit("unchanged")
+	before := h.ConfigVersion()
+	for _, c := range circuits {
+		c.SetConfigThreadSafe(Config{Execution: ExecutionConfig{Timeout: 5 * time.Second}})
+	}
+	rollingBack = true
+	if err := h.RollbackTo(before); err != nil {
+		t.Fatal(err)
+	}
+	if notified != len(circuits) {
+		t.Errorf("expected every rolled back circuit to be reported once, got %d", notified)
+	}
+}
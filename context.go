@@ -0,0 +1,162 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// timeoutContext is the context given to runFunc when the circuit has a timeout.  It behaves like
+// context.WithDeadline, but does no work until someone calls Done: a run that only checks Err, or never looks at its
+// context at all, costs no channel or timer.
+type timeoutContext struct {
+	parent   context.Context
+	deadline time.Time
+	// circuit is the circuit running with this context, or nil.  See runningCircuitKey.
+	circuit *Circuit
+
+	// All these variables must be accessed with the mutex
+	mu         sync.Mutex
+	done       chan struct{}
+	err        error
+	timer      *pooledTimer
+	stopParent func() bool
+}
+
+var _ context.Context = &timeoutContext{}
+
+// newTimeoutContext returns a context that ends at deadline.  It must be passed to release once the run is over.
+func newTimeoutContext(parent context.Context, deadline time.Time) *timeoutContext {
+	return &timeoutContext{
+		parent:   parent,
+		deadline: deadline,
+	}
+}
+
+// release ends the context, like the cancel function of context.WithDeadline
+func (t *timeoutContext) release() {
+	t.cancel(context.Canceled)
+}
+
+// cancelWithLock ends the context with err, if it has not ended already
+func (t *timeoutContext) cancelWithLock(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+	if t.done != nil {
+		select {
+		case <-t.done:
+		default:
+			close(t.done)
+		}
+	}
+	if t.timer != nil {
+		t.timer.release()
+		t.timer = nil
+	}
+	if t.stopParent != nil {
+		t.stopParent()
+		t.stopParent = nil
+	}
+}
+
+func (t *timeoutContext) cancel(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cancelWithLock(err)
+}
+
+// errWithLock returns why the context ended, checking the deadline and parent since nothing may be watching them
+func (t *timeoutContext) errWithLock() error {
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.parent.Err(); err != nil {
+		t.cancelWithLock(err)
+	} else if !time.Now().Before(t.deadline) {
+		t.cancelWithLock(context.DeadlineExceeded)
+	}
+	return t.err
+}
+
+func (t *timeoutContext) Deadline() (deadline time.Time, ok bool) {
+	if parentDeadline, ok := t.parent.Deadline(); ok && parentDeadline.Before(t.deadline) {
+		return parentDeadline, true
+	}
+	return t.deadline, true
+}
+
+func (t *timeoutContext) Done() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		return t.done
+	}
+	t.done = make(chan struct{})
+	if t.errWithLock() != nil {
+		close(t.done)
+		return t.done
+	}
+	t.timer = newPooledTimer(t, time.Until(t.deadline))
+	if t.parent.Done() != nil {
+		parent := t.parent
+		t.stopParent = context.AfterFunc(parent, func() {
+			t.cancel(parent.Err())
+		})
+	}
+	return t.done
+}
+
+func (t *timeoutContext) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.errWithLock()
+}
+
+func (t *timeoutContext) Value(key interface{}) interface{} {
+	if _, isRunningCircuit := key.(runningCircuitKey); isRunningCircuit && t.circuit != nil {
+		return t.circuit
+	}
+	return t.parent.Value(key)
+}
+
+func (t *timeoutContext) String() string {
+	return "circuit.timeoutContext"
+}
+
+// pooledTimer times out a timeoutContext.  Creating a timer for every run is a measurable cost for busy circuits, so
+// timers are reused once stopped.
+type pooledTimer struct {
+	timer *time.Timer
+	// owner is the context to time out.  It is only changed while the timer is stopped.
+	owner atomic.Pointer[timeoutContext]
+}
+
+var timerPool sync.Pool
+
+func newPooledTimer(owner *timeoutContext, d time.Duration) *pooledTimer {
+	if p, ok := timerPool.Get().(*pooledTimer); ok {
+		p.owner.Store(owner)
+		p.timer.Reset(d)
+		return p
+	}
+	p := &pooledTimer{}
+	p.owner.Store(owner)
+	p.timer = time.AfterFunc(d, p.fire)
+	return p
+}
+
+func (p *pooledTimer) fire() {
+	if owner := p.owner.Load(); owner != nil {
+		owner.cancel(context.DeadlineExceeded)
+	}
+}
+
+// release stops the timer.  It is only reused if it never fired: a timer that fired may still be running fire.
+func (p *pooledTimer) release() {
+	if p.timer.Stop() {
+		p.owner.Store(nil)
+		timerPool.Put(p)
+	}
+}
@@ -0,0 +1,116 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testContextKey struct{}
+
+func TestTimeoutContext(t *testing.T) {
+	t.Run("times out", func(t *testing.T) {
+		ctx := newTimeoutContext(context.Background(), time.Now().Add(time.Millisecond))
+		defer ctx.release()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected the context to end at its deadline")
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", ctx.Err())
+		}
+	})
+	t.Run("err without done", func(t *testing.T) {
+		ctx := newTimeoutContext(context.Background(), time.Now().Add(-time.Millisecond))
+		defer ctx.release()
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", ctx.Err())
+		}
+		select {
+		case <-ctx.Done():
+		default:
+			t.Error("expected done to already be closed")
+		}
+	})
+	t.Run("parent canceled", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "value"))
+		ctx := newTimeoutContext(parent, time.Now().Add(time.Hour))
+		defer ctx.release()
+		if ctx.Value(testContextKey{}) != "value" {
+			t.Error("expected parent values")
+		}
+		done := ctx.Done()
+		if ctx.Err() != nil {
+			t.Error("context should not end yet")
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected the context to end with its parent")
+		}
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("expected canceled, got %v", ctx.Err())
+		}
+	})
+	t.Run("earlier parent deadline", func(t *testing.T) {
+		parentDeadline := time.Now().Add(time.Minute)
+		parent, cancel := context.WithDeadline(context.Background(), parentDeadline)
+		defer cancel()
+		ctx := newTimeoutContext(parent, time.Now().Add(time.Hour))
+		defer ctx.release()
+		if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(parentDeadline) {
+			t.Errorf("expected parent deadline, got %v", deadline)
+		}
+	})
+	t.Run("released", func(t *testing.T) {
+		ctx := newTimeoutContext(context.Background(), time.Now().Add(time.Hour))
+		done := ctx.Done()
+		ctx.release()
+		select {
+		case <-done:
+		default:
+			t.Error("expected release to end the context")
+		}
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("expected canceled, got %v", ctx.Err())
+		}
+	})
+	t.Run("reused timer", func(t *testing.T) {
+		first := newTimeoutContext(context.Background(), time.Now().Add(time.Hour))
+		first.Done()
+		first.release()
+		second := newTimeoutContext(context.Background(), time.Now().Add(time.Millisecond))
+		defer second.release()
+		select {
+		case <-second.Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected the context to end at its deadline")
+		}
+		if !errors.Is(first.Err(), context.Canceled) {
+			t.Errorf("expected the first context to stay canceled, got %v", first.Err())
+		}
+	})
+}
+
+func TestTimeoutContext_keptAfterRun(t *testing.T) {
+	c := NewCircuitFromConfig("TestTimeoutContext_keptAfterRun", Config{})
+	kept := make([]context.Context, 0, 2)
+	for _, value := range []string{"first", "second"} {
+		ctx := context.WithValue(context.Background(), testContextKey{}, value)
+		if err := c.Execute(ctx, func(ctx context.Context) error {
+			kept = append(kept, ctx)
+			return nil
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if kept[0].Value(testContextKey{}) != "first" || kept[1].Value(testContextKey{}) != "second" {
+		t.Errorf("expected kept contexts to keep their own values, got %v and %v", kept[0].Value(testContextKey{}), kept[1].Value(testContextKey{}))
+	}
+	if !errors.Is(kept[0].Err(), context.Canceled) {
+		t.Errorf("expected a kept context to end with its run, got %v", kept[0].Err())
+	}
+}
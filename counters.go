@@ -0,0 +1,54 @@
+package circuit
+
+import (
+	"context"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// CounterMetrics can optionally be implemented by RunMetrics that want the custom counters named by
+// GeneralConfig.Counters.  Counter is called each time a run adds to one with AddCounter.
+type CounterMetrics interface {
+	Counter(ctx context.Context, now time.Time, name string, delta int64)
+}
+
+var _ CounterMetrics = RunMetricsCollection(nil)
+
+func newCounters(names []string) map[string]*faststats.AtomicInt64 {
+	if len(names) == 0 {
+		return nil
+	}
+	ret := make(map[string]*faststats.AtomicInt64, len(names))
+	for _, name := range names {
+		ret[name] = &faststats.AtomicInt64{}
+	}
+	return ret
+}
+
+// AddCounter adds delta to the custom counter name of the circuit whose runFunc is running with ctx.  It does nothing
+// if ctx is not from a circuit's run, or if the circuit's GeneralConfig.Counters does not name the counter.
+func AddCounter(ctx context.Context, name string, delta int64) {
+	c, ok := ctx.Value(runningCircuitKey{}).(*Circuit)
+	if !ok || c == nil {
+		return
+	}
+	counter, exists := c.counters[name]
+	if !exists {
+		return
+	}
+	counter.Add(delta)
+	c.CmdMetricCollector.Counter(ctx, c.now(), name, delta)
+}
+
+// Counters returns the total of each custom counter named by GeneralConfig.Counters since the circuit was created
+func (c *Circuit) Counters() map[string]int64 {
+	if c == nil {
+		return nil
+	}
+	ret := make(map[string]int64, len(c.counters))
+	for name, counter := range c.counters {
+		ret[name] = counter.Get()
+	}
+	return ret
+}
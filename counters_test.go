@@ -0,0 +1,74 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type counterCollector struct {
+	RunMetrics
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (c *counterCollector) Counter(_ context.Context, _ time.Time, name string, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int64)
+	}
+	c.counts[name] += delta
+}
+
+func TestAddCounter(t *testing.T) {
+	collector := &counterCollector{RunMetrics: neverOpensFactory()}
+	c := NewCircuitFromConfig("TestAddCounter", Config{
+		General: GeneralConfig{
+			Counters: []string{"cache_hits", "cache_misses"},
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{collector},
+		},
+	})
+	for i := 0; i < 3; i++ {
+		err := c.Run(context.Background(), func(ctx context.Context) error {
+			AddCounter(ctx, "cache_hits", 2)
+			AddCounter(ctx, "not_registered", 1)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Outside a run, there is no circuit to count against
+	AddCounter(context.Background(), "cache_hits", 1)
+
+	expected := map[string]int64{"cache_hits": 6, "cache_misses": 0}
+	if counters := c.Counters(); !reflect.DeepEqual(counters, expected) {
+		t.Errorf("expected %v, got %v", expected, counters)
+	}
+	if !reflect.DeepEqual(collector.counts, map[string]int64{"cache_hits": 6}) {
+		t.Errorf("expected the collector to see only registered counters, got %v", collector.counts)
+	}
+}
+
+func TestAddCounter_Nested(t *testing.T) {
+	outer := NewCircuitFromConfig("outer", Config{General: GeneralConfig{Counters: []string{"retries"}}})
+	inner := NewCircuitFromConfig("inner", Config{General: GeneralConfig{Counters: []string{"retries"}}})
+	err := outer.Run(context.Background(), func(ctx context.Context) error {
+		AddCounter(ctx, "retries", 1)
+		return inner.Run(ctx, func(ctx context.Context) error {
+			AddCounter(ctx, "retries", 1)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outer.Counters()["retries"] != 1 || inner.Counters()["retries"] != 1 {
+		t.Errorf("expected each circuit to count its own run, got outer=%v inner=%v", outer.Counters(), inner.Counters())
+	}
+}
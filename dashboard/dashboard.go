@@ -0,0 +1,139 @@
+package dashboard
+
+import (
+	_ "embed" // Used to embed the page
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Handler serves the dashboard page at its root and the stream the page reads at /events.  Mount it with
+// http.StripPrefix if it is not at the root of your mux.
+type Handler struct {
+	Manager *circuit.Manager
+	// Source, if set, is where samples come from instead of Manager, such as the circuits of many processes combined by
+	// package aggregator
+	Source func() []Sample
+	// TickDuration is how often the page is sent new samples.  The default is one second.
+	TickDuration time.Duration
+}
+
+var _ http.Handler = &Handler{}
+
+// Sample is what the page is sent about a circuit each tick
+type Sample struct {
+	Name   string               `json:"name"`
+	State  circuit.CircuitState `json:"state"`
+	Reason circuit.StateReason  `json:"reason"`
+	// Requests is the number of requests in the rolling window
+	Requests int64 `json:"requests"`
+	// ErrorPercentage is from 0 to 100
+	ErrorPercentage float64 `json:"error_percentage"`
+	// LatencyMean and LatencyP99 are in milliseconds
+	LatencyMean        float64 `json:"latency_mean_ms"`
+	LatencyP99         float64 `json:"latency_p99_ms"`
+	ConcurrentCommands int64   `json:"concurrent_commands"`
+}
+
+func (h *Handler) tickDuration() time.Duration {
+	if h.TickDuration == 0 {
+		return time.Second
+	}
+	return h.TickDuration
+}
+
+// ServeHTTP serves the page, or the event stream
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(rw, "use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	switch strings.Trim(req.URL.Path, "/") {
+	case "":
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = rw.Write(indexHTML)
+	case "events":
+		h.events(rw, req)
+	default:
+		http.NotFound(rw, req)
+	}
+}
+
+// events sends every circuit's Sample as a server sent event each tick until the client leaves
+func (h *Handler) events(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(h.tickDuration())
+	defer ticker.Stop()
+	for {
+		asJSON, err := json.Marshal(h.Samples())
+		if err != nil {
+			return
+		}
+		if _, err := rw.Write(append(append([]byte("data: "), asJSON...), '\n', '\n')); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Samples returns the current Sample of every circuit, sorted by name
+func (h *Handler) Samples() []Sample {
+	if h.Source != nil {
+		return h.Source()
+	}
+	circuits := h.Manager.AllCircuits()
+	sort.Slice(circuits, func(i, j int) bool {
+		return circuits[i].Name() < circuits[j].Name()
+	})
+	ret := make([]Sample, 0, len(circuits))
+	for _, c := range circuits {
+		ret = append(ret, sample(c))
+	}
+	return ret
+}
+
+func sample(c *circuit.Circuit) Sample {
+	ret := Sample{
+		Name:               c.Name(),
+		State:              c.TimeInState().Current,
+		Reason:             c.StateInfo().Reason,
+		ConcurrentCommands: c.ConcurrentCommands(),
+	}
+	stats := rolling.FindCommandMetrics(c)
+	if stats == nil {
+		return ret
+	}
+	now := c.Config().General.TimeKeeper.Now()
+	ret.Requests = stats.LegitimateAttemptsAt(now)
+	ret.ErrorPercentage = 100 * stats.ErrorPercentageAt(now)
+	snap := stats.Latencies.SnapshotAt(now)
+	ret.LatencyMean = milliseconds(snap.Mean())
+	ret.LatencyP99 = milliseconds(snap.Percentile(99))
+	return ret
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+func TestHandler_Page(t *testing.T) {
+	h := &Handler{Manager: &circuit.Manager{}}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), "EventSource") {
+		t.Errorf("expected the page, got %d", rw.Code)
+	}
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", rw.Code)
+	}
+}
+
+func TestHandler_Events(t *testing.T) {
+	sf := rolling.StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("hello-world")
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return nil
+	}, nil)
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return errors.New("bad")
+	}, nil)
+	m.MustCreateCircuit("no-stats", circuit.Config{})
+
+	h := &Handler{
+		Manager:      m,
+		TickDuration: time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx))
+	first, _, _ := strings.Cut(rw.Body.String(), "\n\n")
+	var samples []Sample
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(first, "data: ")), &samples); err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || samples[0].Name != "hello-world" {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+	if samples[0].Requests != 2 || samples[0].ErrorPercentage != 50 || samples[0].State != circuit.StateClosed {
+		t.Errorf("unexpected sample %+v", samples[0])
+	}
+}
+
+func TestHandler_Source(t *testing.T) {
+	h := &Handler{
+		Source: func() []Sample {
+			return []Sample{{Name: "from-source"}}
+		},
+	}
+	if samples := h.Samples(); len(samples) != 1 || samples[0].Name != "from-source" {
+		t.Errorf("expected samples from Source, got %+v", samples)
+	}
+}
@@ -0,0 +1,7 @@
+/*
+Package dashboard serves a small web page that shows every circuit of a Manager live: whether it is open, its error
+rate, and a sparkline of its latency.  It is for teams that do not run the hystrix dashboard.  It is a single
+http.Handler with the page embedded, so there is nothing else to deploy.  Error rates and latencies come from rolling
+stats, so circuits should be created with rolling.StatFactory.
+*/
+package dashboard
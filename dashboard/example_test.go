@@ -0,0 +1,22 @@
+package dashboard_test
+
+import (
+	"net/http"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/dashboard"
+	"github.com/cep21/circuit/v4/metrics/rolling"
+)
+
+// This example serves the dashboard at /circuits/
+func ExampleHandler() {
+	// The dashboard reads error rates and latencies from rolling stats
+	sf := rolling.StatFactory{}
+	m := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	http.Handle("/circuits/", http.StripPrefix("/circuits", &dashboard.Handler{
+		Manager: &m,
+	}))
+	// Output:
+}
@@ -0,0 +1,144 @@
+package circuit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// OutcomeType is what happened to a run
+type OutcomeType string
+
+const (
+	// OutcomeSuccess means the run worked
+	OutcomeSuccess OutcomeType = "success"
+	// OutcomeFailure means the run returned an error
+	OutcomeFailure OutcomeType = "failure"
+	// OutcomeTimeout means the run took longer than the timeout
+	OutcomeTimeout OutcomeType = "timeout"
+	// OutcomeBadRequest means the run returned a BadRequest error
+	OutcomeBadRequest OutcomeType = "bad_request"
+	// OutcomeInterrupt means the caller's context ended during the run
+	OutcomeInterrupt OutcomeType = "interrupt"
+	// OutcomeConcurrencyLimitReject means the run was not started because of the concurrency limit
+	OutcomeConcurrencyLimitReject OutcomeType = "concurrency_limit_reject"
+	// OutcomeShortCircuit means the run was not started because the circuit was open
+	OutcomeShortCircuit OutcomeType = "short_circuit"
+)
+
+// Outcome is one event from the stream a DecisionEngine observes
+type Outcome struct {
+	Type OutcomeType
+	Time time.Time
+	// Duration is how long the run took.  It is zero for runs that never started.
+	Duration time.Duration
+	// Open is true if the circuit was open when the run happened
+	Open bool
+}
+
+// Recommendation is what a DecisionEngine wants the circuit to do
+type Recommendation int
+
+const (
+	// RecommendNothing leaves the circuit in its state
+	RecommendNothing Recommendation = iota
+	// RecommendOpen opens a closed circuit
+	RecommendOpen
+	// RecommendClose closes an open circuit
+	RecommendClose
+)
+
+// DecisionEngine decides when a circuit opens and closes.  It is a simpler alternative to writing a ClosedToOpen and
+// OpenToClosed pair, meant for experimental policies, such as ones driven by a model.  Set
+// GeneralConfig.DecisionEngineFactory to use one.
+//
+// The circuit still applies its own rules on top of the engine's recommendations: forced states, maintenance windows,
+// MinClosedDuration and WarmUpDuration are honored, and a recommendation to open is only acted on after a failure or
+// timeout, and to close only after a success.
+type DecisionEngine interface {
+	// Observe receives every outcome of the circuit's runs, and returns what the circuit should do next.  The latest
+	// recommendation is used at the circuit's next decision.  It is called concurrently.
+	Observe(outcome Outcome) Recommendation
+	// Admit decides if a run may start.  open is true if the circuit is open.  Returning true for an open circuit lets
+	// a half open request through, and returning false for a closed circuit short circuits the run.  It is called for
+	// every run, so it should be fast.
+	Admit(now time.Time, open bool) bool
+	// StateChanged tells the engine the circuit opened or closed
+	StateChanged(now time.Time, open bool)
+}
+
+// decisionEngineAdapter lets a DecisionEngine act as both a ClosedToOpen and an OpenToClosed
+type decisionEngineAdapter struct {
+	engine         DecisionEngine
+	open           atomic.Bool
+	recommendation atomic.Int32
+}
+
+var _ ClosedToOpen = &decisionEngineAdapter{}
+var _ OpenToClosed = &decisionEngineAdapter{}
+
+func (d *decisionEngineAdapter) observe(outcomeType OutcomeType, now time.Time, duration time.Duration) {
+	rec := d.engine.Observe(Outcome{
+		Type:     outcomeType,
+		Time:     now,
+		Duration: duration,
+		Open:     d.open.Load(),
+	})
+	d.recommendation.Store(int32(rec))
+}
+
+func (d *decisionEngineAdapter) Success(_ context.Context, now time.Time, duration time.Duration) {
+	d.observe(OutcomeSuccess, now, duration)
+}
+
+func (d *decisionEngineAdapter) ErrFailure(_ context.Context, now time.Time, duration time.Duration) {
+	d.observe(OutcomeFailure, now, duration)
+}
+
+func (d *decisionEngineAdapter) ErrTimeout(_ context.Context, now time.Time, duration time.Duration) {
+	d.observe(OutcomeTimeout, now, duration)
+}
+
+func (d *decisionEngineAdapter) ErrBadRequest(_ context.Context, now time.Time, duration time.Duration) {
+	d.observe(OutcomeBadRequest, now, duration)
+}
+
+func (d *decisionEngineAdapter) ErrInterrupt(_ context.Context, now time.Time, duration time.Duration) {
+	d.observe(OutcomeInterrupt, now, duration)
+}
+
+func (d *decisionEngineAdapter) ErrConcurrencyLimitReject(_ context.Context, now time.Time) {
+	d.observe(OutcomeConcurrencyLimitReject, now, 0)
+}
+
+func (d *decisionEngineAdapter) ErrShortCircuit(_ context.Context, now time.Time) {
+	d.observe(OutcomeShortCircuit, now, 0)
+}
+
+func (d *decisionEngineAdapter) Opened(_ context.Context, now time.Time) {
+	d.open.Store(true)
+	d.recommendation.Store(int32(RecommendNothing))
+	d.engine.StateChanged(now, true)
+}
+
+func (d *decisionEngineAdapter) Closed(_ context.Context, now time.Time) {
+	d.open.Store(false)
+	d.recommendation.Store(int32(RecommendNothing))
+	d.engine.StateChanged(now, false)
+}
+
+func (d *decisionEngineAdapter) ShouldOpen(_ context.Context, _ time.Time) bool {
+	return d.recommendation.CompareAndSwap(int32(RecommendOpen), int32(RecommendNothing))
+}
+
+func (d *decisionEngineAdapter) Prevent(_ context.Context, now time.Time) bool {
+	return !d.engine.Admit(now, false)
+}
+
+func (d *decisionEngineAdapter) ShouldClose(_ context.Context, _ time.Time) bool {
+	return d.recommendation.CompareAndSwap(int32(RecommendClose), int32(RecommendNothing))
+}
+
+func (d *decisionEngineAdapter) Allow(_ context.Context, now time.Time) bool {
+	return d.engine.Admit(now, true)
+}
@@ -0,0 +1,107 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// consecutiveFailures opens after two failures in a row, and closes after one successful probe
+type consecutiveFailures struct {
+	mu        sync.Mutex
+	failures  int
+	allow     bool
+	outcomes  []Outcome
+	stateSeen []bool
+}
+
+func (e *consecutiveFailures) Observe(outcome Outcome) Recommendation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.outcomes = append(e.outcomes, outcome)
+	switch outcome.Type {
+	case OutcomeFailure:
+		e.failures++
+		if e.failures >= 2 {
+			return RecommendOpen
+		}
+	case OutcomeSuccess:
+		e.failures = 0
+		if outcome.Open {
+			return RecommendClose
+		}
+	}
+	return RecommendNothing
+}
+
+func (e *consecutiveFailures) Admit(_ time.Time, open bool) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !open || e.allow
+}
+
+func (e *consecutiveFailures) StateChanged(_ time.Time, open bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stateSeen = append(e.stateSeen, open)
+}
+
+func TestDecisionEngine(t *testing.T) {
+	ctx := context.Background()
+	engine := &consecutiveFailures{}
+	c := NewCircuitFromConfig("TestDecisionEngine", Config{
+		General: GeneralConfig{
+			DecisionEngineFactory: func() DecisionEngine {
+				return engine
+			},
+		},
+	})
+	fail := func(_ context.Context) error {
+		return errors.New("bad")
+	}
+	pass := func(_ context.Context) error {
+		return nil
+	}
+
+	_ = c.Run(ctx, fail)
+	if c.IsOpen() {
+		t.Fatal("expected one failure to not open the circuit")
+	}
+	_ = c.Run(ctx, fail)
+	if !c.IsOpen() {
+		t.Fatal("expected the engine to open the circuit")
+	}
+	if err := c.Run(ctx, pass); err == nil {
+		t.Fatal("expected the open circuit to short circuit")
+	}
+
+	engine.mu.Lock()
+	engine.allow = true
+	engine.mu.Unlock()
+	if err := c.Run(ctx, pass); err != nil {
+		t.Fatal(err)
+	}
+	if c.IsOpen() {
+		t.Fatal("expected the engine to close the circuit after a probe")
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	expected := []OutcomeType{OutcomeFailure, OutcomeFailure, OutcomeShortCircuit, OutcomeSuccess}
+	if len(engine.outcomes) != len(expected) {
+		t.Fatalf("expected each outcome once, got %+v", engine.outcomes)
+	}
+	for i, o := range engine.outcomes {
+		if o.Type != expected[i] {
+			t.Errorf("outcome %d: expected %s, got %s", i, expected[i], o.Type)
+		}
+	}
+	if !engine.outcomes[3].Open {
+		t.Error("expected the probe to be observed as open")
+	}
+	if len(engine.stateSeen) != 2 || !engine.stateSeen[0] || engine.stateSeen[1] {
+		t.Errorf("expected an open then a close, got %v", engine.stateSeen)
+	}
+}
@@ -0,0 +1,143 @@
+package circuit
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// OpenCircuitCause explains why an open circuit is probably open
+type OpenCircuitCause struct {
+	// Name of the open circuit
+	Name string
+	// RootCauses are the open circuits this circuit depends on (directly or not) that have no open dependencies of their
+	// own.  They are the likely reason this circuit is open.  It is empty if this circuit is itself a root cause.
+	RootCauses []string
+}
+
+// IsRootCause returns true if no dependency of the circuit is open
+func (o OpenCircuitCause) IsRootCause() bool {
+	return len(o.RootCauses) == 0
+}
+
+// OpenCircuitCauses returns every open circuit, along with the open circuits it depends on that are the likely root
+// cause.  Dependencies are declared with GeneralConfig.DependsOn.  When many circuits open together, the ones that are
+// their own root cause are where to look first.
+func (h *Manager) OpenCircuitCauses() []OpenCircuitCause {
+	if h == nil {
+		return nil
+	}
+	var ret []OpenCircuitCause
+	for _, c := range h.AllCircuits() {
+		if !c.IsOpen() {
+			continue
+		}
+		ret = append(ret, OpenCircuitCause{
+			Name:       c.Name(),
+			RootCauses: h.OpenRootCauses(c.Name()),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// OpenRootCauses returns the open circuits, that circuitName depends on directly or not, that have no open dependencies
+// of their own.  It returns nil if none of the dependencies are open.
+func (h *Manager) OpenRootCauses(circuitName string) []string {
+	if h == nil {
+		return nil
+	}
+	var ret []string
+	for _, dep := range h.dependencies(circuitName) {
+		if h.GetCircuit(dep).IsOpen() && !h.hasOpenDependency(dep) {
+			ret = append(ret, dep)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// dependencies returns every existing circuit that circuitName depends on, directly or not.  Each is only listed once,
+// even if it is reached by many paths.
+func (h *Manager) dependencies(circuitName string) []string {
+	visited := map[string]struct{}{circuitName: {}}
+	var ret []string
+	next := []string{circuitName}
+	for len(next) != 0 {
+		name := next[len(next)-1]
+		next = next[:len(next)-1]
+		c := h.GetCircuit(name)
+		if c == nil {
+			continue
+		}
+		for _, dep := range c.Config().General.DependsOn {
+			if _, exists := visited[dep]; exists {
+				continue
+			}
+			visited[dep] = struct{}{}
+			if h.GetCircuit(dep) != nil {
+				ret = append(ret, dep)
+				next = append(next, dep)
+			}
+		}
+	}
+	return ret
+}
+
+// hasOpenDependency returns true if any circuit that circuitName depends on, directly or not, is open
+func (h *Manager) hasOpenDependency(circuitName string) bool {
+	for _, dep := range h.dependencies(circuitName) {
+		if h.GetCircuit(dep).IsOpen() {
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressDownstreamAlerts creates a CommandPropertiesConstructor that attaches the Metrics from alerts to each
+// circuit, but only reports a circuit opening or closing if none of its dependencies are open.  Use it to only page
+// about the root cause when a failure cascades through many circuits.  Add it to Manager.DefaultCircuitProperties.
+func (h *Manager) SuppressDownstreamAlerts(alerts func(circuitName string) Metrics) CommandPropertiesConstructor {
+	return func(circuitName string) Config {
+		m := alerts(circuitName)
+		if m == nil {
+			return Config{}
+		}
+		return Config{
+			Metrics: MetricsCollectors{
+				Circuit: []Metrics{&downstreamSuppressor{
+					manager:     h,
+					circuitName: circuitName,
+					alerts:      m,
+				}},
+			},
+		}
+	}
+}
+
+// downstreamSuppressor forwards Opened only if the circuit is a root cause, and Closed only if the matching Opened was
+// forwarded
+type downstreamSuppressor struct {
+	manager     *Manager
+	circuitName string
+	alerts      Metrics
+	// sentOpened is true while the alerts were told the circuit opened, but not yet that it closed
+	sentOpened atomic.Bool
+}
+
+var _ Metrics = &downstreamSuppressor{}
+
+func (d *downstreamSuppressor) Closed(ctx context.Context, now time.Time) {
+	if d.sentOpened.Swap(false) {
+		d.alerts.Closed(ctx, now)
+	}
+}
+
+func (d *downstreamSuppressor) Opened(ctx context.Context, now time.Time) {
+	if len(d.manager.OpenRootCauses(d.circuitName)) == 0 && !d.sentOpened.Swap(true) {
+		d.alerts.Opened(ctx, now)
+	}
+}
@@ -0,0 +1,101 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type countingMetrics struct {
+	opened int
+	closed int
+}
+
+func (c *countingMetrics) Opened(_ context.Context, _ time.Time) {
+	c.opened++
+}
+
+func (c *countingMetrics) Closed(_ context.Context, _ time.Time) {
+	c.closed++
+}
+
+func TestManager_OpenCircuitCauses(t *testing.T) {
+	ctx := context.Background()
+	alerts := map[string]*countingMetrics{}
+	h := Manager{}
+	h.DefaultCircuitProperties = []CommandPropertiesConstructor{
+		h.SuppressDownstreamAlerts(func(circuitName string) Metrics {
+			alerts[circuitName] = &countingMetrics{}
+			return alerts[circuitName]
+		}),
+	}
+	db := h.MustCreateCircuit("db")
+	h.MustCreateCircuit("cache")
+	users := h.MustCreateCircuit("users", Config{General: GeneralConfig{DependsOn: []string{"db", "cache"}}})
+	api := h.MustCreateCircuit("api", Config{General: GeneralConfig{DependsOn: []string{"users", "api"}}})
+
+	db.OpenCircuit(ctx)
+	users.OpenCircuit(ctx)
+	api.OpenCircuit(ctx)
+
+	expected := []OpenCircuitCause{
+		{Name: "api", RootCauses: []string{"db"}},
+		{Name: "db"},
+		{Name: "users", RootCauses: []string{"db"}},
+	}
+	if causes := h.OpenCircuitCauses(); !reflect.DeepEqual(causes, expected) {
+		t.Errorf("unexpected causes %v", causes)
+	}
+	if alerts["db"].opened != 1 {
+		t.Error("root causes should alert")
+	}
+	if alerts["users"].opened != 0 || alerts["api"].opened != 0 {
+		t.Error("downstream circuits should not alert")
+	}
+}
+
+func TestManager_OpenRootCausesDiamond(t *testing.T) {
+	ctx := context.Background()
+	h := Manager{}
+	h.MustCreateCircuit("a", Config{General: GeneralConfig{DependsOn: []string{"b", "c"}}})
+	h.MustCreateCircuit("b", Config{General: GeneralConfig{DependsOn: []string{"d"}}})
+	c := h.MustCreateCircuit("c", Config{General: GeneralConfig{DependsOn: []string{"d"}}})
+	d := h.MustCreateCircuit("d")
+	c.OpenCircuit(ctx)
+	d.OpenCircuit(ctx)
+	// c is open, but so is its own dependency d, no matter which path reaches d first
+	if causes := h.OpenRootCauses("a"); !reflect.DeepEqual(causes, []string{"d"}) {
+		t.Errorf("expected only d as the root cause, got %v", causes)
+	}
+}
+
+func TestManager_SuppressDownstreamAlertsPaired(t *testing.T) {
+	ctx := context.Background()
+	alerts := map[string]*countingMetrics{}
+	h := Manager{}
+	h.DefaultCircuitProperties = []CommandPropertiesConstructor{
+		h.SuppressDownstreamAlerts(func(circuitName string) Metrics {
+			alerts[circuitName] = &countingMetrics{}
+			return alerts[circuitName]
+		}),
+	}
+	db := h.MustCreateCircuit("db")
+	users := h.MustCreateCircuit("users", Config{General: GeneralConfig{DependsOn: []string{"db"}}})
+
+	// users opened on its own, so its close is sent even though db opened in between
+	users.OpenCircuit(ctx)
+	db.OpenCircuit(ctx)
+	users.CloseCircuit(ctx)
+	if alerts["users"].opened != 1 || alerts["users"].closed != 1 {
+		t.Errorf("expected the open and close to be paired, got %+v", alerts["users"])
+	}
+
+	// users opened because of db, so neither its open nor its close is sent, even after db closes first
+	users.OpenCircuit(ctx)
+	db.CloseCircuit(ctx)
+	users.CloseCircuit(ctx)
+	if alerts["users"].opened != 1 || alerts["users"].closed != 1 {
+		t.Errorf("expected the suppressed open to suppress the close, got %+v", alerts["users"])
+	}
+}
@@ -26,7 +26,9 @@ A circuits start Closed.  The default logic is to open a circuit if more than 20
 window, and over 50% of requests during that 10-second window are failing.
 
 Once failed, the circuit waits 10 seconds before allowing a single request.  If that request succeeds, then the circuit
-closes.  If it fails, then the circuit waits another 10 seconds before allowing another request (and so on).
+closes.  If it fails, then the circuit waits another 10 seconds before allowing another request (and so on).  The
+hystrix closer can require more than one successful request in a row before closing.  See
+hystrix.ConfigureCloser.RequiredConcurrentSuccessful.
 
 Almost every part of this flow can be configured.  See the CommandProperties struct for information.
 
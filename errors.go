@@ -3,10 +3,19 @@ package circuit
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
-var errThrottledConcurrentCommands = &circuitError{concurrencyLimitReached: true, msg: "throttling connections to command"}
-var errCircuitOpen = &circuitError{circuitOpen: true, msg: "circuit is open"}
+// ErrCircuitOpen matches, with errors.Is, every error returned because a circuit is open.  See CircuitOpenError.
+var ErrCircuitOpen Error = &circuitError{circuitOpen: true, msg: "circuit is open"}
+
+// ErrConcurrencyLimitReached matches, with errors.Is, every error returned because a concurrency limit or budget was
+// reached.  See ConcurrencyLimitError.
+var ErrConcurrencyLimitReached Error = &circuitError{concurrencyLimitReached: true, msg: "concurrency limit reached"}
+
+// ErrForcedFallback is passed to the fallback, or returned if there is no fallback, when the context given to Execute
+// came from WithForcedFallback
+var ErrForcedFallback Error = &circuitError{msg: "fallback was forced"}
 
 // circuitError is used for internally generated errors
 type circuitError struct {
@@ -38,6 +47,123 @@ func (m *circuitError) CircuitOpen() bool {
 	return m.circuitOpen
 }
 
+// Is matches ErrCircuitOpen and ErrConcurrencyLimitReached by kind
+func (m *circuitError) Is(target error) bool {
+	return (target == ErrCircuitOpen && m.circuitOpen) || (target == ErrConcurrencyLimitReached && m.concurrencyLimitReached)
+}
+
+// CircuitOpenError is returned, or passed to the fallback, when a run is rejected because the circuit is open.  It
+// implements RetryAfter, so callers can tell their own callers when to come back, for example with an HTTP Retry-After
+// header.
+type CircuitOpenError struct {
+	// Circuit is the name of the circuit that rejected the run
+	Circuit string
+	// Reason is why the circuit is open
+	Reason StateReason
+	// OpenedAt is when the circuit opened
+	OpenedAt time.Time
+	// RetryAt is the soonest the circuit may let a run through.  It is the zero time if OpenToClose does not
+	// implement HalfOpenScheduler.
+	RetryAt time.Time
+	// RejectedAt is when the run was rejected
+	RejectedAt time.Time
+}
+
+var _ Error = &CircuitOpenError{}
+var _ RetryAfter = &CircuitOpenError{}
+
+func (c *CircuitOpenError) Error() string {
+	if c.Reason == "" {
+		return fmt.Sprintf("circuit %s is open", c.Circuit)
+	}
+	return fmt.Sprintf("circuit %s is open: %s", c.Circuit, c.Reason)
+}
+
+// ConcurrencyLimitReached is always false
+func (c *CircuitOpenError) ConcurrencyLimitReached() bool {
+	return false
+}
+
+// CircuitOpen is always true
+func (c *CircuitOpenError) CircuitOpen() bool {
+	return true
+}
+
+// Is matches ErrCircuitOpen
+func (c *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// RetryAfter is how long after the rejection RetryAt is.  It is 0 if RetryAt is unknown or has passed.
+func (c *CircuitOpenError) RetryAfter() time.Duration {
+	if c.RetryAt.IsZero() || !c.RetryAt.After(c.RejectedAt) {
+		return 0
+	}
+	return c.RetryAt.Sub(c.RejectedAt)
+}
+
+// ConcurrencyLimitError is returned, or passed to the fallback, when a run is rejected because too many runs are in
+// flight.  The circuit is healthy, so callers may want to answer with "too many requests" rather than "unavailable".
+type ConcurrencyLimitError struct {
+	// Circuit is the name of the circuit that rejected the run
+	Circuit string
+	// Partition is the ExecutionConfig.PartitionKey of the partition that was full.  It is empty if the circuit was.
+	Partition string
+	// Limit is the concurrency limit that was reached
+	Limit int64
+	// InFlight is the total Cost of the runs in flight when the run was rejected
+	InFlight int64
+	// QueueDepth is how many runs were waiting for a slot, and MaxQueueSize how many may.  See
+	// ExecutionConfig.MaxQueueSize.
+	QueueDepth   int64
+	MaxQueueSize int64
+}
+
+var _ Error = &ConcurrencyLimitError{}
+
+func (c *ConcurrencyLimitError) Error() string {
+	where := "circuit " + c.Circuit
+	if c.Partition != "" {
+		where += " partition " + c.Partition
+	}
+	if c.MaxQueueSize > 0 {
+		return fmt.Sprintf("%s reached its concurrency limit: %d in flight, limit %d, %d of %d queued", where, c.InFlight, c.Limit, c.QueueDepth, c.MaxQueueSize)
+	}
+	return fmt.Sprintf("%s reached its concurrency limit: %d in flight, limit %d", where, c.InFlight, c.Limit)
+}
+
+// ConcurrencyLimitReached is always true
+func (c *ConcurrencyLimitError) ConcurrencyLimitReached() bool {
+	return true
+}
+
+// CircuitOpen is always false
+func (c *ConcurrencyLimitError) CircuitOpen() bool {
+	return false
+}
+
+// Is matches ErrConcurrencyLimitReached
+func (c *ConcurrencyLimitError) Is(target error) bool {
+	return target == ErrConcurrencyLimitReached
+}
+
+// ContextDoneError is returned by Execute when the context given to it ended before the run started.  Neither runFunc
+// nor the fallback is called, no concurrency slot is taken, and nothing is recorded: the dependency was never asked,
+// so the run says nothing about its health.  It is an interrupt, and unwraps to the context's error.
+type ContextDoneError struct {
+	// Err is the context's error, like context.Canceled
+	Err error
+}
+
+func (c *ContextDoneError) Error() string {
+	return "context done before the run started: " + c.Err.Error()
+}
+
+// Unwrap returns the context's error
+func (c *ContextDoneError) Unwrap() error {
+	return c.Err
+}
+
 // BadRequest is implemented by an error returned by runFunc if you want to consider the requestor bad, not the circuit
 // bad.  See http://netflix.github.io/Hystrix/javadoc/com/netflix/hystrix/exception/HystrixBadRequestException.html
 // and https://github.com/Netflix/Hystrix/wiki/How-To-Use#error-propagation for information.
@@ -77,4 +203,52 @@ func (s SimpleBadRequest) BadRequest() bool {
 var _ error = &SimpleBadRequest{}
 var _ BadRequest = &SimpleBadRequest{}
 
+// RetryAfter is implemented by an error returned by runFunc that knows how long the dependency wants callers to back
+// off, for example from an HTTP Retry-After header.  Closers that implement RetryAfterMetrics use it to wait at least
+// that long before letting requests through again.
+type RetryAfter interface {
+	RetryAfter() time.Duration
+}
+
+// RetryAfterOf returns the RetryAfter hint of err, or 0 if it has none
+func RetryAfterOf(err error) time.Duration {
+	if err == nil {
+		return 0
+	}
+	var ra RetryAfter
+	if errors.As(err, &ra) {
+		return ra.RetryAfter()
+	}
+	return 0
+}
+
+// SimpleRetryAfter is a simple wrapper for an error to give it a RetryAfter hint
+type SimpleRetryAfter struct {
+	Err   error
+	After time.Duration
+}
+
+// Cause returns the wrapped error
+func (s SimpleRetryAfter) Cause() error {
+	return s.Err
+}
+
+// Unwrap returns the wrapped error
+func (s SimpleRetryAfter) Unwrap() error {
+	return s.Err
+}
+
+// Error returns the wrapped error's message
+func (s SimpleRetryAfter) Error() string {
+	return s.Err.Error()
+}
+
+// RetryAfter returns After
+func (s SimpleRetryAfter) RetryAfter() time.Duration {
+	return s.After
+}
+
+var _ error = &SimpleRetryAfter{}
+var _ RetryAfter = &SimpleRetryAfter{}
+
 var _ error = &circuitError{}
@@ -1,9 +1,12 @@
 package circuit
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -11,11 +14,167 @@ import (
 func TestIsBadRequest(t *testing.T) {
 	require.False(t, IsBadRequest(nil))
 	require.False(t, IsBadRequest(errors.New("not bad")))
-	require.False(t, IsBadRequest(errThrottledConcurrentCommands))
-	require.False(t, IsBadRequest(errCircuitOpen))
+	require.False(t, IsBadRequest(&ConcurrencyLimitError{}))
+	require.False(t, IsBadRequest(&CircuitOpenError{}))
 	require.False(t, IsBadRequest(&circuitError{}))
 	require.True(t, IsBadRequest(&SimpleBadRequest{}))
 	wrappedErr := fmt.Errorf("wrapped: %w", &SimpleBadRequest{})
 	require.True(t, IsBadRequest(wrappedErr))
 	require.False(t, IsBadRequest(fmt.Errorf("wrapped: %w", errors.New("not bad"))))
 }
+
+func TestRetryAfterOf(t *testing.T) {
+	require.Equal(t, time.Duration(0), RetryAfterOf(nil))
+	require.Equal(t, time.Duration(0), RetryAfterOf(errors.New("no hint")))
+	hinted := SimpleRetryAfter{Err: errors.New("throttled"), After: time.Second}
+	require.Equal(t, time.Second, RetryAfterOf(fmt.Errorf("wrapped: %w", hinted)))
+	require.Equal(t, "throttled", hinted.Error())
+}
+
+type retryAfterCounter struct {
+	RunMetrics
+	hints []time.Duration
+}
+
+func (r *retryAfterCounter) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (r *retryAfterCounter) RetryAfter(_ context.Context, _ time.Time, after time.Duration) {
+	r.hints = append(r.hints, after)
+}
+
+func TestCircuit_RetryAfter(t *testing.T) {
+	metrics := &retryAfterCounter{}
+	c := NewCircuitFromConfig("TestCircuit_RetryAfter", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx := context.Background()
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return SimpleRetryAfter{Err: errors.New("throttled"), After: time.Minute}
+	})
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return errors.New("no hint")
+	})
+	if !reflect.DeepEqual(metrics.hints, []time.Duration{time.Minute}) {
+		t.Errorf("unexpected hints %v", metrics.hints)
+	}
+
+	cfg := c.Config()
+	cfg.Execution.RetryAfterClassifier = func(err error) time.Duration {
+		return time.Second
+	}
+	c.SetConfigNotThreadSafe(cfg)
+	metrics.hints = nil
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return errors.New("classified")
+	})
+	if !reflect.DeepEqual(metrics.hints, []time.Duration{time.Second}) {
+		t.Errorf("expected the classifier's hint, got %v", metrics.hints)
+	}
+}
+
+func isOpenError(err error) bool {
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+func TestCircuitOpenError(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuitOpenError", Config{})
+	now := time.Now()
+	c.OpenCircuit(context.Background())
+	err := c.Run(context.Background(), func(_ context.Context) error {
+		return nil
+	})
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected a CircuitOpenError, got %v", err)
+	}
+	if openErr.Circuit != "TestCircuitOpenError" || openErr.Reason != StateReasonManual || openErr.OpenedAt.Before(now) {
+		t.Errorf("unexpected error %+v", openErr)
+	}
+	if !openErr.CircuitOpen() || openErr.ConcurrencyLimitReached() {
+		t.Error("expected the error to be an open circuit")
+	}
+	require.Equal(t, "circuit TestCircuitOpenError is open: manual", openErr.Error())
+}
+
+func TestCircuitOpenError_RetryAfter(t *testing.T) {
+	now := time.Now()
+	err := &CircuitOpenError{RejectedAt: now, RetryAt: now.Add(time.Minute)}
+	require.Equal(t, time.Minute, RetryAfterOf(err))
+	err = &CircuitOpenError{RejectedAt: now, RetryAt: now.Add(-time.Minute)}
+	require.Equal(t, time.Duration(0), RetryAfterOf(err))
+	require.Equal(t, time.Duration(0), RetryAfterOf(&CircuitOpenError{RejectedAt: now}))
+}
+
+func TestConcurrencyLimitError(t *testing.T) {
+	c := NewCircuitFromConfig("TestConcurrencyLimitError", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+		},
+	})
+	var err error
+	_ = c.Run(context.Background(), func(_ context.Context) error {
+		err = c.Run(context.Background(), func(_ context.Context) error {
+			return nil
+		})
+		return nil
+	})
+	var limitErr *ConcurrencyLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a ConcurrencyLimitError, got %v", err)
+	}
+	if limitErr.Circuit != "TestConcurrencyLimitError" || limitErr.Limit != 1 || limitErr.InFlight != 1 {
+		t.Errorf("unexpected error %+v", limitErr)
+	}
+	require.True(t, errors.Is(err, ErrConcurrencyLimitReached))
+	require.False(t, errors.Is(err, ErrCircuitOpen))
+	require.Equal(t, "circuit TestConcurrencyLimitError reached its concurrency limit: 1 in flight, limit 1", err.Error())
+
+	c.OpenCircuit(context.Background())
+	err = c.Run(context.Background(), func(_ context.Context) error {
+		return nil
+	})
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.False(t, errors.Is(err, ErrConcurrencyLimitReached))
+	require.True(t, errors.Is(ErrRetryBudgetExhausted, ErrConcurrencyLimitReached))
+}
+
+func TestContextDoneError(t *testing.T) {
+	runs := &recordedRuns{}
+	c := NewCircuitFromConfig("TestContextDoneError", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{runs},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := c.Execute(ctx, func(_ context.Context) error {
+		called = true
+		return nil
+	}, func(_ context.Context, _ error) error {
+		called = true
+		return nil
+	})
+	var doneErr *ContextDoneError
+	if !errors.As(err, &doneErr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a ContextDoneError, got %v", err)
+	}
+	if called {
+		t.Error("expected neither runFunc nor the fallback to be called")
+	}
+	if runs.successes != 0 || runs.failures != 0 || c.ConcurrentCommands() != 0 {
+		t.Errorf("expected nothing recorded, got %+v", runs)
+	}
+
+	detached := NewCircuitFromConfig("TestContextDoneError_detached", Config{
+		Execution: ExecutionConfig{
+			DetachContext: true,
+		},
+	})
+	if err := detached.Run(ctx, func(_ context.Context) error { return nil }); err != nil {
+		t.Errorf("expected detached runs to start anyway, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package circuit
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ErrorSample is a single error seen by a circuit
+type ErrorSample struct {
+	// Time is when the run function finished
+	Time time.Time
+	// Duration is how long the run function took
+	Duration time.Duration
+	// Err is what the run function returned, or the timeout error if it returned nil after the timeout
+	Err error
+}
+
+var _ json.Marshaler = ErrorSample{}
+
+// MarshalJSON encodes the sample with the error as a string, since most errors do not encode to JSON
+func (e ErrorSample) MarshalJSON() ([]byte, error) {
+	msg := ""
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return json.Marshal(map[string]interface{}{
+		"time":     e.Time,
+		"duration": e.Duration.String(),
+		"err":      msg,
+	})
+}
+
+// errorSamples is a fixed size ring buffer of the most recent errors.  It only takes a lock when an error is added or
+// read, so it is off the happy path of a circuit.
+type errorSamples struct {
+	samples []ErrorSample
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+func newErrorSamples(size int64) *errorSamples {
+	if size <= 0 {
+		return nil
+	}
+	return &errorSamples{
+		samples: make([]ErrorSample, size),
+	}
+}
+
+func (e *errorSamples) add(sample ErrorSample) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.samples[e.next] = sample
+	e.next++
+	if e.next == len(e.samples) {
+		e.next = 0
+		e.full = true
+	}
+}
+
+// get returns a copy of the samples, in order backwards in time
+func (e *errorSamples) get() []ErrorSample {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	count := e.next
+	if e.full {
+		count = len(e.samples)
+	}
+	ret := make([]ErrorSample, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := e.next - i
+		if idx < 0 {
+			idx += len(e.samples)
+		}
+		ret = append(ret, e.samples[idx])
+	}
+	return ret
+}
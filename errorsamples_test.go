@@ -0,0 +1,67 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCircuit_RecentErrors(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_RecentErrors", Config{
+		General: GeneralConfig{
+			RecentErrorsSize: 2,
+		},
+	})
+	for i := 0; i < 3; i++ {
+		idx := i
+		_ = c.Execute(context.Background(), func(_ context.Context) error {
+			return fmt.Errorf("failure %d", idx)
+		}, nil)
+	}
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return SimpleBadRequest{Err: errors.New("bad request")}
+	}, nil)
+	samples := c.RecentErrors()
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	if samples[0].Err.Error() != "failure 2" || samples[1].Err.Error() != "failure 1" {
+		t.Errorf("unexpected samples %v", samples)
+	}
+}
+
+func TestCircuit_RecentErrorsDisabled(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_RecentErrorsDisabled", Config{
+		General: GeneralConfig{
+			RecentErrorsSize: -1,
+		},
+	})
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return errors.New("failure")
+	}, nil)
+	if len(c.RecentErrors()) != 0 {
+		t.Error("expected no samples when disabled")
+	}
+}
+
+func TestCircuit_RecentBadRequests(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_RecentBadRequests", Config{
+		General: GeneralConfig{
+			RecentBadRequestsSize: 2,
+		},
+	})
+	for _, msg := range []string{"a", "b", "c"} {
+		msg := msg
+		_ = c.Execute(context.Background(), func(_ context.Context) error {
+			return SimpleBadRequest{Err: errors.New(msg)}
+		}, nil)
+	}
+	samples := c.RecentBadRequests()
+	if len(samples) != 2 || samples[0].Err.Error() != "c" || samples[1].Err.Error() != "b" {
+		t.Errorf("expected the last two bad requests, got %v", samples)
+	}
+	if len(c.RecentErrors()) != 0 {
+		t.Error("expected bad requests to not be recent errors")
+	}
+}
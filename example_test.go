@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"time"
 
 	"github.com/cep21/circuit/v4"
@@ -302,3 +303,176 @@ func ExampleError_checking() {
 		fmt.Println("this error is a circuit library error, not the result of runFunc or fallbackFunc")
 	}
 }
+
+// Give a single call a longer timeout than the rest of the traffic through a circuit.  MaxTimeoutOverride caps how
+// long overrides can be.
+func ExampleWithTimeoutOverride() {
+	c := circuit.NewCircuitFromConfig("reports", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			Timeout:            time.Second,
+			MaxTimeoutOverride: time.Minute,
+		},
+	})
+	ctx := circuit.WithTimeoutOverride(context.Background(), 30*time.Second)
+	err := c.Run(ctx, func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		fmt.Println("has more than a second:", time.Until(deadline) > time.Second)
+		return nil
+	})
+	fmt.Println("err:", err)
+	// Output: has more than a second: true
+	// err: <nil>
+}
+
+// Share a Semaphore between circuits so that, together, they never run more than 10 requests at once
+func ExampleSemaphore() {
+	sem := circuit.NewSemaphore(10)
+	h := circuit.Manager{}
+	for _, name := range []string{"primary-db", "replica-db"} {
+		h.MustCreateCircuit(name, circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				ConcurrencyLimiter: sem,
+			},
+		})
+	}
+	err := h.GetCircuit("replica-db").Run(context.Background(), func(_ context.Context) error {
+		fmt.Println("running with", sem.Current(), "of the semaphore")
+		return nil
+	})
+	fmt.Println("err:", err)
+	// Output: running with 1 of the semaphore
+	// err: <nil>
+}
+
+// Split the time left before a request's deadline between the calls needed to answer it
+func ExampleBudget() {
+	h := circuit.Manager{}
+	auth := h.MustCreateCircuit("auth")
+	lookup := h.MustCreateCircuit("lookup")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// auth gets a quarter of the second, and lookup gets whatever is left
+	budget := circuit.NewBudget(ctx, 1, 3)
+	_ = auth.Run(budget.Next(), func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		fmt.Println("auth has at most 250ms:", time.Until(deadline) <= 250*time.Millisecond)
+		return nil
+	})
+	_ = lookup.Run(budget.Next(), func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		fmt.Println("lookup has more than 500ms:", time.Until(deadline) > 500*time.Millisecond)
+		return nil
+	})
+	// Output: auth has at most 250ms: true
+	// lookup has more than 500ms: true
+}
+
+// Share a timeout between every database circuit, and override it for one of them
+func ExampleConfigLayers() {
+	layers := &circuit.ConfigLayers{
+		Global: circuit.Config{
+			Execution: circuit.ExecutionConfig{
+				Timeout: time.Second,
+			},
+		},
+		Groups: map[string]circuit.Config{
+			"db": {Execution: circuit.ExecutionConfig{Timeout: 200 * time.Millisecond}},
+		},
+		GroupOf: func(circuitName string) string {
+			return strings.Split(circuitName, ".")[0]
+		},
+		Circuits: map[string]circuit.Config{
+			"db.reports": {Execution: circuit.ExecutionConfig{Timeout: 5 * time.Second}},
+		},
+	}
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{layers.Configure},
+	}
+	for _, name := range []string{"db.users", "db.reports", "cache"} {
+		fmt.Println(name, h.MustCreateCircuit(name).Config().Execution.Timeout)
+	}
+	for _, source := range layers.Explain("db.users") {
+		fmt.Println(source.Field, "from", source.Layer, source.Group)
+	}
+	// Output: db.users 200ms
+	// db.reports 5s
+	// cache 1s
+	// Execution.Timeout from group db
+}
+
+// Configure every payments circuit with one rule, and change it later without restarting
+func ExampleConfigRules() {
+	rules := &circuit.ConfigRules{}
+	_ = rules.SetRules([]circuit.ConfigRule{
+		{Pattern: "payments.*", Config: circuit.Config{Execution: circuit.ExecutionConfig{Timeout: 500 * time.Millisecond}}},
+	})
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{rules.Configure},
+	}
+	charge := h.MustCreateCircuit("payments.charge")
+	fmt.Println(charge.Config().Execution.Timeout)
+
+	_ = rules.SetRules([]circuit.ConfigRule{
+		{Pattern: "payments.*", Config: circuit.Config{Execution: circuit.ExecutionConfig{Timeout: 250 * time.Millisecond}}},
+	})
+	rules.Apply(&h)
+	fmt.Println(charge.Config().Execution.Timeout)
+	// Output: 500ms
+	// 250ms
+}
+
+// Mark retries with WithRetry so they only go through while they fit in the circuit's retry budget.  Here retries
+// may add 20% to the load of first attempts.
+func ExampleWithRetry() {
+	c := circuit.NewCircuitFromConfig("retried", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			RetryBudgetPercent:      20,
+			RetryBudgetMinPerSecond: -1,
+		},
+	})
+	ctx := context.Background()
+	call := func(ctx context.Context) error {
+		return c.Execute(ctx, func(_ context.Context) error {
+			return errors.New("dependency is down")
+		}, nil)
+	}
+	rejected := 0
+	for i := 0; i < 10; i++ {
+		if err := call(ctx); err == nil {
+			continue
+		}
+		if err := call(circuit.WithRetry(ctx)); errors.Is(err, circuit.ErrRetryBudgetExhausted) {
+			rejected++
+		}
+	}
+	fmt.Println("retries rejected:", rejected)
+	fmt.Println("retries made:", c.RetryBudget().Retries)
+	// Output: retries rejected: 8
+	// retries made: 2
+}
+
+func ExampleFallbackFromCache() {
+	c := circuit.NewCircuitFromConfig("prices", circuit.Config{})
+	lastGood := mapCache{"apple": 3}
+	type itemKey struct{}
+	price := func(item string) (int, error) {
+		ctx := context.WithValue(context.Background(), itemKey{}, item)
+		return circuit.ExecuteValue(ctx, c, func(_ context.Context) (int, error) {
+			return 0, errors.New("price service is down")
+		}, circuit.FallbackFromCache[string, int](lastGood, func(ctx context.Context) string {
+			return ctx.Value(itemKey{}).(string)
+		}))
+	}
+	fmt.Println(price("apple"))
+	fmt.Println(price("pear"))
+	// Output: 3 <nil>
+	// 0 price service is down
+}
+
+type mapCache map[string]int
+
+func (m mapCache) Get(key string) (int, bool) {
+	v, exists := m[key]
+	return v, exists
+}
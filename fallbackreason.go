@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+// FallbackReason is why a fallback was called.  Fallbacks get it with FallbackReasonOf, and the error that caused it
+// as their error argument.
+type FallbackReason string
+
+const (
+	// FallbackReasonOpen is a run rejected because the circuit is open
+	FallbackReasonOpen FallbackReason = "open"
+	// FallbackReasonTimeout is a run that took longer than its timeout
+	FallbackReasonTimeout FallbackReason = "timeout"
+	// FallbackReasonRejected is a run rejected by a concurrency limit, a ConcurrencyLimiter, or the retry budget
+	FallbackReasonRejected FallbackReason = "rejected"
+	// FallbackReasonError is a run that failed
+	FallbackReasonError FallbackReason = "error"
+	// FallbackReasonForced is a run sent to the fallback by WithForcedFallback
+	FallbackReasonForced FallbackReason = "forced"
+	// FallbackReasonShadow is a shadow fallback served by ExecuteShadow.  See FallbackConfig.ShadowServesFallback.
+	FallbackReasonShadow FallbackReason = "shadow"
+)
+
+type fallbackReasonKey struct{}
+
+// FallbackReasonOf returns why the fallback given ctx was called, or "" if ctx is not a fallback's context
+func FallbackReasonOf(ctx context.Context) FallbackReason {
+	reason, _ := ctx.Value(fallbackReasonKey{}).(FallbackReason)
+	return reason
+}
+
+// FallbackReasonMetrics can optionally be implemented by FallbackMetrics that want to know why fallbacks are called.
+// FallbackReason is called each time a fallback is called, before any of the FallbackMetrics methods.
+type FallbackReasonMetrics interface {
+	FallbackReason(ctx context.Context, now time.Time, reason FallbackReason)
+}
+
+var _ FallbackReasonMetrics = FallbackMetricsCollection(nil)
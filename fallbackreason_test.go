@@ -0,0 +1,96 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/internal/testhelp"
+)
+
+type fallbackReasons struct {
+	FallbackMetrics
+	mu      sync.Mutex
+	reasons []FallbackReason
+}
+
+func (f *fallbackReasons) FallbackReason(_ context.Context, _ time.Time, reason FallbackReason) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reasons = append(f.reasons, reason)
+}
+
+func (f *fallbackReasons) Success(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (f *fallbackReasons) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func TestFallbackReasonOf(t *testing.T) {
+	if reason := FallbackReasonOf(context.Background()); reason != "" {
+		t.Errorf("expected no reason outside a fallback, got %q", reason)
+	}
+	testCases := []struct {
+		name     string
+		config   Config
+		ctx      context.Context
+		runFunc  func(context.Context) error
+		expected FallbackReason
+	}{
+		{
+			name:     "error",
+			ctx:      context.Background(),
+			runFunc:  testhelp.AlwaysFails,
+			expected: FallbackReasonError,
+		},
+		{
+			name:     "open",
+			config:   Config{General: GeneralConfig{ForceOpen: true}},
+			ctx:      context.Background(),
+			runFunc:  testhelp.AlwaysPasses,
+			expected: FallbackReasonOpen,
+		},
+		{
+			name:     "timeout",
+			config:   Config{Execution: ExecutionConfig{Timeout: time.Millisecond}},
+			ctx:      context.Background(),
+			runFunc:  testhelp.SleepsForX(time.Millisecond * 20),
+			expected: FallbackReasonTimeout,
+		},
+		{
+			name:     "rejected",
+			config:   Config{Execution: ExecutionConfig{MaxConcurrentRequests: 1}},
+			ctx:      WithCost(context.Background(), 2),
+			runFunc:  testhelp.AlwaysPasses,
+			expected: FallbackReasonRejected,
+		},
+		{
+			name:     "forced",
+			ctx:      WithForcedFallback(context.Background()),
+			runFunc:  testhelp.AlwaysPasses,
+			expected: FallbackReasonForced,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := &fallbackReasons{}
+			tc.config.Metrics.Fallback = []FallbackMetrics{metrics}
+			c := NewCircuitFromConfig("TestFallbackReasonOf", tc.config)
+			var reason FallbackReason
+			err := c.Execute(tc.ctx, tc.runFunc, func(ctx context.Context, _ error) error {
+				reason = FallbackReasonOf(ctx)
+				return errors.New("fallback failed")
+			})
+			if err == nil {
+				t.Fatal("expected the fallback's error")
+			}
+			if reason != tc.expected {
+				t.Errorf("expected reason %q, got %q", tc.expected, reason)
+			}
+			if len(metrics.reasons) != 1 || metrics.reasons[0] != tc.expected {
+				t.Errorf("expected metrics to see reason %q, got %v", tc.expected, metrics.reasons)
+			}
+		})
+	}
+}
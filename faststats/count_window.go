@@ -0,0 +1,119 @@
+package faststats
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+)
+
+// Values stored in each CountWindow slot
+const (
+	countWindowEmpty int64 = iota
+	countWindowSuccess
+	countWindowFailure
+)
+
+// CountWindow tracks the outcome of the last N events, no matter how long ago they happened.  It is useful for low
+// volume sources, where a time based window is mostly empty and gives a distorted picture of health.
+type CountWindow struct {
+	// The len(slots) is constant and not mutable.  Each slot is atomic, so no mutex is needed.
+	slots []AtomicInt64
+	next  AtomicInt64
+
+	total    AtomicInt64
+	failures AtomicInt64
+}
+
+// NewCountWindow creates a window over the last size events
+func NewCountWindow(size int) CountWindow {
+	return CountWindow{
+		slots: make([]AtomicInt64, size),
+	}
+}
+
+var _ json.Marshaler = &CountWindow{}
+var _ fmt.Stringer = &CountWindow{}
+
+type jsonCountWindow struct {
+	Size     int
+	Total    int64
+	Failures int64
+}
+
+// MarshalJSON JSON encodes the window.  It is thread safe.
+func (c *CountWindow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonCountWindow{
+		Size:     len(c.slots),
+		Total:    c.Total(),
+		Failures: c.Failures(),
+	})
+}
+
+// String for debugging
+func (c *CountWindow) String() string {
+	return fmt.Sprintf("CountWindow(size=%d, total=%d, failures=%d)", len(c.slots), c.Total(), c.Failures())
+}
+
+// Success records a successful event, pushing the oldest event out of the window
+func (c *CountWindow) Success() {
+	c.add(countWindowSuccess)
+}
+
+// Failure records a failed event, pushing the oldest event out of the window
+func (c *CountWindow) Failure() {
+	c.add(countWindowFailure)
+}
+
+func (c *CountWindow) add(value int64) {
+	if len(c.slots) == 0 {
+		return
+	}
+	idx := (c.next.Add(1) - 1) % int64(len(c.slots))
+	c.replace(int(idx), value)
+}
+
+func (c *CountWindow) replace(idx int, value int64) {
+	old := c.slots[idx].Swap(value)
+	if old == value {
+		return
+	}
+	if old == countWindowEmpty {
+		c.total.Add(1)
+	}
+	if value == countWindowEmpty {
+		c.total.Add(-1)
+	}
+	if old == countWindowFailure {
+		c.failures.Add(-1)
+	}
+	if value == countWindowFailure {
+		c.failures.Add(1)
+	}
+}
+
+// Size is the most events the window can hold
+func (c *CountWindow) Size() int {
+	return len(c.slots)
+}
+
+// MemoryBytes estimates the memory held by the window's slots
+func (c *CountWindow) MemoryBytes() int64 {
+	return int64(unsafe.Sizeof(*c)) + int64(len(c.slots))*int64(unsafe.Sizeof(AtomicInt64{}))
+}
+
+// Total is how many events are in the window.  It is never more than Size.
+func (c *CountWindow) Total() int64 {
+	return c.total.Get()
+}
+
+// Failures is how many events in the window were failures
+func (c *CountWindow) Failures() int64 {
+	return c.failures.Get()
+}
+
+// Reset empties the window
+func (c *CountWindow) Reset() {
+	for i := range c.slots {
+		c.replace(i, countWindowEmpty)
+	}
+}
@@ -0,0 +1,54 @@
+package faststats
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestCountWindow(t *testing.T) {
+	c := NewCountWindow(3)
+	c.Failure()
+	c.Success()
+	if c.Total() != 2 || c.Failures() != 1 {
+		t.Fatalf("unexpected window %s", c.String())
+	}
+	c.Success()
+	c.Success()
+	if c.Total() != 3 || c.Failures() != 0 {
+		t.Fatalf("oldest failure should leave the window %s", c.String())
+	}
+	if _, err := json.Marshal(&c); err != nil {
+		t.Fatal(err)
+	}
+	c.Reset()
+	if c.Total() != 0 || c.Failures() != 0 {
+		t.Fatalf("reset should empty the window %s", c.String())
+	}
+}
+
+func TestCountWindowEmpty(t *testing.T) {
+	var c CountWindow
+	c.Failure()
+	if c.Total() != 0 {
+		t.Fatal("an empty window should ignore events")
+	}
+}
+
+func TestCountWindowConcurrent(t *testing.T) {
+	c := NewCountWindow(10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Failure()
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Total() != 10 || c.Failures() != 10 {
+		t.Fatalf("unexpected window %s", c.String())
+	}
+}
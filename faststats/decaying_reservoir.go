@@ -0,0 +1,143 @@
+package faststats
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// decayingRescaleInterval is how often priorities are moved to a new landmark so they do not overflow a float64
+const decayingRescaleInterval = time.Hour
+
+// DecayingReservoir keeps a fixed size sample of durations that favors recent ones.  Older durations are not dropped
+// all at once, like they are when a rolling bucket expires, but become exponentially less likely to stay in the sample
+// as they age.  Alpha is how quickly that happens, per second: with an alpha of 0.015 a duration from five minutes ago
+// is about 90x less likely to be kept than one from now.
+//
+// It uses forward decay priority sampling, described in "Forward Decay: A Practical Time Decay Model for Streaming
+// Systems" by Cormode et al.
+type DecayingReservoir struct {
+	size  int
+	alpha float64
+
+	mu        sync.Mutex
+	randFloat func() float64
+	samples   decayingSamples
+	landmark  time.Time
+	nextScale time.Time
+}
+
+// NewDecayingReservoir creates a reservoir that keeps up to size durations, decaying at alpha per second
+func NewDecayingReservoir(size int, alpha float64, now time.Time) *DecayingReservoir {
+	return &DecayingReservoir{
+		size:      size,
+		alpha:     alpha,
+		samples:   make(decayingSamples, 0, size),
+		landmark:  now,
+		nextScale: now.Add(decayingRescaleInterval),
+	}
+}
+
+// AddDuration adds a duration to the reservoir, possibly pushing out an older one
+func (d *DecayingReservoir) AddDuration(dur time.Duration, now time.Time) {
+	if d.size <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rescaleIfNeeded(now)
+	// 1-rand.Float64() is in (0, 1], so the priority is never infinite
+	priority := math.Exp(d.alpha*now.Sub(d.landmark).Seconds()) / (1 - d.float64())
+	if len(d.samples) < d.size {
+		heap.Push(&d.samples, decayingSample{priority: priority, value: dur})
+		return
+	}
+	if priority > d.samples[0].priority {
+		d.samples[0] = decayingSample{priority: priority, value: dur}
+		heap.Fix(&d.samples, 0)
+	}
+}
+
+// SetRandFloat64 sets what picks the durations kept.  It should act like rand.Float64, which is the default.
+func (d *DecayingReservoir) SetRandFloat64(f func() float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.randFloat = f
+}
+
+func (d *DecayingReservoir) float64() float64 {
+	if d.randFloat == nil {
+		return rand.Float64()
+	}
+	return d.randFloat()
+}
+
+// SnapshotAt returns the durations currently in the reservoir
+func (d *DecayingReservoir) SnapshotAt(now time.Time) SortedDurations {
+	d.mu.Lock()
+	d.rescaleIfNeeded(now)
+	ret := make([]time.Duration, len(d.samples))
+	for i, s := range d.samples {
+		ret[i] = s.value
+	}
+	d.mu.Unlock()
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i] < ret[j]
+	})
+	return ret
+}
+
+// Reset removes every duration from the reservoir
+func (d *DecayingReservoir) Reset(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.samples = d.samples[:0]
+	d.landmark = now
+	d.nextScale = now.Add(decayingRescaleInterval)
+}
+
+// MemoryBytes estimates the memory held by a full reservoir
+func (d *DecayingReservoir) MemoryBytes() int64 {
+	return int64(unsafe.Sizeof(*d)) + int64(d.size)*int64(unsafe.Sizeof(decayingSample{}))
+}
+
+// rescaleIfNeeded moves the landmark to now.  Scaling every priority by the same factor keeps their order, so the
+// heap stays valid.
+func (d *DecayingReservoir) rescaleIfNeeded(now time.Time) {
+	if now.Before(d.nextScale) {
+		return
+	}
+	factor := math.Exp(-d.alpha * now.Sub(d.landmark).Seconds())
+	for i := range d.samples {
+		d.samples[i].priority *= factor
+	}
+	d.landmark = now
+	d.nextScale = now.Add(decayingRescaleInterval)
+}
+
+type decayingSample struct {
+	priority float64
+	value    time.Duration
+}
+
+// decayingSamples is a min heap of samples by priority
+type decayingSamples []decayingSample
+
+func (s decayingSamples) Len() int           { return len(s) }
+func (s decayingSamples) Less(i, j int) bool { return s[i].priority < s[j].priority }
+func (s decayingSamples) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *decayingSamples) Push(x interface{}) {
+	*s = append(*s, x.(decayingSample))
+}
+
+func (s *decayingSamples) Pop() interface{} {
+	old := *s
+	x := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return x
+}
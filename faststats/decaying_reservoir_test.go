@@ -0,0 +1,108 @@
+package faststats
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDecayingReservoir_Fresh(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingReservoir(10, 0.015, now)
+	expectSnap(t, "at empty", x.SnapshotAt(now), 0, -1, map[float64]time.Duration{
+		50: -1,
+	})
+}
+
+func TestDecayingReservoir_Size(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingReservoir(10, 0.015, now)
+	for i := 0; i < 5; i++ {
+		x.AddDuration(time.Second, now)
+	}
+	expectSnap(t, "under size", x.SnapshotAt(now), 5, time.Second, nil)
+	for i := 0; i < 100; i++ {
+		x.AddDuration(time.Second, now)
+	}
+	expectSnap(t, "over size", x.SnapshotAt(now), 10, time.Second, nil)
+	x.Reset(now)
+	expectSnap(t, "after reset", x.SnapshotAt(now), 0, -1, nil)
+}
+
+func TestDecayingReservoir_SetRandFloat64(t *testing.T) {
+	kept := func(seed int64) SortedDurations {
+		now := time.Now()
+		x := NewDecayingReservoir(10, 0.015, now)
+		x.SetRandFloat64(rand.New(rand.NewSource(seed)).Float64)
+		for i := 0; i < 1000; i++ {
+			x.AddDuration(time.Duration(i), now)
+		}
+		return x.SnapshotAt(now)
+	}
+	if first, second := kept(7), kept(7); !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same seed to keep the same durations, got %v and %v", first, second)
+	}
+	if first, second := kept(7), kept(8); reflect.DeepEqual(first, second) {
+		t.Errorf("expected other seeds to keep other durations, got %v for both", first)
+	}
+}
+
+func TestDecayingReservoir_FavorsRecent(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingReservoir(100, 0.1, now)
+	for i := 0; i < 1000; i++ {
+		x.AddDuration(time.Second, now)
+	}
+	// A minute later old durations are e^6 (about 400x) less likely to be kept
+	later := now.Add(time.Minute)
+	for i := 0; i < 1000; i++ {
+		x.AddDuration(time.Millisecond, later)
+	}
+	snap := x.SnapshotAt(later)
+	if p := snap.Percentile(90); p != time.Millisecond {
+		t.Errorf("expect recent durations to dominate, got p90=%s", p)
+	}
+}
+
+func TestDecayingReservoir_Rescale(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingReservoir(10, 0.015, now)
+	x.AddDuration(time.Second, now)
+	// Without rescaling, priorities this far out would overflow
+	later := now.Add(24 * time.Hour)
+	for i := 0; i < 10; i++ {
+		x.AddDuration(time.Millisecond, later)
+	}
+	expectSnap(t, "after rescale", x.SnapshotAt(later), 10, time.Millisecond, nil)
+}
+
+func TestDecayingReservoir_Concurrent(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingReservoir(100, 0.015, now)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				x.AddDuration(time.Millisecond, now)
+				x.SnapshotAt(now)
+			}
+		}()
+	}
+	wg.Wait()
+	expectSnap(t, "after concurrent adds", x.SnapshotAt(now), 100, time.Millisecond, nil)
+}
+
+func TestNewDecayingPercentile(t *testing.T) {
+	now := time.Now()
+	x := NewDecayingPercentile(10, 0.015, now)
+	x.AddDuration(time.Second, now)
+	expectSnap(t, "at first", x.SnapshotAt(now), 1, time.Second, map[float64]time.Duration{
+		50: time.Second,
+	})
+	x.Reset(now)
+	expectSnap(t, "after reset", x.SnapshotAt(now), 0, -1, nil)
+}
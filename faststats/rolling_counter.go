@@ -2,10 +2,12 @@ package faststats
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 )
 
 // RollingCounter uses a slice of buckets to keep track of counts of an event over time with a sliding window
@@ -16,7 +18,7 @@ type RollingCounter struct {
 
 	// Neither of these need to be locked (atomic operations)
 	rollingSum AtomicInt64
-	totalSum   AtomicInt64
+	totalSum   TotalCounter
 
 	rollingBucket RollingBuckets
 }
@@ -41,15 +43,20 @@ type jsonCounter struct {
 	Buckets       []AtomicInt64
 	RollingSum    *AtomicInt64
 	TotalSum      *AtomicInt64
+	TotalEpochs   int64 `json:",omitempty"`
 	RollingBucket *RollingBuckets
 }
 
 // MarshalJSON JSON encodes a counter.  It is thread safe.
 func (r *RollingCounter) MarshalJSON() ([]byte, error) {
+	var totalSum AtomicInt64
+	epochs, sum := r.totalSum.Epochs()
+	totalSum.Set(sum)
 	return json.Marshal(jsonCounter{
 		Buckets:       r.buckets,
 		RollingSum:    &r.rollingSum,
-		TotalSum:      &r.totalSum,
+		TotalSum:      &totalSum,
+		TotalEpochs:   epochs,
 		RollingBucket: &r.rollingBucket,
 	})
 }
@@ -60,13 +67,72 @@ func (r *RollingCounter) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &into); err != nil {
 		return err
 	}
+	if err := into.validate(); err != nil {
+		return err
+	}
 	r.buckets = into.Buckets
 	r.rollingSum.Store(into.RollingSum.Get())
-	r.totalSum.Store(into.TotalSum.Get())
+	r.totalSum.Set(into.TotalEpochs, into.TotalSum.Get())
 	r.rollingBucket.Store(into.RollingBucket)
 	return nil
 }
 
+// errIncompleteCounter is returned for an encoded counter that is missing fields
+var errIncompleteCounter = errors.New("incomplete rolling counter")
+
+func (j *jsonCounter) validate() error {
+	if j.RollingSum == nil || j.TotalSum == nil || j.RollingBucket == nil {
+		return errIncompleteCounter
+	}
+	if len(j.Buckets) != j.RollingBucket.NumBuckets || (j.RollingBucket.NumBuckets > 0 && j.RollingBucket.BucketWidth <= 0) {
+		return fmt.Errorf("%w: %d buckets of width %s", errIncompleteCounter, len(j.Buckets), j.RollingBucket.BucketWidth)
+	}
+	return nil
+}
+
+// RestoreJSON adds the counts of a counter encoded by MarshalJSON, and takes its total.  Unlike UnmarshalJSON, it keeps
+// the bucket width and count of r: each encoded bucket is counted in the bucket of r that covers the same time.  Counts
+// older than the rolling window at now are dropped.  It is *NOT* thread safe, and is meant for counters not used yet.
+func (r *RollingCounter) RestoreJSON(b []byte, now time.Time) error {
+	var into jsonCounter
+	if err := json.Unmarshal(b, &into); err != nil {
+		return err
+	}
+	if err := into.validate(); err != nil {
+		return err
+	}
+	if len(r.buckets) > 0 {
+		// Start the window early enough to hold every count still inside it
+		window := time.Duration(r.rollingBucket.NumBuckets) * r.rollingBucket.BucketWidth
+		r.Reset(now)
+		r.rollingBucket.StartTime = now.Add(-window)
+		r.rollingBucket.LastAbsIndex.Set(0)
+		old := into.RollingBucket
+		last := old.LastAbsIndex.Get()
+		// Oldest first, so the window only moves forward
+		for i := int64(old.NumBuckets) - 1; i >= 0; i-- {
+			absIndex := last - i
+			if absIndex < 0 {
+				continue
+			}
+			count := into.Buckets[absIndex%int64(old.NumBuckets)].Get()
+			if count == 0 {
+				continue
+			}
+			at := old.StartTime.Add(time.Duration(absIndex) * old.BucketWidth)
+			if at.After(now) {
+				at = now
+			}
+			if idx := r.rollingBucket.Advance(at, r.clearBucket); idx >= 0 {
+				r.buckets[idx].Add(count)
+				r.rollingSum.Add(count)
+			}
+		}
+	}
+	r.totalSum.Set(into.TotalEpochs, into.TotalSum.Get())
+	return nil
+}
+
 // String for debugging
 func (r *RollingCounter) String() string {
 	return r.StringAt(time.Now())
@@ -84,7 +150,12 @@ func (r *RollingCounter) StringAt(now time.Time) string {
 
 // Inc adds a single event to the current bucket
 func (r *RollingCounter) Inc(now time.Time) {
-	r.totalSum.Add(1)
+	r.Add(now, 1)
+}
+
+// Add adds delta events to the current bucket
+func (r *RollingCounter) Add(now time.Time, delta int64) {
+	r.totalSum.Add(delta)
 	if len(r.buckets) == 0 {
 		return
 	}
@@ -92,8 +163,8 @@ func (r *RollingCounter) Inc(now time.Time) {
 	if idx < 0 {
 		return
 	}
-	r.buckets[idx].Add(1)
-	r.rollingSum.Add(1)
+	r.buckets[idx].Add(delta)
+	r.rollingSum.Add(delta)
 }
 
 // RollingSumAt returns the total number of events in the rolling time window
@@ -108,9 +179,20 @@ func (r *RollingCounter) RollingSum() int64 {
 	return r.rollingSum.Get()
 }
 
-// TotalSum returns the total number of events of all time
+// TotalSum returns the total number of events of all time.  It stops at math.MaxInt64 instead of overflowing.
 func (r *RollingCounter) TotalSum() int64 {
-	return r.totalSum.Get()
+	return r.totalSum.Sum()
+}
+
+// TotalEpochs returns the total number of events of all time as how many TotalEpochSize epochs have finished, and
+// how many events were counted in the current one.  Unlike TotalSum, it keeps counting past math.MaxInt64.
+func (r *RollingCounter) TotalEpochs() (epochs int64, sum int64) {
+	return r.totalSum.Epochs()
+}
+
+// MemoryBytes estimates the memory held by the counter's buckets
+func (r *RollingCounter) MemoryBytes() int64 {
+	return int64(unsafe.Sizeof(*r)) + int64(len(r.buckets))*int64(unsafe.Sizeof(AtomicInt64{}))
 }
 
 // GetBuckets returns a copy of the buckets in order backwards in time
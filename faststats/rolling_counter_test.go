@@ -2,6 +2,7 @@ package faststats
 
 import (
 	"encoding/json"
+	"math"
 	"runtime"
 	"strconv"
 	"strings"
@@ -223,6 +224,23 @@ func TestRollingCounter_Inc(t *testing.T) {
 	}
 }
 
+func TestRollingCounter_Add(t *testing.T) {
+	now := time.Now()
+	x := NewRollingCounter(time.Millisecond, 10, now)
+	x.Add(now, 5)
+	x.Inc(now)
+	if ans := x.RollingSumAt(now); ans != 6 {
+		t.Errorf("Should see six items, not %d", ans)
+	}
+	x.Add(now.Add(time.Second), 3)
+	if ans := x.RollingSumAt(now.Add(time.Second)); ans != 3 {
+		t.Errorf("Should see only the newest three items, not %d", ans)
+	}
+	if x.TotalSum() != 9 {
+		t.Errorf("Should see nine items in total, not %d", x.TotalSum())
+	}
+}
+
 func expectBuckets(t *testing.T, now time.Time, in *RollingCounter, b []int64) {
 	a := in.GetBuckets(now)
 	if len(a) != len(b) {
@@ -292,3 +310,83 @@ func TestRollingCounter_MoveForward(t *testing.T) {
 		t.Errorf("Should see a sum of 1 after advancing past all the buckets, saw %d", s)
 	}
 }
+
+func TestRollingCounter_MemoryBytes(t *testing.T) {
+	small := NewRollingCounter(time.Second, 10, time.Now())
+	large := NewRollingCounter(time.Second, 20, time.Now())
+	if large.MemoryBytes()-small.MemoryBytes() != 80 {
+		t.Errorf("expected 8 bytes a bucket, got %d and %d", small.MemoryBytes(), large.MemoryBytes())
+	}
+}
+
+func TestRollingCounter_TotalEpochs(t *testing.T) {
+	now := time.Now()
+	x := NewRollingCounter(time.Second, 10, now)
+	x.Add(now, math.MaxInt64)
+	x.Add(now, math.MaxInt64)
+	if x.TotalSum() != math.MaxInt64 {
+		t.Errorf("expected the total to saturate, got %d", x.TotalSum())
+	}
+	epochs, sum := x.TotalEpochs()
+	if epochs != 3 || sum != TotalEpochSize-2 {
+		t.Errorf("unexpected epochs %d %d", epochs, sum)
+	}
+	b, err := json.Marshal(&x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var y RollingCounter
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+	if e, s := y.TotalEpochs(); e != epochs || s != sum {
+		t.Errorf("expected epochs to survive JSON, got %d %d", e, s)
+	}
+}
+
+func TestRollingCounter_RestoreJSON(t *testing.T) {
+	now := time.Now()
+	old := NewRollingCounter(time.Second, 10, now)
+	old.Add(now, 3)
+	old.Add(now.Add(5*time.Second), 4)
+	old.Add(now.Add(9*time.Second), 5)
+	b, err := json.Marshal(&old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	later := now.Add(10 * time.Second)
+	x := NewRollingCounter(2*time.Second, 4, later)
+	if err := x.RestoreJSON(b, later); err != nil {
+		t.Fatal(err)
+	}
+	if len(x.GetBuckets(later)) != 4 {
+		t.Error("expected the restored counter to keep its bucket count")
+	}
+	// The window of the new counter is 8 seconds, so the count at the start of the old window is dropped
+	if sum := x.RollingSumAt(later); sum != 9 {
+		t.Errorf("expected a rolling sum of 9, got %d", sum)
+	}
+	if x.TotalSum() != 12 {
+		t.Errorf("expected the total to carry over, got %d", x.TotalSum())
+	}
+	if sum := x.RollingSumAt(later.Add(3 * time.Second)); sum != 5 {
+		t.Errorf("expected old counts to roll out of the window, got %d", sum)
+	}
+
+	for _, partial := range []string{
+		`{}`,
+		`{"Buckets":[1],"RollingSum":1,"TotalSum":1}`,
+		`{"RollingSum":1,"TotalSum":1,"RollingBucket":{"NumBuckets":1,"BucketWidth":1}}`,
+		`{"Buckets":[1],"RollingSum":1,"TotalSum":1,"RollingBucket":{"NumBuckets":1,"BucketWidth":0}}`,
+		`{"Buckets":[1],"RollingSum":1,"RollingBucket":{"NumBuckets":1,"BucketWidth":1}}`,
+	} {
+		y := NewRollingCounter(time.Second, 10, now)
+		if err := y.RestoreJSON([]byte(partial), now); err == nil {
+			t.Errorf("expected an error restoring %s", partial)
+		}
+		if err := y.UnmarshalJSON([]byte(partial)); err == nil {
+			t.Errorf("expected an error unmarshaling %s", partial)
+		}
+	}
+}
@@ -8,14 +8,17 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/cep21/circuit/v4/internal/evar"
 )
 
-// RollingPercentile is a bucketed array of time.Duration that cycles over time
+// RollingPercentile is a bucketed array of time.Duration that cycles over time.  If created with
+// NewDecayingPercentile, it instead keeps its durations in a DecayingReservoir.
 type RollingPercentile struct {
 	buckets       []durationsBucket
 	rollingBucket RollingBuckets
+	reservoir     *DecayingReservoir
 }
 
 // SortedDurations is a sorted list of time.Duration that allows fast Percentile operations
@@ -118,6 +121,14 @@ func NewRollingPercentile(bucketWidth time.Duration, numBuckets int, bucketSize
 	}
 }
 
+// NewDecayingPercentile creates a percentile tracker backed by a DecayingReservoir of size durations instead of
+// rolling buckets, so recent durations are weighted more smoothly
+func NewDecayingPercentile(size int, alpha float64, now time.Time) RollingPercentile {
+	return RollingPercentile{
+		reservoir: NewDecayingReservoir(size, alpha, now),
+	}
+}
+
 func makeBuckets(numBuckets int, bucketSize int) []durationsBucket {
 	ret := make([]durationsBucket, numBuckets)
 	for i := 0; i < numBuckets; i++ {
@@ -137,6 +148,9 @@ func (r *RollingPercentile) Var() expvar.Var {
 
 // SortedDurations creates a raw []time.Duration in sorted order that is stored in these buckets
 func (r *RollingPercentile) SortedDurations(now time.Time) []time.Duration {
+	if r.reservoir != nil {
+		return r.reservoir.SnapshotAt(now)
+	}
 	if len(r.buckets) == 0 {
 		return nil
 	}
@@ -167,6 +181,10 @@ func (r *RollingPercentile) clearBucket(idx int) {
 
 // AddDuration adds a duration to the rolling buckets
 func (r *RollingPercentile) AddDuration(d time.Duration, now time.Time) {
+	if r.reservoir != nil {
+		r.reservoir.AddDuration(d, now)
+		return
+	}
 	if len(r.buckets) == 0 {
 		return
 	}
@@ -174,14 +192,38 @@ func (r *RollingPercentile) AddDuration(d time.Duration, now time.Time) {
 	r.buckets[idx].addDuration(d)
 }
 
+// SetRandFloat64 sets what picks the durations a decaying reservoir keeps.  Rolling buckets keep every duration, so
+// it does nothing for them.
+func (r *RollingPercentile) SetRandFloat64(f func() float64) {
+	if r.reservoir != nil {
+		r.reservoir.SetRandFloat64(f)
+	}
+}
+
 // Reset the counter to all zero values.
 func (r *RollingPercentile) Reset(now time.Time) {
+	if r.reservoir != nil {
+		r.reservoir.Reset(now)
+		return
+	}
 	r.rollingBucket.Advance(now, r.clearBucket)
 	for i := 0; i < r.rollingBucket.NumBuckets; i++ {
 		r.clearBucket(i)
 	}
 }
 
+// MemoryBytes estimates the memory held by the buckets of durations, or by the reservoir
+func (r *RollingPercentile) MemoryBytes() int64 {
+	ret := int64(unsafe.Sizeof(*r))
+	for i := range r.buckets {
+		ret += int64(unsafe.Sizeof(r.buckets[i])) + int64(len(r.buckets[i].durationsSomeInvalid))*int64(unsafe.Sizeof(AtomicInt64{}))
+	}
+	if r.reservoir != nil {
+		ret += r.reservoir.MemoryBytes()
+	}
+	return ret
+}
+
 // durationsBucket supports atomically adding durations to a size limited list
 type durationsBucket struct {
 	// durations is a fixed size and cannot change during operation
@@ -207,3 +207,10 @@ func TestRollingPercentile_Movement(t *testing.T) {
 		100: -1,
 	})
 }
+
+func TestRollingPercentile_MemoryBytes(t *testing.T) {
+	r := NewRollingPercentile(time.Second, 10, 100, time.Now())
+	if r.MemoryBytes() < 10*100*8 {
+		t.Errorf("expected at least 8 bytes a duration, got %d", r.MemoryBytes())
+	}
+}
@@ -0,0 +1,140 @@
+package faststats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// SlidingLog keeps the time and outcome of each recent event, up to a fixed number of events.  Counts over the window
+// are exact, instead of approximated by buckets, which matters for low volume sources: at under one event a second a
+// bucket dropping out of a RollingCounter can move the error rate by a large step.
+type SlidingLog struct {
+	window time.Duration
+
+	mu sync.Mutex
+	// entries is a ring of the most recent events.  len(entries) is constant and not mutable.
+	entries []slidingLogEntry
+	// next is where the next event is written, and count is how many entries are in use
+	next  int
+	count int
+	// evicted is the time of the newest event pushed out of the ring to make space for another
+	evicted time.Time
+}
+
+type slidingLogEntry struct {
+	at     time.Time
+	failed bool
+}
+
+// NewSlidingLog creates a log that counts events within window of now, keeping at most size events
+func NewSlidingLog(window time.Duration, size int) *SlidingLog {
+	return &SlidingLog{
+		window:  window,
+		entries: make([]slidingLogEntry, size),
+	}
+}
+
+var _ json.Marshaler = &SlidingLog{}
+var _ fmt.Stringer = &SlidingLog{}
+
+type jsonSlidingLog struct {
+	Window   time.Duration
+	Size     int
+	Total    int64
+	Failures int64
+	Exact    bool
+}
+
+// MarshalJSON JSON encodes the log's counts as of now.  It is thread safe.
+func (s *SlidingLog) MarshalJSON() ([]byte, error) {
+	total, failures, exact := s.CountsAt(time.Now())
+	return json.Marshal(jsonSlidingLog{
+		Window:   s.window,
+		Size:     len(s.entries),
+		Total:    total,
+		Failures: failures,
+		Exact:    exact,
+	})
+}
+
+// String for debugging
+func (s *SlidingLog) String() string {
+	total, failures, exact := s.CountsAt(time.Now())
+	return fmt.Sprintf("SlidingLog(window=%s, size=%d, total=%d, failures=%d, exact=%t)", s.window, len(s.entries), total, failures, exact)
+}
+
+// Success logs a successful event at now
+func (s *SlidingLog) Success(now time.Time) {
+	s.add(now, false)
+}
+
+// Failure logs a failed event at now
+func (s *SlidingLog) Failure(now time.Time) {
+	s.add(now, true)
+}
+
+func (s *SlidingLog) add(now time.Time, failed bool) {
+	if len(s.entries) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == len(s.entries) {
+		if at := s.entries[s.next].at; at.After(s.evicted) {
+			s.evicted = at
+		}
+	} else {
+		s.count++
+	}
+	s.entries[s.next] = slidingLogEntry{at: now, failed: failed}
+	s.next = (s.next + 1) % len(s.entries)
+}
+
+// Window is how far back the log counts events
+func (s *SlidingLog) Window() time.Duration {
+	return s.window
+}
+
+// Size is the most events the log keeps
+func (s *SlidingLog) Size() int {
+	return len(s.entries)
+}
+
+// MemoryBytes estimates the memory held by the log's entries
+func (s *SlidingLog) MemoryBytes() int64 {
+	return int64(unsafe.Sizeof(*s)) + int64(len(s.entries))*int64(unsafe.Sizeof(slidingLogEntry{}))
+}
+
+// CountsAt returns how many events, and how many failed events, happened in the window ending at now.  exact is false
+// if events in the window were pushed out of a full log, in which case the counts only cover the newest Size events.
+func (s *SlidingLog) CountsAt(now time.Time) (total int64, failures int64, exact bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := now.Add(-s.window)
+	for i := 0; i < s.count; i++ {
+		e := s.entries[i]
+		if !e.at.After(start) || e.at.After(now) {
+			continue
+		}
+		total++
+		if e.failed {
+			failures++
+		}
+	}
+	return total, failures, !s.evicted.After(start)
+}
+
+// Reset empties the log
+func (s *SlidingLog) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.entries {
+		s.entries[i] = slidingLogEntry{}
+	}
+	s.next = 0
+	s.count = 0
+	s.evicted = time.Time{}
+}
@@ -0,0 +1,50 @@
+package faststats
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSlidingLog(t *testing.T) {
+	now := time.Now()
+	s := NewSlidingLog(time.Minute, 3)
+	s.Failure(now)
+	s.Success(now.Add(30 * time.Second))
+	if total, failures, exact := s.CountsAt(now.Add(45 * time.Second)); total != 2 || failures != 1 || !exact {
+		t.Fatalf("unexpected counts %s", s.String())
+	}
+	// The failure leaves the window exactly a minute later, not when its bucket would
+	if total, failures, exact := s.CountsAt(now.Add(time.Minute)); total != 1 || failures != 0 || !exact {
+		t.Fatalf("expected the failure to leave the window, got %d %d %t", total, failures, exact)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+	s.Reset()
+	if total, _, _ := s.CountsAt(now); total != 0 {
+		t.Fatal("reset should empty the log")
+	}
+}
+
+func TestSlidingLog_full(t *testing.T) {
+	now := time.Now()
+	s := NewSlidingLog(time.Minute, 2)
+	s.Failure(now)
+	s.Success(now.Add(time.Second))
+	s.Success(now.Add(2 * time.Second))
+	if total, failures, exact := s.CountsAt(now.Add(3 * time.Second)); total != 2 || failures != 0 || exact {
+		t.Fatalf("expected inexact counts once events in the window are evicted, got %d %d %t", total, failures, exact)
+	}
+	if _, _, exact := s.CountsAt(now.Add(time.Minute + time.Millisecond)); !exact {
+		t.Fatal("expected exact counts once the evicted event is out of the window")
+	}
+}
+
+func TestSlidingLog_empty(t *testing.T) {
+	s := NewSlidingLog(time.Minute, 0)
+	s.Failure(time.Now())
+	if total, _, exact := s.CountsAt(time.Now()); total != 0 || !exact {
+		t.Fatal("an empty log should ignore events")
+	}
+}
@@ -2,7 +2,9 @@ package faststats
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -12,12 +14,15 @@ import (
 // it depends on when the OS decides to trigger the timer.
 type TimedCheck struct {
 	sleepDuration     AtomicInt64
+	sleepJitter       AtomicInt64
 	eventCountToAllow AtomicInt64
 
 	isFastFail        AtomicBoolean
 	isFailFastVersion AtomicInt64
 
 	TimeAfterFunc func(time.Duration, func()) *time.Timer
+	// RandInt63n picks the sleep jitter.  It should act like rand.Int63n, which is the default.
+	RandInt63n func(n int64) int64
 
 	// All 3 of these variables must be accessed with the RWMutex
 	nextOpenTime               time.Time
@@ -33,6 +38,7 @@ var _ fmt.Stringer = &TimedCheck{}
 // marshalStruct is used by JSON marshalling
 type marshalStruct struct {
 	SleepDuration              int64
+	SleepJitter                int64
 	EventCountToAllow          int64
 	NextOpenTime               time.Time
 	CurrentlyAllowedEventCount int64
@@ -50,6 +56,7 @@ func (c *TimedCheck) MarshalJSON() ([]byte, error) {
 	defer c.mu.Unlock()
 	return json.Marshal(marshalStruct{
 		SleepDuration:              c.sleepDuration.Get(),
+		SleepJitter:                c.sleepJitter.Get(),
 		EventCountToAllow:          c.eventCountToAllow.Get(),
 		NextOpenTime:               c.nextOpenTime,
 		CurrentlyAllowedEventCount: c.currentlyAllowedEventCount,
@@ -65,9 +72,43 @@ func (c *TimedCheck) UnmarshalJSON(b []byte) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.sleepDuration.Set(into.SleepDuration)
+	c.sleepJitter.Set(into.SleepJitter)
 	c.eventCountToAllow.Set(into.EventCountToAllow)
 	c.nextOpenTime = into.NextOpenTime
 	c.currentlyAllowedEventCount = into.CurrentlyAllowedEventCount
+	// Check falls back to nextOpenTime, so stop any fast fail that was started before the state was replaced
+	if c.lastSetTimer != nil {
+		c.lastSetTimer.Stop()
+		c.lastSetTimer = nil
+	}
+	c.isFailFastVersion.Add(1)
+	c.isFastFail.Set(false)
+	return nil
+}
+
+// RestoreJSON restores when the next event is allowed, and how many were allowed since, from MarshalJSON.  Unlike
+// UnmarshalJSON, it keeps the sleep duration, jitter and event count of c.  It is *NOT* thread safe.
+func (c *TimedCheck) RestoreJSON(b []byte) error {
+	var into struct {
+		NextOpenTime               *time.Time
+		CurrentlyAllowedEventCount int64
+	}
+	if err := json.Unmarshal(b, &into); err != nil {
+		return err
+	}
+	if into.NextOpenTime == nil {
+		return errors.New("incomplete timed check: missing NextOpenTime")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextOpenTime = *into.NextOpenTime
+	c.currentlyAllowedEventCount = into.CurrentlyAllowedEventCount
+	if c.lastSetTimer != nil {
+		c.lastSetTimer.Stop()
+		c.lastSetTimer = nil
+	}
+	c.isFailFastVersion.Add(1)
+	c.isFastFail.Set(false)
 	return nil
 }
 
@@ -77,6 +118,27 @@ func (c *TimedCheck) SetSleepDuration(newDuration time.Duration) {
 	c.sleepDuration.Set(newDuration.Nanoseconds())
 }
 
+// SetSleepJitter adds a random amount of extra sleep, up to maxJitter, each time the check starts sleeping.  This keeps
+// many checks that started sleeping at the same time from all waking up at the same time.
+func (c *TimedCheck) SetSleepJitter(maxJitter time.Duration) {
+	c.sleepJitter.Set(maxJitter.Nanoseconds())
+}
+
+func (c *TimedCheck) nextSleepDuration() time.Duration {
+	ret := c.sleepDuration.Duration()
+	if jitter := c.sleepJitter.Get(); jitter > 0 {
+		ret += time.Duration(c.int63n(jitter))
+	}
+	return ret
+}
+
+func (c *TimedCheck) int63n(n int64) int64 {
+	if c.RandInt63n == nil {
+		return rand.Int63n(n)
+	}
+	return c.RandInt63n(n)
+}
+
 func (c *TimedCheck) afterFunc(d time.Duration, f func()) *time.Timer {
 	if c.TimeAfterFunc == nil {
 		return time.AfterFunc(d, f)
@@ -102,11 +164,12 @@ func (c *TimedCheck) resetOpenTimeWithLock(now time.Time) {
 		c.lastSetTimer.Stop()
 		c.lastSetTimer = nil
 	}
-	c.nextOpenTime = now.Add(c.sleepDuration.Duration())
+	sleepDuration := c.nextSleepDuration()
+	c.nextOpenTime = now.Add(sleepDuration)
 	c.currentlyAllowedEventCount = 0
 	c.isFastFail.Set(true)
 	currentVersion := c.isFailFastVersion.Add(1)
-	c.lastSetTimer = c.afterFunc(c.sleepDuration.Duration(), func() {
+	c.lastSetTimer = c.afterFunc(sleepDuration, func() {
 		// If sleep start is called again, don't reset from an old version
 		if currentVersion == c.isFailFastVersion.Get() {
 			c.isFastFail.Set(false)
@@ -114,6 +177,13 @@ func (c *TimedCheck) resetOpenTimeWithLock(now time.Time) {
 	})
 }
 
+// NextOpenTime returns the soonest Check can return true
+func (c *TimedCheck) NextOpenTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nextOpenTime
+}
+
 // Check returns true if a check is allowed at this time
 func (c *TimedCheck) Check(now time.Time) bool {
 	if c.isFastFail.Get() {
@@ -71,6 +71,45 @@ func TestTimedCheck_Check(t *testing.T) {
 	}
 }
 
+func TestTimedCheck_Jitter(t *testing.T) {
+	c := clock.MockClock{}
+	x := TimedCheck{
+		TimeAfterFunc: c.AfterFunc,
+	}
+	x.SetSleepDuration(time.Second)
+	x.SetSleepJitter(time.Second)
+	now := time.Now()
+	c.Set(now)
+	x.SleepStart(now)
+	if x.Check(c.Set(now.Add(time.Millisecond * 999))) {
+		t.Fatal("Jitter should never shorten the sleep")
+	}
+	if !x.Check(c.Set(now.Add(time.Second * 2))) {
+		t.Fatal("Jitter should never be more than the max jitter")
+	}
+}
+
+func TestTimedCheck_RandInt63n(t *testing.T) {
+	c := clock.MockClock{}
+	x := TimedCheck{
+		TimeAfterFunc: c.AfterFunc,
+		RandInt63n: func(n int64) int64 {
+			return n / 2
+		},
+	}
+	x.SetSleepDuration(time.Second)
+	x.SetSleepJitter(time.Second)
+	now := time.Now()
+	c.Set(now)
+	x.SleepStart(now)
+	if x.Check(c.Set(now.Add(time.Millisecond * 1499))) {
+		t.Fatal("Expected the jitter picked by RandInt63n")
+	}
+	if !x.Check(c.Set(now.Add(time.Millisecond * 1500))) {
+		t.Fatal("Expected the check to open after the picked jitter")
+	}
+}
+
 func TestTimedCheck(t *testing.T) {
 	sleepDuration := time.Millisecond * 100
 	now := time.Now()
@@ -113,3 +152,38 @@ func TestTimedCheckRaces(_ *testing.T) {
 	})
 	wg.Wait()
 }
+
+func TestTimedCheck_RestoreJSON(t *testing.T) {
+	c := clock.MockClock{}
+	now := time.Now()
+	c.Set(now)
+	old := TimedCheck{
+		TimeAfterFunc: c.AfterFunc,
+	}
+	old.SetSleepDuration(time.Minute)
+	old.SleepStart(now)
+	b, err := json.Marshal(&old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := TimedCheck{
+		TimeAfterFunc: c.AfterFunc,
+	}
+	x.SetSleepDuration(time.Second)
+	if err := x.RestoreJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if !x.NextOpenTime().Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the next open time to carry over, got %s", x.NextOpenTime())
+	}
+	if !x.Check(c.Set(now.Add(time.Minute))) {
+		t.Fatal("expected a check at the restored time")
+	}
+	if !x.NextOpenTime().Equal(now.Add(time.Minute + time.Second)) {
+		t.Error("expected the restored check to keep its own sleep duration")
+	}
+	if err := x.RestoreJSON([]byte(`{"SleepDuration":1}`)); err == nil {
+		t.Error("expected an error for a state without NextOpenTime")
+	}
+}
@@ -0,0 +1,64 @@
+package faststats
+
+import (
+	"math"
+	"runtime"
+)
+
+// TotalEpochSize is how many events a TotalCounter counts in one epoch before starting the next.  It is half the
+// range of an int64, so adds that cross into the next epoch have room to land before they are moved out.
+const TotalEpochSize int64 = 1 << 62
+
+// TotalCounter counts events of all time without overflowing.  Every TotalEpochSize events it starts a new epoch, so
+// the lifetime count is Epochs()*TotalEpochSize plus what is counted in the current epoch.  Adds are a single atomic
+// operation, except for the one add each epoch that crosses into the next.
+type TotalCounter struct {
+	sum AtomicInt64
+	// seq is twice the number of finished epochs.  It is odd while an add is moving TotalEpochSize out of sum.
+	seq AtomicInt64
+}
+
+// Add adds delta events.  A negative delta is taken from the current epoch, which can leave it below zero.
+func (t *TotalCounter) Add(delta int64) {
+	if delta >= TotalEpochSize {
+		// Count whole epochs directly, so sum never gets close to overflowing
+		t.seq.Add(2 * (delta / TotalEpochSize))
+		delta %= TotalEpochSize
+	}
+	n := t.sum.Add(delta)
+	if n < TotalEpochSize || n-delta >= TotalEpochSize {
+		return
+	}
+	// This add crossed into the next epoch.  Readers retry while seq is odd, so they never count the epoch twice.
+	t.seq.Add(1)
+	t.sum.Add(-TotalEpochSize)
+	t.seq.Add(1)
+}
+
+// Epochs returns how many epochs have finished, and how many events were counted in the current one
+func (t *TotalCounter) Epochs() (epochs int64, sum int64) {
+	for {
+		before := t.seq.Get()
+		sum = t.sum.Get()
+		if before%2 == 0 && t.seq.Get() == before {
+			// An add may have crossed into the next epoch without moving it out of sum yet
+			return before/2 + sum/TotalEpochSize, sum % TotalEpochSize
+		}
+		runtime.Gosched()
+	}
+}
+
+// Sum returns the lifetime count, or math.MaxInt64 if it is too large for an int64
+func (t *TotalCounter) Sum() int64 {
+	epochs, sum := t.Epochs()
+	if epochs > (math.MaxInt64-sum)/TotalEpochSize {
+		return math.MaxInt64
+	}
+	return epochs*TotalEpochSize + sum
+}
+
+// Set replaces the count with the given epochs and events counted in the current epoch.  It is not thread safe.
+func (t *TotalCounter) Set(epochs int64, sum int64) {
+	t.seq.Set(2 * (epochs + sum/TotalEpochSize))
+	t.sum.Set(sum % TotalEpochSize)
+}
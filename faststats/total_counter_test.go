@@ -0,0 +1,49 @@
+package faststats
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestTotalCounter(t *testing.T) {
+	var c TotalCounter
+	c.Set(0, TotalEpochSize-1)
+	c.Add(3)
+	if epochs, sum := c.Epochs(); epochs != 1 || sum != 2 {
+		t.Fatalf("expected to cross into the next epoch, got %d %d", epochs, sum)
+	}
+	if c.Sum() != TotalEpochSize+2 {
+		t.Errorf("unexpected sum %d", c.Sum())
+	}
+	c.Set(2, 5)
+	if c.Sum() != math.MaxInt64 {
+		t.Errorf("expected the sum to saturate, got %d", c.Sum())
+	}
+	c.Add(TotalEpochSize)
+	if epochs, sum := c.Epochs(); epochs != 3 || sum != 5 {
+		t.Errorf("expected epochs to keep counting, got %d %d", epochs, sum)
+	}
+}
+
+func TestTotalCounter_concurrent(t *testing.T) {
+	var c TotalCounter
+	c.Set(0, TotalEpochSize-500)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Add(1)
+				if epochs, sum := c.Epochs(); epochs*TotalEpochSize+sum < TotalEpochSize-500 || sum >= TotalEpochSize {
+					t.Errorf("inconsistent read %d %d", epochs, sum)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if epochs, sum := c.Epochs(); epochs != 1 || sum != 500 {
+		t.Errorf("expected every add counted once, got %d %d", epochs, sum)
+	}
+}
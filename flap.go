@@ -0,0 +1,62 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+// flapBuckets is how many buckets GeneralConfig.FlapWindow is split into
+const flapBuckets = 10
+
+// Flaps returns how many times the circuit opened or closed inside GeneralConfig.FlapWindow.  A circuit that keeps
+// opening and closing every few seconds is usually better off staying open.  See GeneralConfig.FlapThreshold.
+func (c *Circuit) Flaps() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.flaps.RollingSumAt(c.now())
+}
+
+// holdOpenIfFlapping holds a circuit that just opened open for FlapHoldOpen if it opened and closed too often
+func (c *Circuit) holdOpenIfFlapping(now time.Time) {
+	threshold := c.config().General.FlapThreshold
+	if threshold <= 0 || c.flaps.RollingSumAt(now) < threshold {
+		return
+	}
+	c.extendHoldOpen(now.Add(c.config().General.FlapHoldOpen))
+}
+
+// HoldOpen opens the circuit and keeps it open until the given time.  OpenToClose is not asked to allow half open
+// requests before then, but CloseCircuit still closes it.  Use it to take a dependency out of service for a known
+// time, like an outlier host.  It has no effect on a circuit that is forced closed.
+func (c *Circuit) HoldOpen(ctx context.Context, until time.Time) {
+	if c.config().General.ForcedClosed {
+		return
+	}
+	// Hold before opening, so no half open request gets in between
+	c.extendHoldOpen(until)
+	c.openCircuit(ctx, c.now(), StateReasonManual)
+}
+
+// extendHoldOpen holds the circuit open until at least until
+func (c *Circuit) extendHoldOpen(until time.Time) {
+	for {
+		current := c.holdOpenUntil.Get()
+		if current >= until.UnixNano() || c.holdOpenUntil.CompareAndSwap(current, until.UnixNano()) {
+			return
+		}
+	}
+}
+
+// isHeldOpen returns true if a flapping circuit should not allow half open requests yet
+func (c *Circuit) isHeldOpen(now time.Time) bool {
+	holdOpenUntil := c.holdOpenUntil.Get()
+	return holdOpenUntil != 0 && now.UnixNano() < holdOpenUntil
+}
+
+// inMinClosedDuration returns true if the circuit closed too recently to consider opening again
+func (c *Circuit) inMinClosedDuration(now time.Time) bool {
+	minClosed := c.config().General.MinClosedDuration
+	closedAt := c.closedAt.Get()
+	return minClosed > 0 && closedAt != 0 && now.UnixNano()-closedAt < minClosed.Nanoseconds()
+}
@@ -0,0 +1,136 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func flappingCircuit(name string, general GeneralConfig, now *time.Time) *Circuit {
+	general.ClosedToOpenFactory = func() ClosedToOpen {
+		return alwaysOpensOpener{}
+	}
+	general.OpenToClosedFactory = func() OpenToClosed {
+		return &scheduledCloser{}
+	}
+	general.TimeKeeper = TimeKeeper{
+		Now: func() time.Time {
+			return *now
+		},
+	}
+	return NewCircuitFromConfig(name, Config{General: general})
+}
+
+func TestCircuit_Flaps(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := flappingCircuit("TestCircuit_Flaps", GeneralConfig{
+		FlapWindow: 10 * time.Second,
+	}, &now)
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		_ = c.Run(ctx, func(_ context.Context) error {
+			return errors.New("bad")
+		})
+		now = now.Add(time.Second)
+		_ = c.Run(ctx, func(_ context.Context) error {
+			return nil
+		})
+	}
+	if c.Flaps() != 6 {
+		t.Errorf("expected 6 opens and closes, got %d", c.Flaps())
+	}
+	now = now.Add(time.Minute)
+	if c.Flaps() != 0 {
+		t.Errorf("expected flaps to leave the window, got %d", c.Flaps())
+	}
+}
+
+func TestCircuit_FlapThreshold(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := flappingCircuit("TestCircuit_FlapThreshold", GeneralConfig{
+		FlapThreshold: 3,
+		FlapHoldOpen:  time.Minute,
+	}, &now)
+	fail := func(_ context.Context) error {
+		return errors.New("bad")
+	}
+	pass := func(_ context.Context) error {
+		return nil
+	}
+	_ = c.Run(ctx, fail)
+	_ = c.Run(ctx, pass)
+	if c.IsOpen() {
+		t.Fatal("expected the circuit to close before it flaps too much")
+	}
+	// The 3rd open or close holds the circuit open
+	_ = c.Run(ctx, fail)
+	if !c.IsOpen() {
+		t.Fatal("expected the circuit to open")
+	}
+	now = now.Add(30 * time.Second)
+	if err := c.Run(ctx, pass); err == nil {
+		t.Error("expected a flapping circuit to stay open")
+	}
+	now = now.Add(31 * time.Second)
+	_ = c.Run(ctx, pass)
+	if c.IsOpen() {
+		t.Error("expected the circuit to close once it is not held open")
+	}
+}
+
+func TestCircuit_HoldOpen(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := flappingCircuit("TestCircuit_HoldOpen", GeneralConfig{}, &now)
+	pass := func(_ context.Context) error {
+		return nil
+	}
+	c.HoldOpen(ctx, now.Add(time.Minute))
+	now = now.Add(59 * time.Second)
+	if err := c.Run(ctx, pass); err == nil || !c.IsOpen() {
+		t.Fatal("expected the circuit to stay open until the hold is over")
+	}
+	now = now.Add(time.Second)
+	if err := c.Run(ctx, pass); err != nil || c.IsOpen() {
+		t.Fatalf("expected the circuit to close once the hold is over, got %v", err)
+	}
+
+	// Closing the circuit ends the hold
+	c.HoldOpen(ctx, now.Add(time.Hour))
+	c.CloseCircuit(ctx)
+	c.OpenCircuit(ctx)
+	if err := c.Run(ctx, pass); err != nil || c.IsOpen() {
+		t.Errorf("expected a closed circuit to forget its hold, got %v", err)
+	}
+}
+
+func TestCircuit_MinClosedDuration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := flappingCircuit("TestCircuit_MinClosedDuration", GeneralConfig{
+		MinClosedDuration: 10 * time.Second,
+	}, &now)
+	fail := func(_ context.Context) error {
+		return errors.New("bad")
+	}
+	_ = c.Run(ctx, fail)
+	if !c.IsOpen() {
+		t.Fatal("a circuit that never closed should open right away")
+	}
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	now = now.Add(5 * time.Second)
+	_ = c.Run(ctx, fail)
+	if c.IsOpen() {
+		t.Error("expected the circuit to stay closed for the minimum closed duration")
+	}
+	now = now.Add(5 * time.Second)
+	_ = c.Run(ctx, fail)
+	if !c.IsOpen() {
+		t.Error("expected the circuit to open after the minimum closed duration")
+	}
+}
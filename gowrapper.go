@@ -2,6 +2,7 @@ package circuit
 
 import (
 	"context"
+	"time"
 
 	"github.com/cep21/circuit/v4/faststats"
 )
@@ -11,13 +12,35 @@ import (
 type goroutineWrapper struct {
 	skipCatchPanics faststats.AtomicBoolean
 	lostErrors      func(err error, panics interface{})
+	// circuit is the circuit runs are in, or nil for runs that are not in a circuit
+	circuit *Circuit
 }
 
+// AbandonMetrics can optionally be implemented by RunMetrics that want to know what happens to runs started by Go
+// whose context ended.  RunEndedInGrace is called when runFunc returned inside ExecutionConfig.TimeoutGracePeriod,
+// with how long Go waited for it.  RunAbandoned is called when Go returned while runFunc was still running.
+type AbandonMetrics interface {
+	RunEndedInGrace(ctx context.Context, now time.Time, wait time.Duration)
+	RunAbandoned(ctx context.Context, now time.Time)
+}
+
+var _ AbandonMetrics = RunMetricsCollection(nil)
+
 func (g *goroutineWrapper) run(runFunc func(context.Context) error) func(context.Context) error {
+	return g.spawn(runFunc, true)
+}
+
+// spawn runs runFunc in a goroutine, returning early if ctx ends.  Only runFunc, and not the fallback, waits for
+// ExecutionConfig.TimeoutGracePeriod and is reported to AbandonMetrics.
+func (g *goroutineWrapper) spawn(runFunc func(context.Context) error, grace bool) func(context.Context) error {
 	if runFunc == nil {
 		return nil
 	}
 	return func(ctx context.Context) error {
+		if ctx.Done() == nil {
+			// The context can never end, so there is nothing to end early for.  Save spawning a goroutine.
+			return runFunc(ctx)
+		}
 		var panicResult chan interface{}
 		if !g.skipCatchPanics.Get() {
 			panicResult = make(chan interface{}, 1)
@@ -35,6 +58,11 @@ func (g *goroutineWrapper) run(runFunc func(context.Context) error) func(context
 		}()
 		select {
 		case <-ctx.Done():
+			if grace {
+				if ended, err := g.waitForGrace(ctx, runFuncErr, panicResult); ended {
+					return err
+				}
+			}
 			// runFuncErr is a lost error.
 			if g.lostErrors != nil {
 				go g.waitForErrors(runFuncErr, panicResult)
@@ -48,14 +76,50 @@ func (g *goroutineWrapper) run(runFunc func(context.Context) error) func(context
 	}
 }
 
+// waitForGrace waits up to ExecutionConfig.TimeoutGracePeriod for runFunc to return after ctx ended.  It returns
+// true and runFunc's error if it did.
+func (g *goroutineWrapper) waitForGrace(ctx context.Context, runFuncErr chan error, panicResult chan interface{}) (bool, error) {
+	if g.circuit == nil {
+		return false, nil
+	}
+	cfg := g.circuit.config()
+	grace := cfg.Execution.TimeoutGracePeriod
+	if grace == 0 {
+		g.circuit.CmdMetricCollector.RunAbandoned(ctx, g.circuit.now())
+		return false, nil
+	}
+	var expired chan struct{}
+	if grace > 0 {
+		expired = make(chan struct{})
+		timer := cfg.General.TimeKeeper.AfterFunc(grace, func() {
+			close(expired)
+		})
+		if timer != nil {
+			defer timer.Stop()
+		}
+	}
+	start := g.circuit.now()
+	select {
+	case err := <-runFuncErr:
+		now := g.circuit.now()
+		g.circuit.CmdMetricCollector.RunEndedInGrace(ctx, now, now.Sub(start))
+		return true, err
+	case panicVal := <-panicResult:
+		panic(panicVal)
+	case <-expired:
+		g.circuit.CmdMetricCollector.RunAbandoned(ctx, g.circuit.now())
+		return false, nil
+	}
+}
+
 func (g *goroutineWrapper) fallback(runFunc func(context.Context, error) error) func(context.Context, error) error {
 	if runFunc == nil {
 		return nil
 	}
 	return func(ctx context.Context, err error) error {
-		return g.run(func(funcCtx context.Context) error {
+		return g.spawn(func(funcCtx context.Context) error {
 			return runFunc(funcCtx, err)
-		})(ctx)
+		}, false)(ctx)
 	}
 }
 
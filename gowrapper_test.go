@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+	"github.com/cep21/circuit/v4/internal/clock"
 )
 
 type errWaiter struct {
@@ -116,6 +122,25 @@ func Test_goroutineWrapper_foreground(t *testing.T) {
 	}
 }
 
+func Test_goroutineWrapper_synchronous(t *testing.T) {
+	var g goroutineWrapper
+	calledFromTest := func(context.Context) error {
+		buf := make([]byte, 1<<16)
+		if !strings.Contains(string(buf[:runtime.Stack(buf, false)]), "Test_goroutineWrapper_synchronous(") {
+			return errors.New("not called on the caller's goroutine")
+		}
+		return nil
+	}
+	if err := g.run(calledFromTest)(context.Background()); err != nil {
+		t.Error("expected a context that never ends to run synchronously:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.run(calledFromTest)(ctx); err == nil {
+		t.Error("expected a context that can end to use a goroutine")
+	}
+}
+
 func Test_goroutineWrapper_run(t *testing.T) {
 	deadCtx, onEnd := context.WithCancel(context.Background())
 	onEnd()
@@ -195,3 +220,157 @@ func Test_goroutineWrapper_run(t *testing.T) {
 		})
 	}
 }
+
+type abandons struct {
+	RunMetrics
+	mu           sync.Mutex
+	endedInGrace int
+	abandoned    int
+	timeouts     int
+}
+
+func (a *abandons) RunEndedInGrace(_ context.Context, _ time.Time, _ time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endedInGrace++
+}
+
+func (a *abandons) RunAbandoned(_ context.Context, _ time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.abandoned++
+}
+
+func (a *abandons) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.timeouts++
+}
+
+func (a *abandons) RunError(_ context.Context, _ time.Time, _ error) {}
+
+func (a *abandons) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (a *abandons) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (a *abandons) counts() (int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.endedInGrace, a.abandoned
+}
+
+func TestCircuit_GoTimeoutGracePeriod(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		grace                time.Duration
+		expectEnded          bool
+		expectedEndedInGrace int
+		expectedAbandoned    int
+	}{
+		{name: "abandon", expectedAbandoned: 1},
+		{name: "grace", grace: time.Minute, expectEnded: true, expectedEndedInGrace: 1},
+		{name: "never abandon", grace: -1, expectEnded: true, expectedEndedInGrace: 1},
+		{name: "grace too short", grace: time.Millisecond, expectedAbandoned: 1},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := &abandons{}
+			c := NewCircuitFromConfig("TestCircuit_GoTimeoutGracePeriod", Config{
+				Execution: ExecutionConfig{
+					Timeout:            time.Millisecond,
+					TimeoutGracePeriod: tc.grace,
+				},
+				Metrics: MetricsCollectors{
+					Run: []RunMetrics{metrics},
+				},
+			})
+			var ended faststats.AtomicBoolean
+			_ = c.Go(context.Background(), func(_ context.Context) error {
+				// Like a run holding a lock, this does not stop when its context ends
+				time.Sleep(50 * time.Millisecond)
+				ended.Set(true)
+				return nil
+			}, nil)
+			if metrics.timeouts != 1 {
+				t.Errorf("expected the run to time out, got %d timeouts", metrics.timeouts)
+			}
+			if ended.Get() != tc.expectEnded {
+				t.Errorf("expected the run to have ended=%t when Go returned", tc.expectEnded)
+			}
+			endedInGrace, abandoned := metrics.counts()
+			if endedInGrace != tc.expectedEndedInGrace || abandoned != tc.expectedAbandoned {
+				t.Errorf("expected %d ended in grace and %d abandoned, got %d and %d", tc.expectedEndedInGrace, tc.expectedAbandoned, endedInGrace, abandoned)
+			}
+		})
+	}
+}
+
+func TestCircuit_GoTimeoutGracePeriodClock(t *testing.T) {
+	mockClock := clock.MockClock{}
+	mockClock.Set(time.Now())
+	metrics := &abandons{}
+	c := NewCircuitFromConfig("TestCircuit_GoTimeoutGracePeriodClock", Config{
+		General: GeneralConfig{
+			TimeKeeper: TimeKeeper{
+				Now:       mockClock.Now,
+				AfterFunc: mockClock.AfterFunc,
+			},
+		},
+		Execution: ExecutionConfig{
+			Timeout:            -1,
+			TimeoutGracePeriod: time.Hour,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		cancel()
+		// Let Go start waiting for the grace period before it ends
+		time.Sleep(10 * time.Millisecond)
+		mockClock.Add(time.Hour)
+	}()
+	_ = c.Go(ctx, func(_ context.Context) error {
+		<-release
+		return nil
+	}, nil)
+	if endedInGrace, abandoned := metrics.counts(); endedInGrace != 0 || abandoned != 1 {
+		t.Errorf("expected the grace period to follow the circuit's clock, got %d ended in grace and %d abandoned", endedInGrace, abandoned)
+	}
+}
+
+func TestCircuit_GoFallbackNotAbandoned(t *testing.T) {
+	metrics := &abandons{}
+	c := NewCircuitFromConfig("TestCircuit_GoFallbackNotAbandoned", Config{
+		Execution: ExecutionConfig{
+			Timeout:            -1,
+			TimeoutGracePeriod: time.Minute,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	err := c.Go(ctx, func(_ context.Context) error {
+		return errors.New("failure")
+	}, func(_ context.Context, _ error) error {
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	if err == nil {
+		t.Error("expected the canceled fallback to return the context's error")
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Error("expected the fallback to not wait for the grace period")
+	}
+	if endedInGrace, abandoned := metrics.counts(); endedInGrace != 0 || abandoned != 0 {
+		t.Errorf("expected the fallback to not be reported as a run, got %d ended in grace and %d abandoned", endedInGrace, abandoned)
+	}
+}
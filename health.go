@@ -0,0 +1,111 @@
+package circuit
+
+import (
+	"time"
+)
+
+// HealthStats is optionally implemented by RunMetrics that keep rolling stats of a circuit's runs, like
+// rolling.RunStats.  Circuit.HealthScore uses the first run collector that implements it.
+type HealthStats interface {
+	// ErrorPercentageAt returns [0.0 - 1.0] of the recent runs that failed or timed out
+	ErrorPercentageAt(now time.Time) float64
+	// LatencyPercentileAt returns the p [0.0 - 100.0] percentile of recent run durations, or -1 if there are none
+	LatencyPercentileAt(now time.Time, p float64) time.Duration
+}
+
+// The weights of each part of a healthy circuit's HealthScore
+const (
+	healthErrorWeight      = 2
+	healthLatencyWeight    = 1
+	healthSaturationWeight = 1
+)
+
+// healthLatencyPercentile is the latency HealthScore compares to the timeout
+const healthLatencyPercentile = 99
+
+// HealthScore returns how healthy the circuit is, from 0 (unusable) to 100 (healthy), so routers and automation have
+// one number to act on.  It is the weighted health of:
+//
+//   - the error rate, if a run collector implements HealthStats
+//   - the 99th percentile latency as a fraction of ExecutionConfig.Timeout, if a run collector implements HealthStats
+//   - the concurrent commands as a fraction of ExecutionConfig.MaxConcurrentRequests
+//
+// The error rate counts twice as much as the others.  Parts the circuit cannot measure are left out.  The result is
+// then scaled by the state of the circuit: an open circuit scores 0, and a half open one at most 50.
+func (c *Circuit) HealthScore() float64 {
+	if c == nil {
+		return 100
+	}
+	var stateFactor float64
+	switch c.TimeInState().Current {
+	case StateOpen:
+		return 0
+	case StateHalfOpen:
+		stateFactor = 0.5
+	default:
+		stateFactor = 1
+	}
+	cfg := c.config()
+	now := c.now()
+	var total, weights float64
+	add := func(weight float64, health float64) {
+		total += weight * clampHealth(health)
+		weights += weight
+	}
+	if stats := c.healthStats(); stats != nil {
+		add(healthErrorWeight, 1-stats.ErrorPercentageAt(now))
+		latency := stats.LatencyPercentileAt(now, healthLatencyPercentile)
+		if timeout := cfg.Execution.Timeout; timeout > 0 && latency >= 0 {
+			add(healthLatencyWeight, 1-float64(latency)/float64(timeout))
+		}
+	}
+	if limit := cfg.Execution.MaxConcurrentRequests; limit > 0 && cfg.Execution.ConcurrencyLimiter == nil {
+		add(healthSaturationWeight, 1-float64(c.ConcurrentCommands())/float64(limit))
+	}
+	if weights == 0 {
+		return 100 * stateFactor
+	}
+	return 100 * stateFactor * total / weights
+}
+
+// healthStats returns the first run collector that implements HealthStats, or nil if none do
+func (c *Circuit) healthStats() HealthStats {
+	for _, m := range c.CmdMetricCollector {
+		if stats, ok := m.(HealthStats); ok {
+			return stats
+		}
+	}
+	return nil
+}
+
+func clampHealth(health float64) float64 {
+	if health < 0 {
+		return 0
+	}
+	if health > 1 {
+		return 1
+	}
+	return health
+}
+
+// HealthScore returns the weighted average of the HealthScore of every circuit, from 0 to 100.  weight gives how much
+// each circuit counts, for example more for the circuits on the critical path.  Circuits with a weight of 0 or less
+// are left out.  A nil weight counts every circuit the same.  It is 100 if no circuit is counted.
+func (h *Manager) HealthScore(weight func(c *Circuit) float64) float64 {
+	var total, weights float64
+	for _, c := range h.AllCircuits() {
+		w := 1.0
+		if weight != nil {
+			w = weight(c)
+		}
+		if w <= 0 {
+			continue
+		}
+		total += w * c.HealthScore()
+		weights += w
+	}
+	if weights == 0 {
+		return 100
+	}
+	return total / weights
+}
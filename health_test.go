@@ -0,0 +1,112 @@
+package circuit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeHealthStats struct {
+	RunMetrics
+	errorPercentage float64
+	latency         time.Duration
+}
+
+func (f *fakeHealthStats) ErrorPercentageAt(_ time.Time) float64 {
+	return f.errorPercentage
+}
+
+func (f *fakeHealthStats) LatencyPercentileAt(_ time.Time, _ float64) time.Duration {
+	return f.latency
+}
+
+func TestCircuit_HealthScore(t *testing.T) {
+	stats := &fakeHealthStats{errorPercentage: 0.5, latency: 500 * time.Millisecond}
+	c := NewCircuitFromConfig("TestCircuit_HealthScore", Config{
+		Execution: ExecutionConfig{
+			Timeout:               time.Second,
+			MaxConcurrentRequests: 4,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{stats},
+		},
+	})
+	// Errors count twice: (2*0.5 + 0.5 + 1) / 4
+	if score := c.HealthScore(); math.Abs(score-62.5) > 0.001 {
+		t.Errorf("expected a score of 62.5, got %v", score)
+	}
+	stats.latency = -1
+	// No latency to compare: (2*0.5 + 1) / 3
+	if score := c.HealthScore(); math.Abs(score-200.0/3) > 0.001 {
+		t.Errorf("expected a score of 66.7, got %v", score)
+	}
+	c.OpenCircuit(context.Background())
+	if score := c.HealthScore(); score != 0 {
+		t.Errorf("expected an open circuit to score 0, got %v", score)
+	}
+}
+
+func TestCircuit_HealthScore_Saturation(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_HealthScore_Saturation", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 2,
+		},
+	})
+	if score := c.HealthScore(); score != 100 {
+		t.Errorf("expected an idle circuit to score 100, got %v", score)
+	}
+	var score float64
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		score = c.HealthScore()
+		return nil
+	}, nil)
+	if score != 50 {
+		t.Errorf("expected a half full circuit to score 50, got %v", score)
+	}
+}
+
+func TestCircuit_HealthScore_HalfOpen(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_HealthScore_HalfOpen", Config{
+		General: GeneralConfig{
+			InitialState: StateHalfOpen,
+		},
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: -1,
+		},
+	})
+	var score float64
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		score = c.HealthScore()
+		return nil
+	}, nil)
+	if score != 50 {
+		t.Errorf("expected a half open circuit to score 50, got %v", score)
+	}
+}
+
+func TestManager_HealthScore(t *testing.T) {
+	var h Manager
+	h.MustCreateCircuit("critical").OpenCircuit(context.Background())
+	h.MustCreateCircuit("optional")
+	h.MustCreateCircuit("ignored").OpenCircuit(context.Background())
+	if score := h.HealthScore(nil); math.Abs(score-100.0/3) > 0.001 {
+		t.Errorf("expected a score of 33.3, got %v", score)
+	}
+	score := h.HealthScore(func(c *Circuit) float64 {
+		switch c.Name() {
+		case "critical":
+			return 3
+		case "optional":
+			return 1
+		}
+		return 0
+	})
+	if score != 25 {
+		t.Errorf("expected a score of 25, got %v", score)
+	}
+	var empty Manager
+	if score := empty.HealthScore(nil); score != 100 {
+		t.Errorf("expected no circuits to score 100, got %v", score)
+	}
+}
@@ -0,0 +1,101 @@
+package circuit
+
+import (
+	"sort"
+	"strings"
+)
+
+// NameSeparator separates the levels of hierarchical circuit names.  A circuit named "payments.gateway.charge" is in
+// the levels "payments" and "payments.gateway".  Manager.Rollups uses it so dashboards can show a dependency, like
+// "payments.gateway", next to each of its endpoints.
+const NameSeparator = "."
+
+// Rollup is the health of every circuit at or under one level of the name hierarchy
+type Rollup struct {
+	// Level is the name the circuits are under.  It is "" for the rollup of every circuit.
+	Level string
+	// Circuits are the names of the circuits at or under Level, sorted
+	Circuits []string
+	// Open are the names of the open circuits at or under Level, sorted
+	Open []string
+	// ConcurrentCommands and ConcurrentFallbacks are summed over the circuits
+	ConcurrentCommands  int64
+	ConcurrentFallbacks int64
+}
+
+// AllOpen returns true if there are circuits in the rollup and every one of them is open
+func (r Rollup) AllOpen() bool {
+	return len(r.Circuits) != 0 && len(r.Open) == len(r.Circuits)
+}
+
+// NameLevels returns the levels a hierarchical circuit name is in, from the top.  The name itself is the last level.
+// NameLevels("payments.gateway.charge") is ["payments", "payments.gateway", "payments.gateway.charge"].
+func NameLevels(name string) []string {
+	var ret []string
+	for i := 0; i < len(name); i++ {
+		if strings.HasPrefix(name[i:], NameSeparator) {
+			ret = append(ret, name[:i])
+		}
+	}
+	return append(ret, name)
+}
+
+// InLevel returns true if the circuit name is level itself or is under it.  Every name is in the level "".
+func InLevel(name string, level string) bool {
+	return level == "" || name == level || strings.HasPrefix(name, level+NameSeparator)
+}
+
+// CircuitsIn returns the circuits at or under level, sorted by name
+func (h *Manager) CircuitsIn(level string) []*Circuit {
+	var ret []*Circuit
+	for _, c := range h.AllCircuits() {
+		if InLevel(c.Name(), level) {
+			ret = append(ret, c)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name() < ret[j].Name()
+	})
+	return ret
+}
+
+// Rollup returns the health of the circuits at or under level.  Use "" for every circuit.
+func (h *Manager) Rollup(level string) Rollup {
+	return rollupOf(level, h.CircuitsIn(level))
+}
+
+// Rollups returns a Rollup for every level of every circuit name, sorted by level, so "payments" comes just before
+// "payments.gateway".  Each circuit has a Rollup of its own as the last level of its name.
+func (h *Manager) Rollups() []Rollup {
+	byLevel := make(map[string][]*Circuit)
+	for _, c := range h.AllCircuits() {
+		for _, level := range NameLevels(c.Name()) {
+			byLevel[level] = append(byLevel[level], c)
+		}
+	}
+	ret := make([]Rollup, 0, len(byLevel))
+	for level, circuits := range byLevel {
+		ret = append(ret, rollupOf(level, circuits))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Level < ret[j].Level
+	})
+	return ret
+}
+
+func rollupOf(level string, circuits []*Circuit) Rollup {
+	ret := Rollup{
+		Level: level,
+	}
+	for _, c := range circuits {
+		ret.Circuits = append(ret.Circuits, c.Name())
+		if c.IsOpen() {
+			ret.Open = append(ret.Open, c.Name())
+		}
+		ret.ConcurrentCommands += c.ConcurrentCommands()
+		ret.ConcurrentFallbacks += c.ConcurrentFallbacks()
+	}
+	sort.Strings(ret.Circuits)
+	sort.Strings(ret.Open)
+	return ret
+}
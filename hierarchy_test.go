@@ -0,0 +1,47 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestNameLevels(t *testing.T) {
+	if levels := NameLevels("payments.gateway.charge"); !reflect.DeepEqual(levels, []string{"payments", "payments.gateway", "payments.gateway.charge"}) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+	if levels := NameLevels("db"); !reflect.DeepEqual(levels, []string{"db"}) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+	if !InLevel("payments.gateway", "payments") || InLevel("paymentsv2", "payments") || !InLevel("db", "") {
+		t.Error("unexpected InLevel")
+	}
+}
+
+func TestManager_Rollups(t *testing.T) {
+	h := Manager{}
+	h.MustCreateCircuit("payments.gateway.charge").OpenCircuit(context.Background())
+	h.MustCreateCircuit("payments.gateway.refund")
+	h.MustCreateCircuit("payments.ledger")
+	h.MustCreateCircuit("paymentsv2")
+
+	gateway := h.Rollup("payments.gateway")
+	if !reflect.DeepEqual(gateway.Circuits, []string{"payments.gateway.charge", "payments.gateway.refund"}) {
+		t.Errorf("unexpected circuits %v", gateway.Circuits)
+	}
+	if !reflect.DeepEqual(gateway.Open, []string{"payments.gateway.charge"}) || gateway.AllOpen() {
+		t.Errorf("expected only charge to be open, got %v", gateway.Open)
+	}
+	if all := h.Rollup(""); len(all.Circuits) != 4 {
+		t.Errorf("expected every circuit in the top rollup, got %v", all.Circuits)
+	}
+
+	var levels []string
+	for _, r := range h.Rollups() {
+		levels = append(levels, r.Level)
+	}
+	expected := []string{"payments", "payments.gateway", "payments.gateway.charge", "payments.gateway.refund", "payments.ledger", "paymentsv2"}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
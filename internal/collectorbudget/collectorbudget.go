@@ -0,0 +1,104 @@
+// Package collectorbudget checks that metric collectors do not slow circuits down.  It runs a standard workload
+// through a circuit with a collector's config and through a circuit without it, and compares the two.
+package collectorbudget
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// TimingEnv is the environment variable that turns on checking Budget.Overhead, for example
+// CIRCUIT_BUDGET_TIMING=1 go test ./metrics/...
+const TimingEnv = "CIRCUIT_BUDGET_TIMING"
+
+// Budget is how much a collector may add to each Execute
+type Budget struct {
+	// Overhead is the most time a collector may add to each Execute.  Timings are noisy on shared machines, so it is
+	// only checked when the TimingEnv environment variable is set, and never with -race or -short.
+	Overhead time.Duration
+	// Allocs is the most allocations a collector may add to each Execute
+	Allocs float64
+}
+
+var (
+	errFailure    = errors.New("failure")
+	errBadRequest = circuit.SimpleBadRequest{Err: errors.New("bad request")}
+)
+
+// workload is one of each kind of run most circuits see: a success, a failure that falls back, and a bad request
+func workload(c *circuit.Circuit) {
+	ctx := context.Background()
+	_ = c.Execute(ctx, func(_ context.Context) error {
+		return nil
+	}, nil)
+	_ = c.Execute(ctx, func(_ context.Context) error {
+		return errFailure
+	}, func(_ context.Context, _ error) error {
+		return nil
+	})
+	_ = c.Execute(ctx, func(_ context.Context) error {
+		return errBadRequest
+	}, nil)
+}
+
+// runsPerWorkload is how many times workload calls Execute
+const runsPerWorkload = 3
+
+// Check fails t if a circuit created with config is over budget, compared to a circuit created without it
+func Check(t *testing.T, config circuit.Config, budget Budget) {
+	t.Helper()
+	with := circuit.NewCircuitFromConfig(t.Name(), config)
+	without := circuit.NewCircuitFromConfig(t.Name()+"-baseline", circuit.Config{})
+	// Warm up, so buffers that grow once are not counted
+	for i := 0; i < 1000; i++ {
+		workload(with)
+		workload(without)
+	}
+
+	allocs := (testing.AllocsPerRun(1000, func() { workload(with) }) -
+		testing.AllocsPerRun(1000, func() { workload(without) })) / runsPerWorkload
+	if allocs > budget.Allocs {
+		t.Errorf("collector adds %.2f allocations per Execute, over its budget of %.2f", allocs, budget.Allocs)
+	}
+
+	if raceEnabled || testing.Short() || os.Getenv(TimingEnv) == "" {
+		return
+	}
+	overhead := perExecute(with) - perExecute(without)
+	t.Logf("collector adds %.2f allocations and %s per Execute", allocs, overhead)
+	if overhead > budget.Overhead {
+		t.Errorf("collector adds %s per Execute, over its budget of %s", overhead, budget.Overhead)
+	}
+}
+
+// perExecute is how long Execute takes on c, from the fastest of a few rounds to ignore noise
+func perExecute(c *circuit.Circuit) time.Duration {
+	const rounds = 5
+	const workloadsPerRound = 5000
+	var fastest time.Duration
+	for i := 0; i < rounds; i++ {
+		start := time.Now()
+		for j := 0; j < workloadsPerRound; j++ {
+			workload(c)
+		}
+		took := time.Since(start) / (workloadsPerRound * runsPerWorkload)
+		if i == 0 || took < fastest {
+			fastest = took
+		}
+	}
+	return fastest
+}
+
+// Benchmark runs the standard workload through a circuit created with config, so collectors can be profiled
+func Benchmark(b *testing.B, config circuit.Config) {
+	c := circuit.NewCircuitFromConfig(b.Name(), config)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		workload(c)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package collectorbudget
+
+const raceEnabled = false
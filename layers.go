@@ -0,0 +1,123 @@
+package circuit
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ConfigLayer is a level of ConfigLayers
+type ConfigLayer string
+
+const (
+	// LayerCircuit is ConfigLayers.Circuits
+	LayerCircuit ConfigLayer = "circuit"
+	// LayerGroup is ConfigLayers.Groups
+	LayerGroup ConfigLayer = "group"
+	// LayerGlobal is ConfigLayers.Global
+	LayerGlobal ConfigLayer = "global"
+)
+
+// ConfigLayers resolves a circuit's config from three layers, so shared settings are written once instead of copied
+// into every circuit's config.  Per circuit overrides win over the circuit's group profile, which wins over the global
+// defaults.  Add Configure to Manager.DefaultCircuitProperties to use it.  Do not modify the layers once circuits are
+// being created.
+type ConfigLayers struct {
+	// Global applies to every circuit
+	Global Config
+	// Groups are profiles shared by many circuits, by group name
+	Groups map[string]Config
+	// GroupOf names the group of a circuit.  Circuits whose group is not in Groups only use Global and Circuits.
+	GroupOf func(circuitName string) string
+	// Circuits are per circuit overrides, by circuit name
+	Circuits map[string]Config
+}
+
+// ConfigSource is a field of a circuit's config and the layer that set it
+type ConfigSource struct {
+	// Field is the path of the field in Config, like "Execution.Timeout"
+	Field string
+	Layer ConfigLayer
+	// Group is the circuit's group, if Layer is LayerGroup
+	Group string
+	Value interface{}
+}
+
+// layers returns the circuit's layers, most important first
+func (l *ConfigLayers) layers(circuitName string) []ConfigSource {
+	ret := make([]ConfigSource, 0, 3)
+	if c, exists := l.Circuits[circuitName]; exists {
+		ret = append(ret, ConfigSource{Layer: LayerCircuit, Value: c})
+	}
+	if l.GroupOf != nil {
+		group := l.GroupOf(circuitName)
+		if c, exists := l.Groups[group]; exists {
+			ret = append(ret, ConfigSource{Layer: LayerGroup, Group: group, Value: c})
+		}
+	}
+	return append(ret, ConfigSource{Layer: LayerGlobal, Value: l.Global})
+}
+
+// Configure returns the circuit's merged config.  It is a CommandPropertiesConstructor.
+func (l *ConfigLayers) Configure(circuitName string) Config {
+	ret := Config{}
+	for _, layer := range l.layers(circuitName) {
+		ret.Merge(layer.Value.(Config))
+	}
+	return ret
+}
+
+var _ CommandPropertiesConstructor = (&ConfigLayers{}).Configure
+
+// Explain returns the fields of the circuit's merged config that are set, sorted by field, and the layer each came
+// from.  Fields that combine every layer's values, like the metrics collectors and CustomConfig, have a ConfigSource
+// for each layer that added to them.
+func (l *ConfigLayers) Explain(circuitName string) []ConfigSource {
+	var ret []ConfigSource
+	set := make(map[string]bool)
+	for _, layer := range l.layers(circuitName) {
+		setFields("", reflect.ValueOf(layer.Value.(Config)), func(field string, value reflect.Value) {
+			if set[field] && !combinesLayers(value) {
+				return
+			}
+			set[field] = true
+			ret = append(ret, ConfigSource{
+				Field: field,
+				Layer: layer.Layer,
+				Group: layer.Group,
+				Value: value.Interface(),
+			})
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Field < ret[j].Field
+	})
+	return ret
+}
+
+// combinesLayers is true for the fields Merge combines instead of taking from one layer
+func combinesLayers(value reflect.Value) bool {
+	return value.Kind() == reflect.Map || (value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Interface)
+}
+
+// setFields calls found with the path of every field in v, recursively, that Merge would take.  That is every field
+// that is not the zero value, or for slices and maps, not empty.
+func setFields(prefix string, v reflect.Value, found func(field string, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct {
+			setFields(prefix+f.Name+".", value, found)
+			continue
+		}
+		if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+			continue
+		}
+		if !value.IsZero() {
+			found(prefix+f.Name, value)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package circuit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigLayers(t *testing.T) {
+	layers := &ConfigLayers{
+		Global: Config{
+			Execution: ExecutionConfig{
+				Timeout:               time.Second,
+				MaxConcurrentRequests: 10,
+			},
+			Metrics: MetricsCollectors{
+				Run: []RunMetrics{&countsSuccesses{}},
+			},
+		},
+		Groups: map[string]Config{
+			"db": {
+				Execution: ExecutionConfig{
+					Timeout: 200 * time.Millisecond,
+				},
+			},
+		},
+		GroupOf: func(circuitName string) string {
+			return strings.Split(circuitName, ".")[0]
+		},
+		Circuits: map[string]Config{
+			"db.users": {
+				Execution: ExecutionConfig{
+					MaxConcurrentRequests: 5,
+				},
+				Metrics: MetricsCollectors{
+					Run: []RunMetrics{&countsSuccesses{}},
+				},
+			},
+		},
+	}
+	m := Manager{
+		DefaultCircuitProperties: []CommandPropertiesConstructor{layers.Configure},
+	}
+	expectConfig := func(t *testing.T, name string, timeout time.Duration, maxConcurrent int64) {
+		t.Helper()
+		cfg := m.MustCreateCircuit(name).Config()
+		if cfg.Execution.Timeout != timeout || cfg.Execution.MaxConcurrentRequests != maxConcurrent {
+			t.Errorf("%s: expected timeout %s and max concurrent %d, got %s and %d", name, timeout, maxConcurrent, cfg.Execution.Timeout, cfg.Execution.MaxConcurrentRequests)
+		}
+	}
+	expectConfig(t, "db.users", 200*time.Millisecond, 5)
+	expectConfig(t, "db.orders", 200*time.Millisecond, 10)
+	expectConfig(t, "cache.users", time.Second, 10)
+
+	sources := make(map[string][]ConfigSource)
+	for _, s := range layers.Explain("db.users") {
+		sources[s.Field] = append(sources[s.Field], s)
+	}
+	if s := sources["Execution.Timeout"]; len(s) != 1 || s[0].Layer != LayerGroup || s[0].Group != "db" || s[0].Value != 200*time.Millisecond {
+		t.Errorf("expected the timeout from the db group, got %+v", s)
+	}
+	if s := sources["Execution.MaxConcurrentRequests"]; len(s) != 1 || s[0].Layer != LayerCircuit || s[0].Value != int64(5) {
+		t.Errorf("expected max concurrent requests from the circuit, got %+v", s)
+	}
+	if s := sources["Metrics.Run"]; len(s) != 2 || s[0].Layer != LayerCircuit || s[1].Layer != LayerGlobal {
+		t.Errorf("expected run metrics from the circuit and global layers, got %+v", s)
+	}
+	if s, exists := sources["General.ForceOpen"]; exists {
+		t.Errorf("expected unset fields to not be explained, got %+v", s)
+	}
+}
@@ -0,0 +1,63 @@
+package circuit
+
+import (
+	"context"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// ConcurrencyLimiter decides if a run may start.  Set ExecutionConfig.ConcurrencyLimiter to use one instead of
+// MaxConcurrentRequests, for example a weighted semaphore, an adaptive limiter, or a limiter shared by many circuits.
+type ConcurrencyLimiter interface {
+	// Acquire is called before each run.  Return an error to reject the run.  Errors should implement Error, with
+	// ConcurrencyLimitReached returning true, so callers can tell rejections apart from failures.  If err is nil,
+	// release is called when the run ends.
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
+// Semaphore is a ConcurrencyLimiter that lets a fixed number of runs happen at once, weighing each by its Cost.  Give
+// the same Semaphore to many circuits to limit them together.
+type Semaphore struct {
+	max     faststats.AtomicInt64
+	current faststats.AtomicInt64
+	release func()
+}
+
+var _ ConcurrencyLimiter = &Semaphore{}
+
+// NewSemaphore creates a Semaphore that allows max concurrent runs.  A negative max means no limit.
+func NewSemaphore(max int64) *Semaphore {
+	s := &Semaphore{}
+	s.max.Set(max)
+	// Created once so Acquire does not allocate
+	s.release = func() {
+		s.current.Add(-1)
+	}
+	return s
+}
+
+// SetMax changes how many concurrent runs are allowed.  Runs already going are not stopped.
+func (s *Semaphore) SetMax(max int64) {
+	s.max.Set(max)
+}
+
+// Current is the total Cost of the runs holding the semaphore
+func (s *Semaphore) Current() int64 {
+	return s.current.Get()
+}
+
+// Acquire lets the run start if there is room for its Cost, and returns a *ConcurrencyLimitError otherwise
+func (s *Semaphore) Acquire(ctx context.Context) (func(), error) {
+	cost := Cost(ctx)
+	max := s.max.Get()
+	if current := s.current.Add(cost); max >= 0 && current > max {
+		s.current.Add(-cost)
+		return nil, &ConcurrencyLimitError{Limit: max, InFlight: current - cost}
+	}
+	if cost == 1 {
+		return s.release, nil
+	}
+	return func() {
+		s.current.Add(-cost)
+	}, nil
+}
@@ -0,0 +1,83 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSemaphore_sharedByCircuits(t *testing.T) {
+	sem := NewSemaphore(1)
+	cfg := Config{
+		Execution: ExecutionConfig{
+			ConcurrencyLimiter: sem,
+		},
+	}
+	a := NewCircuitFromConfig("TestSemaphore_a", cfg)
+	b := NewCircuitFromConfig("TestSemaphore_b", cfg)
+	err := a.Run(context.Background(), func(_ context.Context) error {
+		if sem.Current() != 1 {
+			t.Errorf("expected the run to hold the semaphore, got %d", sem.Current())
+		}
+		if err := b.Run(context.Background(), func(_ context.Context) error { return nil }); !errors.Is(err, ErrConcurrencyLimitReached) {
+			t.Errorf("expected the other circuit to be limited, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sem.Current() != 0 {
+		t.Errorf("expected the semaphore to be released, got %d", sem.Current())
+	}
+	if err := b.Run(context.Background(), func(_ context.Context) error { return nil }); err != nil {
+		t.Errorf("expected the released semaphore to allow runs, got %v", err)
+	}
+	sem.SetMax(-1)
+	if _, err := sem.Acquire(context.Background()); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+}
+
+type rejectingLimiter struct {
+	err error
+}
+
+func (r rejectingLimiter) Acquire(_ context.Context) (func(), error) {
+	return nil, r.err
+}
+
+func TestCircuit_ConcurrencyLimiter(t *testing.T) {
+	rejected := errors.New("rejected")
+	c := NewCircuitFromConfig("TestCircuit_ConcurrencyLimiter", Config{
+		Execution: ExecutionConfig{
+			// The limiter replaces MaxConcurrentRequests
+			MaxConcurrentRequests: 100,
+			ConcurrencyLimiter:    rejectingLimiter{err: rejected},
+		},
+	})
+	if err := c.Run(context.Background(), func(_ context.Context) error { return nil }); err != rejected {
+		t.Errorf("expected the limiter's error, got %v", err)
+	}
+	if err := c.Run(WithBypass(context.Background()), func(_ context.Context) error { return nil }); err != nil {
+		t.Errorf("expected bypassed runs to skip the limiter, got %v", err)
+	}
+}
+
+func TestSemaphore_cost(t *testing.T) {
+	sem := NewSemaphore(4)
+	release, err := sem.Acquire(WithCost(context.Background(), 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.Acquire(WithCost(context.Background(), 2)); !errors.Is(err, ErrConcurrencyLimitReached) {
+		t.Errorf("expected no room, got %v", err)
+	}
+	if sem.Current() != 3 {
+		t.Errorf("expected the rejected cost to be given back, got %d", sem.Current())
+	}
+	release()
+	if sem.Current() != 0 {
+		t.Errorf("expected the cost to be released, got %d", sem.Current())
+	}
+}
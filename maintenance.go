@@ -0,0 +1,77 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaintenanceWindow is a time a circuit is forced open.  See GeneralConfig.MaintenanceWindows.
+type MaintenanceWindow struct {
+	// Start is when the circuit is forced open
+	Start time.Time
+	// End is when the circuit is no longer forced open
+	End time.Time
+}
+
+// Contains returns true if now is inside the window
+func (m MaintenanceWindow) Contains(now time.Time) bool {
+	return !now.Before(m.Start) && now.Before(m.End)
+}
+
+// inMaintenance returns true if the circuit is inside a maintenance window right now.  It does not check the time
+// unless there are maintenance windows, since it is called on every request.
+func (c *Circuit) inMaintenance() bool {
+	if c.config().General.MaintenanceWindows == nil {
+		return false
+	}
+	_, inMaintenance := c.maintenanceWindowAt(c.now())
+	return inMaintenance
+}
+
+// checkMaintenance returns true if the circuit is inside a maintenance window at now.  The first call to see a window
+// start or end tells collectors the circuit opened or closed, unless the circuit was open or forced either way.
+func (c *Circuit) checkMaintenance(ctx context.Context, now time.Time) bool {
+	var inMaintenance bool
+	if c.config().General.MaintenanceWindows != nil {
+		_, inMaintenance = c.maintenanceWindowAt(now)
+	}
+	if !c.maintenanceOpen.CompareAndSwap(!inMaintenance, inMaintenance) {
+		return inMaintenance
+	}
+	if cfg := c.config(); cfg.General.ForceOpen || cfg.General.ForcedClosed || c.isOpen.Get() {
+		return inMaintenance
+	}
+	c.setHalfOpen(false, now)
+	if inMaintenance {
+		c.CircuitMetricsCollector.Opened(ctx, now)
+	} else {
+		c.CircuitMetricsCollector.Closed(ctx, now)
+	}
+	return inMaintenance
+}
+
+// maintenanceWindowAt returns the maintenance window that contains now, if there is one
+func (c *Circuit) maintenanceWindowAt(now time.Time) (MaintenanceWindow, bool) {
+	for _, window := range c.config().General.MaintenanceWindows {
+		if window.Contains(now) {
+			return window, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// ScheduleMaintenance replaces the maintenance windows of a running circuit.  The circuit is forced open during each
+// window, so fallbacks serve traffic while the dependency is down for planned maintenance.  Call it with no windows to
+// cancel scheduled maintenance.
+func (h *Manager) ScheduleMaintenance(name string, windows ...MaintenanceWindow) error {
+	c := h.GetCircuit(name)
+	if c == nil {
+		return fmt.Errorf("no circuit named %s", name)
+	}
+	cfg := c.Config()
+	cfg.General.MaintenanceWindows = windows
+	c.SetConfigFrom("maintenance", cfg)
+	c.checkMaintenance(context.Background(), c.now())
+	return nil
+}
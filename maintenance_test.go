@@ -0,0 +1,118 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManager_ScheduleMaintenance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := Manager{}
+	c := h.MustCreateCircuit("TestManager_ScheduleMaintenance", Config{
+		General: GeneralConfig{
+			TimeKeeper: TimeKeeper{
+				Now: func() time.Time {
+					return now
+				},
+			},
+		},
+	})
+	if err := h.ScheduleMaintenance("missing"); err == nil {
+		t.Error("expected an error for a circuit that does not exist")
+	}
+	window := MaintenanceWindow{
+		Start: now.Add(time.Hour),
+		End:   now.Add(2 * time.Hour),
+	}
+	if err := h.ScheduleMaintenance("TestManager_ScheduleMaintenance", window); err != nil {
+		t.Fatal(err)
+	}
+	fallback := func(_ context.Context, _ error) error {
+		return errors.New("fallback")
+	}
+	pass := func(_ context.Context) error {
+		return nil
+	}
+	if err := c.Execute(ctx, pass, fallback); err != nil {
+		t.Error("expected the circuit to work before maintenance")
+	}
+
+	now = now.Add(time.Hour)
+	if !c.IsOpen() {
+		t.Error("expected the circuit to be open during maintenance")
+	}
+	if info := c.StateInfo(); info.Reason != StateReasonMaintenance || !info.Since.Equal(window.Start) {
+		t.Errorf("expected maintenance state, got %+v", info)
+	}
+	if err := c.Execute(ctx, pass, fallback); err == nil || err.Error() != "fallback" {
+		t.Errorf("expected the fallback during maintenance, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if c.IsOpen() {
+		t.Error("expected the circuit to close after maintenance")
+	}
+
+	now = now.Add(-time.Minute)
+	if err := h.ScheduleMaintenance("TestManager_ScheduleMaintenance"); err != nil {
+		t.Fatal(err)
+	}
+	if c.IsOpen() {
+		t.Error("expected canceled maintenance to close the circuit")
+	}
+}
+
+func TestCircuit_maintenanceBlocksProbes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	metrics := &countingMetrics{}
+	c := NewCircuitFromConfig("TestCircuit_maintenanceBlocksProbes", Config{
+		General: GeneralConfig{
+			TimeKeeper: TimeKeeper{
+				Now: func() time.Time {
+					return now
+				},
+			},
+			MaintenanceWindows: []MaintenanceWindow{
+				{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+			},
+		},
+		Metrics: MetricsCollectors{
+			Circuit: []Metrics{metrics},
+		},
+	})
+	ran := 0
+	pass := func(_ context.Context) error {
+		ran++
+		return nil
+	}
+
+	now = now.Add(time.Hour)
+	if err := c.Execute(ctx, pass, nil); err == nil {
+		t.Error("expected the run to be rejected during maintenance")
+	}
+	if metrics.opened != 1 {
+		t.Errorf("expected one open when the window started, got %d", metrics.opened)
+	}
+
+	// Long past any sleep window, a closer would let a half open probe through
+	now = now.Add(time.Minute)
+	if err := c.Execute(ctx, pass, nil); err == nil {
+		t.Error("expected the probe to be rejected during maintenance")
+	}
+	c.close(ctx, now, false)
+	if ran != 0 || metrics.closed != 0 || !c.IsOpen() {
+		t.Errorf("expected no run and no close during maintenance, got ran=%d closed=%d", ran, metrics.closed)
+	}
+
+	now = now.Add(time.Hour)
+	if err := c.Execute(ctx, pass, nil); err != nil {
+		t.Errorf("expected the circuit to work after maintenance, got %v", err)
+	}
+	if ran != 1 || metrics.opened != 1 || metrics.closed != 1 {
+		t.Errorf("expected one run, open and close, got ran=%d opened=%d closed=%d", ran, metrics.opened, metrics.closed)
+	}
+}
@@ -3,7 +3,6 @@ package circuit
 import (
 	"errors"
 	"expvar"
-	"sync"
 )
 
 // CommandPropertiesConstructor is a generic function that can create command properties to configure a circuit by name
@@ -16,9 +15,8 @@ type Manager struct {
 	// to append or modify configuration for your circuit.
 	DefaultCircuitProperties []CommandPropertiesConstructor
 
-	circuitMap map[string]*Circuit
-	// mu locks circuitMap, not DefaultCircuitProperties
-	mu sync.RWMutex
+	circuits       circuitRegistry
+	configVersions configVersions
 }
 
 // AllCircuits returns every hystrix circuit tracked
@@ -26,40 +24,30 @@ func (h *Manager) AllCircuits() []*Circuit {
 	if h == nil {
 		return nil
 	}
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	ret := make([]*Circuit, 0, len(h.circuitMap))
-	for _, c := range h.circuitMap {
-		ret = append(ret, c)
-	}
-	return ret
+	return h.circuits.all()
 }
 
 // Var allows you to expose all your hystrix circuits on expvar
 func (h *Manager) Var() expvar.Var {
 	return expvar.Func(func() interface{} {
-		h.mu.RLock()
-		defer h.mu.RUnlock()
 		ret := make(map[string]interface{})
-		for k, v := range h.circuitMap {
+		for _, v := range h.AllCircuits() {
 			ev := expvarToVal(v.Var())
 			if ev != nil {
-				ret[k] = ev
+				ret[v.Name()] = ev
 			}
 		}
 		return ret
 	})
 }
 
-// GetCircuit returns the circuit with a given name, or nil if the circuit does not exist.  You should not call this
-// in live code.  Instead, store the circuit somewhere and use the circuit directly.
+// GetCircuit returns the circuit with a given name, or nil if the circuit does not exist.  It does not lock, so it is
+// cheap enough to call for every request, but storing the circuit somewhere and using it directly is cheaper still.
 func (h *Manager) GetCircuit(name string) *Circuit {
 	if h == nil {
 		return nil
 	}
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return h.circuitMap[name]
+	return h.circuits.get(name)
 }
 
 // MustCreateCircuit calls CreateCircuit, but panics if the circuit name already exists
@@ -73,11 +61,36 @@ func (h *Manager) MustCreateCircuit(name string, config ...Config) *Circuit {
 
 // CreateCircuit creates a new circuit, or returns error if a circuit with that name already exists
 func (h *Manager) CreateCircuit(name string, configs ...Config) (*Circuit, error) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	if h.circuitMap == nil {
-		h.circuitMap = make(map[string]*Circuit, 5)
+	c, created := h.circuits.getOrCreate(name, func() *Circuit {
+		return h.newCircuit(name, configs)
+	})
+	if !created {
+		return nil, errors.New("circuit with that name already exists")
+	}
+	return c, nil
+}
+
+// GetOrCreateCircuit returns the circuit with a given name, creating it with configs if it does not exist yet.
+// Unlike GetCircuit, it is made to be called in live code.  Use it for circuits with many names that are only known
+// at runtime, like one circuit per host or per tenant.  Looking up an existing circuit does not lock.  configs are
+// ignored if the circuit already exists.
+func (h *Manager) GetOrCreateCircuit(name string, configs ...Config) *Circuit {
+	c, _ := h.circuits.getOrCreate(name, func() *Circuit {
+		return h.newCircuit(name, configs)
+	})
+	return c
+}
+
+// RemoveCircuit stops the manager tracking c, so a new circuit can be created with its name.  It returns false if the
+// manager has no circuit with c's name, or has a different one.  c keeps working for anyone still holding it.
+func (h *Manager) RemoveCircuit(c *Circuit) bool {
+	if h == nil || c == nil {
+		return false
 	}
+	return h.circuits.remove(c.Name(), c)
+}
+
+func (h *Manager) newCircuit(name string, configs []Config) *Circuit {
 	finalConfig := Config{}
 	for _, c := range configs {
 		finalConfig.Merge(c)
@@ -86,10 +99,5 @@ func (h *Manager) CreateCircuit(name string, configs ...Config) (*Circuit, error
 	for i := len(h.DefaultCircuitProperties) - 1; i >= 0; i-- {
 		finalConfig.Merge(h.DefaultCircuitProperties[i](name))
 	}
-	_, exists := h.circuitMap[name]
-	if exists {
-		return nil, errors.New("circuit with that name already exists")
-	}
-	h.circuitMap[name] = NewCircuitFromConfig(name, finalConfig)
-	return h.circuitMap[name], nil
+	return NewCircuitFromConfig(name, finalConfig)
 }
@@ -1,8 +1,11 @@
 package circuit
 
 import (
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestManager_Empty(t *testing.T) {
@@ -60,3 +63,174 @@ func TestDoubleCreate(t *testing.T) {
 		t.Error("Expect panic when must creating twice")
 	}
 }
+
+func TestManager_GetOrCreateCircuit(t *testing.T) {
+	h := Manager{}
+	created := h.MustCreateCircuit("existing")
+	if h.GetOrCreateCircuit("existing") != created {
+		t.Error("expected the existing circuit back")
+	}
+	wg := sync.WaitGroup{}
+	results := make([]*Circuit, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.GetOrCreateCircuit("new", Config{})
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range results {
+		if c == nil || c != results[0] {
+			t.Fatal("expected every caller to see the same circuit")
+		}
+	}
+	if h.GetCircuit("new") != results[0] {
+		t.Error("created circuit should be in the manager")
+	}
+	if len(h.AllCircuits()) != 2 {
+		t.Error("unexpected number of circuits")
+	}
+}
+
+func BenchmarkManager_GetOrCreateCircuit(b *testing.B) {
+	h := Manager{}
+	names := make([]string, 1024)
+	for i := range names {
+		names[i] = "circuit-" + strconv.Itoa(i)
+		h.MustCreateCircuit(names[i])
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if h.GetOrCreateCircuit(names[i%len(names)]) == nil {
+				b.Fatal("expected a circuit")
+			}
+			i++
+		}
+	})
+}
+
+func TestManager_GetCircuitWhileCreating(t *testing.T) {
+	h := Manager{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := strconv.Itoa(i) + "-" + strconv.Itoa(j)
+				h.MustCreateCircuit(name)
+				if h.GetCircuit(name) == nil {
+					t.Errorf("circuit %s missing right after creation", name)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(h.AllCircuits()) != 400 {
+		t.Error("expected every circuit to be created")
+	}
+}
+
+func TestManager_GetOrCreateCircuitFromProperties(t *testing.T) {
+	h := Manager{}
+	// Find another name in the same shard, so a properties func that creates it needs the same shard
+	var sameShard string
+	for i := 0; sameShard == ""; i++ {
+		if name := "dependency-" + strconv.Itoa(i); h.circuits.shardFor(name) == h.circuits.shardFor("main") {
+			sameShard = name
+		}
+	}
+	h.DefaultCircuitProperties = []CommandPropertiesConstructor{
+		func(name string) Config {
+			if name == "main" {
+				h.GetOrCreateCircuit(sameShard)
+			}
+			return Config{}
+		},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.GetOrCreateCircuit("main")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("creating a circuit from DefaultCircuitProperties deadlocked")
+	}
+	if h.GetCircuit(sameShard) == nil || h.GetCircuit("main") == nil {
+		t.Error("expected both circuits to be created")
+	}
+}
+
+func TestManager_GetOrCreateCircuitPanics(t *testing.T) {
+	h := Manager{
+		DefaultCircuitProperties: []CommandPropertiesConstructor{
+			func(_ string) Config {
+				panic("bad properties")
+			},
+		},
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to reach the caller")
+			}
+		}()
+		h.GetOrCreateCircuit("c")
+	}()
+	h.DefaultCircuitProperties = nil
+	if h.GetOrCreateCircuit("c") == nil {
+		t.Error("expected a failed create to not stop the name from being created again")
+	}
+}
+
+func BenchmarkManager_CreateCircuit(b *testing.B) {
+	h := Manager{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.MustCreateCircuit("circuit-" + strconv.Itoa(i))
+	}
+}
+
+func BenchmarkManager_GetCircuit(b *testing.B) {
+	h := Manager{}
+	names := make([]string, 1024)
+	for i := range names {
+		names[i] = "circuit-" + strconv.Itoa(i)
+		h.MustCreateCircuit(names[i])
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if h.GetCircuit(names[i%len(names)]) == nil {
+				b.Fatal("expected a circuit")
+			}
+			i++
+		}
+	})
+}
+
+func TestManager_RemoveCircuit(t *testing.T) {
+	h := Manager{}
+	c := h.MustCreateCircuit("c")
+	other := NewCircuitFromConfig("c", Config{})
+	if h.RemoveCircuit(other) {
+		t.Error("expected a different circuit with the same name to not be removed")
+	}
+	if !h.RemoveCircuit(c) {
+		t.Fatal("expected the circuit to be removed")
+	}
+	if h.GetCircuit("c") != nil || h.RemoveCircuit(c) {
+		t.Error("expected the circuit to be gone")
+	}
+	if _, err := h.CreateCircuit("c"); err != nil {
+		t.Errorf("expected the name to be free again: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package circuit
+
+import (
+	"container/list"
+	"reflect"
+	"unsafe"
+)
+
+// MemoryReporter is optionally implemented by metrics collectors, ClosedToOpen, and OpenToClosed implementations that
+// keep state for a single circuit, like rolling buckets.  Circuit.MemoryUsage adds up what they report.  Collectors
+// shared by many circuits should not implement it, or they are counted once per circuit.
+type MemoryReporter interface {
+	// MemoryBytes estimates the bytes of memory held
+	MemoryBytes() int64
+}
+
+// MemoryUsage estimates the memory held by a circuit, in bytes, by what holds it.  It is meant to compare circuits and
+// bound the footprint of services that create many of them, not to be exact.
+type MemoryUsage struct {
+	// Circuit is the circuit itself, with its flap and retry budget buckets and its custom counters
+	Circuit int64
+	// ErrorSamples are the errors kept by GeneralConfig.RecentErrorsSize and RecentBadRequestsSize.  Errors are
+	// estimated by the length of their message.
+	ErrorSamples int64
+	// Partitions are the partitions tracked for ExecutionConfig.PartitionKey
+	Partitions int64
+	// Metrics are the run, fallback, and circuit metrics collectors that implement MemoryReporter
+	Metrics int64
+	// ClosedToOpen and OpenToClose are the opener and closer, if they implement MemoryReporter
+	ClosedToOpen int64
+	OpenToClose  int64
+}
+
+// Total is the sum of every part
+func (m MemoryUsage) Total() int64 {
+	return m.Circuit + m.ErrorSamples + m.Partitions + m.Metrics + m.ClosedToOpen + m.OpenToClose
+}
+
+// MemoryUsage estimates the memory held by the circuit
+func (c *Circuit) MemoryUsage() MemoryUsage {
+	if c == nil {
+		return MemoryUsage{}
+	}
+	ret := MemoryUsage{
+		Circuit:      int64(unsafe.Sizeof(*c)) + int64(len(c.name)) + c.flaps.MemoryBytes() + c.retryBudget.attempts.MemoryBytes() + c.retryBudget.retries.MemoryBytes(),
+		ErrorSamples: c.recentErrors.memoryBytes() + c.recentBadRequests.memoryBytes(),
+		Partitions:   c.partitions.memoryBytes(),
+		ClosedToOpen: memoryBytes(c.ClosedToOpen),
+		OpenToClose:  memoryBytes(c.OpenToClose),
+	}
+	for name, counter := range c.counters {
+		ret.Circuit += int64(len(name)) + int64(unsafe.Sizeof(*counter))
+	}
+	// The opener and closer are also run and circuit metrics collectors, and a collector can be in more than one list,
+	// so each is only counted once
+	seen := make(map[interface{}]struct{})
+	markSeen := func(v interface{}) bool {
+		if v == nil || !reflect.TypeOf(v).Comparable() {
+			return true
+		}
+		if _, exists := seen[v]; exists {
+			return false
+		}
+		seen[v] = struct{}{}
+		return true
+	}
+	markSeen(c.ClosedToOpen)
+	markSeen(c.OpenToClose)
+	for _, m := range c.CmdMetricCollector {
+		if markSeen(m) {
+			ret.Metrics += memoryBytes(m)
+		}
+	}
+	for _, m := range c.FallbackMetricCollector {
+		if markSeen(m) {
+			ret.Metrics += memoryBytes(m)
+		}
+	}
+	for _, m := range c.CircuitMetricsCollector {
+		if markSeen(m) {
+			ret.Metrics += memoryBytes(m)
+		}
+	}
+	return ret
+}
+
+// memoryBytes returns what v reports if it is a MemoryReporter, and 0 otherwise
+func memoryBytes(v interface{}) int64 {
+	if r, ok := v.(MemoryReporter); ok {
+		return r.MemoryBytes()
+	}
+	return 0
+}
+
+func (e *errorSamples) memoryBytes() int64 {
+	if e == nil {
+		return 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ret := int64(unsafe.Sizeof(*e)) + int64(len(e.samples))*int64(unsafe.Sizeof(ErrorSample{}))
+	for _, sample := range e.samples {
+		if sample.Err != nil {
+			ret += int64(len(sample.Err.Error()))
+		}
+	}
+	return ret
+}
+
+func (p *partitions) memoryBytes() int64 {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Each partition is in the map and the list
+	perPartition := int64(unsafe.Sizeof(partition{})) + int64(unsafe.Sizeof(list.Element{})) + int64(unsafe.Sizeof(""))
+	ret := int64(unsafe.Sizeof(*p)) + int64(p.lru.Len())*perPartition
+	for key := range p.byKey {
+		ret += int64(len(key))
+	}
+	return ret
+}
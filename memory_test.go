@@ -0,0 +1,90 @@
+package circuit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/closers/hystrix"
+)
+
+type memoryCollector struct {
+	bytes int64
+}
+
+func (m *memoryCollector) MemoryBytes() int64 {
+	return m.bytes
+}
+
+func (m *memoryCollector) Success(_ context.Context, _ time.Time, _ time.Duration)       {}
+func (m *memoryCollector) ErrFailure(_ context.Context, _ time.Time, _ time.Duration)    {}
+func (m *memoryCollector) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration)    {}
+func (m *memoryCollector) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {}
+func (m *memoryCollector) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration)  {}
+func (m *memoryCollector) ErrConcurrencyLimitReject(_ context.Context, _ time.Time)      {}
+func (m *memoryCollector) ErrShortCircuit(_ context.Context, _ time.Time)                {}
+
+func TestCircuit_MemoryUsage(t *testing.T) {
+	collector := &memoryCollector{bytes: 1000}
+	c := circuit.NewCircuitFromConfig("TestCircuit_MemoryUsage", circuit.Config{
+		General: circuit.GeneralConfig{
+			RecentErrorsSize: 4,
+		},
+		Metrics: circuit.MetricsCollectors{
+			Run: []circuit.RunMetrics{collector, collector},
+		},
+	})
+	before := c.MemoryUsage()
+	if before.Circuit <= 0 || before.ErrorSamples <= 0 || before.Metrics != 1000 || before.Partitions != 0 {
+		t.Fatalf("unexpected usage %+v", before)
+	}
+	_ = c.Run(context.Background(), func(_ context.Context) error {
+		return errors.New("a long enough error message")
+	})
+	after := c.MemoryUsage()
+	if after.ErrorSamples != before.ErrorSamples+int64(len("a long enough error message")) {
+		t.Errorf("expected the error message to be counted, got %d then %d", before.ErrorSamples, after.ErrorSamples)
+	}
+	if after.Total() != after.Circuit+after.ErrorSamples+after.Metrics+after.ClosedToOpen+after.OpenToClose {
+		t.Errorf("unexpected total %d of %+v", after.Total(), after)
+	}
+}
+
+func TestCircuit_MemoryUsageOpener(t *testing.T) {
+	f := hystrix.Factory{
+		ConfigureOpener: hystrix.ConfigureOpener{
+			RollingCount: 50,
+		},
+	}
+	c := circuit.NewCircuitFromConfig("TestCircuit_MemoryUsageOpener", f.Configure("TestCircuit_MemoryUsageOpener"))
+	opener := c.ClosedToOpen.(*hystrix.Opener)
+	usage := c.MemoryUsage()
+	// The opener is also a run and circuit metrics collector, but is only counted as the opener
+	if usage.ClosedToOpen != opener.MemoryBytes() || usage.Metrics != 0 {
+		t.Errorf("expected the opener to be counted once, got %+v", usage)
+	}
+}
+
+type partitionKeyForTest struct{}
+
+func TestCircuit_MemoryUsagePartitions(t *testing.T) {
+	c := circuit.NewCircuitFromConfig("TestCircuit_MemoryUsagePartitions", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			PartitionKey: func(ctx context.Context) string {
+				return ctx.Value(partitionKeyForTest{}).(string)
+			},
+		},
+	})
+	_ = c.Run(context.WithValue(context.Background(), partitionKeyForTest{}, "tenant"), func(_ context.Context) error {
+		return nil
+	})
+	if c.MemoryUsage().Partitions <= 0 {
+		t.Error("expected partitions to be counted")
+	}
+	var nilCircuit *circuit.Circuit
+	if nilCircuit.MemoryUsage().Total() != 0 {
+		t.Error("expected a nil circuit to hold nothing")
+	}
+}
@@ -90,6 +90,96 @@ func (r RunMetricsCollection) ErrInterrupt(ctx context.Context, now time.Time, d
 	}
 }
 
+// RunError sends RunError to all collectors that implement RunErrorMetrics
+func (r RunMetricsCollection) RunError(ctx context.Context, now time.Time, err error) {
+	for _, c := range r {
+		if re, ok := c.(RunErrorMetrics); ok {
+			re.RunError(ctx, now, err)
+		}
+	}
+}
+
+// Retry sends Retry to all collectors that implement RetryMetrics
+func (r RunMetricsCollection) Retry(ctx context.Context, now time.Time) {
+	for _, c := range r {
+		if rm, ok := c.(RetryMetrics); ok {
+			rm.Retry(ctx, now)
+		}
+	}
+}
+
+// ErrRetryBudgetExhausted sends ErrRetryBudgetExhausted to all collectors that implement RetryMetrics
+func (r RunMetricsCollection) ErrRetryBudgetExhausted(ctx context.Context, now time.Time) {
+	for _, c := range r {
+		if rm, ok := c.(RetryMetrics); ok {
+			rm.ErrRetryBudgetExhausted(ctx, now)
+		}
+	}
+}
+
+// Counter sends Counter to all collectors that implement CounterMetrics
+func (r RunMetricsCollection) Counter(ctx context.Context, now time.Time, name string, delta int64) {
+	for _, c := range r {
+		if cm, ok := c.(CounterMetrics); ok {
+			cm.Counter(ctx, now, name, delta)
+		}
+	}
+}
+
+// Size sends Size to all collectors that implement SizeMetrics
+func (r RunMetricsCollection) Size(ctx context.Context, now time.Time, sent int64, received int64) {
+	for _, c := range r {
+		if sm, ok := c.(SizeMetrics); ok {
+			sm.Size(ctx, now, sent, received)
+		}
+	}
+}
+
+// RetryAfter sends RetryAfter to all collectors that implement RetryAfterMetrics
+func (r RunMetricsCollection) RetryAfter(ctx context.Context, now time.Time, after time.Duration) {
+	for _, c := range r {
+		if ra, ok := c.(RetryAfterMetrics); ok {
+			ra.RetryAfter(ctx, now, after)
+		}
+	}
+}
+
+// RunEndedInGrace sends RunEndedInGrace to all collectors that implement AbandonMetrics
+func (r RunMetricsCollection) RunEndedInGrace(ctx context.Context, now time.Time, wait time.Duration) {
+	for _, c := range r {
+		if am, ok := c.(AbandonMetrics); ok {
+			am.RunEndedInGrace(ctx, now, wait)
+		}
+	}
+}
+
+// RunAbandoned sends RunAbandoned to all collectors that implement AbandonMetrics
+func (r RunMetricsCollection) RunAbandoned(ctx context.Context, now time.Time) {
+	for _, c := range r {
+		if am, ok := c.(AbandonMetrics); ok {
+			am.RunAbandoned(ctx, now)
+		}
+	}
+}
+
+// Overhead sends Overhead to all collectors that implement OverheadMetrics
+func (r RunMetricsCollection) Overhead(ctx context.Context, now time.Time, overhead time.Duration) {
+	for _, c := range r {
+		if om, ok := c.(OverheadMetrics); ok {
+			om.Overhead(ctx, now, overhead)
+		}
+	}
+}
+
+// QueueWait sends QueueWait to all collectors that implement QueueMetrics
+func (r RunMetricsCollection) QueueWait(ctx context.Context, now time.Time, wait time.Duration) {
+	for _, c := range r {
+		if qm, ok := c.(QueueMetrics); ok {
+			qm.QueueWait(ctx, now, wait)
+		}
+	}
+}
+
 // FallbackMetricsCollection sends fallback metrics to all collectors
 type FallbackMetricsCollection []FallbackMetrics
 
@@ -116,6 +206,15 @@ func (r FallbackMetricsCollection) ErrFailure(ctx context.Context, now time.Time
 	}
 }
 
+// FallbackReason sends FallbackReason to all collectors that implement FallbackReasonMetrics
+func (r FallbackMetricsCollection) FallbackReason(ctx context.Context, now time.Time, reason FallbackReason) {
+	for _, c := range r {
+		if frm, ok := c.(FallbackReasonMetrics); ok {
+			frm.FallbackReason(ctx, now, reason)
+		}
+	}
+}
+
 // Var exposes run collectors as expvar
 func (r FallbackMetricsCollection) Var() expvar.Var {
 	return expvar.Func(func() interface{} {
@@ -189,6 +288,24 @@ type RunMetrics interface {
 	ErrShortCircuit(ctx context.Context, now time.Time)
 }
 
+// RunErrorMetrics can optionally be implemented by RunMetrics that want to know the error behind a failure or timeout.
+// RunError is called right after ErrFailure or ErrTimeout, with the error returned by the run function.  If the run
+// function returned nil after the timeout, err is context.DeadlineExceeded.
+type RunErrorMetrics interface {
+	RunError(ctx context.Context, now time.Time, err error)
+}
+
+var _ RunErrorMetrics = RunMetricsCollection(nil)
+
+// RetryAfterMetrics can optionally be implemented by RunMetrics, usually an OpenToClosed, that want to respect a
+// dependency's own backoff guidance.  RetryAfter is called right after ErrFailure or ErrTimeout when the error has a
+// RetryAfter hint.  See ExecutionConfig.RetryAfterClassifier.
+type RetryAfterMetrics interface {
+	RetryAfter(ctx context.Context, now time.Time, after time.Duration)
+}
+
+var _ RetryAfterMetrics = RunMetricsCollection(nil)
+
 // FallbackMetrics is guaranteed to execute one (and only one) of the following functions each time a fallback is executed.
 // Methods with durations are when the fallback is actually executed.  Methods without durations are when the fallback was
 // never called, probably because of some circuit condition.
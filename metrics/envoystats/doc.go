@@ -0,0 +1,6 @@
+/*
+Package envoystats names circuit stats the way Envoy names the stats of an upstream cluster, like
+cluster.<name>.upstream_rq_5xx.  Dashboards and alerts written for a service mesh then cover circuits inside the
+process too, with no new rules.  Send the stats to statsd, or whatever Envoy's stats go to, with a Sink.
+*/
+package envoystats
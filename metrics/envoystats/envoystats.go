@@ -0,0 +1,162 @@
+package envoystats
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Sink receives stats.  Names are full Envoy stat names, like cluster.db.upstream_rq_5xx.
+type Sink interface {
+	// Counter adds delta to a counter
+	Counter(name string, delta int64)
+	// Gauge sets a gauge
+	Gauge(name string, value float64)
+	// Timing records a duration in a histogram
+	Timing(name string, d time.Duration)
+}
+
+// The stats of a cluster, named as Envoy names them.  Runs that fail count as 5xx, and bad requests as 4xx.  A timeout
+// counts as both upstream_rq_timeout and upstream_rq_5xx, as Envoy answers a timeout with a 504.
+const (
+	// UpstreamRqTotal counts runs that were called
+	UpstreamRqTotal = "upstream_rq_total"
+	// UpstreamRqCompleted counts runs that returned, in time or not
+	UpstreamRqCompleted = "upstream_rq_completed"
+	UpstreamRq2xx       = "upstream_rq_2xx"
+	UpstreamRq4xx       = "upstream_rq_4xx"
+	UpstreamRq5xx       = "upstream_rq_5xx"
+	UpstreamRqTimeout   = "upstream_rq_timeout"
+	// UpstreamRqCancelled counts runs that ended because their context ended
+	UpstreamRqCancelled = "upstream_rq_cancelled"
+	// UpstreamRqPendingOverflow counts runs rejected by a concurrency limit
+	UpstreamRqPendingOverflow = "upstream_rq_pending_overflow"
+	// UpstreamCxNoneHealthy counts runs rejected because the circuit is open
+	UpstreamCxNoneHealthy = "upstream_cx_none_healthy"
+	UpstreamRqRetry       = "upstream_rq_retry"
+	// UpstreamRqRetryOverflow counts retries rejected by the retry budget
+	UpstreamRqRetryOverflow = "upstream_rq_retry_overflow"
+	// UpstreamRqTime is how long runs took
+	UpstreamRqTime = "upstream_rq_time"
+	// EjectionsActive is a gauge that is 1 while the circuit is open
+	EjectionsActive = "outlier_detection.ejections_active"
+	// EjectionsTotal counts the times the circuit opened
+	EjectionsTotal = "outlier_detection.ejections_total"
+)
+
+// clusterNameReplacer makes a circuit name a valid cluster name.  Envoy replaces ':' in stat names, since statsd uses
+// it as a separator.
+var clusterNameReplacer = strings.NewReplacer(":", "_")
+
+// StatName returns the full Envoy name of a stat of the cluster for a circuit, like cluster.db.upstream_rq_5xx
+func StatName(circuitName string, stat string) string {
+	return "cluster." + clusterNameReplacer.Replace(circuitName) + "." + stat
+}
+
+// Factory sends the stats of circuits to a Sink, with Envoy's names
+type Factory struct {
+	Sink Sink
+	// ClusterName, if set, picks the Envoy cluster name of a circuit, for example to match the cluster the mesh uses
+	// for the same dependency.  The default is the circuit name.
+	ClusterName func(circuitName string) string
+}
+
+// CommandProperties appends Envoy named stats to a circuit
+func (f *Factory) CommandProperties(circuitName string) circuit.Config {
+	cluster := circuitName
+	if f.ClusterName != nil {
+		cluster = f.ClusterName(circuitName)
+	}
+	s := newClusterStats(f.Sink, cluster)
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Run:     []circuit.RunMetrics{s},
+			Circuit: []circuit.Metrics{s},
+		},
+	}
+}
+
+// clusterStats sends the stats of one circuit.  Names are built once, so sending a stat does not allocate.
+type clusterStats struct {
+	sink  Sink
+	names map[string]string
+}
+
+var _ circuit.RunMetrics = &clusterStats{}
+var _ circuit.RetryMetrics = &clusterStats{}
+var _ circuit.Metrics = &clusterStats{}
+
+func newClusterStats(sink Sink, cluster string) *clusterStats {
+	ret := &clusterStats{
+		sink:  sink,
+		names: make(map[string]string),
+	}
+	for _, stat := range []string{
+		UpstreamRqTotal, UpstreamRqCompleted, UpstreamRq2xx, UpstreamRq4xx, UpstreamRq5xx, UpstreamRqTimeout,
+		UpstreamRqCancelled, UpstreamRqPendingOverflow, UpstreamCxNoneHealthy, UpstreamRqRetry, UpstreamRqRetryOverflow,
+		UpstreamRqTime, EjectionsActive, EjectionsTotal,
+	} {
+		ret.names[stat] = StatName(cluster, stat)
+	}
+	return ret
+}
+
+func (s *clusterStats) inc(stats ...string) {
+	for _, stat := range stats {
+		s.sink.Counter(s.names[stat], 1)
+	}
+}
+
+// completed counts a run that was called and returned
+func (s *clusterStats) completed(duration time.Duration, stats ...string) {
+	s.inc(UpstreamRqTotal, UpstreamRqCompleted)
+	s.inc(stats...)
+	s.sink.Timing(s.names[UpstreamRqTime], duration)
+}
+
+func (s *clusterStats) Success(_ context.Context, _ time.Time, duration time.Duration) {
+	s.completed(duration, UpstreamRq2xx)
+}
+
+func (s *clusterStats) ErrFailure(_ context.Context, _ time.Time, duration time.Duration) {
+	s.completed(duration, UpstreamRq5xx)
+}
+
+func (s *clusterStats) ErrTimeout(_ context.Context, _ time.Time, duration time.Duration) {
+	s.completed(duration, UpstreamRqTimeout, UpstreamRq5xx)
+}
+
+func (s *clusterStats) ErrBadRequest(_ context.Context, _ time.Time, duration time.Duration) {
+	s.completed(duration, UpstreamRq4xx)
+}
+
+func (s *clusterStats) ErrInterrupt(_ context.Context, _ time.Time, _ time.Duration) {
+	s.inc(UpstreamRqTotal, UpstreamRqCancelled)
+}
+
+func (s *clusterStats) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {
+	s.inc(UpstreamRqPendingOverflow)
+}
+
+func (s *clusterStats) ErrShortCircuit(_ context.Context, _ time.Time) {
+	s.inc(UpstreamCxNoneHealthy)
+}
+
+func (s *clusterStats) Retry(_ context.Context, _ time.Time) {
+	s.inc(UpstreamRqRetry)
+}
+
+func (s *clusterStats) ErrRetryBudgetExhausted(_ context.Context, _ time.Time) {
+	s.inc(UpstreamRqRetryOverflow)
+}
+
+func (s *clusterStats) Opened(_ context.Context, _ time.Time) {
+	s.inc(EjectionsTotal)
+	s.sink.Gauge(s.names[EjectionsActive], 1)
+}
+
+func (s *clusterStats) Closed(_ context.Context, _ time.Time) {
+	s.sink.Gauge(s.names[EjectionsActive], 0)
+}
@@ -0,0 +1,113 @@
+package envoystats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/collectorbudget"
+)
+
+type recordingSink struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	gauges   map[string]float64
+	timings  map[string]int
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{
+		counters: make(map[string]int64),
+		gauges:   make(map[string]float64),
+		timings:  make(map[string]int),
+	}
+}
+
+func (r *recordingSink) Counter(name string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += delta
+}
+
+func (r *recordingSink) Gauge(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = value
+}
+
+func (r *recordingSink) Timing(name string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings[name]++
+}
+
+func TestFactory(t *testing.T) {
+	sink := newRecordingSink()
+	f := Factory{Sink: sink}
+	c := circuit.NewCircuitFromConfig("payments:charge", f.CommandProperties("payments:charge"))
+	ctx := context.Background()
+	_ = c.Execute(ctx, func(_ context.Context) error { return nil }, nil)
+	_ = c.Execute(ctx, func(_ context.Context) error { return errors.New("bad") }, nil)
+	_ = c.Execute(ctx, func(_ context.Context) error {
+		return circuit.SimpleBadRequest{Err: errors.New("bad input")}
+	}, nil)
+	c.OpenCircuit(ctx)
+	_ = c.Execute(ctx, func(_ context.Context) error { return nil }, nil)
+
+	expected := map[string]int64{
+		"cluster.payments_charge.upstream_rq_total":                 3,
+		"cluster.payments_charge.upstream_rq_completed":             3,
+		"cluster.payments_charge.upstream_rq_2xx":                   1,
+		"cluster.payments_charge.upstream_rq_5xx":                   1,
+		"cluster.payments_charge.upstream_rq_4xx":                   1,
+		"cluster.payments_charge.upstream_cx_none_healthy":          1,
+		"cluster.payments_charge.outlier_detection.ejections_total": 1,
+	}
+	for name, count := range expected {
+		if sink.counters[name] != count {
+			t.Errorf("expected %s to be %d, got %d", name, count, sink.counters[name])
+		}
+	}
+	if sink.timings["cluster.payments_charge.upstream_rq_time"] != 3 {
+		t.Errorf("expected 3 timings, got %v", sink.timings)
+	}
+	if sink.gauges["cluster.payments_charge.outlier_detection.ejections_active"] != 1 {
+		t.Errorf("expected the circuit to be ejected, got %v", sink.gauges)
+	}
+}
+
+func TestFactory_ClusterName(t *testing.T) {
+	sink := newRecordingSink()
+	f := Factory{
+		Sink: sink,
+		ClusterName: func(circuitName string) string {
+			return "outbound|443||" + circuitName
+		},
+	}
+	c := circuit.NewCircuitFromConfig("db", f.CommandProperties("db"))
+	_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+	if sink.counters["cluster.outbound|443||db.upstream_rq_2xx"] != 1 {
+		t.Errorf("expected stats under the cluster name, got %v", sink.counters)
+	}
+}
+
+type discardSink struct{}
+
+func (discardSink) Counter(string, int64)        {}
+func (discardSink) Gauge(string, float64)        {}
+func (discardSink) Timing(string, time.Duration) {}
+
+func TestFactory_Budget(t *testing.T) {
+	f := Factory{Sink: discardSink{}}
+	collectorbudget.Check(t, f.CommandProperties(""), collectorbudget.Budget{
+		Overhead: time.Microsecond,
+	})
+}
+
+func BenchmarkFactory(b *testing.B) {
+	f := Factory{Sink: discardSink{}}
+	collectorbudget.Benchmark(b, f.CommandProperties(""))
+}
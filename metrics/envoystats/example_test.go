@@ -0,0 +1,39 @@
+package envoystats_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/metrics/envoystats"
+)
+
+type printSink struct{}
+
+func (printSink) Counter(name string, delta int64) {
+	fmt.Println(name, delta)
+}
+
+func (printSink) Gauge(name string, value float64) {
+	fmt.Println(name, value)
+}
+
+func (printSink) Timing(_ string, _ time.Duration) {}
+
+// This example sends circuit stats with the names Envoy gives the stats of the same dependency.  Use a Sink that sends
+// to statsd, or wherever your mesh's stats go.
+func ExampleFactory() {
+	f := envoystats.Factory{
+		Sink: printSink{},
+	}
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.CommandProperties},
+	}
+	c := h.MustCreateCircuit("db")
+	_ = c.Execute(context.Background(), func(_ context.Context) error { return nil }, nil)
+	// Output:
+	// cluster.db.upstream_rq_total 1
+	// cluster.db.upstream_rq_completed 1
+	// cluster.db.upstream_rq_2xx 1
+}
@@ -0,0 +1,20 @@
+package responsetimeslo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/internal/collectorbudget"
+)
+
+func TestFactory_Budget(t *testing.T) {
+	f := Factory{}
+	collectorbudget.Check(t, f.CommandProperties(""), collectorbudget.Budget{
+		Overhead: time.Microsecond,
+	})
+}
+
+func BenchmarkFactory(b *testing.B) {
+	f := Factory{}
+	collectorbudget.Benchmark(b, f.CommandProperties(""))
+}
@@ -0,0 +1,170 @@
+package responsetimeslo
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// BurnRateAlert fires when the SLO's error budget is being spent too fast.  It follows the multiwindow, multi-burn-rate
+// alerts of https://sre.google/workbook/alerting-on-slos/: the alert fires only while both Window and ShortWindow
+// spend the budget fast enough, so it fires quickly and stops soon after the problem does.
+type BurnRateAlert struct {
+	// Name tells alerts apart, like "page" or "ticket"
+	Name string
+	// BudgetSpent is the part of Config.BudgetPeriod's error budget that, spent inside Window, fires the alert.  For
+	// example 0.02 fires when 2% of a month's budget is spent in an hour.
+	BudgetSpent float64
+	// Window is the long window the budget is measured over, like an hour
+	Window time.Duration
+	// ShortWindow must also be spending the budget fast enough for the alert to fire.  The default is Window/12.
+	ShortWindow time.Duration
+}
+
+// BurnRate is how many times faster than sustainable the budget must be spent for the alert to fire
+func (b BurnRateAlert) BurnRate(budgetPeriod time.Duration) float64 {
+	if b.Window <= 0 {
+		return 0
+	}
+	return b.BudgetSpent * float64(budgetPeriod) / float64(b.Window)
+}
+
+func (b BurnRateAlert) shortWindow() time.Duration {
+	if b.ShortWindow <= 0 {
+		return b.Window / 12
+	}
+	return b.ShortWindow
+}
+
+// DefaultBurnRateAlerts are the two pages recommended by the SRE workbook: 2% of the budget spent in an hour, and 5%
+// spent in six hours.
+var DefaultBurnRateAlerts = []BurnRateAlert{
+	{Name: "page-fast", BudgetSpent: 0.02, Window: time.Hour},
+	{Name: "page-slow", BudgetSpent: 0.05, Window: 6 * time.Hour},
+}
+
+// BurnRateEvent is sent to collectors that implement AlertCollector when a burn rate alert starts or stops firing
+type BurnRateEvent struct {
+	Alert BurnRateAlert
+	// Firing is true when the alert starts firing, and false when it stops
+	Firing bool
+	// BurnRate and ShortBurnRate are how fast the budget was spent over Window and ShortWindow
+	BurnRate      float64
+	ShortBurnRate float64
+	Time          time.Time
+}
+
+// AlertCollector can optionally be implemented by Collectors that want to know when burn rate alerts fire
+type AlertCollector interface {
+	BurnRateAlert(event BurnRateEvent)
+}
+
+// burnRateEvaluationInterval is how often alerts are checked, at most
+const burnRateEvaluationInterval = time.Second
+
+// burnRateBuckets is how many buckets each alert window is split into
+const burnRateBuckets = 20
+
+// burnRateWindow counts requests that pass and fail the SLO inside a window
+type burnRateWindow struct {
+	passed faststats.RollingCounter
+	failed faststats.RollingCounter
+}
+
+func newBurnRateWindow(window time.Duration, now time.Time) burnRateWindow {
+	return burnRateWindow{
+		passed: faststats.NewRollingCounter(window/burnRateBuckets, burnRateBuckets, now),
+		failed: faststats.NewRollingCounter(window/burnRateBuckets, burnRateBuckets, now),
+	}
+}
+
+// burnRate is how many times faster than allowed by errorBudget failures happened in the window
+func (w *burnRateWindow) burnRate(now time.Time, errorBudget float64) float64 {
+	failed := w.failed.RollingSumAt(now)
+	total := failed + w.passed.RollingSumAt(now)
+	if total == 0 || errorBudget <= 0 {
+		return 0
+	}
+	return float64(failed) / float64(total) / errorBudget
+}
+
+type burnRateState struct {
+	alert    BurnRateAlert
+	burnRate float64
+	long     burnRateWindow
+	short    burnRateWindow
+	firing   atomic.Bool
+}
+
+// burnRates tracks every alert of a Tracker's config
+type burnRates struct {
+	errorBudget    float64
+	states         []*burnRateState
+	lastEvaluation faststats.AtomicInt64
+}
+
+func newBurnRates(config Config, now time.Time) *burnRates {
+	if len(config.BurnRateAlerts) == 0 {
+		return nil
+	}
+	ret := &burnRates{
+		errorBudget: 1 - config.Objective,
+	}
+	for _, alert := range config.BurnRateAlerts {
+		ret.states = append(ret.states, &burnRateState{
+			alert:    alert,
+			burnRate: alert.BurnRate(config.BudgetPeriod),
+			long:     newBurnRateWindow(alert.Window, now),
+			short:    newBurnRateWindow(alert.shortWindow(), now),
+		})
+	}
+	return ret
+}
+
+// record counts a request, and returns the alerts that started or stopped firing
+func (b *burnRates) record(now time.Time, failed bool) []BurnRateEvent {
+	for _, s := range b.states {
+		if failed {
+			s.long.failed.Inc(now)
+			s.short.failed.Inc(now)
+		} else {
+			s.long.passed.Inc(now)
+			s.short.passed.Inc(now)
+		}
+	}
+	lastEvaluation := b.lastEvaluation.Get()
+	if now.UnixNano()-lastEvaluation < burnRateEvaluationInterval.Nanoseconds() || !b.lastEvaluation.CompareAndSwap(lastEvaluation, now.UnixNano()) {
+		return nil
+	}
+	var events []BurnRateEvent
+	for _, s := range b.states {
+		long := s.long.burnRate(now, b.errorBudget)
+		short := s.short.burnRate(now, b.errorBudget)
+		firing := long >= s.burnRate && short >= s.burnRate
+		if s.firing.CompareAndSwap(!firing, firing) {
+			events = append(events, BurnRateEvent{
+				Alert:         s.alert,
+				Firing:        firing,
+				BurnRate:      long,
+				ShortBurnRate: short,
+				Time:          now,
+			})
+		}
+	}
+	return events
+}
+
+// firing returns the names of the alerts firing
+func (b *burnRates) firing() []string {
+	if b == nil {
+		return nil
+	}
+	var ret []string
+	for _, s := range b.states {
+		if s.firing.Load() {
+			ret = append(ret, s.alert.Name)
+		}
+	}
+	return ret
+}
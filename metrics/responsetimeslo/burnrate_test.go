@@ -0,0 +1,74 @@
+package responsetimeslo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type alertCollector struct {
+	events []BurnRateEvent
+}
+
+func (a *alertCollector) Failed() {}
+func (a *alertCollector) Passed() {}
+
+func (a *alertCollector) BurnRateAlert(event BurnRateEvent) {
+	a.events = append(a.events, event)
+}
+
+func TestBurnRateAlert_BurnRate(t *testing.T) {
+	for _, alert := range DefaultBurnRateAlerts {
+		rate := alert.BurnRate(30 * 24 * time.Hour)
+		if alert.Name == "page-fast" && rate != 14.4 {
+			t.Errorf("expected a burn rate of 14.4, got %v", rate)
+		}
+		if alert.Name == "page-slow" && rate != 6 {
+			t.Errorf("expected a burn rate of 6, got %v", rate)
+		}
+	}
+}
+
+func TestTracker_BurnRateAlerts(t *testing.T) {
+	ctx := context.Background()
+	collector := &alertCollector{}
+	r := &Tracker{Collectors: []Collector{collector}}
+	config := Config{BurnRateAlerts: DefaultBurnRateAlerts}
+	config.Merge(defaultConfig)
+	r.SetConfigThreadSafe(config)
+
+	now := time.Now()
+	for i := 0; i < 90; i++ {
+		r.Success(ctx, now, time.Millisecond)
+	}
+	// 10% errors spends the budget 10 times too fast: enough for the slow page, but not the fast one
+	for i := 0; i < 10; i++ {
+		r.ErrFailure(ctx, now, time.Millisecond)
+	}
+	now = now.Add(time.Second)
+	r.Success(ctx, now, time.Millisecond)
+	if len(collector.events) != 1 || collector.events[0].Alert.Name != "page-slow" || !collector.events[0].Firing {
+		t.Fatalf("expected the slow page to fire, got %+v", collector.events)
+	}
+	if firing := r.FiringAlerts(); len(firing) != 1 || firing[0] != "page-slow" {
+		t.Errorf("expected the slow page to be firing, got %v", firing)
+	}
+
+	// Once the short window has no errors, the alert stops, even though the long window still has them
+	now = now.Add(31 * time.Minute)
+	r.Success(ctx, now, time.Millisecond)
+	if len(collector.events) != 2 || collector.events[1].Firing {
+		t.Fatalf("expected the slow page to stop, got %+v", collector.events)
+	}
+	if firing := r.FiringAlerts(); len(firing) != 0 {
+		t.Errorf("expected no alerts firing, got %v", firing)
+	}
+}
+
+func TestTracker_noBurnRateAlerts(t *testing.T) {
+	r := &Tracker{}
+	r.ErrFailure(context.Background(), time.Now(), time.Millisecond)
+	if firing := r.FiringAlerts(); len(firing) != 0 {
+		t.Errorf("expected no alerts without config, got %v", firing)
+	}
+}
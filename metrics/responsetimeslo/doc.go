@@ -1,4 +1,5 @@
 /*
-Package responsetimeslo contains a MetricsCollector that tracks a SLO metric for circuits.
+Package responsetimeslo contains a MetricsCollector that tracks a SLO metric for circuits.  It can also fire
+multiwindow burn rate alerts when the SLO's error budget is spent too fast.
 */
 package responsetimeslo
@@ -4,6 +4,7 @@ import (
 	"context"
 	"expvar"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cep21/circuit/v4"
@@ -25,18 +26,28 @@ type Tracker struct {
 	FailsSLOCount      faststats.AtomicInt64
 	Collectors         []Collector
 
-	mu     sync.Mutex
-	config Config
+	mu        sync.Mutex
+	config    Config
+	burnRates atomic.Pointer[burnRates]
 }
 
 // Config controls how SLO is tracked by default for a Tracker
 type Config struct {
 	// MaximumHealthyTime is the maximum amount of time a request can take and still be considered healthy
 	MaximumHealthyTime time.Duration
+	// Objective is the fraction of requests that should pass the SLO, like 0.99.  One minus it is the error budget.
+	Objective float64
+	// BudgetPeriod is how long the error budget is for, like 30 days
+	BudgetPeriod time.Duration
+	// BurnRateAlerts fire when the error budget is spent too fast.  Collectors that implement AlertCollector are told
+	// when each starts and stops firing.  See DefaultBurnRateAlerts.
+	BurnRateAlerts []BurnRateAlert
 }
 
 var defaultConfig = Config{
 	MaximumHealthyTime: time.Millisecond * 250,
+	Objective:          0.99,
+	BudgetPeriod:       30 * 24 * time.Hour,
 }
 
 // Merge this configuration with another, changing any values that are non zero into other's value
@@ -44,6 +55,15 @@ func (c *Config) Merge(other Config) {
 	if c.MaximumHealthyTime == 0 {
 		c.MaximumHealthyTime = other.MaximumHealthyTime
 	}
+	if c.Objective == 0 {
+		c.Objective = other.Objective
+	}
+	if c.BudgetPeriod == 0 {
+		c.BudgetPeriod = other.BudgetPeriod
+	}
+	if len(c.BurnRateAlerts) == 0 {
+		c.BurnRateAlerts = other.BurnRateAlerts
+	}
 }
 
 // Factory creates SLO monitors for a circuit
@@ -89,60 +109,82 @@ func (r *Factory) CommandProperties(circuitName string) circuit.Config {
 func (r *Tracker) Var() expvar.Var {
 	return expvar.Func(func() interface{} {
 		return map[string]interface{}{
-			"config": r.Config(),
-			"pass":   r.MeetsSLOCount.Get(),
-			"fail":   r.FailsSLOCount.Get(),
+			"config":        r.Config(),
+			"pass":          r.MeetsSLOCount.Get(),
+			"fail":          r.FailsSLOCount.Get(),
+			"alerts_firing": r.FiringAlerts(),
 		}
 	})
 }
 
 // Success adds a healthy check if duration <= maximum healthy time
-func (r *Tracker) Success(_ context.Context, _ time.Time, duration time.Duration) {
+func (r *Tracker) Success(_ context.Context, now time.Time, duration time.Duration) {
 	if duration.Nanoseconds() <= r.MaximumHealthyTime.Get() {
-		r.healthy()
+		r.healthy(now)
 		return
 	}
-	r.failure()
+	r.failure(now)
 }
 
-func (r *Tracker) failure() {
+func (r *Tracker) failure(now time.Time) {
 	r.FailsSLOCount.Add(1)
 	for _, c := range r.Collectors {
 		c.Failed()
 	}
+	r.recordBurn(now, true)
 }
 
-func (r *Tracker) healthy() {
+func (r *Tracker) healthy(now time.Time) {
 	r.MeetsSLOCount.Add(1)
 	for _, c := range r.Collectors {
 		c.Passed()
 	}
+	r.recordBurn(now, false)
+}
+
+func (r *Tracker) recordBurn(now time.Time, failed bool) {
+	b := r.burnRates.Load()
+	if b == nil {
+		return
+	}
+	for _, event := range b.record(now, failed) {
+		for _, c := range r.Collectors {
+			if ac, ok := c.(AlertCollector); ok {
+				ac.BurnRateAlert(event)
+			}
+		}
+	}
+}
+
+// FiringAlerts returns the names of the burn rate alerts firing now
+func (r *Tracker) FiringAlerts() []string {
+	return r.burnRates.Load().firing()
 }
 
 // ErrFailure is always a failure
-func (r *Tracker) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {
-	r.failure()
+func (r *Tracker) ErrFailure(_ context.Context, now time.Time, _ time.Duration) {
+	r.failure(now)
 }
 
 // ErrTimeout is always a failure
-func (r *Tracker) ErrTimeout(_ context.Context, _ time.Time, _ time.Duration) {
-	r.failure()
+func (r *Tracker) ErrTimeout(_ context.Context, now time.Time, _ time.Duration) {
+	r.failure(now)
 }
 
 // ErrConcurrencyLimitReject is always a failure
-func (r *Tracker) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {
+func (r *Tracker) ErrConcurrencyLimitReject(_ context.Context, now time.Time) {
 	// Your endpoint could be healthy, but because we can't process commands fast enough, you're considered unhealthy.
 	// This one could honestly go either way, but generally if a service cannot process commands fast enough, it's not
 	// doing what you want.
-	r.failure()
+	r.failure(now)
 }
 
 // ErrShortCircuit is always a failure
-func (r *Tracker) ErrShortCircuit(_ context.Context, _ time.Time) {
+func (r *Tracker) ErrShortCircuit(_ context.Context, now time.Time) {
 	// We had to end the request early.  It's possible the endpoint we want is healthy, but because we had to trip
 	// our circuit, due to past misbehavior, it is still end endpoint's fault we cannot satisfy this request, so it
 	// fails the SLO.
-	r.failure()
+	r.failure(now)
 }
 
 // ErrBadRequest is ignored
@@ -154,6 +196,7 @@ func (r *Tracker) SetConfigThreadSafe(config Config) {
 	defer r.mu.Unlock()
 	r.config = config
 	r.MaximumHealthyTime.Set(config.MaximumHealthyTime.Nanoseconds())
+	r.burnRates.Store(newBurnRates(config, time.Now()))
 }
 
 // Config returns the tracker's config
@@ -164,10 +207,10 @@ func (r *Tracker) Config() Config {
 }
 
 // ErrInterrupt is only a failure if healthy time has passed
-func (r *Tracker) ErrInterrupt(_ context.Context, _ time.Time, duration time.Duration) {
+func (r *Tracker) ErrInterrupt(_ context.Context, now time.Time, duration time.Duration) {
 	// If it is interrupted, but past the healthy time.  Then it is as good as unhealthy
 	if duration.Nanoseconds() > r.MaximumHealthyTime.Get() {
-		r.failure()
+		r.failure(now)
 	}
 	// Cannot consider this value healthy, since it didn't return
 }
@@ -0,0 +1,148 @@
+package rolling
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// AdaptiveTimeout keeps the timeouts of a Manager's circuits close to how long their runs really take.  Every Interval
+// it sets each circuit's timeout to a percentile of its recent latencies times a multiplier, for example p99 * 1.5,
+// clamped between MinTimeout and MaxTimeout.  This stops timeouts in config from going stale as dependencies get faster
+// or slower.  Latencies come from RunStats, so circuits without them are not changed.
+//
+// Timed out runs record a latency close to the timeout, so a hanging dependency would keep raising its own timeout.
+// A timeout is never raised while the circuit has timed out runs in its rolling window.
+type AdaptiveTimeout struct {
+	Manager *circuit.Manager
+	Config  AdaptiveTimeoutConfig
+	// Filter, if set, picks which circuits are adjusted.  The default is every circuit with RunStats.
+	Filter func(c *circuit.Circuit) bool
+
+	closeChan chan struct{}
+	once      sync.Once
+}
+
+// AdaptiveTimeoutConfig configures AdaptiveTimeout
+type AdaptiveTimeoutConfig struct {
+	// Percentile of recent latencies the timeout is based on
+	Percentile float64
+	// Multiplier gives runs room over Percentile
+	Multiplier float64
+	// MinTimeout is the shortest timeout that is set
+	MinTimeout time.Duration
+	// MaxTimeout is the longest timeout that is set
+	MaxTimeout time.Duration
+	// MinSamples is how many recent latencies a circuit needs before its timeout is changed
+	MinSamples int
+	// Interval is how often timeouts are recomputed
+	Interval time.Duration
+}
+
+// Merge this config with another
+func (a *AdaptiveTimeoutConfig) Merge(other AdaptiveTimeoutConfig) {
+	if a.Percentile == 0 {
+		a.Percentile = other.Percentile
+	}
+	if a.Multiplier == 0 {
+		a.Multiplier = other.Multiplier
+	}
+	if a.MinTimeout == 0 {
+		a.MinTimeout = other.MinTimeout
+	}
+	if a.MaxTimeout == 0 {
+		a.MaxTimeout = other.MaxTimeout
+	}
+	if a.MinSamples == 0 {
+		a.MinSamples = other.MinSamples
+	}
+	if a.Interval == 0 {
+		a.Interval = other.Interval
+	}
+}
+
+var defaultAdaptiveTimeoutConfig = AdaptiveTimeoutConfig{
+	Percentile: 99,
+	Multiplier: 1.5,
+	MinTimeout: 10 * time.Millisecond,
+	MaxTimeout: 30 * time.Second,
+	MinSamples: 100,
+	Interval:   10 * time.Second,
+}
+
+func (a *AdaptiveTimeout) doOnce() {
+	a.closeChan = make(chan struct{})
+}
+
+func (a *AdaptiveTimeout) config() AdaptiveTimeoutConfig {
+	cfg := a.Config
+	cfg.Merge(defaultAdaptiveTimeoutConfig)
+	return cfg
+}
+
+// Start should be called once per AdaptiveTimeout.  It adjusts timeouts every Interval until Close is called.
+func (a *AdaptiveTimeout) Start() error {
+	a.once.Do(a.doOnce)
+	for {
+		select {
+		case <-time.After(a.config().Interval):
+			a.Adjust()
+		case <-a.closeChan:
+			return nil
+		}
+	}
+}
+
+// Close ends the Start function
+func (a *AdaptiveTimeout) Close() error {
+	a.once.Do(a.doOnce)
+	close(a.closeChan)
+	return nil
+}
+
+// Adjust sets the timeout of every circuit once.  Start calls it every Interval.
+func (a *AdaptiveTimeout) Adjust() {
+	cfg := a.config()
+	for _, c := range a.Manager.AllCircuits() {
+		if a.Filter != nil && !a.Filter(c) {
+			continue
+		}
+		timeout, ok := a.timeoutFor(c, cfg)
+		if !ok {
+			continue
+		}
+		current := c.Config()
+		if current.Execution.Timeout == timeout {
+			continue
+		}
+		current.Execution.Timeout = timeout
+		c.SetConfigFrom("adaptive-timeout", current)
+	}
+}
+
+// timeoutFor computes the timeout of a circuit.  ok is false if there are not enough latencies to compute it, or if
+// the timeout would go up while runs are timing out.
+func (a *AdaptiveTimeout) timeoutFor(c *circuit.Circuit, cfg AdaptiveTimeoutConfig) (timeout time.Duration, ok bool) {
+	stats := FindCommandMetrics(c)
+	if stats == nil {
+		return 0, false
+	}
+	current := c.Config()
+	now := current.General.TimeKeeper.Now()
+	snap := stats.Latencies.SnapshotAt(now)
+	if len(snap) == 0 || len(snap) < cfg.MinSamples {
+		return 0, false
+	}
+	timeout = time.Duration(float64(snap.Percentile(cfg.Percentile)) * cfg.Multiplier)
+	if timeout < cfg.MinTimeout {
+		timeout = cfg.MinTimeout
+	}
+	if timeout > cfg.MaxTimeout {
+		timeout = cfg.MaxTimeout
+	}
+	if timeout > current.Execution.Timeout && stats.ErrTimeouts.RollingSumAt(now) > 0 {
+		return 0, false
+	}
+	return timeout, true
+}
@@ -0,0 +1,111 @@
+package rolling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	sf := StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("TestAdaptiveTimeout", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			Timeout: time.Second,
+		},
+	})
+	m.MustCreateCircuit("no-stats", circuit.Config{})
+	a := AdaptiveTimeout{
+		Manager: m,
+		Config: AdaptiveTimeoutConfig{
+			MinSamples: 10,
+			MaxTimeout: 5 * time.Second,
+		},
+	}
+	record := func(count int, latency time.Duration) {
+		now := time.Now()
+		for i := 0; i < count; i++ {
+			sf.RunStats(c.Name()).Success(context.Background(), now, latency)
+		}
+	}
+
+	record(5, 100*time.Millisecond)
+	a.Adjust()
+	if timeout := c.Config().Execution.Timeout; timeout != time.Second {
+		t.Errorf("expected no change without enough samples, got %s", timeout)
+	}
+	record(5, 100*time.Millisecond)
+	a.Adjust()
+	if timeout := c.Config().Execution.Timeout; timeout != 150*time.Millisecond {
+		t.Errorf("expected p99 * 1.5, got %s", timeout)
+	}
+	record(100, time.Minute)
+	a.Adjust()
+	if timeout := c.Config().Execution.Timeout; timeout != 5*time.Second {
+		t.Errorf("expected the timeout to be clamped to MaxTimeout, got %s", timeout)
+	}
+
+	a.Filter = func(c *circuit.Circuit) bool {
+		return false
+	}
+	cfg := c.Config()
+	cfg.Execution.Timeout = time.Second
+	c.SetConfigThreadSafe(cfg)
+	a.Adjust()
+	if timeout := c.Config().Execution.Timeout; timeout != time.Second {
+		t.Errorf("expected filtered circuits to be left alone, got %s", timeout)
+	}
+}
+
+func TestAdaptiveTimeout_StartClose(t *testing.T) {
+	a := AdaptiveTimeout{
+		Manager: &circuit.Manager{},
+		Config: AdaptiveTimeoutConfig{
+			Interval: time.Millisecond,
+		},
+	}
+	done := make(chan error)
+	go func() {
+		done <- a.Start()
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdaptiveTimeout_timeoutsDoNotRaise(t *testing.T) {
+	sf := StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("TestAdaptiveTimeout_timeoutsDoNotRaise", circuit.Config{
+		Execution: circuit.ExecutionConfig{
+			Timeout: 20 * time.Millisecond,
+		},
+	})
+	a := AdaptiveTimeout{
+		Manager: m,
+		Config: AdaptiveTimeoutConfig{
+			MinSamples: 10,
+		},
+	}
+	for i := 0; i < 5; i++ {
+		// A hanging dependency: every run takes a little over the current timeout
+		timeout := c.Config().Execution.Timeout
+		for j := 0; j < 10; j++ {
+			sf.RunStats(c.Name()).ErrTimeout(context.Background(), time.Now(), timeout+time.Millisecond)
+		}
+		a.Adjust()
+		if timeout := c.Config().Execution.Timeout; timeout != 20*time.Millisecond {
+			t.Fatalf("expected timeouts not to raise the timeout, got %s", timeout)
+		}
+	}
+}
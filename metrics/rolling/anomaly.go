@@ -0,0 +1,225 @@
+package rolling
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// AnomalyMetric is what an Anomaly was found in
+type AnomalyMetric string
+
+const (
+	// AnomalyErrorRate is the fraction of runs in an interval that failed or timed out
+	AnomalyErrorRate AnomalyMetric = "error_rate"
+	// AnomalyLatency is the mean latency of recent runs, in seconds
+	AnomalyLatency AnomalyMetric = "latency"
+)
+
+// Anomaly is a circuit metric that is unusually high compared to what the circuit normally sees
+type Anomaly struct {
+	Circuit string
+	Time    time.Time
+	Metric  AnomalyMetric
+	Value   float64
+	// Baseline is the average the circuit learned before this value
+	Baseline float64
+	// Stddev is the standard deviation the circuit learned before this value
+	Stddev float64
+}
+
+// AnomalyDetector learns the usual error rate and latency of each circuit, as a moving average and standard deviation,
+// and reports values that are far above them.  It finds slow degradations before they are bad enough to open a
+// circuit.  Stats come from RunStats, so circuits without them are not checked.
+type AnomalyDetector struct {
+	Manager *circuit.Manager
+	Config  AnomalyDetectorConfig
+	// OnAnomaly is called for every anomaly found
+	OnAnomaly func(a Anomaly)
+	// Filter, if set, picks which circuits are checked.  The default is every circuit with RunStats.
+	Filter func(c *circuit.Circuit) bool
+
+	mu        sync.Mutex
+	baselines map[string]*circuitBaseline
+
+	closeChan chan struct{}
+	once      sync.Once
+}
+
+// AnomalyDetectorConfig configures AnomalyDetector
+type AnomalyDetectorConfig struct {
+	// Alpha is how much each interval moves the baseline, between 0 and 1.  Lower values remember further back.
+	Alpha float64
+	// Threshold is how many standard deviations above the baseline a value must be to be an anomaly
+	Threshold float64
+	// WarmUp is how many intervals a baseline learns from before it reports anomalies
+	WarmUp int
+	// MinRequests is how many runs an interval needs for its error rate to count
+	MinRequests int64
+	// MinErrorRateStddev is the smallest standard deviation used for error rates, so a circuit that never fails does
+	// not report a single failure as an anomaly
+	MinErrorRateStddev float64
+	// MinLatencyStddev is the smallest standard deviation used for latency
+	MinLatencyStddev time.Duration
+	// Interval is how often circuits are checked
+	Interval time.Duration
+}
+
+// Merge this config with another
+func (a *AnomalyDetectorConfig) Merge(other AnomalyDetectorConfig) {
+	if a.Alpha == 0 {
+		a.Alpha = other.Alpha
+	}
+	if a.Threshold == 0 {
+		a.Threshold = other.Threshold
+	}
+	if a.WarmUp == 0 {
+		a.WarmUp = other.WarmUp
+	}
+	if a.MinRequests == 0 {
+		a.MinRequests = other.MinRequests
+	}
+	if a.MinErrorRateStddev == 0 {
+		a.MinErrorRateStddev = other.MinErrorRateStddev
+	}
+	if a.MinLatencyStddev == 0 {
+		a.MinLatencyStddev = other.MinLatencyStddev
+	}
+	if a.Interval == 0 {
+		a.Interval = other.Interval
+	}
+}
+
+var defaultAnomalyDetectorConfig = AnomalyDetectorConfig{
+	Alpha:              0.1,
+	Threshold:          3,
+	WarmUp:             30,
+	MinRequests:        10,
+	MinErrorRateStddev: 0.01,
+	MinLatencyStddev:   time.Millisecond,
+	Interval:           10 * time.Second,
+}
+
+// circuitBaseline is what AnomalyDetector has learned about one circuit
+type circuitBaseline struct {
+	errorRate ewma
+	latency   ewma
+	// lastRequests and lastErrors are the run totals at the last check, so each check sees only its interval
+	lastRequests int64
+	lastErrors   int64
+}
+
+// ewma is an exponentially weighted moving average and variance
+type ewma struct {
+	mean     float64
+	variance float64
+	samples  int
+}
+
+// add learns x, and returns the mean and standard deviation from before x
+func (e *ewma) add(x float64, alpha float64) (mean float64, stddev float64) {
+	mean, stddev = e.mean, math.Sqrt(e.variance)
+	if e.samples == 0 {
+		e.mean = x
+	} else {
+		diff := x - e.mean
+		incr := alpha * diff
+		e.mean += incr
+		e.variance = (1 - alpha) * (e.variance + diff*incr)
+	}
+	e.samples++
+	return mean, stddev
+}
+
+func (a *AnomalyDetector) doOnce() {
+	a.closeChan = make(chan struct{})
+}
+
+func (a *AnomalyDetector) config() AnomalyDetectorConfig {
+	cfg := a.Config
+	cfg.Merge(defaultAnomalyDetectorConfig)
+	return cfg
+}
+
+// Start should be called once per AnomalyDetector.  It checks circuits every Interval until Close is called.
+func (a *AnomalyDetector) Start() error {
+	a.once.Do(a.doOnce)
+	for {
+		select {
+		case <-time.After(a.config().Interval):
+			a.Check()
+		case <-a.closeChan:
+			return nil
+		}
+	}
+}
+
+// Close ends the Start function
+func (a *AnomalyDetector) Close() error {
+	a.once.Do(a.doOnce)
+	close(a.closeChan)
+	return nil
+}
+
+// Check learns from, and looks for anomalies in, every circuit once.  Start calls it every Interval.
+func (a *AnomalyDetector) Check() {
+	cfg := a.config()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.baselines == nil {
+		a.baselines = make(map[string]*circuitBaseline)
+	}
+	for _, c := range a.Manager.AllCircuits() {
+		if a.Filter != nil && !a.Filter(c) {
+			continue
+		}
+		stats := FindCommandMetrics(c)
+		if stats == nil {
+			continue
+		}
+		b, exists := a.baselines[c.Name()]
+		if !exists {
+			b = &circuitBaseline{}
+			a.baselines[c.Name()] = b
+		}
+		a.checkCircuit(c, stats, b, cfg)
+	}
+}
+
+func (a *AnomalyDetector) checkCircuit(c *circuit.Circuit, stats *RunStats, b *circuitBaseline, cfg AnomalyDetectorConfig) {
+	now := c.Config().General.TimeKeeper.Now()
+	errs := stats.ErrFailures.TotalSum() + stats.ErrTimeouts.TotalSum()
+	requests := errs + stats.Successes.TotalSum()
+	intervalRequests, intervalErrors := requests-b.lastRequests, errs-b.lastErrors
+	b.lastRequests, b.lastErrors = requests, errs
+	if intervalRequests >= cfg.MinRequests {
+		rate := float64(intervalErrors) / float64(intervalRequests)
+		mean, stddev := b.errorRate.add(rate, cfg.Alpha)
+		a.check(c, now, AnomalyErrorRate, rate, mean, stddev, cfg.MinErrorRateStddev, b.errorRate.samples, cfg)
+	}
+	if latencies := stats.Latencies.SnapshotAt(now); len(latencies) != 0 {
+		latency := latencies.Mean().Seconds()
+		mean, stddev := b.latency.add(latency, cfg.Alpha)
+		a.check(c, now, AnomalyLatency, latency, mean, stddev, cfg.MinLatencyStddev.Seconds(), b.latency.samples, cfg)
+	}
+}
+
+// check reports value if it is an anomaly.  samples includes value.
+func (a *AnomalyDetector) check(c *circuit.Circuit, now time.Time, metric AnomalyMetric, value float64, mean float64, stddev float64, minStddev float64, samples int, cfg AnomalyDetectorConfig) {
+	if samples <= cfg.WarmUp || a.OnAnomaly == nil {
+		return
+	}
+	if value <= mean+cfg.Threshold*math.Max(stddev, minStddev) {
+		return
+	}
+	a.OnAnomaly(Anomaly{
+		Circuit:  c.Name(),
+		Time:     now,
+		Metric:   metric,
+		Value:    value,
+		Baseline: mean,
+		Stddev:   stddev,
+	})
+}
@@ -0,0 +1,83 @@
+package rolling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	sf := StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("TestAnomalyDetector")
+	m.MustCreateCircuit("no-stats", circuit.Config{})
+	var anomalies []Anomaly
+	a := AnomalyDetector{
+		Manager: m,
+		Config: AnomalyDetectorConfig{
+			WarmUp: 5,
+		},
+		OnAnomaly: func(a Anomaly) {
+			anomalies = append(anomalies, a)
+		},
+	}
+	record := func(successes int, failures int, latency time.Duration) {
+		ctx := context.Background()
+		now := time.Now()
+		stats := sf.RunStats(c.Name())
+		for i := 0; i < successes; i++ {
+			stats.Success(ctx, now, latency)
+		}
+		for i := 0; i < failures; i++ {
+			stats.ErrFailure(ctx, now, latency)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		record(99, 1, 10*time.Millisecond)
+		a.Check()
+	}
+	if len(anomalies) != 0 {
+		t.Fatalf("expected a steady circuit to have no anomalies, got %+v", anomalies)
+	}
+
+	record(70, 30, 10*time.Millisecond)
+	a.Check()
+	if len(anomalies) != 1 || anomalies[0].Metric != AnomalyErrorRate || anomalies[0].Value != 0.3 || anomalies[0].Circuit != c.Name() {
+		t.Fatalf("expected an error rate anomaly, got %+v", anomalies)
+	}
+	if anomalies[0].Baseline < 0.009 || anomalies[0].Baseline > 0.011 {
+		t.Errorf("expected a baseline of 1%%, got %f", anomalies[0].Baseline)
+	}
+
+	anomalies = nil
+	record(1000, 0, time.Second)
+	a.Check()
+	if len(anomalies) != 1 || anomalies[0].Metric != AnomalyLatency {
+		t.Errorf("expected a latency anomaly, got %+v", anomalies)
+	}
+}
+
+func TestAnomalyDetector_warmUp(t *testing.T) {
+	sf := StatFactory{}
+	m := &circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{sf.CreateConfig},
+	}
+	c := m.MustCreateCircuit("TestAnomalyDetector_warmUp")
+	a := AnomalyDetector{
+		Manager: m,
+		OnAnomaly: func(a Anomaly) {
+			t.Errorf("expected no anomalies while warming up, got %+v", a)
+		},
+	}
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 100; j++ {
+			sf.RunStats(c.Name()).ErrFailure(context.Background(), time.Now(), time.Duration(i)*time.Second)
+		}
+		a.Check()
+	}
+}
@@ -0,0 +1,20 @@
+package rolling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/internal/collectorbudget"
+)
+
+func TestStatFactory_Budget(t *testing.T) {
+	s := StatFactory{}
+	collectorbudget.Check(t, s.CreateConfig(""), collectorbudget.Budget{
+		Overhead: 2 * time.Microsecond,
+	})
+}
+
+func BenchmarkStatFactory(b *testing.B) {
+	s := StatFactory{}
+	collectorbudget.Benchmark(b, s.CreateConfig(""))
+}
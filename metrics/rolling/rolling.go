@@ -95,13 +95,52 @@ type RunStats struct {
 	ErrTimeouts                faststats.RollingCounter
 	ErrBadRequests             faststats.RollingCounter
 	ErrInterrupts              faststats.RollingCounter
+	// Retries and ErrRetryBudgetRejects count how runs marked with circuit.WithRetry used the retry budget
+	Retries               faststats.RollingCounter
+	ErrRetryBudgetRejects faststats.RollingCounter
+	// EndedInGrace and Abandons count what happened to runs started by circuit.Go whose context ended
+	EndedInGrace faststats.RollingCounter
+	Abandons     faststats.RollingCounter
+	// Sized, BytesSent, and BytesReceived count runs whose size was reported with circuit.ReportSize, and the bytes
+	// they sent and received
+	Sized         faststats.RollingCounter
+	BytesSent     faststats.RollingCounter
+	BytesReceived faststats.RollingCounter
 
 	// It is analogous to https://github.com/Netflix/Hystrix/wiki/Metrics-and-Monitoring#latency-percentiles-hystrixcommandrun-execution-gauge
 	Latencies faststats.RollingPercentile
+	// QueueWaits is how long runs waited for a slot, when circuit.ExecutionConfig.MaxQueueSize is set
+	QueueWaits faststats.RollingPercentile
+	// Overheads is how long Execute spent in the circuit itself, outside runFunc and the fallback
+	Overheads faststats.RollingPercentile
+	// Outcomes is an exact log of recent successes, failures, and timeouts.  It is only set if
+	// RunStatsConfig.ExactLogSize is, and is then used for error rates instead of the rolling buckets.
+	Outcomes *faststats.SlidingLog
 
 	mu     sync.Mutex
 	config RunStatsConfig
-}
+	// errorsByCause is only populated if config.ErrorFingerprint is set.  It is protected by mu.
+	errorsByCause map[string]*faststats.RollingCounter
+	// counters are the circuit's custom counters, created the first time each is added to.  It is protected by mu.
+	counters map[string]*faststats.RollingCounter
+	// inheritedRand is the circuit's GeneralConfig.Rand.  It picks the latencies a decaying reservoir keeps.  It is
+	// protected by mu.
+	inheritedRand circuit.Rand
+}
+
+// OtherErrorCause is the cause errors are counted under once RunStatsConfig.MaxErrorCauses different causes are tracked
+const OtherErrorCause = "other"
+
+var _ circuit.RunErrorMetrics = &RunStats{}
+var _ circuit.RetryMetrics = &RunStats{}
+var _ circuit.QueueMetrics = &RunStats{}
+var _ circuit.AbandonMetrics = &RunStats{}
+var _ circuit.OverheadMetrics = &RunStats{}
+var _ circuit.HealthStats = &RunStats{}
+var _ circuit.CounterMetrics = &RunStats{}
+var _ circuit.SizeMetrics = &RunStats{}
+var _ circuit.MemoryReporter = &RunStats{}
+var _ circuit.RandInheritor = &RunStats{}
 
 // Var allows exposing RunStats on expvar
 func (r *RunStats) Var() expvar.Var {
@@ -114,7 +153,25 @@ func (r *RunStats) Var() expvar.Var {
 			"ErrTimeouts":                evar.ForExpvar(&r.ErrTimeouts),
 			"ErrBadRequests":             evar.ForExpvar(&r.ErrBadRequests),
 			"ErrInterrupts":              evar.ForExpvar(&r.ErrInterrupts),
+			"Retries":                    evar.ForExpvar(&r.Retries),
+			"ErrRetryBudgetRejects":      evar.ForExpvar(&r.ErrRetryBudgetRejects),
+			"EndedInGrace":               evar.ForExpvar(&r.EndedInGrace),
+			"Abandons":                   evar.ForExpvar(&r.Abandons),
+			"Sized":                      evar.ForExpvar(&r.Sized),
+			"BytesSent":                  evar.ForExpvar(&r.BytesSent),
+			"BytesReceived":              evar.ForExpvar(&r.BytesReceived),
 			"Latencies":                  evar.ForExpvar(&r.Latencies),
+			"QueueWaits":                 evar.ForExpvar(&r.QueueWaits),
+			"Overheads":                  evar.ForExpvar(&r.Overheads),
+		}
+		if r.Outcomes != nil {
+			ret["Outcomes"] = r.Outcomes
+		}
+		if byCause := r.errorCounters(); len(byCause) != 0 {
+			ret["ErrorsByCause"] = byCause
+		}
+		if counters := r.customCounters(); len(counters) != 0 {
+			ret["Counters"] = counters
 		}
 		return ret
 	})
@@ -134,6 +191,24 @@ type RunStatsConfig struct {
 	RollingPercentileNumBuckets int
 	// RollingPercentileBucketSize is https://github.com/Netflix/Hystrix/wiki/Configuration#metricsrollingpercentilebucketsize
 	RollingPercentileBucketSize int
+	// LatencyDecayAlpha, if set, keeps latencies in a faststats.DecayingReservoir that decays at this rate per second
+	// instead of in rolling buckets.  Percentiles then weight recent runs more smoothly.  0.015 is a common value.
+	LatencyDecayAlpha float64
+	// LatencyReservoirSize is how many latencies the decaying reservoir keeps
+	LatencyReservoirSize int
+	// ErrorFingerprint, if set, groups failures and timeouts by cause (for example "timeout", "connection refused",
+	// "503").  It should return a small set of values, since every cause gets its own rolling counter.
+	ErrorFingerprint func(err error) string
+	// MaxErrorCauses is the most causes tracked by ErrorFingerprint.  Other causes are counted as OtherErrorCause.
+	MaxErrorCauses int
+	// ExactLogSize, if set, logs the time of up to this many recent runs so error rates over RollingStatsDuration are
+	// exact.  It is meant for circuits doing less than a run a second, where a bucket leaving the window moves the
+	// error rate by a large step.  Once more runs than this happen within the window, the buckets are used instead.
+	//
+	// It only changes the error rates RunStats reports, like ErrorPercentageAt and the circuit's Health.  Openers keep
+	// their own counts and do not read RunStats: set hystrix.ConfigureOpener.ExactLogSize to the same value so the
+	// circuit also opens from exact counts.
+	ExactLogSize int
 }
 
 // Merge this config with another
@@ -156,6 +231,21 @@ func (r *RunStatsConfig) Merge(other RunStatsConfig) {
 	if r.RollingPercentileBucketSize == 0 {
 		r.RollingPercentileBucketSize = other.RollingPercentileBucketSize
 	}
+	if r.LatencyDecayAlpha == 0 {
+		r.LatencyDecayAlpha = other.LatencyDecayAlpha
+	}
+	if r.LatencyReservoirSize == 0 {
+		r.LatencyReservoirSize = other.LatencyReservoirSize
+	}
+	if r.ErrorFingerprint == nil {
+		r.ErrorFingerprint = other.ErrorFingerprint
+	}
+	if r.MaxErrorCauses == 0 {
+		r.MaxErrorCauses = other.MaxErrorCauses
+	}
+	if r.ExactLogSize == 0 {
+		r.ExactLogSize = other.ExactLogSize
+	}
 }
 
 var defaultRunStatsConfig = RunStatsConfig{
@@ -165,6 +255,8 @@ var defaultRunStatsConfig = RunStatsConfig{
 	RollingPercentileDuration:   60 * time.Second,
 	RollingPercentileNumBuckets: 6,
 	RollingPercentileBucketSize: 100,
+	LatencyReservoirSize:        1028,
+	MaxErrorCauses:              20,
 }
 
 // Config returns the current configuration
@@ -193,12 +285,45 @@ func (r *RunStats) SetConfigNotThreadSafe(config RunStatsConfig) {
 	r.ErrTimeouts = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
 	r.ErrBadRequests = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
 	r.ErrInterrupts = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
-	r.Latencies = faststats.NewRollingPercentile(rollingPercentileBucketWidth, rollingPercentileNumBuckets, rollingPercentileBucketSize, now)
+	r.Retries = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.ErrRetryBudgetRejects = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.EndedInGrace = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.Abandons = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.Sized = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.BytesSent = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.BytesReceived = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	if config.LatencyDecayAlpha > 0 {
+		r.Latencies = faststats.NewDecayingPercentile(config.LatencyReservoirSize, config.LatencyDecayAlpha, now)
+		if r.inheritedRand != nil {
+			r.Latencies.SetRandFloat64(r.inheritedRand.Float64)
+		}
+	} else {
+		r.Latencies = faststats.NewRollingPercentile(rollingPercentileBucketWidth, rollingPercentileNumBuckets, rollingPercentileBucketSize, now)
+	}
+	r.QueueWaits = faststats.NewRollingPercentile(rollingPercentileBucketWidth, rollingPercentileNumBuckets, rollingPercentileBucketSize, now)
+	r.Overheads = faststats.NewRollingPercentile(rollingPercentileBucketWidth, rollingPercentileNumBuckets, rollingPercentileBucketSize, now)
+	r.Outcomes = nil
+	if config.ExactLogSize > 0 {
+		r.Outcomes = faststats.NewSlidingLog(config.RollingStatsDuration, config.ExactLogSize)
+	}
+	r.errorsByCause = nil
+	r.counters = nil
+}
+
+// InheritRand uses the circuit's Rand to pick the latencies kept when LatencyDecayAlpha is set
+func (r *RunStats) InheritRand(rnd circuit.Rand) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inheritedRand = rnd
+	r.Latencies.SetRandFloat64(rnd.Float64)
 }
 
 // Success increments the Successes bucket
 func (r *RunStats) Success(_ context.Context, now time.Time, duration time.Duration) {
 	r.Successes.Inc(now)
+	if r.Outcomes != nil {
+		r.Outcomes.Success(now)
+	}
 	r.Latencies.AddDuration(duration, now)
 }
 
@@ -216,6 +341,9 @@ func (r *RunStats) ErrConcurrencyLimitReject(_ context.Context, now time.Time) {
 // ErrFailure increments the ErrFailure bucket
 func (r *RunStats) ErrFailure(_ context.Context, now time.Time, duration time.Duration) {
 	r.ErrFailures.Inc(now)
+	if r.Outcomes != nil {
+		r.Outcomes.Failure(now)
+	}
 	r.Latencies.AddDuration(duration, now)
 }
 
@@ -227,6 +355,9 @@ func (r *RunStats) ErrShortCircuit(_ context.Context, now time.Time) {
 // ErrTimeout increments the ErrTimeout bucket
 func (r *RunStats) ErrTimeout(_ context.Context, now time.Time, duration time.Duration) {
 	r.ErrTimeouts.Inc(now)
+	if r.Outcomes != nil {
+		r.Outcomes.Failure(now)
+	}
 	r.Latencies.AddDuration(duration, now)
 }
 
@@ -236,6 +367,137 @@ func (r *RunStats) ErrBadRequest(_ context.Context, now time.Time, duration time
 	r.Latencies.AddDuration(duration, now)
 }
 
+// Retry increments the Retries bucket
+func (r *RunStats) Retry(_ context.Context, now time.Time) {
+	r.Retries.Inc(now)
+}
+
+// ErrRetryBudgetExhausted increments the ErrRetryBudgetRejects bucket
+func (r *RunStats) ErrRetryBudgetExhausted(_ context.Context, now time.Time) {
+	r.ErrRetryBudgetRejects.Inc(now)
+}
+
+// RunEndedInGrace increments the EndedInGrace bucket
+func (r *RunStats) RunEndedInGrace(_ context.Context, now time.Time, _ time.Duration) {
+	r.EndedInGrace.Inc(now)
+}
+
+// RunAbandoned increments the Abandons bucket
+func (r *RunStats) RunAbandoned(_ context.Context, now time.Time) {
+	r.Abandons.Inc(now)
+}
+
+// Overhead adds the overhead to Overheads
+func (r *RunStats) Overhead(_ context.Context, now time.Time, overhead time.Duration) {
+	r.Overheads.AddDuration(overhead, now)
+}
+
+// Size adds the bytes a run sent and received to BytesSent and BytesReceived
+func (r *RunStats) Size(_ context.Context, now time.Time, sent int64, received int64) {
+	r.Sized.Inc(now)
+	r.BytesSent.Add(now, sent)
+	r.BytesReceived.Add(now, received)
+}
+
+// AverageSizesAt returns the average bytes sent and received by the runs whose size was reported in the rolling window
+func (r *RunStats) AverageSizesAt(now time.Time) (sent int64, received int64) {
+	sized := r.Sized.RollingSumAt(now)
+	if sized == 0 {
+		return 0, 0
+	}
+	return r.BytesSent.RollingSumAt(now) / sized, r.BytesReceived.RollingSumAt(now) / sized
+}
+
+// QueueWait adds the wait to QueueWaits
+func (r *RunStats) QueueWait(_ context.Context, now time.Time, wait time.Duration) {
+	r.QueueWaits.AddDuration(wait, now)
+}
+
+// RunError counts the error against its cause, if RunStatsConfig.ErrorFingerprint is set
+func (r *RunStats) RunError(_ context.Context, now time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.config.ErrorFingerprint == nil {
+		return
+	}
+	cause := r.config.ErrorFingerprint(err)
+	counter, exists := r.errorsByCause[cause]
+	if !exists {
+		if len(r.errorsByCause) >= r.config.MaxErrorCauses {
+			cause = OtherErrorCause
+			counter, exists = r.errorsByCause[cause]
+		}
+		if !exists {
+			if r.errorsByCause == nil {
+				r.errorsByCause = make(map[string]*faststats.RollingCounter)
+			}
+			bucketWidth := time.Duration(r.config.RollingStatsDuration.Nanoseconds() / int64(r.config.RollingStatsNumBuckets))
+			newCounter := faststats.NewRollingCounter(bucketWidth, r.config.RollingStatsNumBuckets, now)
+			counter = &newCounter
+			r.errorsByCause[cause] = counter
+		}
+	}
+	counter.Inc(now)
+}
+
+func (r *RunStats) errorCounters() map[string]*faststats.RollingCounter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make(map[string]*faststats.RollingCounter, len(r.errorsByCause))
+	for k, v := range r.errorsByCause {
+		ret[k] = v
+	}
+	return ret
+}
+
+// ErrorsByCauseAt returns the rolling count of failures and timeouts for each cause returned by
+// RunStatsConfig.ErrorFingerprint.  It is empty if no fingerprint is configured.
+func (r *RunStats) ErrorsByCauseAt(now time.Time) map[string]int64 {
+	counters := r.errorCounters()
+	ret := make(map[string]int64, len(counters))
+	for k, v := range counters {
+		ret[k] = v.RollingSumAt(now)
+	}
+	return ret
+}
+
+// Counter adds delta to the rolling count of a custom counter.  See circuit.GeneralConfig.Counters.
+func (r *RunStats) Counter(_ context.Context, now time.Time, name string, delta int64) {
+	r.mu.Lock()
+	counter, exists := r.counters[name]
+	if !exists {
+		if r.counters == nil {
+			r.counters = make(map[string]*faststats.RollingCounter)
+		}
+		bucketWidth := time.Duration(r.config.RollingStatsDuration.Nanoseconds() / int64(r.config.RollingStatsNumBuckets))
+		newCounter := faststats.NewRollingCounter(bucketWidth, r.config.RollingStatsNumBuckets, now)
+		counter = &newCounter
+		r.counters[name] = counter
+	}
+	r.mu.Unlock()
+	counter.Add(now, delta)
+}
+
+func (r *RunStats) customCounters() map[string]*faststats.RollingCounter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make(map[string]*faststats.RollingCounter, len(r.counters))
+	for k, v := range r.counters {
+		ret[k] = v
+	}
+	return ret
+}
+
+// CountersAt returns the rolling count of each custom counter added to
+func (r *RunStats) CountersAt(now time.Time) map[string]int64 {
+	counters := r.customCounters()
+	ret := make(map[string]int64, len(counters))
+	for k, v := range counters {
+		ret[k] = v.RollingSumAt(now)
+	}
+	return ret
+}
+
 // ErrorPercentage returns [0.0 - 1.0] what % of request are considered failing in the rolling window.
 func (r *RunStats) ErrorPercentage() float64 {
 	return r.ErrorPercentageAt(time.Now())
@@ -243,29 +505,78 @@ func (r *RunStats) ErrorPercentage() float64 {
 
 // LegitimateAttemptsAt returns the sum of errors and successes
 func (r *RunStats) LegitimateAttemptsAt(now time.Time) int64 {
-	return r.Successes.RollingSumAt(now) + r.ErrorsAt(now)
+	attempts, _ := r.countsAt(now)
+	return attempts
 }
 
 // ErrorsAt returns the # of errors at a moment in time (errors are timeouts and failures)
 func (r *RunStats) ErrorsAt(now time.Time) int64 {
-	return r.ErrFailures.RollingSumAt(now) + r.ErrTimeouts.RollingSumAt(now)
+	_, errs := r.countsAt(now)
+	return errs
+}
+
+// MemoryBytes estimates the memory held by the rolling counters, percentiles, and exact log
+func (r *RunStats) MemoryBytes() int64 {
+	ret := r.Successes.MemoryBytes() + r.ErrConcurrencyLimitRejects.MemoryBytes() + r.ErrFailures.MemoryBytes() +
+		r.ErrShortCircuits.MemoryBytes() + r.ErrTimeouts.MemoryBytes() + r.ErrBadRequests.MemoryBytes() +
+		r.ErrInterrupts.MemoryBytes() + r.Retries.MemoryBytes() + r.ErrRetryBudgetRejects.MemoryBytes() +
+		r.EndedInGrace.MemoryBytes() + r.Abandons.MemoryBytes() + r.Sized.MemoryBytes() + r.BytesSent.MemoryBytes() +
+		r.BytesReceived.MemoryBytes() + r.Latencies.MemoryBytes() + r.QueueWaits.MemoryBytes() + r.Overheads.MemoryBytes()
+	if r.Outcomes != nil {
+		ret += r.Outcomes.MemoryBytes()
+	}
+	for name, counter := range r.errorCounters() {
+		ret += int64(len(name)) + counter.MemoryBytes()
+	}
+	for name, counter := range r.customCounters() {
+		ret += int64(len(name)) + counter.MemoryBytes()
+	}
+	return ret
+}
+
+// countsAt returns the legitimate attempts and errors in the window, from the exact log when it covers the window
+func (r *RunStats) countsAt(now time.Time) (attempts int64, errs int64) {
+	if r.Outcomes != nil {
+		if total, failures, exact := r.Outcomes.CountsAt(now); exact {
+			return total, failures
+		}
+	}
+	errs = r.ErrFailures.RollingSumAt(now) + r.ErrTimeouts.RollingSumAt(now)
+	return r.Successes.RollingSumAt(now) + errs, errs
 }
 
 // ErrorPercentageAt is [0.0 - 1.0] errors/legitimate
 func (r *RunStats) ErrorPercentageAt(now time.Time) float64 {
-	attemptCount := r.LegitimateAttemptsAt(now)
+	attemptCount, errCount := r.countsAt(now)
 	if attemptCount == 0 {
 		return 0
 	}
-	errCount := r.ErrorsAt(now)
 	return float64(errCount) / float64(attemptCount)
 }
 
+// LatencyPercentileAt returns the p [0.0 - 100.0] percentile of run durations in the rolling window, or -1 if there are
+// none
+func (r *RunStats) LatencyPercentileAt(now time.Time, p float64) time.Duration {
+	return r.Latencies.SnapshotAt(now).Percentile(p)
+}
+
 // FallbackStats tracks fallback metrics in rolling buckets
 type FallbackStats struct {
 	Successes                  faststats.RollingCounter
 	ErrConcurrencyLimitRejects faststats.RollingCounter
 	ErrFailures                faststats.RollingCounter
+	// Reasons counts why fallbacks were called, by circuit.FallbackReason
+	Reasons map[circuit.FallbackReason]*faststats.RollingCounter
+}
+
+// fallbackReasons are the reasons FallbackStats counts
+var fallbackReasons = []circuit.FallbackReason{
+	circuit.FallbackReasonOpen,
+	circuit.FallbackReasonTimeout,
+	circuit.FallbackReasonRejected,
+	circuit.FallbackReasonError,
+	circuit.FallbackReasonForced,
+	circuit.FallbackReasonShadow,
 }
 
 // Var allows FallbackStats on expvar
@@ -275,10 +586,19 @@ func (r *FallbackStats) Var() expvar.Var {
 			"Successes":                  r.Successes.TotalSum(),
 			"ErrConcurrencyLimitRejects": r.ErrConcurrencyLimitRejects.TotalSum(),
 			"ErrFailures":                r.ErrFailures.TotalSum(),
+			"Reasons":                    r.reasonTotals(),
 		}
 	})
 }
 
+func (r *FallbackStats) reasonTotals() map[circuit.FallbackReason]int64 {
+	ret := make(map[circuit.FallbackReason]int64, len(r.Reasons))
+	for reason, counter := range r.Reasons {
+		ret[reason] = counter.TotalSum()
+	}
+	return ret
+}
+
 // Success increments the Success bucket
 func (r *FallbackStats) Success(_ context.Context, now time.Time, _ time.Duration) {
 	r.Successes.Inc(now)
@@ -294,6 +614,13 @@ func (r *FallbackStats) ErrFailure(_ context.Context, now time.Time, _ time.Dura
 	r.ErrFailures.Inc(now)
 }
 
+// FallbackReason increments the bucket of reason in Reasons
+func (r *FallbackStats) FallbackReason(_ context.Context, now time.Time, reason circuit.FallbackReason) {
+	if counter, exists := r.Reasons[reason]; exists {
+		counter.Inc(now)
+	}
+}
+
 // FallbackStatsConfig configures how to track fallback stats
 type FallbackStatsConfig struct {
 	// Rolling Stats size is https://github.com/Netflix/Hystrix/wiki/Configuration#metricsrollingstatstimeinmilliseconds
@@ -324,6 +651,17 @@ var defaultFallbackStatsConfig = FallbackStatsConfig{
 }
 
 var _ circuit.FallbackMetrics = &FallbackStats{}
+var _ circuit.FallbackReasonMetrics = &FallbackStats{}
+var _ circuit.MemoryReporter = &FallbackStats{}
+
+// MemoryBytes estimates the memory held by the rolling counters
+func (r *FallbackStats) MemoryBytes() int64 {
+	ret := r.Successes.MemoryBytes() + r.ErrConcurrencyLimitRejects.MemoryBytes() + r.ErrFailures.MemoryBytes()
+	for _, counter := range r.Reasons {
+		ret += counter.MemoryBytes()
+	}
+	return ret
+}
 
 // SetConfigNotThreadSafe sets the configuration for fallback stats
 func (r *FallbackStats) SetConfigNotThreadSafe(config FallbackStatsConfig) {
@@ -334,4 +672,9 @@ func (r *FallbackStats) SetConfigNotThreadSafe(config FallbackStatsConfig) {
 	r.Successes = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
 	r.ErrConcurrencyLimitRejects = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
 	r.ErrFailures = faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+	r.Reasons = make(map[circuit.FallbackReason]*faststats.RollingCounter, len(fallbackReasons))
+	for _, reason := range fallbackReasons {
+		counter := faststats.NewRollingCounter(bucketWidth, numBuckets, now)
+		r.Reasons[reason] = &counter
+	}
 }
@@ -220,3 +220,243 @@ func TestRunStats_ErrorPercentage(t *testing.T) {
 		t.Errorf("Expect all errors")
 	}
 }
+
+func TestErrorsByCause(t *testing.T) {
+	s := StatFactory{
+		RunConfig: RunStatsConfig{
+			ErrorFingerprint: func(err error) string {
+				return strings.SplitN(err.Error(), ":", 2)[0]
+			},
+			MaxErrorCauses: 2,
+		},
+	}
+	c := circuit.NewCircuitFromConfig("TestErrorsByCause", s.CreateConfig(""))
+	for _, msg := range []string{"refused: a", "refused: b", "503: c", "reset: d"} {
+		msg := msg
+		_ = c.Execute(context.Background(), func(_ context.Context) error {
+			return errors.New(msg)
+		}, nil)
+	}
+	byCause := FindCommandMetrics(c).ErrorsByCauseAt(time.Now())
+	if byCause["refused"] != 2 || byCause["503"] != 1 || byCause[OtherErrorCause] != 1 {
+		t.Errorf("unexpected causes %v", byCause)
+	}
+}
+
+func TestRunStats_LatencyDecayAlpha(t *testing.T) {
+	ctx := context.Background()
+	var r RunStats
+	c := RunStatsConfig{
+		LatencyDecayAlpha:    0.015,
+		LatencyReservoirSize: 10,
+	}
+	c.Merge(defaultRunStatsConfig)
+	r.SetConfigNotThreadSafe(c)
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		r.Success(ctx, now, time.Second)
+	}
+	// Rolling buckets would have forgotten these after RollingPercentileDuration
+	snap := r.Latencies.SnapshotAt(now.Add(2 * time.Minute))
+	if len(snap) != 10 || snap.Max() != time.Second {
+		t.Errorf("expect a full reservoir of latencies, got %s", snap)
+	}
+}
+
+func TestRunStats_InheritRand(t *testing.T) {
+	ctx := context.Background()
+	kept := func(seed int64) []time.Duration {
+		f := StatFactory{
+			RunConfig: RunStatsConfig{
+				LatencyDecayAlpha:    0.015,
+				LatencyReservoirSize: 10,
+			},
+		}
+		cfg := f.CreateConfig("TestRunStats_InheritRand")
+		cfg.General.Rand = circuit.NewRand(seed)
+		c := circuit.NewCircuitFromConfig("TestRunStats_InheritRand", cfg)
+		r := FindCommandMetrics(c)
+		now := time.Now()
+		for i := 0; i < 1000; i++ {
+			r.Success(ctx, now, time.Duration(i))
+		}
+		return r.Latencies.SnapshotAt(now)
+	}
+	first, second := kept(7), kept(7)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the circuit's Rand to keep the same latencies, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestRunStats_QueueWait(t *testing.T) {
+	var r RunStats
+	r.SetConfigNotThreadSafe(defaultRunStatsConfig)
+	now := time.Now()
+	r.QueueWait(context.Background(), now, time.Second)
+	if r.QueueWaits.SnapshotAt(now).Max() != time.Second {
+		t.Errorf("expect a 1 sec queue wait")
+	}
+}
+
+func TestFallbackStats_FallbackReason(t *testing.T) {
+	var r FallbackStats
+	r.SetConfigNotThreadSafe(defaultFallbackStatsConfig)
+	now := time.Now()
+	r.FallbackReason(context.Background(), now, circuit.FallbackReasonOpen)
+	r.FallbackReason(context.Background(), now, circuit.FallbackReasonOpen)
+	r.FallbackReason(context.Background(), now, circuit.FallbackReasonTimeout)
+	r.FallbackReason(context.Background(), now, "unknown")
+	if count := r.Reasons[circuit.FallbackReasonOpen].RollingSumAt(now); count != 2 {
+		t.Errorf("expect 2 open fallbacks, got %d", count)
+	}
+	if count := r.Reasons[circuit.FallbackReasonTimeout].RollingSumAt(now); count != 1 {
+		t.Errorf("expect 1 timeout fallback, got %d", count)
+	}
+	if count := r.Reasons[circuit.FallbackReasonError].RollingSumAt(now); count != 0 {
+		t.Errorf("expect no error fallbacks, got %d", count)
+	}
+}
+
+func TestRunStats_RunAbandoned(t *testing.T) {
+	var r RunStats
+	r.SetConfigNotThreadSafe(defaultRunStatsConfig)
+	now := time.Now()
+	r.RunAbandoned(context.Background(), now)
+	r.RunEndedInGrace(context.Background(), now, time.Second)
+	r.RunEndedInGrace(context.Background(), now, time.Second)
+	if count := r.Abandons.RollingSumAt(now); count != 1 {
+		t.Errorf("expect 1 abandoned run, got %d", count)
+	}
+	if count := r.EndedInGrace.RollingSumAt(now); count != 2 {
+		t.Errorf("expect 2 runs ended in grace, got %d", count)
+	}
+}
+
+func TestRunStats_Overhead(t *testing.T) {
+	var r RunStats
+	r.SetConfigNotThreadSafe(defaultRunStatsConfig)
+	now := time.Now()
+	r.Overhead(context.Background(), now, time.Microsecond)
+	if r.Overheads.SnapshotAt(now).Max() != time.Microsecond {
+		t.Errorf("expect a 1 microsecond overhead")
+	}
+}
+
+func TestRunStats_HealthScore(t *testing.T) {
+	s := StatFactory{}
+	c := circuit.NewCircuitFromConfig("TestRunStats_HealthScore", s.CreateConfig(""))
+	cmdMetrics := FindCommandMetrics(c)
+	if latency := cmdMetrics.LatencyPercentileAt(time.Now(), 99); latency != -1 {
+		t.Errorf("expected no latency before any runs, got %v", latency)
+	}
+	_ = c.Execute(context.Background(), testhelp.AlwaysPasses, nil)
+	_ = c.Execute(context.Background(), testhelp.AlwaysFails, nil)
+	if latency := cmdMetrics.LatencyPercentileAt(time.Now(), 99); latency < 0 {
+		t.Errorf("expected a latency after runs, got %v", latency)
+	}
+	if score := c.HealthScore(); score >= 100 || score <= 0 {
+		t.Errorf("expected errors to lower the health score, got %v", score)
+	}
+}
+
+func TestRunStats_Counters(t *testing.T) {
+	s := StatFactory{}
+	config := s.CreateConfig("")
+	config.General.Counters = []string{"cache_hits"}
+	c := circuit.NewCircuitFromConfig("TestRunStats_Counters", config)
+	for i := 0; i < 3; i++ {
+		_ = c.Execute(context.Background(), func(ctx context.Context) error {
+			circuit.AddCounter(ctx, "cache_hits", 1)
+			return nil
+		}, nil)
+	}
+	cmdMetrics := FindCommandMetrics(c)
+	if hits := cmdMetrics.CountersAt(time.Now())["cache_hits"]; hits != 3 {
+		t.Errorf("expected three cache hits in the window, got %d", hits)
+	}
+	if hits := cmdMetrics.CountersAt(time.Now().Add(time.Hour))["cache_hits"]; hits != 0 {
+		t.Errorf("expected cache hits to leave the window, got %d", hits)
+	}
+}
+
+func TestRunStats_Size(t *testing.T) {
+	s := StatFactory{}
+	c := circuit.NewCircuitFromConfig("TestRunStats_Size", s.CreateConfig(""))
+	for _, size := range []int64{100, 300} {
+		_ = c.Execute(context.Background(), func(ctx context.Context) error {
+			circuit.ReportSize(ctx, size, size*10)
+			return nil
+		}, nil)
+	}
+	cmdMetrics := FindCommandMetrics(c)
+	sent, received := cmdMetrics.AverageSizesAt(time.Now())
+	if sent != 200 || received != 2000 {
+		t.Errorf("expected average sizes of 200 and 2000, got %d and %d", sent, received)
+	}
+	if sent, received := cmdMetrics.AverageSizesAt(time.Now().Add(time.Hour)); sent != 0 || received != 0 {
+		t.Errorf("expected no sizes outside the window, got %d and %d", sent, received)
+	}
+}
+
+func TestRunStats_ExactLogSize(t *testing.T) {
+	ctx := context.Background()
+	var r RunStats
+	c := RunStatsConfig{
+		ExactLogSize: 10,
+	}
+	c.Merge(defaultRunStatsConfig)
+	r.SetConfigNotThreadSafe(c)
+	now := time.Now()
+	failedAt := now.Add(900 * time.Millisecond)
+	r.ErrFailure(ctx, failedAt, time.Second)
+	r.Success(ctx, now.Add(9500*time.Millisecond), time.Second)
+	// The bucket holding the failure has rolled out, but it happened less than 10 seconds ago
+	at := now.Add(10500 * time.Millisecond)
+	if r.ErrFailures.RollingSumAt(at) != 0 {
+		t.Fatal("expected the failure's bucket to have rolled out")
+	}
+	if r.ErrorPercentageAt(at) != 0.5 || r.LegitimateAttemptsAt(at) != 2 {
+		t.Errorf("expected exact counts, got %f of %d", r.ErrorPercentageAt(at), r.LegitimateAttemptsAt(at))
+	}
+	if r.ErrorPercentageAt(failedAt.Add(10*time.Second)) != 0 {
+		t.Errorf("expected the failure to leave the window")
+	}
+}
+
+func TestStatFactory_ExactLogSize(t *testing.T) {
+	s := StatFactory{
+		RunConfig: RunStatsConfig{
+			ExactLogSize: 5,
+		},
+	}
+	c := circuit.NewCircuitFromConfig("TestStatFactory_ExactLogSize", s.CreateConfig(""))
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return errors.New("bad")
+	}, nil)
+	stats := FindCommandMetrics(c)
+	if stats.Outcomes == nil || stats.Outcomes.Size() != 5 {
+		t.Fatal("expected the factory to create an exact log")
+	}
+	if stats.ErrorPercentage() != 1.0 {
+		t.Errorf("expected all errors, got %f", stats.ErrorPercentage())
+	}
+}
+
+func TestRunStats_MemoryBytes(t *testing.T) {
+	s := StatFactory{}
+	c := circuit.NewCircuitFromConfig("TestRunStats_MemoryBytes", s.CreateConfig(""))
+	withoutLog := FindCommandMetrics(c).MemoryBytes()
+	if withoutLog <= 0 || FindFallbackMetrics(c).MemoryBytes() <= 0 {
+		t.Fatal("expected stats to hold memory")
+	}
+	if usage := c.MemoryUsage(); usage.Metrics != withoutLog+FindFallbackMetrics(c).MemoryBytes() {
+		t.Errorf("expected the circuit to count its stats, got %+v", usage)
+	}
+	s.RunConfig.ExactLogSize = 100
+	withLog := FindCommandMetrics(circuit.NewCircuitFromConfig("TestRunStats_MemoryBytes", s.CreateConfig(""))).MemoryBytes()
+	if withLog <= withoutLog {
+		t.Errorf("expected the exact log to add memory, got %d and %d", withoutLog, withLog)
+	}
+}
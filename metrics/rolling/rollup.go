@@ -0,0 +1,87 @@
+package rolling
+
+import (
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// RunRollup is the rolling run counts of every circuit at or under one level of the name hierarchy.  See
+// circuit.NameSeparator.
+type RunRollup struct {
+	// Level is the name the circuits are under.  It is "" for the rollup of every circuit.
+	Level string
+	// Circuits is how many circuits with RunStats are in the rollup
+	Circuits int
+
+	Successes                  int64
+	ErrConcurrencyLimitRejects int64
+	ErrFailures                int64
+	ErrShortCircuits           int64
+	ErrTimeouts                int64
+	ErrBadRequests             int64
+	ErrInterrupts              int64
+}
+
+// Errors returns the # of errors in the rollup (errors are timeouts and failures)
+func (r RunRollup) Errors() int64 {
+	return r.ErrFailures + r.ErrTimeouts
+}
+
+// LegitimateAttempts returns the sum of errors and successes
+func (r RunRollup) LegitimateAttempts() int64 {
+	return r.Successes + r.Errors()
+}
+
+// ErrorPercentage is [0.0 - 1.0] errors/legitimate
+func (r RunRollup) ErrorPercentage() float64 {
+	attemptCount := r.LegitimateAttempts()
+	if attemptCount == 0 {
+		return 0
+	}
+	return float64(r.Errors()) / float64(attemptCount)
+}
+
+func (r *RunRollup) add(stats *RunStats, now time.Time) {
+	r.Circuits++
+	r.Successes += stats.Successes.RollingSumAt(now)
+	r.ErrConcurrencyLimitRejects += stats.ErrConcurrencyLimitRejects.RollingSumAt(now)
+	r.ErrFailures += stats.ErrFailures.RollingSumAt(now)
+	r.ErrShortCircuits += stats.ErrShortCircuits.RollingSumAt(now)
+	r.ErrTimeouts += stats.ErrTimeouts.RollingSumAt(now)
+	r.ErrBadRequests += stats.ErrBadRequests.RollingSumAt(now)
+	r.ErrInterrupts += stats.ErrInterrupts.RollingSumAt(now)
+}
+
+// RollupAt sums the rolling run counts of the circuits of m at or under level.  Circuits without RunStats are skipped.
+func RollupAt(m *circuit.Manager, level string, now time.Time) RunRollup {
+	ret := RunRollup{
+		Level: level,
+	}
+	for _, c := range m.CircuitsIn(level) {
+		if stats := FindCommandMetrics(c); stats != nil {
+			ret.add(stats, now)
+		}
+	}
+	return ret
+}
+
+// RollupsAt returns a RunRollup for every level of every circuit name of m, in the order of circuit.Manager.Rollups
+func RollupsAt(m *circuit.Manager, now time.Time) []RunRollup {
+	rollups := m.Rollups()
+	ret := make([]RunRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		runRollup := RunRollup{
+			Level: rollup.Level,
+		}
+		for _, name := range rollup.Circuits {
+			if c := m.GetCircuit(name); c != nil {
+				if stats := FindCommandMetrics(c); stats != nil {
+					runRollup.add(stats, now)
+				}
+			}
+		}
+		ret = append(ret, runRollup)
+	}
+	return ret
+}
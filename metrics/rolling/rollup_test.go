@@ -0,0 +1,30 @@
+package rolling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/testhelp"
+)
+
+func TestRollupAt(t *testing.T) {
+	s := StatFactory{}
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{s.CreateConfig},
+	}
+	ctx := context.Background()
+	_ = h.MustCreateCircuit("payments.gateway.charge").Execute(ctx, testhelp.AlwaysFails, nil)
+	_ = h.MustCreateCircuit("payments.gateway.refund").Execute(ctx, testhelp.AlwaysPasses, nil)
+	_ = h.MustCreateCircuit("payments.ledger").Execute(ctx, testhelp.AlwaysPasses, nil)
+
+	gateway := RollupAt(&h, "payments.gateway", time.Now())
+	if gateway.Circuits != 2 || gateway.Successes != 1 || gateway.ErrFailures != 1 || gateway.ErrorPercentage() != 0.5 {
+		t.Errorf("unexpected gateway rollup %+v", gateway)
+	}
+	rollups := RollupsAt(&h, time.Now())
+	if len(rollups) != 5 || rollups[0].Level != "payments" || rollups[0].LegitimateAttempts() != 3 {
+		t.Errorf("unexpected rollups %+v", rollups)
+	}
+}
@@ -0,0 +1,6 @@
+/*
+Package stategauge exposes the state of each circuit as a labeled gauge, like circuit_state{name="db",state="open"} 1.
+Every circuit has one gauge for each state, and only the one for its current state is 1.  Alerting systems can then
+trigger on a circuit that stays open, like circuit_state{state="open"} == 1 for 5m, without parsing logs.
+*/
+package stategauge
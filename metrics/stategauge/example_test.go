@@ -0,0 +1,35 @@
+package stategauge_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/metrics/stategauge"
+)
+
+type printSink struct{}
+
+func (printSink) Gauge(name string, labels map[string]string, value float64) {
+	fmt.Printf("%s{name=%q,state=%q} %v\n", name, labels[stategauge.LabelName], labels[stategauge.LabelState], value)
+}
+
+// This example sets a gauge for each state of a circuit as it opens.  Use a Sink that sets a labeled gauge in your
+// metrics system, and alert on circuit_state{state="open"} staying 1.
+func ExampleFactory() {
+	f := stategauge.Factory{
+		Sink: printSink{},
+	}
+	h := circuit.Manager{
+		DefaultCircuitProperties: []circuit.CommandPropertiesConstructor{f.CommandProperties},
+	}
+	c := h.MustCreateCircuit("db")
+	c.OpenCircuit(context.Background())
+	// Output:
+	// circuit_state{name="db",state="closed"} 1
+	// circuit_state{name="db",state="open"} 0
+	// circuit_state{name="db",state="half-open"} 0
+	// circuit_state{name="db",state="closed"} 0
+	// circuit_state{name="db",state="open"} 1
+	// circuit_state{name="db",state="half-open"} 0
+}
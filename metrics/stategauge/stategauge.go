@@ -0,0 +1,93 @@
+package stategauge
+
+import (
+	"context"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+)
+
+// Name is the name of the gauge
+const Name = "circuit_state"
+
+// The labels of the gauge
+const (
+	// LabelName is the name of the circuit
+	LabelName = "name"
+	// LabelState is one of States
+	LabelState = "state"
+)
+
+// States are the values of LabelState.  Each circuit has a gauge for every one of them.
+var States = []circuit.CircuitState{circuit.StateClosed, circuit.StateOpen, circuit.StateHalfOpen}
+
+// Sink receives gauges.  It must not change labels, which are reused for every call.
+type Sink interface {
+	// Gauge sets the gauge called name, with labels, to value
+	Gauge(name string, labels map[string]string, value float64)
+}
+
+// Factory sets the state gauges of circuits as they open and close.  Circuits only tell collectors when they open or
+// close, so a half open circuit is reported as open.  Use Collect to also report half open circuits.
+type Factory struct {
+	Sink Sink
+}
+
+// CommandProperties appends the state gauges to a circuit.  The circuit starts out closed.
+func (f *Factory) CommandProperties(circuitName string) circuit.Config {
+	g := newStateGauge(f.Sink, circuitName)
+	g.set(circuit.StateClosed)
+	return circuit.Config{
+		Metrics: circuit.MetricsCollectors{
+			Circuit: []circuit.Metrics{g},
+		},
+	}
+}
+
+// Collect sets the state gauges of every circuit in m.  Call it before each scrape, or on a timer.
+func Collect(sink Sink, m *circuit.Manager) {
+	for _, c := range m.AllCircuits() {
+		newStateGauge(sink, c.Name()).set(c.TimeInState().Current)
+	}
+}
+
+// stateGauge sets the state gauges of one circuit.  Labels are built once, so setting a gauge does not allocate.
+type stateGauge struct {
+	sink   Sink
+	labels []map[string]string
+}
+
+var _ circuit.Metrics = &stateGauge{}
+
+func newStateGauge(sink Sink, circuitName string) *stateGauge {
+	ret := &stateGauge{
+		sink:   sink,
+		labels: make([]map[string]string, 0, len(States)),
+	}
+	for _, state := range States {
+		ret.labels = append(ret.labels, map[string]string{
+			LabelName:  circuitName,
+			LabelState: string(state),
+		})
+	}
+	return ret
+}
+
+// set makes the gauge of current 1, and every other state's gauge 0
+func (g *stateGauge) set(current circuit.CircuitState) {
+	for i, state := range States {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		g.sink.Gauge(Name, g.labels[i], value)
+	}
+}
+
+func (g *stateGauge) Opened(_ context.Context, _ time.Time) {
+	g.set(circuit.StateOpen)
+}
+
+func (g *stateGauge) Closed(_ context.Context, _ time.Time) {
+	g.set(circuit.StateClosed)
+}
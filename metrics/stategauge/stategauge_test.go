@@ -0,0 +1,95 @@
+package stategauge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4"
+	"github.com/cep21/circuit/v4/internal/collectorbudget"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{
+		gauges: make(map[string]float64),
+	}
+}
+
+func (r *recordingSink) Gauge(name string, labels map[string]string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name+"{"+labels[LabelName]+","+labels[LabelState]+"}"] = value
+}
+
+func (r *recordingSink) expect(t *testing.T, circuitName string, current circuit.CircuitState) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, state := range States {
+		key := Name + "{" + circuitName + "," + string(state) + "}"
+		value, exists := r.gauges[key]
+		if !exists {
+			t.Errorf("expected gauge %s to be set", key)
+			continue
+		}
+		if (state == current) != (value == 1) {
+			t.Errorf("expected %s to be in state %s, but %s is %v", circuitName, current, key, value)
+		}
+	}
+}
+
+func TestFactory(t *testing.T) {
+	sink := newRecordingSink()
+	f := Factory{Sink: sink}
+	c := circuit.NewCircuitFromConfig("db", f.CommandProperties("db"))
+	sink.expect(t, "db", circuit.StateClosed)
+	c.OpenCircuit(context.Background())
+	sink.expect(t, "db", circuit.StateOpen)
+	c.CloseCircuit(context.Background())
+	sink.expect(t, "db", circuit.StateClosed)
+}
+
+func TestFactory_InitialState(t *testing.T) {
+	sink := newRecordingSink()
+	f := Factory{Sink: sink}
+	cfg := f.CommandProperties("db")
+	cfg.General.InitialState = circuit.StateOpen
+	circuit.NewCircuitFromConfig("db", cfg)
+	sink.expect(t, "db", circuit.StateOpen)
+}
+
+func TestCollect(t *testing.T) {
+	sink := newRecordingSink()
+	var h circuit.Manager
+	h.MustCreateCircuit("closed")
+	h.MustCreateCircuit("open").OpenCircuit(context.Background())
+	halfOpen := h.MustCreateCircuit("half-open", circuit.Config{
+		General: circuit.GeneralConfig{
+			InitialState: circuit.StateHalfOpen,
+		},
+	})
+	_ = halfOpen.Execute(context.Background(), func(_ context.Context) error {
+		Collect(sink, &h)
+		return nil
+	}, nil)
+	sink.expect(t, "closed", circuit.StateClosed)
+	sink.expect(t, "open", circuit.StateOpen)
+	sink.expect(t, "half-open", circuit.StateHalfOpen)
+}
+
+type discardSink struct{}
+
+func (discardSink) Gauge(string, map[string]string, float64) {}
+
+func TestFactory_Budget(t *testing.T) {
+	f := Factory{Sink: discardSink{}}
+	collectorbudget.Check(t, f.CommandProperties(""), collectorbudget.Budget{
+		Overhead: time.Microsecond,
+	})
+}
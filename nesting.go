@@ -0,0 +1,97 @@
+package circuit
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// runningCircuitKey finds the circuit whose runFunc is running in a context.  It is answered by timeoutContext
+// directly, so runs with a timeout do not allocate a context to carry it.
+type runningCircuitKey struct{}
+
+// nestedCalls counts calls to other circuits made from inside a circuit's runs, by the called circuit's name
+type nestedCalls struct {
+	counts sync.Map
+}
+
+func (n *nestedCalls) add(child string) {
+	if count, exists := n.counts.Load(child); exists {
+		count.(*faststats.AtomicInt64).Add(1)
+		return
+	}
+	count, _ := n.counts.LoadOrStore(child, &faststats.AtomicInt64{})
+	count.(*faststats.AtomicInt64).Add(1)
+}
+
+func (n *nestedCalls) get() map[string]int64 {
+	ret := make(map[string]int64)
+	n.counts.Range(func(key, value interface{}) bool {
+		ret[key.(string)] = value.(*faststats.AtomicInt64).Get()
+		return true
+	})
+	return ret
+}
+
+// withRunningCircuit marks ctx as running inside c.  Without a timeout, marking ctx costs an allocation, so it is only
+// done if something will look for the circuit: GeneralConfig.DetectNesting, custom counters, SizeMetrics collectors,
+// or a nested ctx that would otherwise still name the outer circuit.
+func (c *Circuit) withRunningCircuit(ctx context.Context, cfg *configSnapshot, nested bool) context.Context {
+	if t, ok := ctx.(*timeoutContext); ok {
+		t.circuit = c
+		return t
+	}
+	if !nested && !c.markRunning && !cfg.General.DetectNesting {
+		return ctx
+	}
+	return context.WithValue(ctx, runningCircuitKey{}, c)
+}
+
+// recordNesting remembers that this circuit was called from inside another circuit's run.  It returns true if it was.
+func (c *Circuit) recordNesting(ctx context.Context) bool {
+	if parent, ok := ctx.Value(runningCircuitKey{}).(*Circuit); ok && parent != nil {
+		parent.nestedCalls.add(c.Name())
+		return true
+	}
+	return false
+}
+
+// NestedCalls counts the calls made to other circuits from inside this circuit's runs, by the name of the circuit
+// called.  Nested circuits usually mean a timeout is counted twice: once by the inner circuit, and again by the outer
+// one.  Circuits without a timeout only count them with GeneralConfig.DetectNesting.
+func (c *Circuit) NestedCalls() map[string]int64 {
+	return c.nestedCalls.get()
+}
+
+// CircuitCall is a count of calls from inside one circuit's runs to another circuit.  See Manager.CallGraph.
+type CircuitCall struct {
+	// Parent is the circuit whose runFunc made the calls
+	Parent string
+	// Child is the circuit that was called
+	Child string
+	// Calls is how many times Child was called from inside Parent
+	Calls int64
+}
+
+// CallGraph lists every circuit that was called from inside another circuit's run, sorted by parent then child
+func (h *Manager) CallGraph() []CircuitCall {
+	var ret []CircuitCall
+	for _, c := range h.AllCircuits() {
+		for child, calls := range c.NestedCalls() {
+			ret = append(ret, CircuitCall{
+				Parent: c.Name(),
+				Child:  child,
+				Calls:  calls,
+			})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Parent != ret[j].Parent {
+			return ret[i].Parent < ret[j].Parent
+		}
+		return ret[i].Child < ret[j].Child
+	})
+	return ret
+}
@@ -0,0 +1,80 @@
+package circuit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestManager_CallGraph(t *testing.T) {
+	h := Manager{}
+	outer := h.MustCreateCircuit("outer")
+	noTimeout := h.MustCreateCircuit("no-timeout", Config{
+		General: GeneralConfig{
+			DetectNesting: true,
+		},
+		Execution: ExecutionConfig{
+			Timeout: -1,
+		},
+	})
+	inner := h.MustCreateCircuit("inner")
+	callInner := func(ctx context.Context) error {
+		return inner.Run(ctx, func(_ context.Context) error { return nil })
+	}
+	for i := 0; i < 2; i++ {
+		if err := outer.Run(context.Background(), callInner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := noTimeout.Run(context.Background(), callInner); err != nil {
+		t.Fatal(err)
+	}
+	// Calls that are not nested are not counted
+	if err := callInner(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []CircuitCall{
+		{Parent: "no-timeout", Child: "inner", Calls: 1},
+		{Parent: "outer", Child: "inner", Calls: 2},
+	}
+	if graph := h.CallGraph(); !reflect.DeepEqual(graph, expected) {
+		t.Errorf("expected %v, got %v", expected, graph)
+	}
+	if nested := inner.NestedCalls(); len(nested) != 0 {
+		t.Errorf("expected inner to call nothing, got %v", nested)
+	}
+}
+
+func TestCircuit_NestedCallsWithoutTimeout(t *testing.T) {
+	outer := NewCircuitFromConfig("outer", Config{})
+	middle := NewCircuitFromConfig("middle", Config{Execution: ExecutionConfig{Timeout: -1}})
+	inner := NewCircuitFromConfig("inner", Config{})
+	err := outer.Run(context.Background(), func(ctx context.Context) error {
+		return middle.Run(ctx, func(ctx context.Context) error {
+			return inner.Run(ctx, func(_ context.Context) error { return nil })
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := outer.NestedCalls(); !reflect.DeepEqual(calls, map[string]int64{"middle": 1}) {
+		t.Errorf("expected outer to only call middle, got %v", calls)
+	}
+	if calls := middle.NestedCalls()["inner"]; calls != 1 {
+		t.Errorf("expected a nested circuit without a timeout to still be seen as the parent, got %d", calls)
+	}
+}
+
+func TestCircuit_NestedCallsWithGo(t *testing.T) {
+	outer := NewCircuitFromConfig("outer", Config{})
+	inner := NewCircuitFromConfig("inner", Config{})
+	err := outer.Go(context.Background(), func(ctx context.Context) error {
+		return inner.Run(ctx, func(_ context.Context) error { return nil })
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := outer.NestedCalls()["inner"]; calls != 1 {
+		t.Errorf("expected the nested call to be seen from the goroutine, got %d", calls)
+	}
+}
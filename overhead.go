@@ -0,0 +1,58 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+// OverheadMetrics can optionally be implemented by RunMetrics that want to know how much time Execute spends in the
+// circuit itself: admission checks, metric callbacks and timers.  It is the time Execute took, less the time in
+// runFunc, the fallback, and waiting in the queue.  Overhead is only measured when a collector implements
+// OverheadMetrics, so circuits that do not use it do not pay for it.
+type OverheadMetrics interface {
+	Overhead(ctx context.Context, now time.Time, overhead time.Duration)
+}
+
+var _ OverheadMetrics = RunMetricsCollection(nil)
+
+// overheadTimer adds up the time an Execute spends outside the circuit.  It uses the wall clock, not
+// GeneralConfig.TimeKeeper, since overhead is about this process and not the dependency.  A nil overheadTimer measures
+// nothing.
+type overheadTimer struct {
+	start       time.Time
+	notOverhead time.Duration
+}
+
+// now is the time to pass to exclude
+func (o *overheadTimer) now() time.Time {
+	if o == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// exclude takes the time since start, from now, out of the overhead
+func (o *overheadTimer) exclude(start time.Time) {
+	if o != nil {
+		o.notOverhead += time.Since(start)
+	}
+}
+
+// measuresOverhead is true if any collector implements OverheadMetrics
+func (r RunMetricsCollection) measuresOverhead() bool {
+	for _, c := range r {
+		if _, ok := c.(OverheadMetrics); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// reportOverhead tells OverheadMetrics how long an Execute spent in the circuit itself
+func (c *Circuit) reportOverhead(ctx context.Context, overhead *overheadTimer) {
+	spent := time.Since(overhead.start) - overhead.notOverhead
+	if spent < 0 {
+		spent = 0
+	}
+	c.CmdMetricCollector.Overhead(ctx, c.now(), spent)
+}
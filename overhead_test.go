@@ -0,0 +1,86 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type overheads struct {
+	RunMetrics
+	overheads []time.Duration
+}
+
+func (o *overheads) Overhead(_ context.Context, _ time.Time, overhead time.Duration) {
+	o.overheads = append(o.overheads, overhead)
+}
+
+func (o *overheads) Success(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (o *overheads) ErrFailure(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (o *overheads) RunError(_ context.Context, _ time.Time, _ error) {}
+
+func TestCircuit_Overhead(t *testing.T) {
+	metrics := &overheads{}
+	c := NewCircuitFromConfig("TestCircuit_Overhead", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	sleep := 20 * time.Millisecond
+	err := c.Execute(context.Background(), func(_ context.Context) error {
+		time.Sleep(sleep)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Execute(context.Background(), func(_ context.Context) error {
+		return errors.New("failure")
+	}, func(_ context.Context, _ error) error {
+		time.Sleep(sleep)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.overheads) != 2 {
+		t.Fatalf("expected an overhead for each run, got %v", metrics.overheads)
+	}
+	for _, overhead := range metrics.overheads {
+		if overhead >= sleep {
+			t.Errorf("expected time in runFunc and the fallback to not be overhead, got %s", overhead)
+		}
+	}
+}
+
+func TestCircuit_OverheadDoesNotAllocate(t *testing.T) {
+	ctx := context.Background()
+	runFunc := func(ctx context.Context) error {
+		return ctx.Err()
+	}
+	plain := NewCircuitFromConfig("TestCircuit_OverheadDoesNotAllocate", Config{})
+	c := NewCircuitFromConfig("TestCircuit_OverheadDoesNotAllocate", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{&overheads{}},
+		},
+	})
+	// Fill the collector's slice so appending to it does not allocate
+	for i := 0; i < 200; i++ {
+		_ = c.Execute(ctx, runFunc, nil)
+	}
+	c.CmdMetricCollector[len(c.CmdMetricCollector)-1].(*overheads).overheads = make([]time.Duration, 0, 1000)
+	execute := func(c *Circuit) func() {
+		return func() {
+			if err := c.Execute(ctx, runFunc, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// The context given to runFunc is allocated either way, so only compare to a run that does not measure overhead
+	if allocs, plainAllocs := testing.AllocsPerRun(100, execute(c)), testing.AllocsPerRun(100, execute(plain)); allocs != plainAllocs {
+		t.Errorf("expected measuring overhead to not allocate, saw %v allocations instead of %v", allocs, plainAllocs)
+	}
+}
@@ -0,0 +1,128 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+type timeoutOverrideKey struct{}
+
+type bypassKey struct{}
+
+type forceFallbackKey struct{}
+
+type costKey struct{}
+
+type retryKey struct{}
+
+// overridesHidden hides the overrides of a context.  See withoutOverrides.
+type overridesHidden struct {
+	context.Context
+}
+
+func (o overridesHidden) Value(key interface{}) interface{} {
+	switch key.(type) {
+	case timeoutOverrideKey, bypassKey, forceFallbackKey, costKey, retryKey:
+		return nil
+	}
+	return o.Context.Value(key)
+}
+
+// withoutOverrides returns ctx without the overrides from WithTimeoutOverride, WithBypass, WithForcedFallback,
+// WithCost, and WithRetry.  Overrides apply to the circuit they are given to, so they are removed from the contexts
+// given to runFunc and the fallback: otherwise every circuit those call would be overridden too.
+func withoutOverrides(ctx context.Context) context.Context {
+	if _, hidden := ctx.(overridesHidden); hidden {
+		return ctx
+	}
+	if ctx.Value(timeoutOverrideKey{}) == nil && ctx.Value(bypassKey{}) == nil && ctx.Value(forceFallbackKey{}) == nil && ctx.Value(costKey{}) == nil &&
+		ctx.Value(retryKey{}) == nil {
+		return ctx
+	}
+	return overridesHidden{Context: ctx}
+}
+
+// WithTimeoutOverride returns a context that runs circuits with timeout instead of ExecutionConfig.Timeout.  Use this
+// for calls that legitimately need a different budget than the rest of the traffic through the same circuit.  The
+// override is capped at ExecutionConfig.MaxTimeoutOverride.  Overrides that are not positive are ignored.  The override
+// only applies to the circuit given ctx: it is removed from the contexts given to runFunc and the fallback, so the
+// circuits they call use their own timeouts.
+func WithTimeoutOverride(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutOverrideKey{}, timeout)
+}
+
+// timeout is how long a run with ctx may take, or 0 if it has no timeout
+func (c *Circuit) timeout(ctx context.Context) time.Duration {
+	cfg := c.config()
+	timeout := cfg.Execution.Timeout
+	override, ok := ctx.Value(timeoutOverrideKey{}).(time.Duration)
+	if !ok || override <= 0 {
+		return timeout
+	}
+	maxOverride := cfg.Execution.MaxTimeoutOverride
+	if maxOverride == 0 {
+		maxOverride = timeout
+	}
+	if maxOverride > 0 && override > maxOverride {
+		return maxOverride
+	}
+	return override
+}
+
+// WithBypass returns a context that runs circuits even if they are open or at their concurrency limits.  Results are
+// still recorded, so they count towards opening and closing the circuit.  Use this for admin or debugging traffic that
+// must reach the dependency no matter what.  Only the circuit given ctx is bypassed: it is removed from the contexts
+// given to runFunc and the fallback, so the circuits they call can still reject them.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func isBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+// WithForcedFallback returns a context that makes Execute call the fallback with ErrForcedFallback instead of runFunc.
+// Nothing is recorded for runFunc, since it did not run.  Use this to send some traffic to a degraded mode, for
+// example from a feature flag, through the fallbacks circuits already have.  Only the circuit given ctx is forced: it
+// is removed from the context given to the fallback, so the fallback and FallbackConfig.Circuit run normally.
+func WithForcedFallback(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceFallbackKey{}, true)
+}
+
+func isFallbackForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceFallbackKey{}).(bool)
+	return forced
+}
+
+// WithCost returns a context that makes runs use cost of a circuit's concurrency limit instead of 1, and of its
+// partition's limit if ExecutionConfig.PartitionKey is set.  Use this so heavy calls, like batches, use more of the
+// limit than cheap ones through the same circuit.  Costs below 1 are
+// ignored.  A run that costs more than the whole limit is always rejected.  The cost only applies to the circuit given
+// ctx: it is removed from the contexts given to runFunc and the fallback, so the circuits they call cost 1.
+func WithCost(ctx context.Context, cost int64) context.Context {
+	return context.WithValue(ctx, costKey{}, cost)
+}
+
+// Cost is how much of a concurrency limit a run with ctx uses.  It is 1 unless ctx came from WithCost.
+// ConcurrencyLimiter implementations can use it to weigh runs.
+func Cost(ctx context.Context) int64 {
+	if cost, ok := ctx.Value(costKey{}).(int64); ok && cost > 1 {
+		return cost
+	}
+	return 1
+}
+
+// WithRetry returns a context that marks runs as retries of an earlier failed call.  Retries are only let through
+// while they fit in the circuit's retry budget, so retry storms cannot multiply the load on a struggling dependency.
+// See ExecutionConfig.RetryBudgetPercent.  Only the circuit given ctx sees the retry: it is removed from the contexts
+// given to runFunc and the fallback, so the circuits they call do not charge their own retry budgets.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// IsRetry returns true if ctx came from WithRetry
+func IsRetry(ctx context.Context) bool {
+	retry, _ := ctx.Value(retryKey{}).(bool)
+	return retry
+}
@@ -0,0 +1,208 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutOverride(t *testing.T) {
+	timeoutOf := func(c *Circuit, ctx context.Context) time.Duration {
+		var timeout time.Duration
+		start := time.Now()
+		_ = c.Run(ctx, func(ctx context.Context) error {
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = deadline.Sub(start).Round(time.Second)
+			}
+			return nil
+		})
+		return timeout
+	}
+	c := NewCircuitFromConfig("TestWithTimeoutOverride", Config{
+		Execution: ExecutionConfig{
+			Timeout: 10 * time.Second,
+		},
+	})
+	if timeout := timeoutOf(c, context.Background()); timeout != 10*time.Second {
+		t.Errorf("expected the circuit's timeout without an override, got %s", timeout)
+	}
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), 2*time.Second)); timeout != 2*time.Second {
+		t.Errorf("expected overrides to shorten the timeout, got %s", timeout)
+	}
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), time.Minute)); timeout != 10*time.Second {
+		t.Errorf("expected overrides to be capped at the timeout by default, got %s", timeout)
+	}
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), -time.Second)); timeout != 10*time.Second {
+		t.Errorf("expected negative overrides to be ignored, got %s", timeout)
+	}
+
+	cfg := c.Config()
+	cfg.Execution.MaxTimeoutOverride = 30 * time.Second
+	c.SetConfigThreadSafe(cfg)
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), 20*time.Second)); timeout != 20*time.Second {
+		t.Errorf("expected overrides up to MaxTimeoutOverride, got %s", timeout)
+	}
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), time.Minute)); timeout != 30*time.Second {
+		t.Errorf("expected overrides to be capped at MaxTimeoutOverride, got %s", timeout)
+	}
+
+	cfg.Execution.MaxTimeoutOverride = -1
+	c.SetConfigThreadSafe(cfg)
+	if timeout := timeoutOf(c, WithTimeoutOverride(context.Background(), time.Hour)); timeout != time.Hour {
+		t.Errorf("expected no cap, got %s", timeout)
+	}
+}
+
+func TestWithTimeoutOverride_timesOut(t *testing.T) {
+	c := NewCircuitFromConfig("TestWithTimeoutOverride_timesOut", Config{
+		Execution: ExecutionConfig{
+			Timeout: time.Hour,
+		},
+	})
+	err := c.Run(WithTimeoutOverride(context.Background(), time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the run to time out, got %v", err)
+	}
+}
+
+type countsSuccesses struct {
+	neverOpens
+	successes int
+}
+
+func (c *countsSuccesses) Success(_ context.Context, _ time.Time, _ time.Duration) {
+	c.successes++
+}
+
+func TestWithBypass(t *testing.T) {
+	metrics := &countsSuccesses{}
+	c := NewCircuitFromConfig("TestWithBypass", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	c.OpenCircuit(context.Background())
+	ran := false
+	run := func(_ context.Context) error {
+		ran = true
+		return nil
+	}
+	if err := c.Run(context.Background(), run); !isOpenError(err) || ran {
+		t.Fatalf("expected the open circuit to short circuit, got %v", err)
+	}
+	if err := c.Run(WithBypass(context.Background()), run); err != nil || !ran {
+		t.Fatalf("expected bypassed runs to ignore the open circuit, got %v", err)
+	}
+	if metrics.successes != 1 {
+		t.Errorf("expected the bypassed run to be recorded, got %d successes", metrics.successes)
+	}
+
+	c.CloseCircuit(context.Background())
+	err := c.Run(context.Background(), func(_ context.Context) error {
+		if err := c.Run(context.Background(), run); !errors.Is(err, ErrConcurrencyLimitReached) {
+			t.Errorf("expected the concurrency limit, got %v", err)
+		}
+		return c.Run(WithBypass(context.Background()), run)
+	})
+	if err != nil {
+		t.Errorf("expected bypassed runs to ignore the concurrency limit, got %v", err)
+	}
+}
+
+func TestWithForcedFallback(t *testing.T) {
+	metrics := &countsSuccesses{}
+	c := NewCircuitFromConfig("TestWithForcedFallback", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx := WithForcedFallback(context.Background())
+	var fallbackErr error
+	err := c.Execute(ctx, func(_ context.Context) error {
+		t.Error("runFunc should not be called")
+		return nil
+	}, func(_ context.Context, err error) error {
+		fallbackErr = err
+		return nil
+	})
+	if err != nil || fallbackErr != ErrForcedFallback {
+		t.Errorf("expected the fallback to get ErrForcedFallback, got %v and %v", err, fallbackErr)
+	}
+	if metrics.successes != 0 {
+		t.Error("expected nothing to be recorded for runFunc")
+	}
+	if err := c.Run(ctx, func(_ context.Context) error { return nil }); err != ErrForcedFallback {
+		t.Errorf("expected ErrForcedFallback without a fallback, got %v", err)
+	}
+}
+
+func TestWithCost(t *testing.T) {
+	c := NewCircuitFromConfig("TestWithCost", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 4,
+		},
+	})
+	cheap := func(_ context.Context) error { return nil }
+	err := c.Run(WithCost(context.Background(), 3), func(_ context.Context) error {
+		if c.ConcurrentCommands() != 3 {
+			t.Errorf("expected the run to count 3 times, got %d", c.ConcurrentCommands())
+		}
+		if err := c.Run(context.Background(), cheap); err != nil {
+			t.Errorf("expected room for a cheap run, got %v", err)
+		}
+		if err := c.Run(WithCost(context.Background(), 2), cheap); !errors.Is(err, ErrConcurrencyLimitReached) {
+			t.Errorf("expected the heavy run to be limited, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ConcurrentCommands() != 0 {
+		t.Errorf("expected the cost to be given back, got %d", c.ConcurrentCommands())
+	}
+	if cost := Cost(WithCost(context.Background(), -5)); cost != 1 {
+		t.Errorf("expected costs below 1 to be ignored, got %d", cost)
+	}
+}
+
+func TestOverrides_notNested(t *testing.T) {
+	nested := NewCircuitFromConfig("TestOverrides_notNested.nested", Config{
+		Execution: ExecutionConfig{
+			Timeout:               time.Second,
+			MaxConcurrentRequests: 1,
+		},
+	})
+	nested.OpenCircuit(context.Background())
+	c := NewCircuitFromConfig("TestOverrides_notNested", Config{})
+	ctx := WithRetry(WithCost(WithBypass(WithTimeoutOverride(WithForcedFallback(context.Background()), time.Minute)), 2))
+	checkNested := func(ctx context.Context) error {
+		if ctx.Value(forceFallbackKey{}) != nil || isBypassed(ctx) || Cost(ctx) != 1 || nested.timeout(ctx) != time.Second || IsRetry(ctx) {
+			t.Error("expected the overrides to be removed")
+		}
+		// The nested circuit is open, and not bypassed
+		if err := nested.Run(ctx, func(_ context.Context) error { return nil }); !isOpenError(err) {
+			t.Errorf("expected the nested circuit to short circuit, got %v", err)
+		}
+		return nil
+	}
+	fallbackCalled := false
+	err := c.Execute(ctx, checkNested, func(ctx context.Context, err error) error {
+		fallbackCalled = true
+		return checkNested(ctx)
+	})
+	if err != nil || !fallbackCalled {
+		t.Fatalf("expected the forced fallback to run, got %v", err)
+	}
+	// Without the forced fallback, runFunc gets a context without the other overrides
+	if err := c.Run(WithRetry(WithCost(WithBypass(context.Background()), 2)), checkNested); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,108 @@
+package circuit
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// PartitionStats describes a single partition of a circuit's concurrency limit.  See ExecutionConfig.PartitionKey.
+type PartitionStats struct {
+	// Key is the value returned by ExecutionConfig.PartitionKey
+	Key string
+	// ConcurrentCommands is how many commands of this partition are currently running, weighed by their Cost
+	ConcurrentCommands int64
+	// ConcurrencyLimitRejects is how many commands of this partition were rejected by
+	// ExecutionConfig.MaxConcurrentRequestsPerPartition since the partition was created
+	ConcurrencyLimitRejects int64
+	// LastUsed is the last time a command of this partition started
+	LastUsed time.Time
+}
+
+type partition struct {
+	key                     string
+	concurrentCommands      faststats.AtomicInt64
+	concurrencyLimitRejects faststats.AtomicInt64
+	// lastUsed is protected by the partitions mutex
+	lastUsed time.Time
+}
+
+// partitions tracks a concurrency count per partition key.  The least recently used idle partitions are forgotten
+// once there are more than maxPartitions.
+type partitions struct {
+	maxPartitions int
+	byKey         map[string]*list.Element
+	// Most recently used partitions are at the front
+	lru *list.List
+	mu  sync.Mutex
+}
+
+func newPartitions(maxPartitions int) *partitions {
+	return &partitions{
+		maxPartitions: maxPartitions,
+		byKey:         make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// acquire adds a running command that costs cost to a partition.  The returned partition must be passed to release
+// with the same cost.
+func (p *partitions) acquire(key string, now time.Time, cost int64) (*partition, int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var part *partition
+	if elem, exists := p.byKey[key]; exists {
+		p.lru.MoveToFront(elem)
+		part = elem.Value.(*partition)
+	} else {
+		part = &partition{key: key}
+		p.byKey[key] = p.lru.PushFront(part)
+		p.evictWithLock(part)
+	}
+	part.lastUsed = now
+	return part, part.concurrentCommands.Add(cost)
+}
+
+func (p *partitions) release(part *partition, cost int64) {
+	part.concurrentCommands.Add(-cost)
+}
+
+// evictWithLock forgets the least recently used idle partitions until there are at most maxPartitions.  acquiring is
+// the partition being acquired, which is idle until its command is counted, and is never forgotten.
+func (p *partitions) evictWithLock(acquiring *partition) {
+	elem := p.lru.Back()
+	for p.lru.Len() > p.maxPartitions && elem != nil {
+		prev := elem.Prev()
+		part := elem.Value.(*partition)
+		if part != acquiring && part.concurrentCommands.Get() == 0 {
+			p.lru.Remove(elem)
+			delete(p.byKey, part.key)
+		}
+		elem = prev
+	}
+}
+
+func (p *partitions) stats() []PartitionStats {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	ret := make([]PartitionStats, 0, p.lru.Len())
+	for elem := p.lru.Front(); elem != nil; elem = elem.Next() {
+		part := elem.Value.(*partition)
+		ret = append(ret, PartitionStats{
+			Key:                     part.key,
+			ConcurrentCommands:      part.concurrentCommands.Get(),
+			ConcurrencyLimitRejects: part.concurrencyLimitRejects.Get(),
+			LastUsed:                part.lastUsed,
+		})
+	}
+	p.mu.Unlock()
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Key < ret[j].Key
+	})
+	return ret
+}
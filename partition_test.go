@@ -0,0 +1,108 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type tenantKey struct{}
+
+func tenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+func TestCircuit_PartitionKey(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_PartitionKey", Config{
+		Execution: ExecutionConfig{
+			PartitionKey:                      tenantFromContext,
+			MaxConcurrentRequests:             10,
+			MaxConcurrentRequestsPerPartition: 1,
+		},
+	})
+	noisy := context.WithValue(context.Background(), tenantKey{}, "noisy")
+	quiet := context.WithValue(context.Background(), tenantKey{}, "quiet")
+
+	err := c.Execute(noisy, func(_ context.Context) error {
+		err := c.Execute(noisy, func(_ context.Context) error { return nil }, nil)
+		var limitErr *ConcurrencyLimitError
+		if !errors.As(err, &limitErr) || limitErr.Partition != "noisy" || limitErr.Limit != 1 || limitErr.InFlight != 1 {
+			t.Errorf("expected the noisy partition to be throttled, got %v", err)
+		}
+		return c.Execute(quiet, func(_ context.Context) error { return nil }, nil)
+	}, nil)
+	if err != nil {
+		t.Errorf("other partitions should not be throttled: %v", err)
+	}
+
+	stats := c.Partitions()
+	if len(stats) != 2 || stats[0].Key != "noisy" || stats[0].ConcurrencyLimitRejects != 1 || stats[1].ConcurrencyLimitRejects != 0 {
+		t.Errorf("unexpected partition stats %v", stats)
+	}
+}
+
+func TestCircuit_PartitionCost(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_PartitionCost", Config{
+		Execution: ExecutionConfig{
+			PartitionKey:                      tenantFromContext,
+			MaxConcurrentRequests:             10,
+			MaxConcurrentRequestsPerPartition: 4,
+		},
+	})
+	tenant := context.WithValue(context.Background(), tenantKey{}, "batch")
+	err := c.Execute(WithCost(tenant, 3), func(_ context.Context) error {
+		if stats := c.Partitions(); len(stats) != 1 || stats[0].ConcurrentCommands != 3 {
+			t.Errorf("expected the partition to count the cost of the run, got %v", stats)
+		}
+		err := c.Execute(WithCost(tenant, 2), func(_ context.Context) error { return nil }, nil)
+		var limitErr *ConcurrencyLimitError
+		if !errors.As(err, &limitErr) || limitErr.Partition != "batch" || limitErr.InFlight != 3 {
+			t.Errorf("expected a run that does not fit in the partition to be throttled, got %v", err)
+		}
+		return c.Execute(tenant, func(_ context.Context) error { return nil }, nil)
+	}, nil)
+	if err != nil {
+		t.Errorf("expected a run that fits in the partition to run: %v", err)
+	}
+	if stats := c.Partitions(); stats[0].ConcurrentCommands != 0 {
+		t.Errorf("expected the cost to be released, got %v", stats)
+	}
+}
+
+func TestPartitions_Evict(t *testing.T) {
+	p := newPartitions(2)
+	now := time.Now()
+	busy, _ := p.acquire("busy", now, 1)
+	idle, _ := p.acquire("idle", now, 1)
+	p.release(idle, 1)
+	third, _ := p.acquire("third", now, 1)
+	p.release(third, 1)
+	stats := p.stats()
+	if len(stats) != 2 || stats[0].Key != "busy" || stats[1].Key != "third" {
+		t.Errorf("expected the idle partition to be evicted, got %v", stats)
+	}
+	p.release(busy, 1)
+	_, _ = p.acquire("fourth", now, 1)
+	if stats := p.stats(); len(stats) != 2 || stats[0].Key != "fourth" || stats[1].Key != "third" {
+		t.Errorf("expected the least recently used partition to be evicted, got %v", stats)
+	}
+}
+
+func TestPartitions_EvictNotAcquiring(t *testing.T) {
+	p := newPartitions(1)
+	now := time.Now()
+	busy, _ := p.acquire("busy", now, 1)
+	// Every other partition is busy, so the new partition is the only idle one until its command is counted
+	added, running := p.acquire("added", now, 1)
+	if running != 1 {
+		t.Errorf("expected the new partition to count its command, got %d", running)
+	}
+	stats := p.stats()
+	if len(stats) != 2 || stats[0].Key != "added" || stats[1].Key != "busy" {
+		t.Errorf("expected the acquired partition to be kept while others are busy, got %v", stats)
+	}
+	p.release(added, 1)
+	p.release(busy, 1)
+}
@@ -0,0 +1,215 @@
+package circuit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// QueueMetrics can optionally be implemented by RunMetrics that want to know how long runs wait for a slot when
+// ExecutionConfig.MaxConcurrentWait or ExecutionConfig.MaxQueueSize is set.  QueueWait is called each time a run leaves
+// the queue, whether it got a slot or was rejected.  The time spent waiting is not part of the run's duration.
+type QueueMetrics interface {
+	QueueWait(ctx context.Context, now time.Time, wait time.Duration)
+}
+
+var _ QueueMetrics = RunMetricsCollection(nil)
+
+// QueueOrder is the order runs waiting for a slot get one in.  See ExecutionConfig.QueueOrder.
+type QueueOrder string
+
+const (
+	// QueueFIFO gives slots to the run that has waited longest
+	QueueFIFO QueueOrder = "fifo"
+	// QueueLIFO gives slots to the run that has waited least.  During overload the oldest runs have often been
+	// given up on by their callers, so serving the newest first keeps the runs that finish useful.  Runs that are
+	// never served are shed when their wait ends.
+	QueueLIFO QueueOrder = "lifo"
+	// QueueAdaptiveLIFO is QueueFIFO until the queue is congested, then QueueLIFO.  The queue is congested while its
+	// oldest run has used over half of its wait.
+	QueueAdaptiveLIFO QueueOrder = "adaptive-lifo"
+)
+
+// queueOrder is the current ExecutionConfig.QueueOrder
+func (c *Circuit) queueOrder() QueueOrder {
+	if order := c.config().Execution.QueueOrder; order != "" {
+		return order
+	}
+	return QueueFIFO
+}
+
+// QueueDepth returns how many runs are waiting for a slot.  See ExecutionConfig.MaxConcurrentWait.
+func (c *Circuit) QueueDepth() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.queue.depth.Get()
+}
+
+// queueTimeout is how long a run with ctx may wait in the queue, or 0 if only ctx bounds it
+func (c *Circuit) queueTimeout(ctx context.Context) time.Duration {
+	if wait := c.config().Execution.MaxConcurrentWait; wait > 0 {
+		return wait
+	}
+	return c.timeout(ctx)
+}
+
+// waitInQueue waits for room under MaxConcurrentRequests for a run that did not fit.  The run's cost must not be
+// counted in concurrentCommands.  It returns true, with the cost counted, if the run got a slot.
+func (c *Circuit) waitInQueue(ctx context.Context, cost int64, overhead *overheadTimer) bool {
+	cfg := c.config()
+	maxSize := cfg.Execution.MaxQueueSize
+	if cfg.Execution.MaxConcurrentWait > 0 {
+		if maxSize <= 0 {
+			maxSize = math.MaxInt64
+		}
+	} else if maxSize <= 0 {
+		return false
+	}
+	if maxConcurrent := cfg.Execution.MaxConcurrentRequests; maxConcurrent >= 0 && cost > maxConcurrent {
+		// It would never fit
+		return false
+	}
+	start := c.now()
+	queueStart := overhead.now()
+	admitted, joined := c.queue.wait(ctx, maxSize, c.queueTimeout(ctx), cfg.General.TimeKeeper, c.queueOrder, func() bool {
+		if !c.throttleConcurrentCommands(c.concurrentCommands.Add(cost)) {
+			return true
+		}
+		c.concurrentCommands.Add(-cost)
+		return false
+	})
+	if joined {
+		now := c.now()
+		c.CmdMetricCollector.QueueWait(ctx, now, now.Sub(start))
+		overhead.exclude(queueStart)
+	}
+	return admitted
+}
+
+// releaseCommands ends a run of cost, and lets the next queued run try for its slot
+func (c *Circuit) releaseCommands(cost int64) {
+	c.concurrentCommands.Add(-cost)
+	if c.queue.depth.Get() > 0 {
+		c.queue.wakeOne(c.queueOrder(), c.now())
+	}
+}
+
+// runQueue is a line of runs waiting for a slot, oldest first
+type runQueue struct {
+	depth   faststats.AtomicInt64
+	mu      sync.Mutex
+	waiters list.List
+}
+
+// queueWaiter is a run in a runQueue
+type queueWaiter struct {
+	woken  chan struct{}
+	joined time.Time
+	// halfway is when the run has used half of its wait, or zero if only its context bounds the wait
+	halfway time.Time
+}
+
+// wait joins the line and calls tryAcquire each time the run is woken and a slot frees up.  order picks which run is
+// woken.  admitted is true once tryAcquire is, and false if timeout passes on clock or ctx ends first.  joined is false
+// if the line was already maxSize long.
+func (q *runQueue) wait(ctx context.Context, maxSize int64, timeout time.Duration, clock TimeKeeper, order func() QueueOrder, tryAcquire func() bool) (admitted bool, joined bool) {
+	if q.depth.Add(1) > maxSize {
+		q.depth.Add(-1)
+		return false, false
+	}
+	defer q.depth.Add(-1)
+	w := &queueWaiter{
+		woken:  make(chan struct{}, 1),
+		joined: clock.Now(),
+	}
+	var expired chan struct{}
+	if timeout > 0 {
+		w.halfway = w.joined.Add(timeout / 2)
+		expired = make(chan struct{})
+		timer := clock.AfterFunc(timeout, func() {
+			close(expired)
+		})
+		if timer != nil {
+			defer timer.Stop()
+		}
+	}
+	q.mu.Lock()
+	elem := q.waiters.PushBack(w)
+	q.mu.Unlock()
+	for {
+		// Try after joining the line, so a slot freed before joining is not missed
+		if tryAcquire() {
+			q.leave(elem, w, order(), clock.Now)
+			return true, true
+		}
+		select {
+		case <-w.woken:
+			// Woken runs are out of the line.  Keep the place in line in case the slot is taken first.
+			elem = q.rejoin(w)
+		case <-expired:
+			q.leave(elem, w, order(), clock.Now)
+			return false, true
+		case <-ctx.Done():
+			q.leave(elem, w, order(), clock.Now)
+			return false, true
+		}
+	}
+}
+
+// rejoin puts a woken run back in line where it was
+func (q *runQueue) rejoin(w *queueWaiter) *list.Element {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for e := q.waiters.Back(); e != nil; e = e.Prev() {
+		if !e.Value.(*queueWaiter).joined.After(w.joined) {
+			return q.waiters.InsertAfter(w, e)
+		}
+	}
+	return q.waiters.PushFront(w)
+}
+
+// leave removes a run from the line.  A wake up the run got but will not use is passed on to the next run.
+func (q *runQueue) leave(elem *list.Element, w *queueWaiter, order QueueOrder, now func() time.Time) {
+	q.mu.Lock()
+	q.waiters.Remove(elem)
+	q.mu.Unlock()
+	// Runs are only woken while in the line, so once out of it no wake up can arrive later
+	select {
+	case <-w.woken:
+		q.wakeOne(order, now())
+	default:
+	}
+}
+
+// wakeOne lets the next run in order try for a slot
+func (q *runQueue) wakeOne(order QueueOrder, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	next := q.waiters.Front()
+	if next == nil {
+		return
+	}
+	if q.newestFirst(order, next.Value.(*queueWaiter), now) {
+		next = q.waiters.Back()
+	}
+	q.waiters.Remove(next)
+	// Wake it before unlocking, so a run that leaves the line sees every wake up it got.  This never blocks: runs
+	// empty woken before they get back in line.
+	next.Value.(*queueWaiter).woken <- struct{}{}
+}
+
+// newestFirst is true if the newest run should be woken before oldest, the run at the front of the line
+func (q *runQueue) newestFirst(order QueueOrder, oldest *queueWaiter, now time.Time) bool {
+	switch order {
+	case QueueLIFO:
+		return true
+	case QueueAdaptiveLIFO:
+		return !oldest.halfway.IsZero() && now.After(oldest.halfway)
+	}
+	return false
+}
@@ -0,0 +1,338 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+	"github.com/cep21/circuit/v4/internal/clock"
+)
+
+type queueWaits struct {
+	RunMetrics
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (q *queueWaits) QueueWait(_ context.Context, _ time.Time, wait time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.waits = append(q.waits, wait)
+}
+
+func (q *queueWaits) Success(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func (q *queueWaits) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {}
+
+func (q *queueWaits) count() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waits)
+}
+
+func TestCircuit_Queue(t *testing.T) {
+	metrics := &queueWaits{}
+	c := NewCircuitFromConfig("TestCircuit_Queue", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+			MaxQueueSize:          1,
+			Timeout:               time.Minute,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx := context.Background()
+	running := make(chan struct{})
+	finish := make(chan struct{})
+	firstDone := make(chan error)
+	go func() {
+		firstDone <- c.Run(ctx, func(_ context.Context) error {
+			close(running)
+			<-finish
+			return nil
+		})
+	}()
+	<-running
+	queuedDone := make(chan error)
+	go func() {
+		queuedDone <- c.Run(ctx, func(_ context.Context) error {
+			return nil
+		})
+	}()
+	for c.QueueDepth() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	err := c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	var circuitErr Error
+	if !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Errorf("expected a full queue to reject the run, got %v", err)
+	}
+	close(finish)
+	if err := <-firstDone; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-queuedDone; err != nil {
+		t.Errorf("expected the queued run to get the slot, got %v", err)
+	}
+	if c.QueueDepth() != 0 {
+		t.Errorf("expected an empty queue, got %d", c.QueueDepth())
+	}
+	if count := metrics.count(); count != 1 {
+		t.Errorf("expected one queue wait, got %d", count)
+	}
+}
+
+func TestCircuit_QueueWait(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_QueueWait", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+			MaxConcurrentWait:     10 * time.Millisecond,
+		},
+	})
+	ctx := context.Background()
+	running := make(chan struct{})
+	finish := make(chan struct{})
+	defer close(finish)
+	go func() {
+		_ = c.Run(ctx, func(_ context.Context) error {
+			close(running)
+			<-finish
+			return nil
+		})
+	}()
+	<-running
+	start := time.Now()
+	err := c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	var circuitErr Error
+	if !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Errorf("expected the run to be rejected after MaxConcurrentWait, got %v", err)
+	}
+	if waited := time.Since(start); waited < 10*time.Millisecond {
+		t.Errorf("expected the run to wait in the queue, waited %s", waited)
+	}
+
+	// Waiting can be turned off while the circuit runs
+	cfg := c.Config()
+	cfg.Execution.MaxConcurrentWait = -1
+	c.SetConfigThreadSafe(cfg)
+	start = time.Now()
+	err = c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	if !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Errorf("expected the run to be rejected, got %v", err)
+	}
+	if waited := time.Since(start); waited >= 10*time.Millisecond {
+		t.Errorf("expected the run to be rejected right away, waited %s", waited)
+	}
+}
+
+func TestCircuit_MaxConcurrentWaitContext(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_MaxConcurrentWaitContext", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+			MaxConcurrentWait:     time.Hour,
+		},
+	})
+	running := make(chan struct{})
+	finish := make(chan struct{})
+	defer close(finish)
+	go func() {
+		_ = c.Run(context.Background(), func(_ context.Context) error {
+			close(running)
+			<-finish
+			return nil
+		})
+	}()
+	<-running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	var circuitErr Error
+	if !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Errorf("expected the run to stop waiting when its context ends, got %v", err)
+	}
+}
+
+func TestCircuit_QueueWaitClock(t *testing.T) {
+	mock := &clock.MockClock{}
+	mock.Set(time.Now())
+	c := NewCircuitFromConfig("TestCircuit_QueueWaitClock", Config{
+		General: GeneralConfig{
+			TimeKeeper: TimeKeeper{
+				Now:       mock.Now,
+				AfterFunc: mock.AfterFunc,
+			},
+		},
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 1,
+			MaxConcurrentWait:     time.Hour,
+		},
+	})
+	running := make(chan struct{})
+	finish := make(chan struct{})
+	defer close(finish)
+	go func() {
+		_ = c.Run(context.Background(), func(_ context.Context) error {
+			close(running)
+			<-finish
+			return nil
+		})
+	}()
+	<-running
+	queuedDone := make(chan error)
+	go func() {
+		queuedDone <- c.Run(context.Background(), func(_ context.Context) error {
+			return nil
+		})
+	}()
+	for c.QueueDepth() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// The wait is only over once the circuit's clock says so
+	mock.Add(time.Hour)
+	var circuitErr Error
+	if err := <-queuedDone; !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Errorf("expected the run to stop waiting when the clock passes its wait, got %v", err)
+	}
+}
+
+func TestRunQueue_LeavePassesOnWakeUp(t *testing.T) {
+	var q runQueue
+	first := &queueWaiter{woken: make(chan struct{}, 1), joined: time.Now()}
+	second := &queueWaiter{woken: make(chan struct{}, 1), joined: first.joined.Add(time.Millisecond)}
+	firstElem := q.waiters.PushBack(first)
+	q.waiters.PushBack(second)
+	q.wakeOne(QueueFIFO, time.Now())
+	// The first run gives up right after it was picked, before reading its wake up
+	q.leave(firstElem, first, QueueFIFO, time.Now)
+	select {
+	case <-second.woken:
+	default:
+		t.Error("expected the wake up the first run did not use to go to the second")
+	}
+	if q.waiters.Len() != 0 {
+		t.Errorf("expected both runs out of the line, got %d", q.waiters.Len())
+	}
+}
+
+func TestCircuit_QueueConcurrent(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_QueueConcurrent", Config{
+		Execution: ExecutionConfig{
+			MaxConcurrentRequests: 2,
+			MaxQueueSize:          100,
+			Timeout:               time.Minute,
+		},
+	})
+	var current, highest faststats.AtomicInt64
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Run(context.Background(), func(_ context.Context) error {
+				now := current.Add(1)
+				defer current.Add(-1)
+				for {
+					high := highest.Get()
+					if now <= high || highest.CompareAndSwap(high, now) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected every run to get a slot, got %v", err)
+		}
+	}
+	if highest.Get() > 2 {
+		t.Errorf("expected at most 2 concurrent runs, saw %d", highest.Get())
+	}
+}
+
+func TestCircuit_QueueOrder(t *testing.T) {
+	testCases := []struct {
+		name      string
+		order     QueueOrder
+		wait      time.Duration
+		congested time.Duration
+		expected  []int
+	}{
+		{name: "default", wait: time.Hour, expected: []int{0, 1, 2}},
+		{name: "fifo", order: QueueFIFO, wait: time.Hour, expected: []int{0, 1, 2}},
+		{name: "lifo", order: QueueLIFO, wait: time.Hour, expected: []int{2, 1, 0}},
+		{name: "adaptive", order: QueueAdaptiveLIFO, wait: time.Hour, expected: []int{0, 1, 2}},
+		{name: "adaptive congested", order: QueueAdaptiveLIFO, wait: 400 * time.Millisecond, congested: 250 * time.Millisecond, expected: []int{2, 1, 0}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCircuitFromConfig("TestCircuit_QueueOrder", Config{
+				Execution: ExecutionConfig{
+					MaxConcurrentRequests: 1,
+					MaxConcurrentWait:     tc.wait,
+					QueueOrder:            tc.order,
+				},
+			})
+			running := make(chan struct{})
+			finish := make(chan struct{})
+			go func() {
+				_ = c.Run(context.Background(), func(_ context.Context) error {
+					close(running)
+					<-finish
+					return nil
+				})
+			}()
+			<-running
+
+			var mu sync.Mutex
+			var ran []int
+			var wg sync.WaitGroup
+			for i := 0; i < len(tc.expected); i++ {
+				i := i
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := c.Run(context.Background(), func(_ context.Context) error {
+						mu.Lock()
+						defer mu.Unlock()
+						ran = append(ran, i)
+						return nil
+					})
+					if err != nil {
+						t.Errorf("expected queued run %d to run, got %v", i, err)
+					}
+				}()
+				// Join the queue one at a time, so the order they joined is known
+				for c.QueueDepth() != int64(i+1) {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			time.Sleep(tc.congested)
+			close(finish)
+			wg.Wait()
+			for i := range tc.expected {
+				if ran[i] != tc.expected[i] {
+					t.Fatalf("expected runs in order %v, got %v", tc.expected, ran)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package circuit
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// Rand is the source of the random choices made for a circuit, like sleep window jitter or which requests are let
+// through while traffic ramps up.  Give each circuit its own seeded Rand to make simulations reproducible, or to
+// deliberately spread out the choices of a fleet.  It must be safe to call from many goroutines.
+type Rand interface {
+	// Int63n returns a number in [0, n)
+	Int63n(n int64) int64
+	// Float64 returns a number in [0.0, 1.0)
+	Float64() float64
+}
+
+// RandInheritor is optionally implemented by ClosedToOpen, OpenToClosed, and metrics collectors that make random
+// choices.  A circuit with GeneralConfig.Rand set gives it to them when it is set up.
+type RandInheritor interface {
+	// InheritRand sets the Rand used for choices that were not given a Rand of their own
+	InheritRand(r Rand)
+}
+
+// inheritRand gives r to the metrics collectors that are a RandInheritor.  The opener and closer are collectors too.
+func (c *Circuit) inheritRand(r Rand) {
+	inherit := func(v interface{}) {
+		if inheritor, ok := v.(RandInheritor); ok {
+			inheritor.InheritRand(r)
+		}
+	}
+	for _, m := range c.CmdMetricCollector {
+		inherit(m)
+	}
+	for _, m := range c.FallbackMetricCollector {
+		inherit(m)
+	}
+	for _, m := range c.CircuitMetricsCollector {
+		inherit(m)
+	}
+}
+
+// NewRand returns a Rand that always makes the same choices for the same seed
+func NewRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// CircuitSeed mixes seed with a circuit's name.  Circuits given NewRand(CircuitSeed(seed, name)) make different choices
+// from each other, but the same choices every time for the same seed.
+func CircuitSeed(seed int64, circuitName string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(circuitName))
+	return seed ^ int64(h.Sum64())
+}
+
+// lockedRand makes a rand.Rand safe to share between goroutines
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Int63n(n int64) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
@@ -0,0 +1,69 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewRand(t *testing.T) {
+	a := NewRand(42)
+	b := NewRand(42)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Int63n(1000), b.Int63n(1000); x != y {
+			t.Fatalf("expected the same choices for the same seed, got %d and %d", x, y)
+		}
+		if x, y := a.Float64(), b.Float64(); x != y {
+			t.Fatalf("expected the same choices for the same seed, got %f and %f", x, y)
+		}
+	}
+}
+
+func TestCircuitSeed(t *testing.T) {
+	if CircuitSeed(1, "a") != CircuitSeed(1, "a") {
+		t.Fatal("expected the same seed for the same circuit")
+	}
+	if CircuitSeed(1, "a") == CircuitSeed(1, "b") {
+		t.Fatal("expected circuits to get different seeds")
+	}
+	if CircuitSeed(1, "a") == CircuitSeed(2, "a") {
+		t.Fatal("expected different seeds to stay different")
+	}
+}
+
+// randCollector remembers the Rand its circuit gave it
+type randCollector struct {
+	RunMetrics
+	rand Rand
+}
+
+func (r *randCollector) InheritRand(rnd Rand) {
+	r.rand = rnd
+}
+
+func (r *randCollector) Success(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func TestCircuit_InheritRand(t *testing.T) {
+	rnd := NewRand(1)
+	collector := &randCollector{}
+	NewCircuitFromConfig("TestCircuit_InheritRand", Config{
+		General: GeneralConfig{
+			Rand: rnd,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{collector},
+		},
+	})
+	if collector.rand != rnd {
+		t.Error("expected collectors to inherit the circuit's Rand")
+	}
+	withoutRand := &randCollector{}
+	NewCircuitFromConfig("TestCircuit_InheritRandUnset", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{withoutRand},
+		},
+	})
+	if withoutRand.rand != nil {
+		t.Error("expected no Rand without GeneralConfig.Rand")
+	}
+}
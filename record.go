@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errRecordedFailure is the failure RecordFailure records when it is not given an error
+var errRecordedFailure = errors.New("recorded failure")
+
+// RecordSuccess records a call to the dependency that worked, but was made outside the circuit.  It counts towards the
+// circuit's health like a run that worked, and can close an open circuit.  Use it for work done elsewhere, like in an
+// async pipeline or a callback API, instead of a fake Execute.
+func (c *Circuit) RecordSuccess(ctx context.Context, duration time.Duration) {
+	if c.isEmptyOrNil() || c.config().General.Disabled {
+		return
+	}
+	c.checkSuccess(ctx, c.now(), duration)
+}
+
+// RecordFailure records a call to the dependency that failed with err, but was made outside the circuit.  err is
+// classified like an error returned by runFunc: bad requests and ExecutionConfig.SuccessErrors do not count as
+// failures.  Other errors, or a nil err, count as a failure and can open the circuit.
+func (c *Circuit) RecordFailure(ctx context.Context, duration time.Duration, err error) {
+	if c.isEmptyOrNil() || c.config().General.Disabled {
+		return
+	}
+	if err == nil {
+		err = errRecordedFailure
+	}
+	now := c.now()
+	if c.checkErrBadRequest(ctx, err, now, duration) {
+		return
+	}
+	if c.isSuccessError(err) {
+		c.checkSuccess(ctx, now, duration)
+		return
+	}
+	c.checkErrFailure(ctx, err, now, duration)
+}
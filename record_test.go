@@ -0,0 +1,88 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type recordedRuns struct {
+	RunMetrics
+	successes   int
+	failures    int
+	badRequests int
+	durations   []time.Duration
+}
+
+func (r *recordedRuns) Success(_ context.Context, _ time.Time, duration time.Duration) {
+	r.successes++
+	r.durations = append(r.durations, duration)
+}
+
+func (r *recordedRuns) ErrFailure(_ context.Context, _ time.Time, duration time.Duration) {
+	r.failures++
+	r.durations = append(r.durations, duration)
+}
+
+func (r *recordedRuns) ErrBadRequest(_ context.Context, _ time.Time, _ time.Duration) {
+	r.badRequests++
+}
+
+func (r *recordedRuns) RunError(_ context.Context, _ time.Time, _ error) {}
+
+func TestCircuit_RecordSuccess(t *testing.T) {
+	runs := &recordedRuns{}
+	c := NewCircuitFromConfig("TestCircuit_RecordSuccess", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{runs},
+		},
+	})
+	c.RecordSuccess(context.Background(), time.Second)
+	if runs.successes != 1 || runs.durations[0] != time.Second {
+		t.Errorf("expected one success of 1s, got %d %v", runs.successes, runs.durations)
+	}
+
+	var empty *Circuit
+	empty.RecordSuccess(context.Background(), time.Second)
+	empty.RecordFailure(context.Background(), time.Second, nil)
+}
+
+func TestCircuit_RecordFailure(t *testing.T) {
+	errNotFound := errors.New("not found")
+	runs := &recordedRuns{}
+	c := NewCircuitFromConfig("TestCircuit_RecordFailure", Config{
+		General: GeneralConfig{
+			ClosedToOpenFactory: openOnFirstErrorFactory,
+		},
+		Execution: ExecutionConfig{
+			SuccessErrors: []func(error) bool{
+				func(err error) bool {
+					return errors.Is(err, errNotFound)
+				},
+			},
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{runs},
+		},
+	})
+	ctx := context.Background()
+	c.RecordFailure(ctx, time.Second, SimpleBadRequest{Err: errors.New("bad input")})
+	c.RecordFailure(ctx, time.Second, errNotFound)
+	if c.IsOpen() {
+		t.Fatal("bad requests and success errors should not open the circuit")
+	}
+	if runs.badRequests != 1 || runs.successes != 1 {
+		t.Errorf("expected a bad request and a success, got %d and %d", runs.badRequests, runs.successes)
+	}
+	c.RecordFailure(ctx, time.Second, errors.New("dependency failed"))
+	if !c.IsOpen() {
+		t.Error("expected a recorded failure to open the circuit")
+	}
+	if runs.failures != 1 {
+		t.Errorf("expected one failure, got %d", runs.failures)
+	}
+	if errs := c.RecentErrors(); len(errs) != 1 {
+		t.Errorf("expected the failure in recent errors, got %v", errs)
+	}
+}
@@ -0,0 +1,28 @@
+package circuit
+
+// RedactedError is an error whose message was rewritten by GeneralConfig.RedactError.  Unwrap returns the original
+// error, so errors.Is and errors.As still work, but Error only returns the rewritten message.
+type RedactedError struct {
+	// Err is the error runFunc returned
+	Err error
+	// Message is what GeneralConfig.RedactError made of Err's message
+	Message string
+}
+
+func (r *RedactedError) Error() string {
+	return r.Message
+}
+
+// Unwrap returns the original error
+func (r *RedactedError) Unwrap() error {
+	return r.Err
+}
+
+// redact rewrites err's message with GeneralConfig.RedactError, if it is set
+func (c *Circuit) redact(err error) error {
+	redact := c.config().General.RedactError
+	if redact == nil || err == nil {
+		return err
+	}
+	return &RedactedError{Err: err, Message: redact(err.Error())}
+}
@@ -0,0 +1,59 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type runErrorCollector struct {
+	RunMetrics
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *runErrorCollector) RunError(_ context.Context, _ time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func TestCircuit_RedactError(t *testing.T) {
+	collector := &runErrorCollector{RunMetrics: neverOpensFactory()}
+	c := NewCircuitFromConfig("TestCircuit_RedactError", Config{
+		General: GeneralConfig{
+			RedactError: func(msg string) string {
+				return strings.ReplaceAll(msg, "hunter2", "REDACTED")
+			},
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{collector},
+		},
+	})
+	errSecret := errors.New("login failed with password hunter2")
+	err := c.Execute(context.Background(), func(_ context.Context) error {
+		return errSecret
+	}, nil)
+	if err != errSecret {
+		t.Errorf("expected the caller to get the original error, got %v", err)
+	}
+	_ = c.Execute(context.Background(), func(_ context.Context) error {
+		return SimpleBadRequest{Err: errors.New("bad token hunter2")}
+	}, nil)
+
+	if samples := c.RecentErrors(); len(samples) != 1 || samples[0].Err.Error() != "login failed with password REDACTED" {
+		t.Errorf("expected the recent error to be redacted, got %v", samples)
+	}
+	if samples := c.RecentBadRequests(); len(samples) != 1 || samples[0].Err.Error() != "bad token REDACTED" {
+		t.Errorf("expected the recent bad request to be redacted, got %v", samples)
+	}
+	if len(collector.errs) != 1 || collector.errs[0].Error() != "login failed with password REDACTED" {
+		t.Fatalf("expected collectors to see the redacted error, got %v", collector.errs)
+	}
+	if !errors.Is(collector.errs[0], errSecret) {
+		t.Error("expected the redacted error to unwrap to the original")
+	}
+}
@@ -0,0 +1,104 @@
+package circuit
+
+import "sync"
+
+// numRegistryShards is how many independently locked maps the circuit registry is split into
+const numRegistryShards = 32
+
+// circuitRegistry stores circuits by name.  Circuits are looked up on every request, so each shard keeps them in a
+// sync.Map: lookups never lock.  Splitting into shards lets circuits with different names be added in parallel.  The
+// zero value is ready to use.
+type circuitRegistry struct {
+	shards [numRegistryShards]registryShard
+}
+
+type registryShard struct {
+	circuits sync.Map
+	// pending holds a channel for each circuit being created, closed once the circuit is stored or creating it failed.
+	// It is guarded by mu.
+	pending map[string]chan struct{}
+	mu      sync.Mutex
+}
+
+func (s *registryShard) get(name string) *Circuit {
+	if c, exists := s.circuits.Load(name); exists {
+		return c.(*Circuit)
+	}
+	return nil
+}
+
+// shardFor hashes the name with FNV-1a.  It is inlined, rather than using hash/fnv, to avoid allocations on lookups.
+func (r *circuitRegistry) shardFor(name string) *registryShard {
+	const offset32 = 2166136261
+	const prime32 = 16777619
+	hash := uint32(offset32)
+	for i := 0; i < len(name); i++ {
+		hash ^= uint32(name[i])
+		hash *= prime32
+	}
+	return &r.shards[hash%numRegistryShards]
+}
+
+func (r *circuitRegistry) get(name string) *Circuit {
+	return r.shardFor(name).get(name)
+}
+
+// getOrCreate returns the circuit with name, calling create if it does not exist.  created is true if create was
+// called.  create runs without the shard's lock, so it can look up other circuits, but only one caller creates each
+// name: the others wait for it.
+func (r *circuitRegistry) getOrCreate(name string, create func() *Circuit) (c *Circuit, created bool) {
+	shard := r.shardFor(name)
+	for {
+		if c := shard.get(name); c != nil {
+			return c, false
+		}
+		shard.mu.Lock()
+		if c := shard.get(name); c != nil {
+			shard.mu.Unlock()
+			return c, false
+		}
+		if wait, exists := shard.pending[name]; exists {
+			shard.mu.Unlock()
+			<-wait
+			// Check again, in case creating it failed
+			continue
+		}
+		if shard.pending == nil {
+			shard.pending = make(map[string]chan struct{})
+		}
+		done := make(chan struct{})
+		shard.pending[name] = done
+		shard.mu.Unlock()
+		return shard.create(name, done, create), true
+	}
+}
+
+// create calls create and stores the circuit.  done is closed even if create panics.
+func (s *registryShard) create(name string, done chan struct{}, create func() *Circuit) *Circuit {
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, name)
+		s.mu.Unlock()
+		close(done)
+	}()
+	c := create()
+	s.circuits.Store(name, c)
+	return c
+}
+
+// remove deletes the circuit with name if it is c.  It returns false if the registry held a different circuit, or none.
+func (r *circuitRegistry) remove(name string, c *Circuit) bool {
+	return r.shardFor(name).circuits.CompareAndDelete(name, c)
+}
+
+// all returns every circuit in the registry
+func (r *circuitRegistry) all() []*Circuit {
+	var ret []*Circuit
+	for i := range r.shards {
+		r.shards[i].circuits.Range(func(_, c interface{}) bool {
+			ret = append(ret, c.(*Circuit))
+			return true
+		})
+	}
+	return ret
+}
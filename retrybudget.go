@@ -0,0 +1,98 @@
+package circuit
+
+import (
+	"context"
+	"time"
+
+	"github.com/cep21/circuit/v4/faststats"
+)
+
+// retryBudgetBuckets is how many buckets ExecutionConfig.RetryBudgetWindow is split into
+const retryBudgetBuckets = 10
+
+// ErrRetryBudgetExhausted is returned, or passed to the fallback, when a run marked with WithRetry does not fit in the
+// circuit's retry budget.  It reports ConcurrencyLimitReached, since the retry was shed to protect the dependency.
+var ErrRetryBudgetExhausted Error = &circuitError{concurrencyLimitReached: true, msg: "retry budget exhausted"}
+
+// RetryMetrics can optionally be implemented by RunMetrics that want to know how a circuit's retry budget is used.
+// Retry is called for each retry let through.  ErrRetryBudgetExhausted is called for each retry rejected, right after
+// ErrConcurrencyLimitReject.
+type RetryMetrics interface {
+	Retry(ctx context.Context, now time.Time)
+	ErrRetryBudgetExhausted(ctx context.Context, now time.Time)
+}
+
+var _ RetryMetrics = RunMetricsCollection(nil)
+
+// RetryBudgetStats is how much of a circuit's retry budget is used inside ExecutionConfig.RetryBudgetWindow
+type RetryBudgetStats struct {
+	// Attempts is how many runs were first attempts
+	Attempts int64
+	// Retries is how many retries were let through
+	Retries int64
+	// Available is how many more retries would be let through now.  It is -1 if there is no budget.
+	Available int64
+}
+
+// retryBudget counts first attempts and retries inside ExecutionConfig.RetryBudgetWindow
+type retryBudget struct {
+	attempts faststats.RollingCounter
+	retries  faststats.RollingCounter
+	window   time.Duration
+}
+
+func newRetryBudget(window time.Duration, now time.Time) retryBudget {
+	return retryBudget{
+		attempts: faststats.NewRollingCounter(window/retryBudgetBuckets, retryBudgetBuckets, now),
+		retries:  faststats.NewRollingCounter(window/retryBudgetBuckets, retryBudgetBuckets, now),
+		window:   window,
+	}
+}
+
+// RetryBudget returns how much of the retry budget is used.  See ExecutionConfig.RetryBudgetPercent.
+func (c *Circuit) RetryBudget() RetryBudgetStats {
+	if c == nil {
+		return RetryBudgetStats{}
+	}
+	now := c.now()
+	return RetryBudgetStats{
+		Attempts:  c.retryBudget.attempts.RollingSumAt(now),
+		Retries:   c.retryBudget.retries.RollingSumAt(now),
+		Available: c.retriesAvailable(now),
+	}
+}
+
+// retriesAvailable is how many retries fit in the budget now, or -1 if there is no budget
+func (c *Circuit) retriesAvailable(now time.Time) int64 {
+	cfg := c.config()
+	percent := cfg.Execution.RetryBudgetPercent
+	if percent < 0 {
+		return -1
+	}
+	minPerSecond := cfg.Execution.RetryBudgetMinPerSecond
+	if minPerSecond < 0 {
+		minPerSecond = 0
+	}
+	allowed := c.retryBudget.attempts.RollingSumAt(now)*percent/100 + int64(float64(minPerSecond)*c.retryBudget.window.Seconds())
+	available := allowed - c.retryBudget.retries.RollingSumAt(now)
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// checkRetryBudget records a run against the retry budget.  It returns false if the run is a retry that does not fit.
+func (c *Circuit) checkRetryBudget(ctx context.Context, now time.Time) bool {
+	if !IsRetry(ctx) {
+		c.retryBudget.attempts.Inc(now)
+		return true
+	}
+	if c.retriesAvailable(now) == 0 {
+		c.CmdMetricCollector.ErrConcurrencyLimitReject(ctx, now)
+		c.CmdMetricCollector.ErrRetryBudgetExhausted(ctx, now)
+		return false
+	}
+	c.retryBudget.retries.Inc(now)
+	c.CmdMetricCollector.Retry(ctx, now)
+	return true
+}
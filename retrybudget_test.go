@@ -0,0 +1,126 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type retryCounter struct {
+	RunMetrics
+	retries   int
+	exhausted int
+}
+
+func (r *retryCounter) Retry(_ context.Context, _ time.Time) {
+	r.retries++
+}
+
+func (r *retryCounter) ErrRetryBudgetExhausted(_ context.Context, _ time.Time) {
+	r.exhausted++
+}
+
+func (r *retryCounter) ErrConcurrencyLimitReject(_ context.Context, _ time.Time) {}
+
+func (r *retryCounter) Success(_ context.Context, _ time.Time, _ time.Duration) {}
+
+func TestCircuit_RetryBudget(t *testing.T) {
+	metrics := &retryCounter{}
+	c := NewCircuitFromConfig("TestCircuit_RetryBudget", Config{
+		Execution: ExecutionConfig{
+			RetryBudgetPercent:      20,
+			RetryBudgetMinPerSecond: -1,
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{metrics},
+		},
+	})
+	ctx := context.Background()
+	run := func(ctx context.Context) error {
+		return c.Run(ctx, func(_ context.Context) error {
+			return nil
+		})
+	}
+	for i := 0; i < 10; i++ {
+		if err := run(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := run(WithRetry(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(WithRetry(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	err := run(WithRetry(ctx))
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("expected the third retry over 10 attempts to be rejected, got %v", err)
+	}
+	var circuitErr Error
+	if !errors.As(err, &circuitErr) || !circuitErr.ConcurrencyLimitReached() {
+		t.Error("expected a concurrency limit error")
+	}
+	if err := run(WithBypass(WithRetry(ctx))); err != nil {
+		t.Errorf("expected bypassed retries to skip the budget, got %v", err)
+	}
+	if stats := c.RetryBudget(); stats != (RetryBudgetStats{Attempts: 10, Retries: 2, Available: 0}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if metrics.retries != 2 || metrics.exhausted != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestCircuit_RetryBudgetMinPerSecond(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_RetryBudgetMinPerSecond", Config{
+		Execution: ExecutionConfig{
+			RetryBudgetMinPerSecond: 1,
+			RetryBudgetWindow:       2 * time.Second,
+		},
+	})
+	if available := c.RetryBudget().Available; available != 2 {
+		t.Errorf("expected 2 retries without any attempts, got %d", available)
+	}
+}
+
+func TestCircuit_RetryBudgetDisabled(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_RetryBudgetDisabled", Config{
+		Execution: ExecutionConfig{
+			RetryBudgetPercent:      -1,
+			RetryBudgetMinPerSecond: -1,
+		},
+	})
+	for i := 0; i < 5; i++ {
+		err := c.Run(WithRetry(context.Background()), func(_ context.Context) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if available := c.RetryBudget().Available; available != -1 {
+		t.Errorf("expected no budget, got %d", available)
+	}
+}
+
+func TestCircuit_RetryBudgetNotNested(t *testing.T) {
+	nested := NewCircuitFromConfig("TestCircuit_RetryBudgetNotNested.nested", Config{
+		Execution: ExecutionConfig{
+			RetryBudgetPercent:      20,
+			RetryBudgetMinPerSecond: -1,
+		},
+	})
+	c := NewCircuitFromConfig("TestCircuit_RetryBudgetNotNested", Config{})
+	err := c.Run(WithRetry(context.Background()), func(ctx context.Context) error {
+		return nested.Run(ctx, func(_ context.Context) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("expected the nested circuit to not see a retry, got %v", err)
+	}
+	if stats := nested.RetryBudget(); stats.Attempts != 1 || stats.Retries != 0 {
+		t.Errorf("expected a first attempt in the nested circuit, got %+v", stats)
+	}
+}
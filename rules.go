@@ -0,0 +1,147 @@
+package circuit
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sync"
+)
+
+// ConfigRule applies Config to every circuit whose name matches Pattern
+type ConfigRule struct {
+	// Pattern is a path.Match pattern, like "payments.*".  "*" matches any run of characters except "/".
+	Pattern string
+	Config  Config
+}
+
+// ConfigRules configures circuits by name pattern, so thousands of similar circuits can be configured with a handful
+// of rules.  Add Configure to Manager.DefaultCircuitProperties to apply the rules when circuits are created, and call
+// Apply after SetRules to apply changed rules to circuits that already exist.  The zero value has no rules.
+type ConfigRules struct {
+	mu    sync.RWMutex
+	rules []ConfigRule
+}
+
+// SetRules replaces the rules.  When many rules match a circuit, the earlier rule wins.  It fails, without changing
+// the rules, if a pattern is malformed.
+func (r *ConfigRules) SetRules(rules []ConfigRule) error {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Pattern, err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append([]ConfigRule(nil), rules...)
+	return nil
+}
+
+// Rules returns a copy of the current rules
+func (r *ConfigRules) Rules() []ConfigRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ConfigRule(nil), r.rules...)
+}
+
+// Configure returns the merged config of every rule matching the circuit.  It is a CommandPropertiesConstructor.
+func (r *ConfigRules) Configure(circuitName string) Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret := Config{}
+	for _, rule := range r.rules {
+		// Patterns were checked by SetRules, so the error is always nil
+		if matched, _ := path.Match(rule.Pattern, circuitName); matched {
+			ret.Merge(rule.Config)
+		}
+	}
+	return ret
+}
+
+var _ CommandPropertiesConstructor = (&ConfigRules{}).Configure
+
+// Apply sets the values of the current rules on every matching circuit in m, and returns how many circuits changed.
+// Circuits that already have the rules' values are left alone, so applying the same rules twice changes nothing the
+// second time.  Fields the rules do not set keep their current value, so removing a rule does not undo it.  Metrics
+// collectors and the opener and closer factories are only read when a circuit is created, so rules cannot change them
+// on existing circuits.
+//
+// The rules win over every other source of config: a field the rules set replaces the circuit's value, even one set
+// through the admin API or by an AdaptiveTimeout, until that source sets it again.  Leave a field out of the rules to
+// let another source own it.
+func (r *ConfigRules) Apply(m *Manager) int {
+	updated := 0
+	for _, c := range m.AllCircuits() {
+		cfg := r.Configure(c.Name())
+		if isEmptyConfig(cfg) {
+			continue
+		}
+		current := c.Config()
+		cfg.Merge(current)
+		cfg.Metrics = current.Metrics
+		if sameValue(reflect.ValueOf(cfg), reflect.ValueOf(current)) {
+			continue
+		}
+		c.SetConfigFrom("rules", cfg)
+		updated++
+	}
+	return updated
+}
+
+// isEmptyConfig is true if no rule matched, so there is nothing to apply
+func isEmptyConfig(cfg Config) bool {
+	empty := true
+	setFields("", reflect.ValueOf(cfg), func(_ string, _ reflect.Value) {
+		empty = false
+	})
+	return empty
+}
+
+// sameValue is reflect.DeepEqual, except that functions are equal if they are the same function.  Configs hold
+// callbacks, which DeepEqual never finds equal.
+func sameValue(a reflect.Value, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Func:
+		return a.Pointer() == b.Pointer()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Elem().Type() == b.Elem().Type() && sameValue(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !sameValue(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameValue(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !sameValue(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+	case reflect.Ptr:
+		return a.Pointer() == b.Pointer()
+	default:
+		return a.Equal(b)
+	}
+}
@@ -0,0 +1,91 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigRules(t *testing.T) {
+	rules := &ConfigRules{}
+	err := rules.SetRules([]ConfigRule{
+		{Pattern: "payments.*", Config: Config{Execution: ExecutionConfig{Timeout: 500 * time.Millisecond}}},
+		{Pattern: "*", Config: Config{Execution: ExecutionConfig{Timeout: 2 * time.Second, MaxConcurrentRequests: 20}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Manager{
+		DefaultCircuitProperties: []CommandPropertiesConstructor{rules.Configure},
+	}
+	charge := m.MustCreateCircuit("payments.charge")
+	search := m.MustCreateCircuit("search")
+	if cfg := charge.Config(); cfg.Execution.Timeout != 500*time.Millisecond || cfg.Execution.MaxConcurrentRequests != 20 {
+		t.Errorf("expected the first matching rule to win, got %+v", cfg.Execution)
+	}
+	if cfg := search.Config(); cfg.Execution.Timeout != 2*time.Second {
+		t.Errorf("expected the catch all rule, got %+v", cfg.Execution)
+	}
+
+	err = rules.SetRules([]ConfigRule{
+		{Pattern: "payments.*", Config: Config{Execution: ExecutionConfig{Timeout: 300 * time.Millisecond}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated := rules.Apply(&m); updated != 1 {
+		t.Errorf("expected one circuit to match, got %d", updated)
+	}
+	if updated := rules.Apply(&m); updated != 0 {
+		t.Errorf("expected applying the same rules again to change nothing, got %d", updated)
+	}
+	if timeout := charge.config().Execution.Timeout; timeout != 300*time.Millisecond {
+		t.Errorf("expected the running circuit to use the new timeout, got %d", timeout)
+	}
+	if cfg := charge.Config(); cfg.Execution.MaxConcurrentRequests != 20 {
+		t.Errorf("expected fields the rules no longer set to keep their value, got %+v", cfg.Execution)
+	}
+	if cfg := search.Config(); cfg.Execution.Timeout != 2*time.Second {
+		t.Errorf("expected circuits no rule matches to be left alone, got %+v", cfg.Execution)
+	}
+
+	if err := rules.SetRules([]ConfigRule{{Pattern: "["}}); err == nil {
+		t.Error("expected a malformed pattern to fail")
+	}
+	if len(rules.Rules()) != 1 {
+		t.Error("expected a failed SetRules to keep the current rules")
+	}
+}
+
+func TestConfigRules_ApplyUnchanged(t *testing.T) {
+	rules := &ConfigRules{}
+	isBadRequest := func(err error) bool { return false }
+	err := rules.SetRules([]ConfigRule{
+		{Pattern: "*", Config: Config{
+			General: GeneralConfig{
+				CustomConfig: map[interface{}]interface{}{"team": "payments"},
+			},
+			Execution: ExecutionConfig{
+				Timeout:       time.Second,
+				SuccessErrors: []func(err error) bool{isBadRequest},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Manager{
+		DefaultCircuitProperties: []CommandPropertiesConstructor{rules.Configure},
+	}
+	m.MustCreateCircuit("charge")
+	if updated := rules.Apply(&m); updated != 0 {
+		t.Errorf("expected circuits created with the rules to already match them, got %d", updated)
+	}
+	c := m.MustCreateCircuit("refund")
+	c.SetConfigFrom("admin", Config{Execution: ExecutionConfig{Timeout: 2 * time.Second}})
+	if updated := rules.Apply(&m); updated != 1 {
+		t.Errorf("expected only the circuit set by the admin API to change, got %d", updated)
+	}
+	if timeout := c.config().Execution.Timeout; timeout != time.Second {
+		t.Errorf("expected the rules to win over the admin API, got %s", timeout)
+	}
+}
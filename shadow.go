@@ -0,0 +1,87 @@
+package circuit
+
+import (
+	"context"
+)
+
+// ExecuteShadow is Execute, but when FallbackConfig.Shadow is set it runs both runFunc and fallbackFunc for every
+// request and compares them.  runFunc always runs first, through the circuit, so the circuit tracks its health like
+// normal.  If runFunc works, or fails with an error configured as a success, fallbackFunc is then called with a nil
+// error as a shadow.  compare is called with both results, and should return false if they disagree.  Since results
+// are usually captured by closures, compare is where you check them.  Disagreements are counted in ShadowMismatches.
+//
+// The result of runFunc is returned, unless FallbackConfig.ShadowServesFallback is set.  Then the result of
+// fallbackFunc is returned instead.  Shadow fallbacks are not counted in fallback metrics, unless they are served.
+// Otherwise they are called like any fallback: in FallbackConfig.Circuit if set, with FallbackReasonShadow and without
+// this circuit's overrides.  They are skipped, and nothing is compared, if the fallback is disabled or at its
+// concurrency limit.
+//
+// Otherwise ExecuteShadow behaves like Execute: if runFunc fails, or the fallback is forced, the fallback runs like
+// it would in Execute and nothing is compared.
+func (c *Circuit) ExecuteShadow(ctx context.Context, runFunc func(context.Context) error, fallbackFunc func(context.Context, error) error,
+	compare func(runErr error, fallbackErr error) bool) error {
+	if c.isEmptyOrNil() || !c.config().Fallback.Shadow || fallbackFunc == nil {
+		return c.Execute(ctx, runFunc, fallbackFunc)
+	}
+	return c.execute(ctx, runFunc, fallbackFunc, true, compare)
+}
+
+// shadowFallback runs fallbackFunc as a shadow of a runFunc that returned runErr, and compares their results
+func (c *Circuit) shadowFallback(ctx context.Context, runErr error, fallbackFunc func(context.Context, error) error,
+	compare func(runErr error, fallbackErr error) bool, overhead *overheadTimer) error {
+	servesFallback := c.config().Fallback.ShadowServesFallback
+	var fallbackErr error
+	if servesFallback {
+		fallbackErr = c.fallback(ctx, nil, FallbackReasonShadow, fallbackFunc, overhead)
+	} else {
+		var ran bool
+		if fallbackErr, ran = c.unservedShadow(ctx, fallbackFunc, overhead); !ran {
+			return runErr
+		}
+	}
+	if compare != nil {
+		if compare(runErr, fallbackErr) {
+			c.shadowMatches.Add(1)
+		} else {
+			c.shadowMismatches.Add(1)
+		}
+	}
+	if servesFallback {
+		return fallbackErr
+	}
+	return runErr
+}
+
+// unservedShadow calls fallbackFunc like fallback does, but without fallback metrics.  ran is false, and nothing should
+// be compared, if the fallback is disabled or at its concurrency limit.
+func (c *Circuit) unservedShadow(ctx context.Context, fallbackFunc func(context.Context, error) error,
+	overhead *overheadTimer) (fallbackErr error, ran bool) {
+	if c.config().Fallback.Disabled {
+		return nil, false
+	}
+	currentFallbackCount := c.concurrentFallbacks.Add(1)
+	defer c.concurrentFallbacks.Add(-1)
+	if limit := c.config().Fallback.MaxConcurrentRequests; limit >= 0 && currentFallbackCount > limit {
+		return nil, false
+	}
+	fallbackStart := overhead.now()
+	fallbackErr = c.callFallback(fallbackContext(ctx, FallbackReasonShadow), nil, fallbackFunc)
+	overhead.exclude(fallbackStart)
+	return fallbackErr, true
+}
+
+// ShadowMatches returns how many times ExecuteShadow saw runFunc and the fallback agree
+func (c *Circuit) ShadowMatches() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.shadowMatches.Get()
+}
+
+// ShadowMismatches returns how many times ExecuteShadow saw runFunc and the fallback disagree
+func (c *Circuit) ShadowMismatches() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.shadowMismatches.Get()
+}
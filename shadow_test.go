@@ -0,0 +1,162 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cep21/circuit/v4/internal/testhelp"
+)
+
+func TestCircuit_ExecuteShadow(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadow", Config{
+		Fallback: FallbackConfig{
+			Shadow: true,
+		},
+	})
+	var fromRun, fromFallback int
+	run := func(_ context.Context) error {
+		fromRun = 1
+		return nil
+	}
+	fallback := func(_ context.Context, err error) error {
+		if err != nil {
+			return errors.New("shadow fallbacks should see a nil error")
+		}
+		fromFallback = 2
+		return nil
+	}
+	compare := func(runErr error, fallbackErr error) bool {
+		return runErr == fallbackErr && fromRun == fromFallback
+	}
+	if err := c.ExecuteShadow(context.Background(), run, fallback, compare); err != nil {
+		t.Fatal(err)
+	}
+	if fromFallback != 2 {
+		t.Error("expected the fallback to run as a shadow")
+	}
+	if c.ShadowMismatches() != 1 || c.ShadowMatches() != 0 {
+		t.Errorf("expected a mismatch, got %d/%d", c.ShadowMatches(), c.ShadowMismatches())
+	}
+
+	// Failing runs use the fallback normally
+	err := c.ExecuteShadow(context.Background(), testhelp.AlwaysFails, testhelp.AlwaysPassesFallback, compare)
+	if err != nil {
+		t.Errorf("expected the fallback result, got %v", err)
+	}
+	if c.ShadowMismatches() != 1 {
+		t.Error("failed runs should not be compared")
+	}
+}
+
+func TestCircuit_ExecuteShadowServesFallback(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadowServesFallback", Config{
+		Fallback: FallbackConfig{
+			Shadow:               true,
+			ShadowServesFallback: true,
+		},
+	})
+	errFallback := errors.New("fallback result")
+	err := c.ExecuteShadow(context.Background(), testhelp.AlwaysPasses, func(_ context.Context, _ error) error {
+		return errFallback
+	}, func(runErr error, fallbackErr error) bool {
+		return runErr == fallbackErr
+	})
+	if err != errFallback {
+		t.Errorf("expected the fallback result to be served, got %v", err)
+	}
+	if c.ShadowMismatches() != 1 {
+		t.Error("expected a mismatch")
+	}
+}
+
+func TestCircuit_ExecuteShadowDisabled(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadowDisabled", Config{})
+	err := c.ExecuteShadow(context.Background(), testhelp.AlwaysPasses, func(_ context.Context, _ error) error {
+		panic("fallback should not run without shadow mode")
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCircuit_ExecuteShadowForcedFallback(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadowForcedFallback", Config{
+		Fallback: FallbackConfig{
+			Shadow: true,
+		},
+	})
+	var fallbackErr error
+	err := c.ExecuteShadow(WithForcedFallback(context.Background()), func(_ context.Context) error {
+		panic("runFunc should not run when the fallback is forced")
+	}, func(_ context.Context, err error) error {
+		fallbackErr = err
+		return nil
+	}, func(_ error, _ error) bool {
+		panic("forced fallbacks should not be compared")
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if fallbackErr != ErrForcedFallback {
+		t.Errorf("expected the fallback to see ErrForcedFallback, got %v", fallbackErr)
+	}
+}
+
+func TestCircuit_ExecuteShadowComparesSuccessErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadowComparesSuccessErrors", Config{
+		Execution: ExecutionConfig{
+			SuccessErrors: []func(error) bool{
+				func(err error) bool { return err == errNotFound },
+			},
+		},
+		Fallback: FallbackConfig{
+			Shadow: true,
+		},
+	})
+	var comparedRunErr error
+	err := c.ExecuteShadow(context.Background(), func(_ context.Context) error {
+		return errNotFound
+	}, func(_ context.Context, _ error) error {
+		return errNotFound
+	}, func(runErr error, fallbackErr error) bool {
+		comparedRunErr = runErr
+		return runErr == fallbackErr
+	})
+	if err != errNotFound {
+		t.Errorf("expected the run result, got %v", err)
+	}
+	if comparedRunErr != errNotFound {
+		t.Errorf("expected compare to see the run result, got %v", comparedRunErr)
+	}
+	if c.ShadowMatches() != 1 {
+		t.Error("expected a match")
+	}
+}
+
+func TestCircuit_ExecuteShadowFallbackContext(t *testing.T) {
+	fallbackCircuit := NewCircuitFromConfig("TestCircuit_ExecuteShadowFallbackContext.fallback", Config{})
+	c := NewCircuitFromConfig("TestCircuit_ExecuteShadowFallbackContext", Config{
+		Fallback: FallbackConfig{
+			Shadow:  true,
+			Circuit: fallbackCircuit,
+		},
+	})
+	fallback := func(ctx context.Context, _ error) error {
+		if reason := FallbackReasonOf(ctx); reason != FallbackReasonShadow {
+			t.Errorf("expected the shadow reason, got %q", reason)
+		}
+		if IsRetry(ctx) || Cost(ctx) != 1 {
+			t.Error("expected the overrides of the circuit to be hidden from the shadow")
+		}
+		if ctx.Value(runningCircuitKey{}) != fallbackCircuit {
+			t.Error("expected the shadow to run in Fallback.Circuit")
+		}
+		return nil
+	}
+	ctx := WithCost(WithRetry(context.Background()), 3)
+	if err := c.ExecuteShadow(ctx, testhelp.AlwaysPasses, fallback, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+// SizeMetrics can optionally be implemented by RunMetrics that want to know how many bytes each run sent and
+// received.  Wrappers report sizes with ReportSize, so payload growth can be compared with latency.
+type SizeMetrics interface {
+	Size(ctx context.Context, now time.Time, sent int64, received int64)
+}
+
+var _ SizeMetrics = RunMetricsCollection(nil)
+
+// ReportSize tells the circuit's run collectors that a run sent and received this many bytes.  Call it once per run.
+// It can be called after Execute returns, for responses read after the run, like HTTP bodies.
+func (c *Circuit) ReportSize(ctx context.Context, sent int64, received int64) {
+	if c == nil {
+		return
+	}
+	c.CmdMetricCollector.Size(ctx, c.now(), sent, received)
+}
+
+// measuresSizes returns true if any collector implements SizeMetrics
+func (r RunMetricsCollection) measuresSizes() bool {
+	for _, c := range r {
+		if _, ok := c.(SizeMetrics); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportSize calls ReportSize on the circuit whose runFunc is running with ctx.  It does nothing if ctx is not from a
+// circuit's run.
+func ReportSize(ctx context.Context, sent int64, received int64) {
+	if c, ok := ctx.Value(runningCircuitKey{}).(*Circuit); ok {
+		c.ReportSize(ctx, sent, received)
+	}
+}
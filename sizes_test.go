@@ -0,0 +1,48 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sizeCollector struct {
+	RunMetrics
+	mu       sync.Mutex
+	sent     int64
+	received int64
+}
+
+func (s *sizeCollector) Size(_ context.Context, _ time.Time, sent int64, received int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent += sent
+	s.received += received
+}
+
+func TestReportSize(t *testing.T) {
+	collector := &sizeCollector{RunMetrics: neverOpensFactory()}
+	c := NewCircuitFromConfig("TestReportSize", Config{
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{collector},
+		},
+	})
+	err := c.Run(context.Background(), func(ctx context.Context) error {
+		ReportSize(ctx, 10, 20)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sizes known only after the run can be reported to the circuit directly
+	c.ReportSize(context.Background(), 1, 2)
+	// Outside a run, there is no circuit to report to
+	ReportSize(context.Background(), 100, 100)
+	var nilCircuit *Circuit
+	nilCircuit.ReportSize(context.Background(), 100, 100)
+
+	if collector.sent != 11 || collector.received != 22 {
+		t.Errorf("expected 11 bytes sent and 22 received, got %d and %d", collector.sent, collector.received)
+	}
+}
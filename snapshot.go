@@ -0,0 +1,122 @@
+package circuit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StateExporter is optionally implemented by ClosedToOpen and OpenToClosed implementations that can hand what they
+// learned about a dependency's health to another process.  See Manager.ExportState.
+type StateExporter interface {
+	// ExportState encodes the current state
+	ExportState() (json.RawMessage, error)
+	// ImportState replaces the current state with one from ExportState.  It is only called before the circuit is used.
+	ImportState(state json.RawMessage) error
+}
+
+// managerSnapshot is the format of Manager.ExportState
+type managerSnapshot struct {
+	Circuits map[string]circuitSnapshot
+}
+
+type circuitSnapshot struct {
+	Open   bool
+	Reason StateReason
+	Since  time.Time
+	Opener json.RawMessage `json:",omitempty"`
+	Closer json.RawMessage `json:",omitempty"`
+}
+
+// ExportState encodes whether each circuit is open, along with what the openers and closers that implement
+// StateExporter have learned, such as the hystrix rolling error counts.  Pass it to ImportState of a new process, for
+// example during blue/green deploys, so the new process does not start cold.
+func (h *Manager) ExportState() ([]byte, error) {
+	snapshot := managerSnapshot{
+		Circuits: make(map[string]circuitSnapshot),
+	}
+	for _, c := range h.AllCircuits() {
+		s, err := c.snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("circuit %s: %w", c.Name(), err)
+		}
+		snapshot.Circuits[c.Name()] = s
+	}
+	return json.Marshal(snapshot)
+}
+
+// ImportState restores state from ExportState into circuits with the same name.  Circuits that do not exist in this
+// manager are ignored.  Call it after creating the circuits, but before they are used.  Only counts and times are
+// restored: settings such as bucket sizes and sleep windows come from this process's config.  A snapshot missing
+// fields that the openers and closers need returns an error.
+func (h *Manager) ImportState(state []byte) error {
+	var snapshot managerSnapshot
+	if err := json.Unmarshal(state, &snapshot); err != nil {
+		return err
+	}
+	for name, s := range snapshot.Circuits {
+		c := h.GetCircuit(name)
+		if c == nil {
+			continue
+		}
+		if err := c.restore(s); err != nil {
+			return fmt.Errorf("circuit %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Circuit) snapshot() (circuitSnapshot, error) {
+	c.state.mu.Lock()
+	ret := circuitSnapshot{
+		Open:   c.isOpen.Get(),
+		Reason: c.state.reason,
+		Since:  c.state.since,
+	}
+	c.state.mu.Unlock()
+	var err error
+	if exporter, ok := c.ClosedToOpen.(StateExporter); ok {
+		if ret.Opener, err = exporter.ExportState(); err != nil {
+			return ret, err
+		}
+	}
+	if exporter, ok := c.OpenToClose.(StateExporter); ok {
+		if ret.Closer, err = exporter.ExportState(); err != nil {
+			return ret, err
+		}
+	}
+	return ret, nil
+}
+
+// restore sets the circuit's state, then imports the opener and closer state.  The import comes last because
+// Opened and Closed reset what openers and closers have learned.
+func (c *Circuit) restore(s circuitSnapshot) error {
+	ctx := context.Background()
+	now := c.now()
+	if s.Open != c.isOpen.Get() {
+		if s.Open {
+			c.CircuitMetricsCollector.Opened(ctx, now)
+		} else {
+			c.CircuitMetricsCollector.Closed(ctx, now)
+		}
+		c.isOpen.Set(s.Open)
+	}
+	c.state.mu.Lock()
+	c.state.reason = s.Reason
+	c.state.since = s.Since
+	c.state.halfOpen = false
+	c.state.moveToWithLock(c.phaseWithLock(), now)
+	c.state.mu.Unlock()
+	if exporter, ok := c.ClosedToOpen.(StateExporter); ok && len(s.Opener) != 0 {
+		if err := exporter.ImportState(s.Opener); err != nil {
+			return fmt.Errorf("opener: %w", err)
+		}
+	}
+	if exporter, ok := c.OpenToClose.(StateExporter); ok && len(s.Closer) != 0 {
+		if err := exporter.ImportState(s.Closer); err != nil {
+			return fmt.Errorf("closer: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManager_ExportState(t *testing.T) {
+	old := Manager{}
+	old.MustCreateCircuit("open").OpenCircuit(context.Background())
+	old.MustCreateCircuit("closed")
+	state, err := old.ExportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := Manager{}
+	open := replacement.MustCreateCircuit("open")
+	closed := replacement.MustCreateCircuit("closed", Config{
+		General: GeneralConfig{
+			InitialState: StateOpen,
+		},
+	})
+	if err := replacement.ImportState(state); err != nil {
+		t.Fatal(err)
+	}
+	if info := open.StateInfo(); !info.Open || info.Reason != StateReasonManual {
+		t.Errorf("expected the manually opened circuit to stay open, got %+v", info)
+	}
+	if closed.IsOpen() {
+		t.Error("expected the closed state to replace the initial state")
+	}
+}
@@ -0,0 +1,229 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StateReason is why a circuit is in its current state
+type StateReason string
+
+const (
+	// StateReasonInitial means the circuit has not opened or closed since it was created
+	StateReasonInitial StateReason = "initial"
+	// StateReasonThreshold means ClosedToOpen decided the circuit was unhealthy and opened it
+	StateReasonThreshold StateReason = "threshold"
+	// StateReasonRecovered means OpenToClosed decided the circuit was healthy again and closed it
+	StateReasonRecovered StateReason = "recovered"
+	// StateReasonManual means OpenCircuit or CloseCircuit was called
+	StateReasonManual StateReason = "manual"
+	// StateReasonForced means the circuit is held in its state by GeneralConfig.ForceOpen or GeneralConfig.ForcedClosed
+	StateReasonForced StateReason = "forced"
+	// StateReasonMaintenance means the circuit is open because of one of GeneralConfig.MaintenanceWindows
+	StateReasonMaintenance StateReason = "maintenance"
+)
+
+// StateInfo explains a circuit's current state.  See Circuit.StateInfo.
+type StateInfo struct {
+	// Open is true if the circuit is open
+	Open bool
+	// Reason is why the circuit entered this state
+	Reason StateReason
+	// Since is when the circuit entered this state.  It is the creation time of the circuit if it never changed state.
+	Since time.Time
+	// NextProbe is the soonest an open circuit will let a request through.  It is the latest of when OpenToClose allows
+	// a half open request, if it implements HalfOpenScheduler, the end of a Circuit.HoldOpen, and the end of the current
+	// maintenance window.  It is the zero time if the circuit is closed, or none of those apply.
+	NextProbe time.Time
+}
+
+// HalfOpenScheduler is optionally implemented by OpenToClosed implementations that allow half open requests on a
+// schedule.
+type HalfOpenScheduler interface {
+	// NextAllowed returns the soonest Allow may return true
+	NextAllowed() time.Time
+}
+
+// CircuitState is one of the states a circuit can be in
+type CircuitState string
+
+const (
+	// StateClosed means the circuit is letting requests through
+	StateClosed CircuitState = "closed"
+	// StateOpen means the circuit is short circuiting requests
+	StateOpen CircuitState = "open"
+	// StateHalfOpen means the circuit is open, but OpenToClose has let a request through to test if it can close
+	StateHalfOpen CircuitState = "half-open"
+)
+
+// TimeInState is how long a circuit has spent in each state.  See Circuit.TimeInState.
+type TimeInState struct {
+	// Current is the state the circuit is in now
+	Current CircuitState
+	// CurrentDuration is how long the circuit has been in its current state
+	CurrentDuration time.Duration
+	// Closed is the total time the circuit was closed, including the current state
+	Closed time.Duration
+	// Open is the total time the circuit was open and not half open, including the current state
+	Open time.Duration
+	// HalfOpen is the total time the circuit was half open, including the current state
+	HalfOpen time.Duration
+}
+
+// stateChange remembers why and when a circuit last changed state
+type stateChange struct {
+	reason      StateReason
+	since       time.Time
+	forcedSince time.Time
+
+	// halfOpen is set when an open circuit lets a request through, and reset when that request fails
+	halfOpen bool
+	// phase is the CircuitState the circuit is in, and phaseSince when it entered it
+	phase      CircuitState
+	phaseSince time.Time
+	// The total time spent in earlier phases
+	closedFor   time.Duration
+	openFor     time.Duration
+	halfOpenFor time.Duration
+
+	mu sync.Mutex
+}
+
+// moveToWithLock changes phase, adding the time spent in the previous phase to its total
+func (s *stateChange) moveToWithLock(phase CircuitState, now time.Time) {
+	if phase == s.phase {
+		return
+	}
+	if s.phase != "" {
+		s.addTimeWithLock(s.phase, now.Sub(s.phaseSince))
+	}
+	s.phase = phase
+	s.phaseSince = now
+}
+
+func (s *stateChange) addTimeWithLock(phase CircuitState, d time.Duration) {
+	switch phase {
+	case StateClosed:
+		s.closedFor += d
+	case StateOpen:
+		s.openFor += d
+	case StateHalfOpen:
+		s.halfOpenFor += d
+	}
+}
+
+// phaseWithLock returns the state the circuit is in, taking forced config into account
+func (c *Circuit) phaseWithLock() CircuitState {
+	if !c.IsOpen() {
+		return StateClosed
+	}
+	if c.state.halfOpen && !c.config().General.ForceOpen {
+		return StateHalfOpen
+	}
+	return StateOpen
+}
+
+// setState records that the circuit just opened or closed
+func (c *Circuit) setState(reason StateReason, now time.Time) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.reason = reason
+	c.state.since = now
+	c.state.halfOpen = false
+	c.state.moveToWithLock(c.phaseWithLock(), now)
+}
+
+// setInitialState opens a new circuit if GeneralConfig.InitialState asks for it
+func (c *Circuit) setInitialState(state CircuitState, now time.Time) {
+	if state != StateOpen && state != StateHalfOpen {
+		return
+	}
+	if c.config().General.ForcedClosed {
+		return
+	}
+	c.CircuitMetricsCollector.Opened(context.Background(), now)
+	c.isOpen.Set(true)
+	c.probeNext.Set(state == StateHalfOpen)
+	c.setState(StateReasonInitial, now)
+}
+
+// setHalfOpen records that an open circuit let a request through, or that the request failed
+func (c *Circuit) setHalfOpen(halfOpen bool, now time.Time) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.halfOpen = halfOpen
+	c.state.moveToWithLock(c.phaseWithLock(), now)
+}
+
+// TimeInState returns how long the circuit has spent closed, open, and half open since it was created.  Use this to
+// report how long a dependency was short circuited.
+func (c *Circuit) TimeInState() TimeInState {
+	if c == nil {
+		return TimeInState{}
+	}
+	now := c.now()
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.moveToWithLock(c.phaseWithLock(), now)
+	ret := TimeInState{
+		Current:         c.state.phase,
+		CurrentDuration: now.Sub(c.state.phaseSince),
+		Closed:          c.state.closedFor,
+		Open:            c.state.openFor,
+		HalfOpen:        c.state.halfOpenFor,
+	}
+	switch ret.Current {
+	case StateClosed:
+		ret.Closed += ret.CurrentDuration
+	case StateOpen:
+		ret.Open += ret.CurrentDuration
+	case StateHalfOpen:
+		ret.HalfOpen += ret.CurrentDuration
+	}
+	return ret
+}
+
+// StateInfo returns the current state of the circuit and why it is in that state
+func (c *Circuit) StateInfo() StateInfo {
+	if c == nil {
+		return StateInfo{}
+	}
+	return c.stateInfoAt(c.now())
+}
+
+func (c *Circuit) stateInfoAt(now time.Time) StateInfo {
+	c.state.mu.Lock()
+	ret := StateInfo{
+		Open:   c.IsOpen(),
+		Reason: c.state.reason,
+		Since:  c.state.since,
+	}
+	// A forced circuit stays forced after a maintenance window, so the window does not matter
+	cfg := c.config()
+	forced := cfg.General.ForceOpen || cfg.General.ForcedClosed
+	window, inMaintenance := c.maintenanceWindowAt(now)
+	if forced {
+		ret.Reason = StateReasonForced
+		ret.Since = c.state.forcedSince
+	} else if inMaintenance {
+		ret.Reason = StateReasonMaintenance
+		ret.Since = window.Start
+	}
+	c.state.mu.Unlock()
+	if !ret.Open {
+		return ret
+	}
+	if scheduler, ok := c.OpenToClose.(HalfOpenScheduler); ok {
+		ret.NextProbe = scheduler.NextAllowed()
+	}
+	if c.isHeldOpen(now) {
+		if heldUntil := time.Unix(0, c.holdOpenUntil.Get()); heldUntil.After(ret.NextProbe) {
+			ret.NextProbe = heldUntil
+		}
+	}
+	if inMaintenance && !forced && window.End.After(ret.NextProbe) {
+		ret.NextProbe = window.End
+	}
+	return ret
+}
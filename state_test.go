@@ -0,0 +1,265 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type scheduledCloser struct {
+	neverCloses
+	nextAllowed time.Time
+}
+
+func (s *scheduledCloser) Allow(_ context.Context, _ time.Time) bool {
+	return true
+}
+
+func (s *scheduledCloser) ShouldClose(_ context.Context, _ time.Time) bool {
+	return true
+}
+
+func (s *scheduledCloser) NextAllowed() time.Time {
+	return s.nextAllowed
+}
+
+type alwaysOpensOpener struct {
+	neverOpens
+}
+
+func (a alwaysOpensOpener) ShouldOpen(_ context.Context, _ time.Time) bool {
+	return true
+}
+
+func TestCircuit_StateInfo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextProbe := now.Add(time.Minute)
+	c := NewCircuitFromConfig("TestCircuit_StateInfo", Config{
+		General: GeneralConfig{
+			ClosedToOpenFactory: func() ClosedToOpen {
+				return alwaysOpensOpener{}
+			},
+			OpenToClosedFactory: func() OpenToClosed {
+				return &scheduledCloser{nextAllowed: nextProbe}
+			},
+			TimeKeeper: TimeKeeper{
+				Now: func() time.Time {
+					return now
+				},
+			},
+		},
+	})
+	expectState := func(t *testing.T, expected StateInfo) {
+		t.Helper()
+		if info := c.StateInfo(); info != expected {
+			t.Errorf("expected %+v, got %+v", expected, info)
+		}
+	}
+
+	expectState(t, StateInfo{Reason: StateReasonInitial, Since: now})
+
+	now = now.Add(time.Second)
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return errors.New("bad")
+	})
+	expectState(t, StateInfo{Open: true, Reason: StateReasonThreshold, Since: now, NextProbe: nextProbe})
+
+	now = now.Add(time.Second)
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	expectState(t, StateInfo{Reason: StateReasonRecovered, Since: now})
+
+	now = now.Add(time.Second)
+	cfg := c.Config()
+	cfg.General.ForceOpen = true
+	c.SetConfigThreadSafe(cfg)
+	expectState(t, StateInfo{Open: true, Reason: StateReasonForced, Since: now, NextProbe: nextProbe})
+
+	cfg.General.ForceOpen = false
+	c.SetConfigThreadSafe(cfg)
+	expectState(t, StateInfo{Reason: StateReasonRecovered, Since: now.Add(-time.Second)})
+
+	c.CloseCircuit(ctx)
+	if c.StateInfo().Reason != StateReasonRecovered {
+		t.Error("closing a closed circuit should not change its state")
+	}
+}
+
+func TestCircuit_StateInfoManual(t *testing.T) {
+	ctx := context.Background()
+	c := NewCircuitFromConfig("TestCircuit_StateInfoManual", Config{})
+	c.OpenCircuit(ctx)
+	if info := c.StateInfo(); !info.Open || info.Reason != StateReasonManual {
+		t.Errorf("expected a manually opened circuit, got %+v", info)
+	}
+	if !c.StateInfo().NextProbe.IsZero() {
+		t.Error("closers without a schedule should not report a next probe")
+	}
+	c.CloseCircuit(ctx)
+	if info := c.StateInfo(); info.Open || info.Reason != StateReasonManual {
+		t.Errorf("expected a manually closed circuit, got %+v", info)
+	}
+	var nilCircuit *Circuit
+	if nilCircuit.StateInfo() != (StateInfo{}) {
+		t.Error("expected an empty state from a nil circuit")
+	}
+}
+
+func TestCircuit_StateInfoNextProbe(t *testing.T) {
+	ctx := context.Background()
+	c := NewCircuitFromConfig("TestCircuit_StateInfoNextProbe", Config{})
+	until := time.Now().Add(time.Hour)
+	c.HoldOpen(ctx, until)
+	if info := c.StateInfo(); !info.NextProbe.Equal(until) {
+		t.Errorf("expected the next probe when the hold ends, got %+v", info)
+	}
+	c.CloseCircuit(ctx)
+
+	window := MaintenanceWindow{Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Minute)}
+	c = NewCircuitFromConfig("TestCircuit_StateInfoNextProbe", Config{
+		General: GeneralConfig{MaintenanceWindows: []MaintenanceWindow{window}},
+	})
+	if c.Execute(ctx, func(_ context.Context) error { return nil }, nil) == nil {
+		t.Fatal("expected the maintenance window to open the circuit")
+	}
+	if info := c.StateInfo(); info.Reason != StateReasonMaintenance || !info.NextProbe.Equal(window.End) {
+		t.Errorf("expected the next probe when the window ends, got %+v", info)
+	}
+}
+
+func TestCircuit_TimeInState(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	closer := &scheduledCloser{}
+	c := NewCircuitFromConfig("TestCircuit_TimeInState", Config{
+		General: GeneralConfig{
+			ClosedToOpenFactory: func() ClosedToOpen {
+				return alwaysOpensOpener{}
+			},
+			OpenToClosedFactory: func() OpenToClosed {
+				return closer
+			},
+			TimeKeeper: TimeKeeper{
+				Now: func() time.Time {
+					return now
+				},
+			},
+		},
+	})
+	fail := func(_ context.Context) error {
+		return errors.New("bad")
+	}
+
+	now = now.Add(time.Second)
+	// Closed for 1 second, then open
+	_ = c.Run(ctx, fail)
+	now = now.Add(2 * time.Second)
+	// Open for 2 seconds, then a half open request starts and takes 3 seconds to fail
+	_ = c.Run(ctx, func(_ context.Context) error {
+		now = now.Add(3 * time.Second)
+		return errors.New("bad")
+	})
+	now = now.Add(4 * time.Second)
+	expected := TimeInState{
+		Current:         StateOpen,
+		CurrentDuration: 4 * time.Second,
+		Closed:          time.Second,
+		Open:            6 * time.Second,
+		HalfOpen:        3 * time.Second,
+	}
+	if got := c.TimeInState(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	// A half open request that works closes the circuit
+	_ = c.Run(ctx, func(_ context.Context) error {
+		return nil
+	})
+	now = now.Add(5 * time.Second)
+	expected = TimeInState{
+		Current:         StateClosed,
+		CurrentDuration: 5 * time.Second,
+		Closed:          6 * time.Second,
+		Open:            6 * time.Second,
+		HalfOpen:        3 * time.Second,
+	}
+	if got := c.TimeInState(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	cfg := c.Config()
+	cfg.General.ForceOpen = true
+	c.SetConfigThreadSafe(cfg)
+	now = now.Add(time.Second)
+	if got := c.TimeInState(); got.Current != StateOpen || got.Open != 7*time.Second {
+		t.Errorf("expected forced open time to count as open, got %+v", got)
+	}
+}
+
+// closesButNeverAllows only closes circuits that let a request through some other way
+type closesButNeverAllows struct {
+	scheduledCloser
+}
+
+func (c *closesButNeverAllows) Allow(_ context.Context, _ time.Time) bool {
+	return false
+}
+
+func TestCircuit_InitialState(t *testing.T) {
+	ctx := context.Background()
+	newCircuit := func(state CircuitState) *Circuit {
+		return NewCircuitFromConfig("TestCircuit_InitialState", Config{
+			General: GeneralConfig{
+				InitialState: state,
+				OpenToClosedFactory: func() OpenToClosed {
+					return &closesButNeverAllows{}
+				},
+			},
+		})
+	}
+	pass := func(_ context.Context) error {
+		return nil
+	}
+	t.Run("closed", func(t *testing.T) {
+		c := newCircuit("")
+		if c.IsOpen() {
+			t.Error("expected circuits to start closed")
+		}
+	})
+	t.Run("open", func(t *testing.T) {
+		c := newCircuit(StateOpen)
+		if info := c.StateInfo(); !info.Open || info.Reason != StateReasonInitial {
+			t.Errorf("expected an initially open circuit, got %+v", info)
+		}
+		if err := c.Run(ctx, pass); err == nil {
+			t.Error("expected an open circuit to short circuit")
+		}
+	})
+	t.Run("half open", func(t *testing.T) {
+		c := newCircuit(StateHalfOpen)
+		if !c.IsOpen() {
+			t.Error("expected a half open circuit to be open")
+		}
+		if c.TimeInState().Current != StateOpen {
+			t.Error("expected the circuit to be open until a request tests it")
+		}
+		if err := c.Run(ctx, pass); err != nil {
+			t.Error("expected the first request through")
+		}
+		if c.IsOpen() {
+			t.Error("expected the working request to close the circuit")
+		}
+	})
+	t.Run("half open failure", func(t *testing.T) {
+		c := newCircuit(StateHalfOpen)
+		_ = c.Run(ctx, func(_ context.Context) error {
+			return errors.New("bad")
+		})
+		if err := c.Run(ctx, pass); err == nil {
+			t.Error("expected only the first request through")
+		}
+	})
+}
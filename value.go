@@ -0,0 +1,151 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ResultClassifier decides how a value returned without an error counts for the circuit.  OutcomeFailure and
+// OutcomeTimeout count it as a failure, and OutcomeBadRequest as a bad request.  Anything else is a success.  Set it
+// as ExecutionConfig.ResultClassifier.
+type ResultClassifier[T any] func(v T) OutcomeType
+
+// ResultError is the error runFunc fails with, as far as the circuit and the fallback can tell, when
+// ExecutionConfig.ResultClassifier does not count a value as a success
+type ResultError struct {
+	// Outcome is what the classifier returned
+	Outcome OutcomeType
+}
+
+func (r *ResultError) Error() string {
+	return "result classified as " + string(r.Outcome)
+}
+
+// BadRequest is true if the value was classified as a bad request
+func (r *ResultError) BadRequest() bool {
+	return r.Outcome == OutcomeBadRequest
+}
+
+var _ BadRequest = &ResultError{}
+
+// resultClassifier returns c's ResultClassifier for values of type T, or nil
+func resultClassifier[T any](c *Circuit) ResultClassifier[T] {
+	if c == nil {
+		return nil
+	}
+	switch classifier := c.config().Execution.ResultClassifier.(type) {
+	case ResultClassifier[T]:
+		return classifier
+	case func(T) OutcomeType:
+		return classifier
+	}
+	return nil
+}
+
+// ExecuteValue is Circuit.Execute for functions that return a value.  It returns the value of runFunc, or of
+// fallbackFunc if runFunc failed and fallbackFunc worked.  The value is the zero value of T when the error is not
+// nil.  fallbackFunc may be nil.  See FallbackValue, FallbackEmpty and FallbackFromCache for common fallbacks.
+//
+// If ExecutionConfig.ResultClassifier does not count a value as a success, the circuit and fallbackFunc see a
+// *ResultError.  The value is still returned, with no error, if fallbackFunc is nil or fails.
+func ExecuteValue[T any](ctx context.Context, c *Circuit, runFunc func(context.Context) (T, error), fallbackFunc func(context.Context, error) (T, error)) (T, error) {
+	var ret T
+	var classified bool
+	classify := resultClassifier[T](c)
+	var fallback func(context.Context, error) error
+	if fallbackFunc != nil {
+		fallback = func(ctx context.Context, err error) error {
+			v, fallbackErr := fallbackFunc(ctx, err)
+			if fallbackErr != nil {
+				return fallbackErr
+			}
+			ret = v
+			return nil
+		}
+	}
+	err := c.Execute(ctx, func(ctx context.Context) error {
+		v, runErr := runFunc(ctx)
+		if runErr != nil {
+			return runErr
+		}
+		ret = v
+		if classify != nil {
+			switch outcome := classify(v); outcome {
+			case OutcomeFailure, OutcomeTimeout, OutcomeBadRequest:
+				classified = true
+				return &ResultError{Outcome: outcome}
+			}
+		}
+		return nil
+	}, fallback)
+	if err != nil {
+		if classified {
+			// The dependency answered, and its answer is better than no answer
+			return ret, nil
+		}
+		var zero T
+		return zero, err
+	}
+	return ret, nil
+}
+
+// FallbackValue is a fallback that always returns v
+func FallbackValue[T any](v T) func(context.Context, error) (T, error) {
+	return func(_ context.Context, _ error) (T, error) {
+		return v, nil
+	}
+}
+
+// FallbackEmpty is a fallback that always returns the zero value of T, like an empty list of recommendations
+func FallbackEmpty[T any]() func(context.Context, error) (T, error) {
+	return FallbackValue(*new(T))
+}
+
+// Cache is anything values can be looked up in by key, like an LRU of the last good answers.  See FallbackFromCache.
+type Cache[K comparable, T any] interface {
+	Get(key K) (T, bool)
+}
+
+// FallbackFromCache is a fallback that returns the value cached for the key of the run.  keyFn picks the key from the
+// run's context.  If nothing is cached, the fallback fails with the error runFunc failed with.  The cache is not
+// filled for you: store values in it when runs work.
+func FallbackFromCache[K comparable, T any](cache Cache[K, T], keyFn func(context.Context) K) func(context.Context, error) (T, error) {
+	return func(ctx context.Context, err error) (T, error) {
+		if v, exists := cache.Get(keyFn(ctx)); exists {
+			return v, nil
+		}
+		var zero T
+		return zero, err
+	}
+}
+
+// MemoizeFallback wraps a fallback so that, while the circuit is open, its result is reused for window instead of
+// computed for every short circuited request.  Use it for expensive fallbacks whose result does not depend on the
+// request, like a default page built from many sources.  Failures that are not an open circuit still run fallbackFunc
+// each time, and fallback errors are never reused.  Concurrent requests wait for one computation instead of each
+// starting their own.
+func MemoizeFallback[T any](window time.Duration, fallbackFunc func(context.Context, error) (T, error)) func(context.Context, error) (T, error) {
+	var mu sync.Mutex
+	var memo T
+	var expires time.Time
+	return func(ctx context.Context, err error) (T, error) {
+		if !errors.Is(err, ErrCircuitOpen) {
+			return fallbackFunc(ctx, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if now.Before(expires) {
+			return memo, nil
+		}
+		v, fallbackErr := fallbackFunc(ctx, err)
+		if fallbackErr != nil {
+			return v, fallbackErr
+		}
+		memo = v
+		expires = now.Add(window)
+		return v, nil
+	}
+}
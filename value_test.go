@@ -0,0 +1,162 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type valueCache map[string]string
+
+func (v valueCache) Get(key string) (string, bool) {
+	ret, exists := v[key]
+	return ret, exists
+}
+
+func TestExecuteValue(t *testing.T) {
+	c := NewCircuitFromConfig("TestExecuteValue", Config{})
+	failure := errors.New("failure")
+	fails := func(_ context.Context) (string, error) {
+		return "", failure
+	}
+	works := func(_ context.Context) (string, error) {
+		return "run", nil
+	}
+	cache := valueCache{"key": "cached"}
+	keyFn := func(_ context.Context) string {
+		return "key"
+	}
+	missingKeyFn := func(_ context.Context) string {
+		return "missing"
+	}
+	testCases := []struct {
+		name        string
+		runFunc     func(context.Context) (string, error)
+		fallback    func(context.Context, error) (string, error)
+		expected    string
+		expectedErr error
+	}{
+		{name: "run works", runFunc: works, fallback: FallbackValue("fallback"), expected: "run"},
+		{name: "no fallback", runFunc: fails, expectedErr: failure},
+		{name: "value", runFunc: fails, fallback: FallbackValue("fallback"), expected: "fallback"},
+		{name: "empty", runFunc: fails, fallback: FallbackEmpty[string](), expected: ""},
+		{name: "cached", runFunc: fails, fallback: FallbackFromCache[string, string](cache, keyFn), expected: "cached"},
+		{name: "not cached", runFunc: fails, fallback: FallbackFromCache[string, string](cache, missingKeyFn), expectedErr: failure},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := ExecuteValue(context.Background(), c, tc.runFunc, tc.fallback)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if ret != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, ret)
+			}
+		})
+	}
+}
+
+type searchResponse struct {
+	Hits    []string
+	Partial bool
+}
+
+func TestExecuteValue_ResultClassifier(t *testing.T) {
+	runs := &recordedRuns{}
+	c := NewCircuitFromConfig("TestExecuteValue_ResultClassifier", Config{
+		Execution: ExecutionConfig{
+			ResultClassifier: ResultClassifier[searchResponse](func(v searchResponse) OutcomeType {
+				if v.Partial {
+					return OutcomeFailure
+				}
+				return OutcomeSuccess
+			}),
+		},
+		Metrics: MetricsCollectors{
+			Run: []RunMetrics{runs},
+		},
+	})
+	partial := func(_ context.Context) (searchResponse, error) {
+		return searchResponse{Hits: []string{"a"}, Partial: true}, nil
+	}
+	ret, err := ExecuteValue(context.Background(), c, partial, nil)
+	if err != nil || len(ret.Hits) != 1 {
+		t.Errorf("expected the partial response without a fallback, got %v %v", ret, err)
+	}
+	var fallbackErr error
+	ret, err = ExecuteValue(context.Background(), c, partial, func(_ context.Context, err error) (searchResponse, error) {
+		fallbackErr = err
+		return searchResponse{Hits: []string{"cached"}}, nil
+	})
+	if err != nil || ret.Hits[0] != "cached" {
+		t.Errorf("expected the fallback's response, got %v %v", ret, err)
+	}
+	var resultErr *ResultError
+	if !errors.As(fallbackErr, &resultErr) || resultErr.Outcome != OutcomeFailure {
+		t.Errorf("expected the fallback to see a ResultError, got %v", fallbackErr)
+	}
+	// Values of other types are not classified
+	if _, err := ExecuteValue(context.Background(), c, func(_ context.Context) (string, error) { return "", nil }, nil); err != nil {
+		t.Error(err)
+	}
+	if runs.failures != 2 || runs.successes != 1 {
+		t.Errorf("expected two failures and a success, got %+v", runs)
+	}
+}
+
+func TestMemoizeFallback(t *testing.T) {
+	c := NewCircuitFromConfig("TestMemoizeFallback", Config{})
+	calls := 0
+	fallback := MemoizeFallback(time.Hour, func(_ context.Context, _ error) (int, error) {
+		calls++
+		return calls, nil
+	})
+	failure := errors.New("failure")
+	fails := func(_ context.Context) (int, error) {
+		return 0, failure
+	}
+	ctx := context.Background()
+	if v, _ := ExecuteValue(ctx, c, fails, fallback); v != 1 {
+		t.Errorf("expected the first fallback value, got %d", v)
+	}
+	if v, _ := ExecuteValue(ctx, c, fails, fallback); v != 2 {
+		t.Errorf("expected failures of a closed circuit to run the fallback, got %d", v)
+	}
+	c.OpenCircuit(ctx)
+	for i := 0; i < 10; i++ {
+		if v, err := ExecuteValue(ctx, c, fails, fallback); v != 3 || err != nil {
+			t.Fatalf("expected the memoized fallback value, got %d %v", v, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected the fallback to run once while open, got %d calls", calls)
+	}
+}
+
+func TestMemoizeFallback_expires(t *testing.T) {
+	c := NewCircuitFromConfig("TestMemoizeFallback_expires", Config{})
+	c.OpenCircuit(context.Background())
+	calls := 0
+	fallback := MemoizeFallback(time.Millisecond, func(_ context.Context, _ error) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("fallback failed")
+		}
+		return calls, nil
+	})
+	run := func(_ context.Context) (int, error) {
+		return 0, nil
+	}
+	if _, err := ExecuteValue(context.Background(), c, run, fallback); err == nil {
+		t.Fatal("expected the fallback error")
+	}
+	if v, _ := ExecuteValue(context.Background(), c, run, fallback); v != 2 {
+		t.Errorf("expected fallback errors not to be memoized, got %d", v)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if v, _ := ExecuteValue(context.Background(), c, run, fallback); v != 3 {
+		t.Errorf("expected the memoized value to expire, got %d", v)
+	}
+}
@@ -0,0 +1,80 @@
+package circuit
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// StuckExecution describes a run that did not return long after its timeout.  See ExecutionConfig.OnStuckExecution.
+type StuckExecution struct {
+	// CircuitName is the name of the circuit the run is inside
+	CircuitName string
+	// Start is when the run started
+	Start time.Time
+	// Timeout is the run's timeout
+	Timeout time.Duration
+	// Elapsed is how long the run had been going when it was reported
+	Elapsed time.Duration
+	// Stack is the stack of the goroutine running runFunc when it was reported.  It is empty if the run returned while
+	// the stack was captured.
+	Stack []byte
+}
+
+// watchForStuckRun reports the current goroutine to ExecutionConfig.OnStuckExecution if the run is not over by
+// StuckTimeoutMultiple timeouts after startTime.  The returned timer must be stopped when the run ends.  It is nil if
+// TimeKeeper.AfterFunc returns no timer, like fake clocks do.
+func (c *Circuit) watchForStuckRun(startTime time.Time, timeout time.Duration) *time.Timer {
+	goroutineID := currentGoroutineID()
+	cfg := c.config()
+	onStuck := cfg.Execution.OnStuckExecution
+	return cfg.General.TimeKeeper.AfterFunc(timeout*time.Duration(cfg.Execution.StuckTimeoutMultiple), func() {
+		onStuck(StuckExecution{
+			CircuitName: c.Name(),
+			Start:       startTime,
+			Timeout:     timeout,
+			Elapsed:     c.now().Sub(startTime),
+			Stack:       goroutineStack(goroutineID),
+		})
+	})
+}
+
+// currentGoroutineID parses the ID of the current goroutine from the first line of its stack, which looks like
+// "goroutine 18 [running]:"
+func currentGoroutineID() string {
+	var buf [64]byte
+	line := buf[:runtime.Stack(buf[:], false)]
+	line = bytes.TrimPrefix(line, []byte("goroutine "))
+	if idx := bytes.IndexByte(line, ' '); idx >= 0 {
+		line = line[:idx]
+	}
+	if _, err := strconv.ParseUint(string(line), 10, 64); err != nil {
+		return ""
+	}
+	return string(line)
+}
+
+// goroutineStack returns the stack of the goroutine with goroutineID, or nil if it does not exist anymore.  Go has no
+// way to ask for a single other goroutine's stack, so this captures every stack and finds the right one.
+func goroutineStack(goroutineID string) []byte {
+	if goroutineID == "" {
+		return nil
+	}
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	header := []byte("goroutine " + goroutineID + " [")
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return stack
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package circuit
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCircuit_OnStuckExecution(t *testing.T) {
+	reports := make(chan StuckExecution, 1)
+	c := NewCircuitFromConfig("TestCircuit_OnStuckExecution", Config{
+		Execution: ExecutionConfig{
+			Timeout:              time.Millisecond,
+			StuckTimeoutMultiple: 2,
+			OnStuckExecution: func(stuck StuckExecution) {
+				reports <- stuck
+			},
+		},
+	})
+	t.Run("stuck", func(t *testing.T) {
+		var stuck StuckExecution
+		_ = c.Execute(context.Background(), func(_ context.Context) error {
+			// Ignore the context, like code that cannot be canceled
+			stuck = <-reports
+			return nil
+		}, nil)
+		if stuck.CircuitName != "TestCircuit_OnStuckExecution" {
+			t.Errorf("unexpected circuit name %s", stuck.CircuitName)
+		}
+		if stuck.Timeout != time.Millisecond {
+			t.Errorf("unexpected timeout %s", stuck.Timeout)
+		}
+		if stuck.Elapsed < 2*time.Millisecond {
+			t.Errorf("reported too early: %s", stuck.Elapsed)
+		}
+		if !bytes.Contains(stuck.Stack, []byte("TestCircuit_OnStuckExecution")) {
+			t.Errorf("expected the stack of the stuck run, got\n%s", stuck.Stack)
+		}
+	})
+	t.Run("not stuck", func(t *testing.T) {
+		_ = c.Execute(context.Background(), func(_ context.Context) error {
+			return nil
+		}, nil)
+		select {
+		case stuck := <-reports:
+			t.Errorf("did not expect a report: %v", stuck)
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
+
+func TestCircuit_OnStuckExecutionWithoutTimer(t *testing.T) {
+	c := NewCircuitFromConfig("TestCircuit_OnStuckExecutionWithoutTimer", Config{
+		General: GeneralConfig{
+			TimeKeeper: TimeKeeper{
+				Now: time.Now,
+				AfterFunc: func(_ time.Duration, _ func()) *time.Timer {
+					return nil
+				},
+			},
+		},
+		Execution: ExecutionConfig{
+			Timeout:          time.Second,
+			OnStuckExecution: func(_ StuckExecution) {},
+		},
+	})
+	if err := c.Run(context.Background(), func(_ context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_goroutineStack(t *testing.T) {
+	id := currentGoroutineID()
+	if id == "" {
+		t.Fatal("expected a goroutine ID")
+	}
+	if !bytes.Contains(goroutineStack(id), []byte("Test_goroutineStack")) {
+		t.Error("expected to find the current goroutine")
+	}
+	if goroutineStack("") != nil {
+		t.Error("expected no stack without an ID")
+	}
+}